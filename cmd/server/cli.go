@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/baltop/commet/internal/config"
+	"github.com/baltop/commet/internal/database"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/baltop/commet/internal/services"
+)
+
+// runCommand 서버 대신 실행하는 하위 명령
+//
+//	commet export -user admin@example.com -dashboard 3 -o board.json
+//	commet import -user admin@example.com -file board.json -dry-run -on-conflict rename
+//	commet retention -dry-run
+func runCommand(name string, args []string) error {
+	switch name {
+	case "export":
+		return exportCommand(args)
+	case "import":
+		return importCommand(args)
+	case "retention":
+		return retentionCommand(args)
+	}
+	return fmt.Errorf("unknown command %q (export, import, retention)", name)
+}
+
+func exportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	user := fs.String("user", "", "대시보드를 볼 수 있는 사용자 이메일")
+	dashboardID := fs.Uint("dashboard", 0, "내보낼 대시보드 ID")
+	out := fs.String("o", "-", "저장할 파일 (- 이면 표준 출력)")
+	kpis := fs.Bool("kpis", true, "KPI 정의 포함")
+	_ = fs.Parse(args)
+	if *user == "" || *dashboardID == 0 {
+		fs.Usage()
+		return errors.New("-user and -dashboard are required")
+	}
+
+	svc, userID, err := openTransferService(*user)
+	if err != nil {
+		return err
+	}
+	doc, err := svc.Export(*dashboardID, userID, *kpis)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func importCommand(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	user := fs.String("user", "", "대시보드를 소유할 사용자 이메일")
+	file := fs.String("file", "-", "가져올 파일 (- 이면 표준 입력)")
+	dryRun := fs.Bool("dry-run", false, "저장하지 않고 결과만 출력")
+	onConflict := fs.String("on-conflict", "error", "이미 있을 때: error, skip, overwrite, rename")
+	name := fs.String("name", "", "문서의 대시보드 이름 대신 사용할 이름")
+	_ = fs.Parse(args)
+	if *user == "" {
+		fs.Usage()
+		return errors.New("-user is required")
+	}
+	mode, err := services.ParseConflictMode(*onConflict)
+	if err != nil {
+		return err
+	}
+
+	r := io.Reader(os.Stdin)
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	doc, err := services.ParseDashboardDocument(r)
+	if err != nil {
+		return err
+	}
+
+	svc, userID, err := openTransferService(*user)
+	if err != nil {
+		return err
+	}
+	result, err := svc.Import(userID, doc, services.ImportOptions{DryRun: *dryRun, Conflict: mode, Name: *name, ManageKPIs: true})
+	if result != nil {
+		printImportResult(os.Stdout, result)
+	}
+	return err
+}
+
+func printImportResult(w io.Writer, r *services.ImportResult) {
+	if r.DryRun {
+		fmt.Fprintln(w, "dry run: nothing was saved")
+	}
+	fmt.Fprintf(w, "dashboard  %-10s %s (%d widgets)", r.Dashboard.Action, r.Dashboard.Name, r.Widgets)
+	if r.DashboardID != 0 {
+		fmt.Fprintf(w, " id=%d", r.DashboardID)
+	}
+	fmt.Fprintln(w)
+	for _, k := range r.KPIs {
+		fmt.Fprintf(w, "kpi        %-10s %s\n", k.Action, k.Name)
+	}
+}
+
+// retentionCommand 보관 정책을 지금 한 번 실행 (삭제 한도 없이 끝까지)
+func retentionCommand(args []string) error {
+	fs := flag.NewFlagSet("retention", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "지우지 않고 지울 행 수만 출력")
+	_ = fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := database.Connect(&cfg.Database)
+	if err != nil {
+		return err
+	}
+	if err := database.Migrate(); err != nil {
+		return err
+	}
+	svc := services.NewRetentionService(repository.NewRetentionRepository(db), cfg.Rollup.Enabled)
+
+	now := time.Now()
+	for {
+		report, err := svc.Enforce(context.Background(), now, *dryRun)
+		if err != nil {
+			return err
+		}
+		printRetentionReport(os.Stdout, report)
+		if !report.Incomplete {
+			return nil
+		}
+	}
+}
+
+func printRetentionReport(w io.Writer, r *services.RetentionReport) {
+	verb := "deleted"
+	if r.DryRun {
+		fmt.Fprintln(w, "dry run: nothing was deleted")
+		verb = "would delete"
+	}
+	for _, item := range r.Items {
+		category := item.Category
+		if item.Default {
+			category = "(default)"
+		}
+		fmt.Fprintf(w, "%-20s %-10s before %s  %s %d rows\n", category, item.Data, item.Before.Format(time.RFC3339), verb, item.Rows)
+	}
+	if len(r.Items) == 0 {
+		fmt.Fprintln(w, "no retention policies")
+	}
+}
+
+// openTransferService 서버와 같은 설정으로 DB에 연결하고 사용자를 찾음
+func openTransferService(email string) (*services.DashboardTransferService, uint, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, 0, err
+	}
+	db, err := database.Connect(&cfg.Database)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := database.Migrate(); err != nil {
+		return nil, 0, err
+	}
+
+	user, err := repository.NewUserRepository(db).FindByEmail(email)
+	if err != nil {
+		return nil, 0, fmt.Errorf("user %s: %w", email, err)
+	}
+	layoutRepo := repository.NewLayoutRepository(db)
+	svc := services.NewDashboardTransferService(services.NewLayoutService(layoutRepo), layoutRepo, repository.NewKPIRepository(db))
+	return svc, user.ID, nil
+}
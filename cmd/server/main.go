@@ -1,23 +1,39 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"html/template"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // 컨테이너에 zoneinfo가 없어도 사용자 시간대 처리
 
 	"github.com/baltop/commet/internal/config"
 	"github.com/baltop/commet/internal/database"
 	"github.com/baltop/commet/internal/handlers"
 	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/models"
 	"github.com/baltop/commet/internal/repository"
 	"github.com/baltop/commet/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// 하위 명령(export, import)은 서버를 띄우지 않고 실행 후 종료
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// 설정 로드
 	cfg, err := config.Load()
 	if err != nil {
@@ -27,6 +43,18 @@ func main() {
 	// Gin 모드 설정
 	gin.SetMode(cfg.Server.Mode)
 
+	// SIGINT/SIGTERM을 받으면 ctx가 취소되고 백그라운드 작업과 서버를 정리
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var workers sync.WaitGroup
+	runWorker := func(fn func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			fn(ctx)
+		}()
+	}
+
 	// 데이터베이스 연결
 	db, err := database.Connect(&cfg.Database)
 	if err != nil {
@@ -42,50 +70,186 @@ func main() {
 	if err := database.SeedSampleData(); err != nil {
 		log.Printf("Warning: Failed to seed sample data: %v", err)
 	}
+	if err := database.SeedKPIs(); err != nil {
+		log.Printf("Warning: Failed to seed KPI definitions: %v", err)
+	}
 
 	// Repository 초기화
 	userRepo := repository.NewUserRepository(db)
 	dashboardRepo := repository.NewDashboardRepository(db)
+	kpiRepo := repository.NewKPIRepository(db)
+	layoutRepo := repository.NewLayoutRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	shareRepo := repository.NewShareRepository(db)
+	annotationRepo := repository.NewAnnotationRepository(db)
+	anomalyRepo := repository.NewAnomalyRepository(db)
+	rollupRepo := repository.NewRollupRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
 
 	// Service 초기화
 	authService := services.NewAuthService(userRepo, cfg.JWT)
-	dashboardService := services.NewDashboardService(dashboardRepo)
+	eventHub := services.NewEventHub(0)
+	dashboardService := services.NewDashboardService(dashboardRepo, eventHub)
+	if cfg.Realtime.Feed == "postgres" {
+		// 다른 인스턴스에서 저장된 데이터도 받도록 NOTIFY 피드로 발행
+		dashboardService.UseChangeFeed()
+		changeFeed := services.NewChangeFeed(cfg.Database.DSN(), database.DataChangeChannel, dashboardRepo, eventHub)
+		runWorker(changeFeed.Run)
+	}
+	// 시계열 차트(화면, 이미지, 보고서)에 주석 표시
+	dashboardService.UseAnnotations(annotationRepo)
+	dashboardService.UseAnomalies(anomalyRepo)
+	exportService := services.NewExportService(dashboardRepo)
+	kpiService := services.NewKPIService(kpiRepo, dashboardRepo)
+	layoutService := services.NewLayoutService(layoutRepo)
+	notificationService := services.NewNotificationService(notificationRepo)
+	orgService := services.NewOrganizationService(orgRepo)
+	if cfg.Share.Secret == "" {
+		log.Printf("Warning: SHARE_SECRET and JWT_SECRET are empty; public share links stop working after restart")
+	}
+	shareService := services.NewShareService(shareRepo, layoutService, cfg.Share.Secret)
+	transferService := services.NewDashboardTransferService(layoutService, layoutRepo, kpiRepo)
+	annotationService := services.NewAnnotationService(annotationRepo, layoutService)
+
+	// 백그라운드 작업 큐 (메일 발송, 웹훅 전송 등). 큐마다 인스턴스당 동시 실행 수 제한
+	jobQueue := services.NewJobQueue(jobRepo, cfg.Jobs.DrainTimeout)
+	jobQueue.AddQueue("default", services.QueueOptions{Concurrency: 4})
+	jobQueue.AddQueue("email", services.QueueOptions{Concurrency: 2})
+	jobQueue.AddQueue("webhooks", services.QueueOptions{Concurrency: 8})
+	jobQueue.AddQueue("reports", services.QueueOptions{Concurrency: 2})
+
+	// 이상치 탐지: 수집된 데이터 포인트를 작업 큐에서 이전 기록과 비교
+	anomalyMethod, err := services.ParseAnomalyMethod(cfg.Anomaly.Method)
+	if err != nil {
+		log.Fatalf("Invalid ANOMALY_METHOD %q", cfg.Anomaly.Method)
+	}
+	anomalyService := services.NewAnomalyService(anomalyRepo, dashboardRepo, jobQueue, services.AnomalyOptions{
+		Method:    anomalyMethod,
+		Threshold: cfg.Anomaly.Threshold,
+		Window:    cfg.Anomaly.Window,
+	})
+
+	// 롤업: 새 데이터를 주기적으로 시간·일 테이블에 모으고, 시계열 조회는 온전한 구간을 롤업에서 읽음
+	if cfg.Rollup.Enabled {
+		services.NewRollupService(rollupRepo, jobQueue, cfg.Rollup.Interval)
+		dashboardRepo.UseRollups()
+	}
+	// 보관 정책: 기간이 지난 원본·롤업을 나눠 지움 (롤업을 쓰면 반영 전 원본은 남김)
+	retentionService := services.NewRetentionService(retentionRepo, cfg.Rollup.Enabled)
+	retentionService.Schedule(jobQueue, cfg.Retention.Interval)
+
+	// 로그인 없는 수집 엔드포인트는 INGEST_TOKEN으로 인증
+	if (cfg.Prometheus.RulesFile != "" || cfg.OTLP.Enabled) && cfg.Ingest.Token == "" {
+		log.Fatalf("PROMETHEUS_RULES_FILE and OTLP_ENABLED require INGEST_TOKEN")
+	}
+	// Prometheus remote-write: 규칙 파일이 있을 때만 수신
+	var prometheusService *services.PrometheusService
+	if cfg.Prometheus.RulesFile != "" {
+		mapper, err := services.LoadPrometheusRules(cfg.Prometheus.RulesFile)
+		if err != nil {
+			log.Fatalf("Failed to load Prometheus rules: %v", err)
+		}
+		log.Printf("Prometheus remote write enabled with %d mapping rules", mapper.Len())
+		prometheusService = services.NewPrometheusService(dashboardService, mapper)
+	}
+
+	// 조직 웹훅: 이벤트마다 전송 기록을 만들고 작업 큐에서 서명해 보냄
+	webhookService := services.NewWebhookService(webhookRepo, orgRepo, jobQueue, nil)
+	// 가입자 정보는 관리자가 소유한 조직에만 보냄
+	webhookService.UseAdmins(cfg.Admin.Emails)
+	authService.OnRegistered(func(user *models.User) {
+		if err := webhookService.EmitToAdmins(models.EventUserRegistered, user.ToResponse()); err != nil {
+			log.Printf("Webhook emit %s failed: %v", models.EventUserRegistered, err)
+		}
+	})
+	dashboardService.OnIngested(func(data []models.DashboardData) {
+		payload := gin.H{"count": len(data), "points": data}
+		if err := webhookService.Emit(models.EventMetricIngested, nil, payload); err != nil {
+			log.Printf("Webhook emit %s failed: %v", models.EventMetricIngested, err)
+		}
+		if err := anomalyService.Enqueue(data); err != nil {
+			log.Printf("Anomaly detection enqueue failed: %v", err)
+		}
+	})
+
+	// StatsD·InfluxDB 라인 프로토콜 (UDP/TCP): 주기마다 모은 값을 저장하고 종료 시 남은 값도 저장
+	if cfg.Metrics.UDPAddr != "" || cfg.Metrics.TCPAddr != "" {
+		metricsListener := services.NewMetricsListener(dashboardService, services.MetricsListenerOptions{
+			UDPAddr:       cfg.Metrics.UDPAddr,
+			TCPAddr:       cfg.Metrics.TCPAddr,
+			FlushInterval: cfg.Metrics.FlushInterval,
+		})
+		if err := metricsListener.Listen(); err != nil {
+			log.Fatalf("Failed to start metrics listener: %v", err)
+		}
+		runWorker(metricsListener.Run)
+	}
+
+	var smtpMailer services.Mailer = services.LogMailer{}
+	switch {
+	case cfg.Mail.DropDir != "":
+		smtpMailer = &services.FileMailer{Dir: cfg.Mail.DropDir, From: cfg.Mail.From}
+	case cfg.Mail.Host != "":
+		smtpMailer = &services.SMTPMailer{
+			Host:     cfg.Mail.Host,
+			Port:     cfg.Mail.Port,
+			User:     cfg.Mail.User,
+			Password: cfg.Mail.Password,
+			From:     cfg.Mail.From,
+		}
+	}
+	mailer := services.NewQueuedMailer(jobQueue, smtpMailer)
+	alertService := services.NewAlertService(alertRepo, dashboardRepo,
+		services.NewEmailNotifier(mailer),
+		services.NewWebhookNotifier(nil),
+		services.NewInAppNotifier(notificationRepo),
+		services.NewOrgWebhookNotifier(webhookService),
+	)
+	// 이상치 수(aggregation=anomalies) 규칙 평가
+	alertService.UseAnomalies(anomalyRepo)
+	runWorker(func(ctx context.Context) { alertService.Run(ctx, cfg.Alert.Interval) })
+
+	if cfg.Chart.Font != "" {
+		if err := services.LoadChartFont(cfg.Chart.Font); err != nil {
+			log.Printf("Warning: Failed to load chart font: %v", err)
+		}
+	}
+
+	// 템플릿 로드 (화면과 보고서 메일 본문이 함께 사용)
+	tmpl := loadTemplates(templateFuncs())
+
+	// 예약 보고서: 보고서 작업이 재시도를 맡으므로 메일 큐를 거치지 않고 바로 발송
+	reportService := services.NewReportService(reportRepo, layoutService, dashboardService, kpiService, smtpMailer, jobQueue, tmpl)
+
+	runWorker(jobQueue.Run)
 
 	// Handler 초기화
 	authHandler := handlers.NewAuthHandler(authService)
-	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
+	dashboardHandler := handlers.NewDashboardHandler(dashboardService, kpiService, layoutService)
+	layoutHandler := handlers.NewLayoutHandler(layoutService, dashboardService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	streamHandler := handlers.NewStreamHandler(dashboardService)
+	kpiHandler := handlers.NewKPIHandler(kpiService)
+	alertHandler := handlers.NewAlertHandler(alertService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, orgService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	shareHandler := handlers.NewShareHandler(shareService, layoutService, dashboardService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	annotationHandler := handlers.NewAnnotationHandler(annotationService)
+	anomalyHandler := handlers.NewAnomalyHandler(anomalyService)
+	retentionHandler := handlers.NewRetentionHandler(retentionService)
 	healthHandler := handlers.NewHealthHandler()
 
 	// Gin 라우터 생성
 	r := gin.Default()
 
-	// 템플릿 로드
-	r.SetFuncMap(template.FuncMap{
-		"dict": func(values ...interface{}) map[string]interface{} {
-			dict := make(map[string]interface{})
-			for i := 0; i < len(values); i += 2 {
-				key, _ := values[i].(string)
-				dict[key] = values[i+1]
-			}
-			return dict
-		},
-		"slice": func(s string, start, end int) string {
-			runes := []rune(s)
-			if start < 0 {
-				start = 0
-			}
-			if end > len(runes) {
-				end = len(runes)
-			}
-			return string(runes[start:end])
-		},
-		"safeJS": func(s string) template.JS {
-			return template.JS(s)
-		},
-	})
-
-	// 템플릿 파일 로드
-	loadTemplates(r)
+	r.SetHTMLTemplate(tmpl)
 
 	// 정적 파일 제공
 	r.Static("/static", "./web/static")
@@ -93,6 +257,16 @@ func main() {
 	// Health check
 	r.GET("/api/health", healthHandler.Health)
 
+	// 외부 수집 (로그인 대신 INGEST_TOKEN)
+	if prometheusService != nil {
+		prometheusHandler := handlers.NewPrometheusHandler(prometheusService)
+		r.POST("/api/prometheus/write", middleware.IngestTokenMiddleware(cfg.Ingest.Token), prometheusHandler.Write)
+	}
+	if cfg.OTLP.Enabled {
+		otlpHandler := handlers.NewOTLPHandler(services.NewOTLPService(dashboardService))
+		r.POST("/v1/metrics", middleware.IngestTokenMiddleware(cfg.Ingest.Token), otlpHandler.Metrics)
+	}
+
 	// 홈페이지 - 로그인 페이지로 리다이렉트
 	r.GET("/", func(c *gin.Context) {
 		// 이미 로그인된 경우 대시보드로
@@ -114,29 +288,140 @@ func main() {
 		auth.POST("/register", authHandler.Register)
 	}
 
+	// 공개 링크 (로그인 없이 읽기 전용, 토큰으로 범위 제한)
+	r.GET("/share/:token", shareHandler.Page)
+	r.POST("/share/:token/unlock", shareHandler.Unlock)
+	r.GET("/share/:token/charts/:widget", shareHandler.Chart)
+	r.GET("/embed/:token", shareHandler.Embed)
+
 	// 로그아웃은 인증된 사용자만
 	r.POST("/auth/logout", middleware.AuthMiddleware(authService), authHandler.Logout)
 
 	// 대시보드 라우트 (Auth required)
 	dashboard := r.Group("/dashboard")
-	dashboard.Use(middleware.AuthMiddleware(authService))
+	dashboard.Use(middleware.AuthMiddleware(authService), middleware.AdminMiddleware(cfg.Admin.Emails))
+	// 모든 사용자에게 적용되는 전역 설정은 ADMIN_EMAILS 사용자만 변경
+	requireAdmin := middleware.RequireAdmin()
 	{
 		dashboard.GET("", dashboardHandler.Index)
-		dashboard.GET("/charts/line", dashboardHandler.LineChart)
-		dashboard.GET("/charts/bar", dashboardHandler.BarChart)
-		dashboard.GET("/charts/pie", dashboardHandler.PieChart)
+		dashboard.GET("/charts/:category", dashboardHandler.Chart)
+		dashboard.GET("/forecast/:category", dashboardHandler.Forecast)
+		dashboard.GET("/anomalies/:category", anomalyHandler.List)
+		dashboard.GET("/export/:category", exportHandler.Export)
+		dashboard.GET("/stream", streamHandler.Stream)
+		dashboard.POST("/data", streamHandler.Ingest)
+		dashboard.GET("/boards/:id", dashboardHandler.Board)
+		dashboard.POST("/boards", layoutHandler.Create)
+		dashboard.PUT("/boards/:id", layoutHandler.Update)
+		dashboard.DELETE("/boards/:id", layoutHandler.Delete)
+		dashboard.POST("/boards/:id/layout", layoutHandler.Reorder)
+		dashboard.POST("/boards/:id/widgets", layoutHandler.AddWidget)
+		dashboard.PUT("/boards/:id/widgets/:widgetID", layoutHandler.UpdateWidget)
+		dashboard.DELETE("/boards/:id/widgets/:widgetID", layoutHandler.RemoveWidget)
+		dashboard.GET("/boards/:id/shares", shareHandler.List)
+		dashboard.POST("/boards/:id/shares", shareHandler.Create)
+		dashboard.DELETE("/boards/:id/shares/:shareID", shareHandler.Revoke)
+		dashboard.GET("/boards/:id/export", transferHandler.Export)
+		dashboard.POST("/import", transferHandler.Import)
+		dashboard.GET("/kpis", kpiHandler.List)
+		dashboard.POST("/kpis", requireAdmin, kpiHandler.Create)
+		dashboard.PUT("/kpis/:id", requireAdmin, kpiHandler.Update)
+		dashboard.DELETE("/kpis/:id", requireAdmin, kpiHandler.Delete)
+		dashboard.GET("/retention", requireAdmin, retentionHandler.List)
+		dashboard.GET("/retention/preview", requireAdmin, retentionHandler.Preview)
+		dashboard.POST("/retention", requireAdmin, retentionHandler.Create)
+		dashboard.PUT("/retention/:id", requireAdmin, retentionHandler.Update)
+		dashboard.DELETE("/retention/:id", requireAdmin, retentionHandler.Delete)
+		dashboard.GET("/annotations", annotationHandler.List)
+		dashboard.POST("/annotations", annotationHandler.Create)
+		dashboard.PUT("/annotations/:id", annotationHandler.Update)
+		dashboard.DELETE("/annotations/:id", annotationHandler.Delete)
+		dashboard.GET("/alerts", alertHandler.List)
+		dashboard.POST("/alerts", alertHandler.Create)
+		dashboard.PUT("/alerts/:id", alertHandler.Update)
+		dashboard.DELETE("/alerts/:id", alertHandler.Delete)
+		dashboard.GET("/alerts/:id/history", alertHandler.History)
+		dashboard.GET("/notifications", notificationHandler.List)
+		dashboard.GET("/notifications/badge", notificationHandler.Badge)
+		dashboard.POST("/notifications/read", notificationHandler.ReadAll)
+		dashboard.GET("/webhooks", webhookHandler.Page)
+		dashboard.POST("/webhooks", webhookHandler.Create)
+		dashboard.PUT("/webhooks/:id", webhookHandler.Update)
+		dashboard.DELETE("/webhooks/:id", webhookHandler.Delete)
+		dashboard.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+		dashboard.POST("/webhooks/:id/deliveries/:deliveryID/redeliver", webhookHandler.Redeliver)
+		dashboard.GET("/reports", reportHandler.List)
+		dashboard.POST("/reports", reportHandler.Create)
+		dashboard.PUT("/reports/:id", reportHandler.Update)
+		dashboard.DELETE("/reports/:id", reportHandler.Delete)
+		dashboard.POST("/reports/:id/send", reportHandler.Send)
+		dashboard.GET("/reports/:id/preview", reportHandler.Preview)
+		dashboard.GET("/reports/:id/pdf", reportHandler.PDF)
 	}
 
 	// 서버 시작
 	addr := ":" + cfg.Server.Port
-	log.Printf("Server starting on http://localhost%s", addr)
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{Addr: addr, Handler: r}
+	// SSE 스트림은 끝나지 않는 요청이므로 종료 시작 시 구독을 닫아 응답을 끝냄
+	srv.RegisterOnShutdown(eventHub.Close)
+
+	go func() {
+		log.Printf("Server starting on http://localhost%s", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+	// 진행 중인 알림 평가와 실행 중인 작업이 끝날 때까지 대기 (작업은 JOBS_DRAIN_TIMEOUT까지)
+	workers.Wait()
+	log.Println("Server stopped")
+}
+
+// templateFuncs 화면 템플릿과 보고서 메일 템플릿이 함께 쓰는 함수
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"dict": func(values ...interface{}) map[string]interface{} {
+			dict := make(map[string]interface{})
+			for i := 0; i < len(values); i += 2 {
+				key, _ := values[i].(string)
+				dict[key] = values[i+1]
+			}
+			return dict
+		},
+		"slice": func(s string, start, end int) string {
+			runes := []rune(s)
+			if start < 0 {
+				start = 0
+			}
+			if end > len(runes) {
+				end = len(runes)
+			}
+			return string(runes[start:end])
+		},
+		"safeJS": func(s string) template.JS {
+			return template.JS(s)
+		},
+		// toJSON 속성 값(data-*)에 넣을 JSON 문자열
+		"toJSON": func(v interface{}) string {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "null"
+			}
+			return string(b)
+		},
 	}
 }
 
-func loadTemplates(r *gin.Engine) {
-	tmpl := template.New("").Funcs(r.FuncMap)
+func loadTemplates(funcs template.FuncMap) *template.Template {
+	tmpl := template.New("").Funcs(funcs)
 
 	// 템플릿 디렉토리 순회
 	err := filepath.Walk("web/templates", func(path string, info os.FileInfo, err error) error {
@@ -173,5 +458,5 @@ func loadTemplates(r *gin.Engine) {
 		log.Printf("Warning: Error walking templates: %v", err)
 	}
 
-	r.SetHTMLTemplate(tmpl)
+	return tmpl
 }
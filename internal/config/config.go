@@ -1,13 +1,30 @@
 package config
 
 import (
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Admin      AdminConfig
+	Realtime   RealtimeConfig
+	Alert      AlertConfig
+	Mail       MailConfig
+	Jobs       JobsConfig
+	Chart      ChartConfig
+	Share      ShareConfig
+	Anomaly    AnomalyConfig
+	Rollup     RollupConfig
+	Retention  RetentionConfig
+	Ingest     IngestConfig
+	Prometheus PrometheusConfig
+	Metrics    MetricsConfig
+	OTLP       OTLPConfig
 }
 
 type ServerConfig struct {
@@ -24,6 +41,97 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// AdminConfig 전역 설정(KPI 정의, 보관 정책)을 바꿀 수 있는 사용자
+// Emails: 가입 이메일 목록 (대소문자까지 일치해야 함). 비어 있으면 아무도 바꿀 수 없음
+type AdminConfig struct {
+	Emails []string
+}
+
+// RealtimeConfig 실시간 이벤트 전달 방식
+// Feed: postgres (LISTEN/NOTIFY, 여러 인스턴스) 또는 memory (단일 인스턴스)
+type RealtimeConfig struct {
+	Feed string
+}
+
+// AlertConfig 알림 규칙 평가 주기
+type AlertConfig struct {
+	Interval time.Duration
+}
+
+// MailConfig SMTP 설정. Host가 비어 있으면 메일을 로그로만 남김
+// DropDir: 지정하면 보내지 않고 .eml 파일로 저장 (로컬 테스트용, Host보다 우선)
+type MailConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+	DropDir  string
+}
+
+// JobsConfig 백그라운드 작업 큐 설정
+// DrainTimeout: 종료 시 실행 중인 작업을 기다리는 최대 시간
+type JobsConfig struct {
+	DrainTimeout time.Duration
+}
+
+// ChartConfig 서버에서 그리는 차트 이미지 설정
+// Font: PNG 라벨용 글꼴 파일. 비어 있으면 내장 Go 글꼴 (한글 없음)
+type ChartConfig struct {
+	Font string
+}
+
+// ShareConfig 공개 링크 설정
+// Secret: 링크 토큰 서명 키. 비어 있으면 JWT_SECRET 사용 (바꾸면 기존 링크가 모두 무효)
+type ShareConfig struct {
+	Secret string
+}
+
+// AnomalyConfig 수집 데이터 이상치 탐지 설정
+// Method: mad, zscore, seasonal 또는 off. Threshold: 점수(표준편차 단위) 기준, Window: 비교할 최근 값 수
+type AnomalyConfig struct {
+	Method    string
+	Threshold float64
+	Window    int
+}
+
+// RollupConfig 시간·일 롤업 테이블 설정
+// Enabled: 시계열 조회에 롤업 사용, Interval: 새 데이터를 반영하는 주기
+type RollupConfig struct {
+	Enabled  bool
+	Interval time.Duration
+}
+
+// RetentionConfig 보관 정책 실행 주기
+type RetentionConfig struct {
+	Interval time.Duration
+}
+
+// IngestConfig 로그인 없이 수집하는 엔드포인트 설정
+// Token: Bearer 토큰 또는 Basic 인증 비밀번호
+type IngestConfig struct {
+	Token string
+}
+
+// PrometheusConfig Prometheus remote-write 수신 설정
+// RulesFile: 시계열을 카테고리·라벨로 옮기는 규칙 파일 (YAML). 비어 있으면 수신하지 않음
+type PrometheusConfig struct {
+	RulesFile string
+}
+
+// MetricsConfig StatsD·InfluxDB 라인 프로토콜 수신 설정
+// UDPAddr, TCPAddr: 수신 주소 (비어 있으면 열지 않음), FlushInterval: 모은 값을 저장하는 주기
+type MetricsConfig struct {
+	UDPAddr       string
+	TCPAddr       string
+	FlushInterval time.Duration
+}
+
+// OTLPConfig OpenTelemetry OTLP/HTTP 지표 수신 (/v1/metrics). 켜려면 INGEST_TOKEN 필요
+type OTLPConfig struct {
+	Enabled bool
+}
+
 type JWTConfig struct {
 	Secret      string
 	ExpiryHours int
@@ -44,6 +152,23 @@ func Load() (*Config, error) {
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_SSLMODE", "disable")
 	viper.SetDefault("JWT_EXPIRY_HOURS", 24)
+	viper.SetDefault("REALTIME_FEED", "postgres")
+	viper.SetDefault("ALERT_INTERVAL", "1m")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_FROM", "commet@localhost")
+	viper.SetDefault("JOBS_DRAIN_TIMEOUT", "30s")
+	viper.SetDefault("ANOMALY_METHOD", "mad")
+	viper.SetDefault("ANOMALY_THRESHOLD", 3.5)
+	viper.SetDefault("ANOMALY_WINDOW", 100)
+	viper.SetDefault("ROLLUP_ENABLED", true)
+	viper.SetDefault("ROLLUP_INTERVAL", "1m")
+	viper.SetDefault("RETENTION_INTERVAL", "1h")
+	viper.SetDefault("METRICS_FLUSH_INTERVAL", "10s")
+
+	shareSecret := viper.GetString("SHARE_SECRET")
+	if shareSecret == "" {
+		shareSecret = viper.GetString("JWT_SECRET")
+	}
 
 	return &Config{
 		Server: ServerConfig{
@@ -62,9 +187,72 @@ func Load() (*Config, error) {
 			Secret:      viper.GetString("JWT_SECRET"),
 			ExpiryHours: viper.GetInt("JWT_EXPIRY_HOURS"),
 		},
+		Admin: AdminConfig{
+			Emails: splitList(viper.GetString("ADMIN_EMAILS")),
+		},
+		Realtime: RealtimeConfig{
+			Feed: viper.GetString("REALTIME_FEED"),
+		},
+		Alert: AlertConfig{
+			Interval: viper.GetDuration("ALERT_INTERVAL"),
+		},
+		Mail: MailConfig{
+			Host:     viper.GetString("SMTP_HOST"),
+			Port:     viper.GetString("SMTP_PORT"),
+			User:     viper.GetString("SMTP_USER"),
+			Password: viper.GetString("SMTP_PASSWORD"),
+			From:     viper.GetString("SMTP_FROM"),
+			DropDir:  viper.GetString("MAIL_DROP_DIR"),
+		},
+		Jobs: JobsConfig{
+			DrainTimeout: viper.GetDuration("JOBS_DRAIN_TIMEOUT"),
+		},
+		Chart: ChartConfig{
+			Font: viper.GetString("CHART_FONT"),
+		},
+		Share: ShareConfig{
+			Secret: shareSecret,
+		},
+		Anomaly: AnomalyConfig{
+			Method:    viper.GetString("ANOMALY_METHOD"),
+			Threshold: viper.GetFloat64("ANOMALY_THRESHOLD"),
+			Window:    viper.GetInt("ANOMALY_WINDOW"),
+		},
+		Rollup: RollupConfig{
+			Enabled:  viper.GetBool("ROLLUP_ENABLED"),
+			Interval: viper.GetDuration("ROLLUP_INTERVAL"),
+		},
+		Retention: RetentionConfig{
+			Interval: viper.GetDuration("RETENTION_INTERVAL"),
+		},
+		Ingest: IngestConfig{
+			Token: viper.GetString("INGEST_TOKEN"),
+		},
+		Prometheus: PrometheusConfig{
+			RulesFile: viper.GetString("PROMETHEUS_RULES_FILE"),
+		},
+		OTLP: OTLPConfig{
+			Enabled: viper.GetBool("OTLP_ENABLED"),
+		},
+		Metrics: MetricsConfig{
+			UDPAddr:       viper.GetString("METRICS_UDP_ADDR"),
+			TCPAddr:       viper.GetString("METRICS_TCP_ADDR"),
+			FlushInterval: viper.GetDuration("METRICS_FLUSH_INTERVAL"),
+		},
 	}, nil
 }
 
+// splitList 쉼표로 나눈 값 목록 (빈 항목 제외)
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (d *DatabaseConfig) DSN() string {
 	return "host=" + d.Host +
 		" user=" + d.User +
@@ -2,6 +2,8 @@ package database
 
 import (
 	"log"
+	"math/rand"
+	"time"
 
 	"github.com/baltop/commet/internal/config"
 	"github.com/baltop/commet/internal/models"
@@ -12,6 +14,9 @@ import (
 
 var DB *gorm.DB
 
+// DataChangeChannel dashboard_data INSERT 시 NOTIFY 되는 채널 (여러 인스턴스 실시간 갱신용)
+const DataChangeChannel = "dashboard_data_changes"
+
 func Connect(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	var err error
 
@@ -32,15 +37,67 @@ func Migrate() error {
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.DashboardData{},
+		&models.KPIDefinition{},
+		&models.Dashboard{},
+		&models.Widget{},
+		&models.AlertRule{},
+		&models.AlertEvent{},
+		&models.Notification{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.Job{},
+		&models.Report{},
+		&models.ShareLink{},
+		&models.Annotation{},
+		&models.Anomaly{},
+		&models.HourlyRollup{},
+		&models.DailyRollup{},
+		&models.RollupState{},
+		&models.RetentionPolicy{},
 	)
 	if err != nil {
 		return err
 	}
+	if err := installChangeTrigger(); err != nil {
+		return err
+	}
 
 	log.Println("Database migrations completed")
 	return nil
 }
 
+// installChangeTrigger 새 데이터 행을 JSON으로 NOTIFY 하는 트리거.
+// 라벨/카테고리 길이가 제한되어 있어 NOTIFY 페이로드 한도(8000바이트)를 넘지 않음
+func installChangeTrigger() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		stmts := []string{
+			`CREATE OR REPLACE FUNCTION notify_dashboard_data() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('` + DataChangeChannel + `', json_build_object(
+		'id', NEW.id,
+		'category', NEW.category,
+		'label', NEW.label,
+		'value', NEW.value,
+		'recorded_at', NEW.recorded_at
+	)::text);
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`,
+			`DROP TRIGGER IF EXISTS dashboard_data_notify ON dashboard_data`,
+			`CREATE TRIGGER dashboard_data_notify AFTER INSERT ON dashboard_data
+	FOR EACH ROW EXECUTE FUNCTION notify_dashboard_data()`,
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func SeedSampleData() error {
 	log.Println("Seeding sample dashboard data...")
 
@@ -52,15 +109,19 @@ func SeedSampleData() error {
 		return nil
 	}
 
-	// 샘플 데이터 생성
+	// 샘플 데이터 생성 (월별 매출은 올해 각 월 1일로 기록해 기간 조회에 사용)
+	year := time.Now().Year()
+	monthStart := func(month int) time.Time {
+		return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	}
 	sampleData := []models.DashboardData{
 		// 월별 매출 데이터 (라인 차트용)
-		{Category: "sales", Label: "1월", Value: 1200},
-		{Category: "sales", Label: "2월", Value: 1900},
-		{Category: "sales", Label: "3월", Value: 3000},
-		{Category: "sales", Label: "4월", Value: 2500},
-		{Category: "sales", Label: "5월", Value: 2800},
-		{Category: "sales", Label: "6월", Value: 3200},
+		{Category: "sales", Label: "1월", RecordedAt: monthStart(1), Value: 1200},
+		{Category: "sales", Label: "2월", RecordedAt: monthStart(2), Value: 1900},
+		{Category: "sales", Label: "3월", RecordedAt: monthStart(3), Value: 3000},
+		{Category: "sales", Label: "4월", RecordedAt: monthStart(4), Value: 2500},
+		{Category: "sales", Label: "5월", RecordedAt: monthStart(5), Value: 2800},
+		{Category: "sales", Label: "6월", RecordedAt: monthStart(6), Value: 3200},
 
 		// 제품별 판매량 (바 차트용)
 		{Category: "products", Label: "제품 A", Value: 450},
@@ -85,3 +146,45 @@ func SeedSampleData() error {
 	log.Println("Sample data seeded successfully")
 	return nil
 }
+
+// SeedKPIs 기본 요약 카드 정의와 최근 60일 일별 샘플 지표 생성
+func SeedKPIs() error {
+	var count int64
+	DB.Model(&models.KPIDefinition{}).Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	log.Println("Seeding KPI definitions...")
+
+	defs := []models.KPIDefinition{
+		{Key: "signups", Title: "총 사용자", Category: "signups", Aggregation: "sum", WindowDays: 30, Format: "number", Icon: "users", Color: "blue", Position: 1},
+		{Key: "revenue", Title: "총 매출", Category: "revenue", Aggregation: "sum", WindowDays: 30, Format: "currency", Icon: "revenue", Color: "green", Position: 2},
+		{Key: "orders", Title: "총 주문", Category: "orders", Aggregation: "sum", WindowDays: 30, Format: "number", Icon: "orders", Color: "purple", Position: 3},
+		{Key: "conversion", Title: "전환율", Category: "conversion", Aggregation: "avg", WindowDays: 30, Format: "percent", Icon: "conversion", Color: "orange", Position: 4},
+	}
+	if err := DB.Create(&defs).Error; err != nil {
+		return err
+	}
+
+	// 결정적인 샘플 값을 위해 고정 시드 사용
+	rnd := rand.New(rand.NewSource(42))
+	today := time.Now().Truncate(24 * time.Hour)
+	var points []models.DashboardData
+	for day := 60; day >= 1; day-- {
+		at := today.AddDate(0, 0, -day).Add(12 * time.Hour)
+		growth := float64(60-day) / 60
+		points = append(points,
+			models.DashboardData{Category: "signups", Label: "가입", Value: float64(30 + rnd.Intn(20) + int(growth*15)), RecordedAt: at},
+			models.DashboardData{Category: "revenue", Label: "매출", Value: float64(1200+rnd.Intn(600)) + growth*400, RecordedAt: at},
+			models.DashboardData{Category: "orders", Label: "주문", Value: float64(15 + rnd.Intn(10) + int(growth*5)), RecordedAt: at},
+			models.DashboardData{Category: "conversion", Label: "전환율", Value: 2.5 + rnd.Float64()*1.5, RecordedAt: at},
+		)
+	}
+	if err := DB.CreateInBatches(points, 100).Error; err != nil {
+		return err
+	}
+
+	log.Println("KPI definitions seeded successfully")
+	return nil
+}
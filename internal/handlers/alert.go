@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AlertHandler struct {
+	alertService *services.AlertService
+}
+
+func NewAlertHandler(alertService *services.AlertService) *AlertHandler {
+	return &AlertHandler{alertService: alertService}
+}
+
+// GET /dashboard/alerts - 내 알림 규칙 목록 (현재 상태 포함)
+func (h *AlertHandler) List(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	rules, err := h.alertService.List(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "알림 규칙을 불러오지 못했습니다."})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// POST /dashboard/alerts - 알림 규칙 생성
+func (h *AlertHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	var req models.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.alertService.Create(claims.UserID, &req)
+	if err != nil {
+		alertError(c, err, "알림 규칙을 저장하지 못했습니다.")
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// PUT /dashboard/alerts/:id - 알림 규칙 수정 (평가 상태 초기화)
+func (h *AlertHandler) Update(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.alertService.Update(id, claims.UserID, &req)
+	if err != nil {
+		alertError(c, err, "알림 규칙을 저장하지 못했습니다.")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DELETE /dashboard/alerts/:id - 알림 규칙과 이력 삭제
+func (h *AlertHandler) Delete(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.Delete(id, claims.UserID); err != nil {
+		alertError(c, err, "알림 규칙을 삭제하지 못했습니다.")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /dashboard/alerts/:id/history - 상태 변경 이력 (pending, firing, resolved)
+func (h *AlertHandler) History(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	events, err := h.alertService.History(id, claims.UserID)
+	if err != nil {
+		alertError(c, err, "알림 이력을 불러오지 못했습니다.")
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+func alertError(c *gin.Context, err error, message string) {
+	if err == services.ErrAlertRuleNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "알림 규칙을 찾을 수 없습니다."})
+		return
+	}
+	if errors.Is(err, services.ErrUnsafeURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "웹훅은 공인 주소의 http(s) URL만 쓸 수 있습니다."})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AnnotationHandler 차트 주석(이벤트 표시) API
+type AnnotationHandler struct {
+	annotationService *services.AnnotationService
+}
+
+func NewAnnotationHandler(annotationService *services.AnnotationService) *AnnotationHandler {
+	return &AnnotationHandler{annotationService: annotationService}
+}
+
+// GET /dashboard/annotations?dashboard_id=&category=&from=&to= - 기간과 겹치는 주석
+func (h *AnnotationHandler) List(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	from, to, err := parseTimeRange(c, requestLocation(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "조회 기간이 올바르지 않습니다."})
+		return
+	}
+	f := repository.AnnotationFilter{Category: c.Query("category"), From: from, To: to}
+	if s := c.Query("dashboard_id"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 ID입니다."})
+			return
+		}
+		dashboardID := uint(id)
+		f.DashboardID = &dashboardID
+	}
+
+	anns, err := h.annotationService.List(claims.UserID, f)
+	if err != nil {
+		annotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, anns)
+}
+
+// POST /dashboard/annotations - 주석 생성
+func (h *AnnotationHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	var req models.AnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a, err := h.annotationService.Create(claims.UserID, &req)
+	if err != nil {
+		annotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+// PUT /dashboard/annotations/:id - 주석 수정 (작성자만)
+func (h *AnnotationHandler) Update(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a, err := h.annotationService.Update(id, claims.UserID, &req)
+	if err != nil {
+		annotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// DELETE /dashboard/annotations/:id - 주석 삭제 (작성자만)
+func (h *AnnotationHandler) Delete(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.annotationService.Delete(id, claims.UserID); err != nil {
+		annotationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func annotationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAnnotation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "주석 내용이나 기간이 올바르지 않습니다."})
+	case errors.Is(err, services.ErrAnnotationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "주석을 찾을 수 없습니다."})
+	case errors.Is(err, services.ErrDashboardNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "대시보드를 찾을 수 없습니다."})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "작성자만 수정할 수 있습니다."})
+	default:
+		log.Printf("Annotation error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "요청을 처리하지 못했습니다."})
+	}
+}
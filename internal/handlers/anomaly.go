@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AnomalyHandler 이상치 탐지 결과 API
+type AnomalyHandler struct {
+	anomalyService *services.AnomalyService
+}
+
+func NewAnomalyHandler(anomalyService *services.AnomalyService) *AnomalyHandler {
+	return &AnomalyHandler{anomalyService: anomalyService}
+}
+
+// GET /dashboard/anomalies/:category?label=&from=&to= - 기간 안에 탐지된 이상치 (기본 최근 30일)
+func (h *AnomalyHandler) List(c *gin.Context) {
+	from, to, err := parseTimeRange(c, requestLocation(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "조회 기간이 올바르지 않습니다."})
+		return
+	}
+
+	anomalies, err := h.anomalyService.List(c.Param("category"), c.Query("label"), services.ChartQuery{From: from, To: to})
+	if err != nil {
+		log.Printf("Anomaly list failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이상치를 불러오지 못했습니다."})
+		return
+	}
+	c.JSON(http.StatusOK, anomalies)
+}
@@ -2,7 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/baltop/commet/internal/middleware"
 	"github.com/baltop/commet/internal/services"
@@ -11,87 +16,201 @@ import (
 
 type DashboardHandler struct {
 	dashboardService *services.DashboardService
+	kpiService       *services.KPIService
+	layoutService    *services.LayoutService
 }
 
-func NewDashboardHandler(dashboardService *services.DashboardService) *DashboardHandler {
-	return &DashboardHandler{dashboardService: dashboardService}
+func NewDashboardHandler(dashboardService *services.DashboardService, kpiService *services.KPIService, layoutService *services.LayoutService) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+		kpiService:       kpiService,
+		layoutService:    layoutService,
+	}
 }
 
-// GET /dashboard - 대시보드 메인 페이지
+// GET /dashboard - 대시보드 메인 페이지 (사용자 기본 대시보드)
 func (h *DashboardHandler) Index(c *gin.Context) {
 	claims := middleware.GetCurrentUser(c)
 
-	stats := h.dashboardService.GetSummaryStats()
-
-	c.HTML(http.StatusOK, "dashboard/index.html", gin.H{
-		"title":          "대시보드",
-		"user":           claims,
-		"totalUsers":     stats["totalUsers"],
-		"totalRevenue":   stats["totalRevenue"],
-		"totalOrders":    stats["totalOrders"],
-		"conversionRate": stats["conversionRate"],
-	})
+	board, err := h.layoutService.Default(claims.UserID)
+	if err != nil {
+		log.Printf("Failed to load default dashboard: %v", err)
+		board = &services.DashboardView{}
+	}
+	h.renderPage(c, board)
 }
 
-// GET /dashboard/charts/line - 라인 차트 데이터 (HTMX partial)
-func (h *DashboardHandler) LineChart(c *gin.Context) {
-	data, err := h.dashboardService.GetSalesData()
+// GET /dashboard/boards/:id - 본인 또는 공유된 대시보드
+func (h *DashboardHandler) Board(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.HTML(http.StatusOK, "components/alert.html", gin.H{
-			"type":    "error",
-			"message": "데이터를 불러오는데 실패했습니다.",
-		})
+		c.Redirect(http.StatusFound, "/dashboard")
 		return
 	}
+	board, err := h.layoutService.Get(uint(id), claims.UserID)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/dashboard")
+		return
+	}
+	h.renderPage(c, board)
+}
 
-	labelsJSON, _ := json.Marshal(data.Labels)
-	valuesJSON, _ := json.Marshal(data.Values)
+func (h *DashboardHandler) renderPage(c *gin.Context, board *services.DashboardView) {
+	claims := middleware.GetCurrentUser(c)
 
-	c.HTML(http.StatusOK, "dashboard/partials/chart_line.html", gin.H{
-		"labels": string(labelsJSON),
-		"values": string(valuesJSON),
-		"title":  "월별 매출 추이",
-	})
+	// ?compare= KPI 카드의 비교 기준 (없거나 잘못되면 이전 기간)
+	cmp, _ := services.ParseComparison(c.Query("compare"))
+	kpis, err := h.kpiService.Compute(time.Now(), requestLocation(c), cmp)
+	if err != nil {
+		log.Printf("Failed to compute KPIs: %v", err)
+	}
+	boards, err := h.layoutService.List(claims.UserID)
+	if err != nil {
+		log.Printf("Failed to list dashboards: %v", err)
+	}
+
+	data := gridData(h.dashboardService, board)
+	data["title"] = "대시보드"
+	data["user"] = claims
+	data["kpis"] = kpis
+	data["boards"] = boards
+	c.HTML(http.StatusOK, "dashboard/index.html", data)
+}
+
+// GET /dashboard/charts/:category?type=&series=&from=&to=&bucket=&agg=&compare=&forecast=&horizon=&label=&unit=&suffix=&max=&legend=
+// 카테고리 + 차트 종류로 구성되는 범용 차트 (HTMX partial). 카테고리 뒤에 .svg, .png를 붙이면 이미지
+func (h *DashboardHandler) Chart(c *gin.Context) {
+	// ?dashboard= 위젯이 놓인 대시보드의 주석도 표시 (볼 수 있는 대시보드만)
+	var dashboardID uint
+	if s := c.Query("dashboard"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			renderChartError(c, errInvalidChartOption)
+			return
+		}
+		claims := middleware.GetCurrentUser(c)
+		if _, err := h.layoutService.Get(uint(id), claims.UserID); err != nil {
+			renderChartError(c, errInvalidChartOption)
+			return
+		}
+		dashboardID = uint(id)
+	}
+	renderChart(c, h.dashboardService, c.Param("category"), dashboardID, true)
+}
+
+// GET /dashboard/forecast/:category?label=&from=&to=&bucket=&agg=&forecast=&horizon=&tags= - 조회 기간 기록으로 이후 버킷 예측 (JSON)
+func (h *DashboardHandler) Forecast(c *gin.Context) {
+	q, err := parseChartQuery(c)
+	if err != nil {
+		renderChartImageError(c, err)
+		return
+	}
+	forecast, err := h.dashboardService.Forecast(c.Param("category"), c.Query("label"), q)
+	if err != nil {
+		renderChartImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, forecast)
 }
 
-// GET /dashboard/charts/bar - 바 차트 데이터 (HTMX partial)
-func (h *DashboardHandler) BarChart(c *gin.Context) {
-	data, err := h.dashboardService.GetProductsData()
+// renderChart 차트 partial 또는 이미지. 대시보드와 공개 링크가 함께 사용
+// (dashboardID는 주석 범위, live가 false면 로그인이 필요한 실시간 스트림을 구독하지 않음)
+func renderChart(c *gin.Context, dashboardService *services.DashboardService, category string, dashboardID uint, live bool) {
+	if ext := path.Ext(category); ext == ".svg" || ext == ".png" {
+		renderChartImage(c, dashboardService, strings.TrimSuffix(category, ext), dashboardID, ext)
+		return
+	}
+
+	req, err := parseChartRequest(c, category)
+	if err != nil {
+		renderChartError(c, err)
+		return
+	}
+	req.Dashboard = dashboardID
+
+	chart, err := dashboardService.RenderChart(req)
 	if err != nil {
-		c.HTML(http.StatusOK, "components/alert.html", gin.H{
-			"type":    "error",
-			"message": "데이터를 불러오는데 실패했습니다.",
-		})
+		renderChartError(c, err)
 		return
 	}
 
-	labelsJSON, _ := json.Marshal(data.Labels)
-	valuesJSON, _ := json.Marshal(data.Values)
+	if !live {
+		chart.Meta.Live = nil
+	}
+	configJSON, _ := json.Marshal(chart.Config)
+	metaJSON, _ := json.Marshal(chart.Meta)
 
-	c.HTML(http.StatusOK, "dashboard/partials/chart_bar.html", gin.H{
-		"labels": string(labelsJSON),
-		"values": string(valuesJSON),
-		"title":  "제품별 판매량",
+	c.HTML(http.StatusOK, "dashboard/partials/chart.html", gin.H{
+		"config": string(configJSON),
+		"meta":   string(metaJSON),
+		"chart":  chart.Meta,
 	})
 }
 
-// GET /dashboard/charts/pie - 파이 차트 데이터 (HTMX partial)
-func (h *DashboardHandler) PieChart(c *gin.Context) {
-	data, err := h.dashboardService.GetTrafficData()
+// renderChartImage 이메일, PDF, 링크 미리보기 등에 넣을 수 있는 SVG/PNG 차트 (?theme=dark|light&width=&height=&title=)
+func renderChartImage(c *gin.Context, dashboardService *services.DashboardService, category string, dashboardID uint, ext string) {
+	req, err := parseChartRequest(c, category)
 	if err != nil {
-		c.HTML(http.StatusOK, "components/alert.html", gin.H{
-			"type":    "error",
-			"message": "데이터를 불러오는데 실패했습니다.",
-		})
+		renderChartImageError(c, err)
+		return
+	}
+	req.Dashboard = dashboardID
+	opts, err := parseChartImageOptions(c, req)
+	if err != nil {
+		renderChartImageError(c, err)
 		return
 	}
 
-	labelsJSON, _ := json.Marshal(data.Labels)
-	valuesJSON, _ := json.Marshal(data.Values)
+	chart, err := dashboardService.RenderChart(req)
+	if err != nil {
+		renderChartImageError(c, err)
+		return
+	}
 
-	c.HTML(http.StatusOK, "dashboard/partials/chart_pie.html", gin.H{
-		"labels": string(labelsJSON),
-		"values": string(valuesJSON),
-		"title":  "트래픽 소스",
+	render, contentType := services.RenderChartSVG, "image/svg+xml"
+	if ext == ".png" {
+		render, contentType = services.RenderChartPNG, "image/png"
+	}
+	opts.Annotations = chart.Meta.Annotations
+	opts.Anomalies = chart.Meta.Anomalies
+	body, err := render(chart.Data, opts)
+	if err != nil {
+		renderChartImageError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=60")
+	// SVG를 직접 열어도 스크립트나 외부 리소스를 불러오지 않도록
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func renderChartError(c *gin.Context, err error) {
+	message, _ := chartErrorMessage(err)
+	c.HTML(http.StatusOK, "components/alert.html", gin.H{
+		"type":    "error",
+		"message": message,
 	})
 }
+
+func renderChartImageError(c *gin.Context, err error) {
+	message, status := chartErrorMessage(err)
+	c.JSON(status, gin.H{"error": message})
+}
+
+func chartErrorMessage(err error) (string, int) {
+	switch err {
+	case errInvalidTimeRange, errInvalidChartOption, services.ErrInvalidBucket, services.ErrInvalidAggregation:
+		return "조회 조건이 올바르지 않습니다.", http.StatusBadRequest
+	case services.ErrUnknownChartType:
+		return "지원하지 않는 차트 종류입니다.", http.StatusBadRequest
+	case services.ErrUnsupportedChartImage:
+		return "이미지로 그릴 수 없는 차트 종류입니다.", http.StatusBadRequest
+	case services.ErrTooManyBuckets:
+		return "기간에 비해 집계 단위가 너무 작습니다.", http.StatusBadRequest
+	case services.ErrNotEnoughHistory:
+		return "예측에 필요한 기록이 부족합니다.", http.StatusUnprocessableEntity
+	}
+	return "데이터를 불러오는데 실패했습니다.", http.StatusInternalServerError
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/baltop/commet/internal/repository"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// GET /dashboard/export/:category?format=csv|json|xlsx&from=&to=&tz=&locale= - 차트 데이터 다운로드
+func (h *ExportHandler) Export(c *gin.Context) {
+	format, err := services.ParseExportFormat(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "지원하지 않는 형식입니다. (csv, json, xlsx)"})
+		return
+	}
+
+	loc := requestLocation(c)
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "기간이 올바르지 않습니다."})
+		return
+	}
+
+	req := services.ExportRequest{
+		Format: format,
+		Filter: repository.DataFilter{
+			Category: c.Param("category"),
+			From:     from,
+			To:       to,
+		},
+		Location: loc,
+		Locale:   services.LookupLocale(requestLocaleTag(c)),
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+services.ExportFilename(req)+`"`)
+	c.Status(http.StatusOK)
+
+	// 헤더가 이미 전송된 뒤라 상태 코드를 바꿀 수 없으므로 로그만 남김
+	if err := h.exportService.Export(c.Writer, req); err != nil {
+		log.Printf("Export %s/%s failed: %v", req.Filter.Category, format, err)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type KPIHandler struct {
+	kpiService *services.KPIService
+}
+
+func NewKPIHandler(kpiService *services.KPIService) *KPIHandler {
+	return &KPIHandler{kpiService: kpiService}
+}
+
+// GET /dashboard/kpis - KPI 정의 목록
+func (h *KPIHandler) List(c *gin.Context) {
+	defs, err := h.kpiService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "KPI 목록을 불러오지 못했습니다."})
+		return
+	}
+	c.JSON(http.StatusOK, defs)
+}
+
+// POST /dashboard/kpis - KPI 정의 생성
+func (h *KPIHandler) Create(c *gin.Context) {
+	var req models.KPIRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	def, err := h.kpiService.Create(&req)
+	if err != nil {
+		if err == services.ErrKPIKeyExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "이미 사용 중인 KPI 키입니다."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "KPI를 저장하지 못했습니다."})
+		return
+	}
+	c.JSON(http.StatusCreated, def)
+}
+
+// PUT /dashboard/kpis/:id - KPI 정의 수정
+func (h *KPIHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 ID입니다."})
+		return
+	}
+
+	var req models.KPIRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	def, err := h.kpiService.Update(uint(id), &req)
+	if err != nil {
+		if err == services.ErrKPINotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "KPI를 찾을 수 없습니다."})
+			return
+		}
+		if err == services.ErrKPIKeyExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "이미 사용 중인 KPI 키입니다."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "KPI를 저장하지 못했습니다."})
+		return
+	}
+	c.JSON(http.StatusOK, def)
+}
+
+// DELETE /dashboard/kpis/:id - KPI 정의 삭제
+func (h *KPIHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 ID입니다."})
+		return
+	}
+	if err := h.kpiService.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "KPI를 삭제하지 못했습니다."})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
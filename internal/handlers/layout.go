@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LayoutHandler 대시보드/위젯 편집 (HTMX)
+type LayoutHandler struct {
+	layoutService    *services.LayoutService
+	dashboardService *services.DashboardService
+}
+
+func NewLayoutHandler(layoutService *services.LayoutService, dashboardService *services.DashboardService) *LayoutHandler {
+	return &LayoutHandler{
+		layoutService:    layoutService,
+		dashboardService: dashboardService,
+	}
+}
+
+// POST /dashboard/boards - 새 대시보드 (이름은 폼 또는 hx-prompt)
+func (h *LayoutHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	req := models.DashboardRequest{
+		Name:   strings.TrimSpace(c.PostForm("name")),
+		Shared: c.PostForm("shared") == "true",
+	}
+	if req.Name == "" {
+		req.Name = strings.TrimSpace(c.GetHeader("HX-Prompt"))
+	}
+	if req.Name == "" || len([]rune(req.Name)) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "대시보드 이름은 1~100자여야 합니다."})
+		return
+	}
+
+	d, err := h.layoutService.Create(claims.UserID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "대시보드를 만들지 못했습니다."})
+		return
+	}
+	redirect(c, "/dashboard/boards/"+strconv.FormatUint(uint64(d.ID), 10))
+}
+
+// PUT /dashboard/boards/:id - 이름/공유 여부 변경
+func (h *LayoutHandler) Update(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	req := models.DashboardRequest{
+		Name:   strings.TrimSpace(c.PostForm("name")),
+		Shared: c.PostForm("shared") == "true",
+	}
+	if req.Name == "" || len([]rune(req.Name)) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "대시보드 이름은 1~100자여야 합니다."})
+		return
+	}
+
+	if _, err := h.layoutService.Update(id, claims.UserID, &req); err != nil {
+		layoutError(c, err)
+		return
+	}
+	redirect(c, "/dashboard/boards/"+c.Param("id"))
+}
+
+// DELETE /dashboard/boards/:id
+func (h *LayoutHandler) Delete(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.layoutService.Delete(id, claims.UserID); err != nil {
+		layoutError(c, err)
+		return
+	}
+	redirect(c, "/dashboard")
+}
+
+// POST /dashboard/boards/:id/widgets - 위젯 추가 후 그리드 다시 렌더링
+func (h *LayoutHandler) AddWidget(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	req, err := bindWidgetRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.layoutService.AddWidget(id, claims.UserID, req); err != nil {
+		layoutError(c, err)
+		return
+	}
+	h.renderGrid(c, id)
+}
+
+// PUT /dashboard/boards/:id/widgets/:widgetID - 위젯 설정 변경
+func (h *LayoutHandler) UpdateWidget(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	widgetID, ok := uintParam(c, "widgetID")
+	if !ok {
+		return
+	}
+	req, err := bindWidgetRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.layoutService.UpdateWidget(id, widgetID, claims.UserID, req); err != nil {
+		layoutError(c, err)
+		return
+	}
+	h.renderGrid(c, id)
+}
+
+// DELETE /dashboard/boards/:id/widgets/:widgetID
+func (h *LayoutHandler) RemoveWidget(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	widgetID, ok := uintParam(c, "widgetID")
+	if !ok {
+		return
+	}
+
+	if err := h.layoutService.RemoveWidget(id, widgetID, claims.UserID); err != nil {
+		layoutError(c, err)
+		return
+	}
+	h.renderGrid(c, id)
+}
+
+// POST /dashboard/boards/:id/layout - 드래그 앤 드롭 순서 저장 (widget=3&widget=1&...)
+func (h *LayoutHandler) Reorder(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var ids []uint
+	for _, s := range c.PostFormArray("widget") {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 위젯 ID입니다."})
+			return
+		}
+		ids = append(ids, uint(v))
+	}
+
+	if err := h.layoutService.Reorder(id, claims.UserID, ids); err != nil {
+		layoutError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *LayoutHandler) renderGrid(c *gin.Context, id uint) {
+	claims := middleware.GetCurrentUser(c)
+	board, err := h.layoutService.Get(id, claims.UserID)
+	if err != nil {
+		layoutError(c, err)
+		return
+	}
+	c.HTML(http.StatusOK, "dashboard/partials/widget_grid.html", gridData(h.dashboardService, board))
+}
+
+// gridData 위젯 그리드 partial에 필요한 데이터
+func gridData(dashboardService *services.DashboardService, board *services.DashboardView) gin.H {
+	categories, err := dashboardService.GetCategories()
+	if err != nil {
+		log.Printf("Failed to list categories: %v", err)
+	}
+	tagKeys, err := dashboardService.GetTagKeys("")
+	if err != nil {
+		log.Printf("Failed to list tag keys: %v", err)
+	}
+	return gin.H{
+		"board":      board,
+		"chartTypes": services.ChartTypes(),
+		"categories": categories,
+		"tagKeys":    tagKeys,
+	}
+}
+
+// bindWidgetRequest JSON 본문 또는 위젯 편집 폼 (option_* 필드는 Options JSON으로 묶음)
+func bindWidgetRequest(c *gin.Context) (*models.WidgetRequest, error) {
+	var req models.WidgetRequest
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	}
+
+	req.Type = c.PostForm("type")
+	req.DataSource = strings.TrimSpace(c.PostForm("data_source"))
+	req.Title = c.PostForm("title")
+	req.Subtitle = c.PostForm("subtitle")
+	req.Width, _ = strconv.Atoi(c.PostForm("width"))
+	req.Height, _ = strconv.Atoi(c.PostForm("height"))
+
+	opts := models.WidgetOptions{
+		Label:    c.PostForm("option_label"),
+		Unit:     c.PostForm("option_unit"),
+		Suffix:   c.PostForm("option_suffix"),
+		Legend:   c.PostForm("option_legend"),
+		Series:   c.PostForm("option_series"),
+		Bucket:   c.PostForm("option_bucket"),
+		Agg:      c.PostForm("option_agg"),
+		Forecast: c.PostForm("option_forecast"),
+		GroupBy:  strings.TrimSpace(c.PostForm("option_group_by")),
+		Tags:     strings.TrimSpace(c.PostForm("option_tags")),
+		Split:    c.PostForm("option_split"),
+		Fill:     c.PostForm("option_fill"),
+	}
+	if s := c.PostForm("option_max"); s != "" {
+		max, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.New("option_max: 숫자여야 합니다.")
+		}
+		opts.Max = max
+	}
+	raw, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	req.Options = raw
+	return &req, nil
+}
+
+func layoutError(c *gin.Context, err error) {
+	var verr *services.WidgetValidationError
+	switch {
+	case errors.As(err, &verr):
+		if isHTMX(c) {
+			c.Header("HX-Retarget", "#widget-form-errors")
+			c.Header("HX-Reswap", "innerHTML")
+			c.HTML(http.StatusOK, "dashboard/partials/form_errors.html", gin.H{"errors": verr.Fields})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "위젯 설정이 올바르지 않습니다.", "fields": verr.Fields})
+	case errors.Is(err, services.ErrDashboardNotFound), errors.Is(err, services.ErrWidgetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "대시보드 또는 위젯을 찾을 수 없습니다."})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "수정 권한이 없습니다."})
+	default:
+		log.Printf("Dashboard layout error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "요청을 처리하지 못했습니다."})
+	}
+}
+
+// redirect HTMX 요청이면 HX-Redirect, 아니면 302
+func redirect(c *gin.Context, location string) {
+	if isHTMX(c) {
+		c.Header("HX-Redirect", location)
+		c.Status(http.StatusOK)
+		return
+	}
+	c.Redirect(http.StatusFound, location)
+}
+
+func isHTMX(c *gin.Context) bool {
+	return c.GetHeader("HX-Request") == "true"
+}
+
+func uintParam(c *gin.Context, name string) (uint, bool) {
+	v, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 ID입니다."})
+		return 0, false
+	}
+	return uint(v), true
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GET /dashboard/notifications/badge - 읽지 않은 알림 수 표시 (HTMX 주기 갱신)
+func (h *NotificationHandler) Badge(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	count, err := h.notificationService.UnreadCount(claims.UserID)
+	if err != nil {
+		log.Printf("Failed to count notifications: %v", err)
+	}
+	c.HTML(http.StatusOK, "dashboard/partials/notification_badge.html", gin.H{"count": count})
+}
+
+// GET /dashboard/notifications - 최근 알림 목록 (HTMX partial)
+func (h *NotificationHandler) List(c *gin.Context) {
+	h.renderList(c)
+}
+
+// POST /dashboard/notifications/read - 모두 읽음 처리 후 목록과 배지 갱신
+func (h *NotificationHandler) ReadAll(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	if err := h.notificationService.MarkAllRead(claims.UserID); err != nil {
+		log.Printf("Failed to mark notifications read: %v", err)
+	}
+	c.Header("HX-Trigger", "notificationsChanged")
+	h.renderList(c)
+}
+
+func (h *NotificationHandler) renderList(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	list, err := h.notificationService.Recent(claims.UserID)
+	if err != nil {
+		log.Printf("Failed to list notifications: %v", err)
+	}
+	c.HTML(http.StatusOK, "dashboard/partials/notifications.html", gin.H{"notifications": list})
+}
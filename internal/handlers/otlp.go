@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 압축된 OTLP 요청 본문 최대 크기
+	maxOTLPBytes = 10 << 20
+	// gzip을 푼 본문 최대 크기
+	maxOTLPDecodedBytes = 32 << 20
+	// google.rpc.Code
+	rpcInvalidArgument = 3
+	rpcUnavailable     = 14
+)
+
+var (
+	errOTLPTooLarge = errors.New("request body too large")
+	errOTLPEncoding = errors.New("unsupported content encoding")
+)
+
+// OTLPHandler OpenTelemetry OTLP/HTTP 지표 수신
+type OTLPHandler struct {
+	otlpService *services.OTLPService
+}
+
+func NewOTLPHandler(otlpService *services.OTLPService) *OTLPHandler {
+	return &OTLPHandler{otlpService: otlpService}
+}
+
+// POST /v1/metrics - ExportMetricsServiceRequest (application/x-protobuf 또는 application/json, gzip 가능).
+// OTLP 내보내기는 429·502·503·504만 다시 보내므로 저장 실패는 503, 해석할 수 없는 본문은 400
+func (h *OTLPHandler) Metrics(c *gin.Context) {
+	var format services.OTLPFormat
+	switch c.ContentType() {
+	case "application/x-protobuf":
+		format = services.OTLPProtobuf
+	case "application/json":
+		format = services.OTLPJSON
+	default:
+		c.String(http.StatusUnsupportedMediaType, "unsupported content type %q", c.ContentType())
+		return
+	}
+
+	body, err := readOTLPBody(c)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, errOTLPTooLarge):
+			status = http.StatusRequestEntityTooLarge
+		case errors.Is(err, errOTLPEncoding):
+			status = http.StatusUnsupportedMediaType
+		}
+		otlpError(c, format, status, rpcInvalidArgument, err.Error())
+		return
+	}
+
+	result, err := h.otlpService.Export(body, format)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOTLP) {
+			otlpError(c, format, http.StatusBadRequest, rpcInvalidArgument, err.Error())
+			return
+		}
+		log.Printf("OTLP export failed: %v", err)
+		otlpError(c, format, http.StatusServiceUnavailable, rpcUnavailable, "failed to store data points")
+		return
+	}
+
+	if format == services.OTLPJSON {
+		c.JSON(http.StatusOK, result.JSONResponse())
+		return
+	}
+	c.Data(http.StatusOK, "application/x-protobuf", result.ProtoResponse())
+}
+
+// readOTLPBody Content-Encoding(gzip 또는 없음)에 맞게 본문을 읽음
+func readOTLPBody(c *gin.Context) ([]byte, error) {
+	var r io.Reader = http.MaxBytesReader(c.Writer, c.Request.Body, maxOTLPBytes)
+	switch enc := c.GetHeader("Content-Encoding"); enc {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = io.LimitReader(gz, maxOTLPDecodedBytes+1)
+	default:
+		return nil, fmt.Errorf("%w %q", errOTLPEncoding, enc)
+	}
+
+	body, err := io.ReadAll(r)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) || len(body) > maxOTLPDecodedBytes {
+		return nil, errOTLPTooLarge
+	}
+	return body, err
+}
+
+// otlpError 오류 응답은 요청과 같은 인코딩의 google.rpc.Status
+func otlpError(c *gin.Context, format services.OTLPFormat, status, code int, message string) {
+	if format == services.OTLPJSON {
+		c.JSON(status, gin.H{"code": code, "message": message})
+		return
+	}
+	c.Data(status, "application/x-protobuf", services.OTLPStatusProto(code, message))
+}
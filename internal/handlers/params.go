@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTimezone = "Asia/Seoul"
+	tzCookieName    = "tz"
+)
+
+var (
+	errInvalidTimeRange   = errors.New("invalid time range")
+	errInvalidChartOption = errors.New("invalid chart option")
+)
+
+// requestLocation ?tz= 파라미터 > tz 쿠키 > 기본값(Asia/Seoul) 순으로 사용자 시간대 결정
+func requestLocation(c *gin.Context) *time.Location {
+	name := c.Query("tz")
+	if name == "" {
+		name, _ = c.Cookie(tzCookieName)
+	}
+	if name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// requestLocaleTag ?locale= 파라미터 > Accept-Language 헤더
+func requestLocaleTag(c *gin.Context) string {
+	if l := c.Query("locale"); l != "" {
+		return l
+	}
+	return c.GetHeader("Accept-Language")
+}
+
+// parseTimeRange ?from=&to= 파싱. 날짜만 주어지면 loc 기준으로 해석하고 to는 그날 끝까지 포함
+func parseTimeRange(c *gin.Context, loc *time.Location) (from, to *time.Time, err error) {
+	if s := c.Query("from"); s != "" {
+		t, _, err := parseTimeParam(s, loc)
+		if err != nil {
+			return nil, nil, errInvalidTimeRange
+		}
+		from = &t
+	}
+	if s := c.Query("to"); s != "" {
+		t, dateOnly, err := parseTimeParam(s, loc)
+		if err != nil {
+			return nil, nil, errInvalidTimeRange
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errInvalidTimeRange
+	}
+	return from, to, nil
+}
+
+func parseTimeParam(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", s, loc); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, false, err
+}
+
+// parseChartQuery ?from=&to=&bucket=&agg=&compare=&forecast=&horizon=&tags=&group_by=&split=&fill= 차트 조회 조건
+func parseChartQuery(c *gin.Context) (services.ChartQuery, error) {
+	loc := requestLocation(c)
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		return services.ChartQuery{}, err
+	}
+	bucket, err := services.ParseBucket(c.Query("bucket"))
+	if err != nil {
+		return services.ChartQuery{}, err
+	}
+	agg, err := services.ParseAggregation(c.Query("agg"))
+	if err != nil {
+		return services.ChartQuery{}, err
+	}
+	cmp, err := services.ParseComparison(c.Query("compare"))
+	if err != nil {
+		return services.ChartQuery{}, errInvalidChartOption
+	}
+	forecast, err := services.ParseForecastOptions(c.Query("forecast"), c.Query("horizon"))
+	if err != nil {
+		return services.ChartQuery{}, errInvalidChartOption
+	}
+	tags, err := services.ParseTagFilter(c.Query("tags"))
+	if err != nil {
+		return services.ChartQuery{}, errInvalidChartOption
+	}
+	groupBy, err := services.ParseGroupBy(c.Query("group_by"))
+	if err != nil {
+		return services.ChartQuery{}, errInvalidChartOption
+	}
+	split := c.Query("split")
+	if (split != "" && split != "label") || (split != "" && groupBy != "") {
+		return services.ChartQuery{}, errInvalidChartOption
+	}
+	fill, err := services.ParseFillMode(c.Query("fill"))
+	if err != nil {
+		return services.ChartQuery{}, errInvalidChartOption
+	}
+	return services.ChartQuery{
+		From:        from,
+		To:          to,
+		Bucket:      bucket,
+		Aggregation: agg,
+		Location:    loc,
+		Compare:     cmp,
+		Forecast:    forecast,
+		Tags:        tags,
+		GroupBy:     groupBy,
+		SplitLabels: split == "label",
+		Fill:        fill,
+	}, nil
+}
+
+// parseChartRequest 범용 차트 엔드포인트 파라미터
+func parseChartRequest(c *gin.Context, category string) (services.ChartRequest, error) {
+	q, err := parseChartQuery(c)
+	if err != nil {
+		return services.ChartRequest{}, err
+	}
+
+	series := services.SeriesMode(c.Query("series"))
+	switch series {
+	case services.SeriesAuto, services.SeriesTime, services.SeriesLabel:
+	default:
+		return services.ChartRequest{}, errInvalidChartOption
+	}
+
+	legend := c.Query("legend")
+	switch legend {
+	case "", "none", "top", "side":
+	default:
+		return services.ChartRequest{}, errInvalidChartOption
+	}
+
+	var max float64
+	if s := c.Query("max"); s != "" {
+		if max, err = strconv.ParseFloat(s, 64); err != nil || max <= 0 {
+			return services.ChartRequest{}, errInvalidChartOption
+		}
+	}
+
+	label := c.Query("label")
+	if label == "" {
+		label = category
+	}
+
+	return services.ChartRequest{
+		Category: category,
+		Type:     c.DefaultQuery("type", "line"),
+		Series:   series,
+		Options: services.ChartOptions{
+			Label:  label,
+			Unit:   c.Query("unit"),
+			Suffix: c.Query("suffix"),
+			Max:    max,
+			Legend: legend,
+		},
+		Query: q,
+	}, nil
+}
+
+// parseChartImageOptions ?theme=&width=&height=&title= 차트 이미지 옵션
+func parseChartImageOptions(c *gin.Context, req services.ChartRequest) (services.ChartImageOptions, error) {
+	opts := services.ChartImageOptions{
+		Type:   req.Type,
+		Theme:  services.LookupChartTheme(c.Query("theme")),
+		Title:  c.Query("title"),
+		Unit:   req.Options.Unit,
+		Suffix: req.Options.Suffix,
+	}
+	for name, dst := range map[string]*int{"width": &opts.Width, "height": &opts.Height} {
+		s := c.Query(name)
+		if s == "" {
+			continue
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < services.ChartImageMinSize || v > services.ChartImageMaxSize {
+			return opts, errInvalidChartOption
+		}
+		*dst = v
+	}
+	if s := c.Query("scale"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 1 || v > 3 {
+			return opts, errInvalidChartOption
+		}
+		opts.Scale = v
+	}
+	return opts, nil
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// 압축된 remote-write 요청 본문 최대 크기
+const maxRemoteWriteBytes = 10 << 20
+
+// PrometheusHandler Prometheus remote-write 수신
+type PrometheusHandler struct {
+	prometheusService *services.PrometheusService
+}
+
+func NewPrometheusHandler(prometheusService *services.PrometheusService) *PrometheusHandler {
+	return &PrometheusHandler{prometheusService: prometheusService}
+}
+
+// POST /api/prometheus/write - snappy 압축 protobuf WriteRequest (remote-write 1.0).
+// 4xx는 Prometheus가 다시 보내지 않고 버리며, 5xx는 다시 보냄
+func (h *PrometheusHandler) Write(c *gin.Context) {
+	if enc := c.GetHeader("Content-Encoding"); enc != "snappy" {
+		c.String(http.StatusUnsupportedMediaType, "unsupported content encoding %q", enc)
+		return
+	}
+	// remote-write 2.0 메시지(io.prometheus.write.v2.Request)는 받지 않음
+	if strings.Contains(c.GetHeader("Content-Type"), "io.prometheus.write.v2") {
+		c.String(http.StatusUnsupportedMediaType, "remote write 2.0 is not supported")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRemoteWriteBytes))
+	if err != nil {
+		c.String(http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	series, err := services.DecodeRemoteWrite(body)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.prometheusService.Write(series)
+	if err != nil {
+		log.Printf("Prometheus remote write failed: %v", err)
+		c.String(http.StatusInternalServerError, "failed to store samples")
+		return
+	}
+	c.Header("X-Commet-Ingested", strconv.Itoa(result.Ingested))
+	c.Header("X-Commet-Dropped", strconv.Itoa(result.Dropped))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// GET /dashboard/reports - 내 예약 보고서 목록
+func (h *ReportHandler) List(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	reports, err := h.reportService.List(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "보고서를 불러오지 못했습니다."})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// POST /dashboard/reports - 예약 보고서 생성
+func (h *ReportHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+
+	var req models.ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.reportService.Create(claims.UserID, &req)
+	if err != nil {
+		reportError(c, err, "보고서를 저장하지 못했습니다.")
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// PUT /dashboard/reports/:id - 예약 보고서 수정 (다음 실행 시각 다시 계산)
+func (h *ReportHandler) Update(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.reportService.Update(id, claims.UserID, &req)
+	if err != nil {
+		reportError(c, err, "보고서를 저장하지 못했습니다.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// DELETE /dashboard/reports/:id - 예약 보고서 삭제
+func (h *ReportHandler) Delete(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.reportService.Delete(id, claims.UserID); err != nil {
+		reportError(c, err, "보고서를 삭제하지 못했습니다.")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /dashboard/reports/:id/send - 일정과 관계없이 지금 한 번 발송
+func (h *ReportHandler) Send(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.reportService.SendNow(id, claims.UserID); err != nil {
+		reportError(c, err, "보고서 발송을 예약하지 못했습니다.")
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// GET /dashboard/reports/:id/preview - 지금 발송하면 보낼 HTML 본문
+func (h *ReportHandler) Preview(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	html, err := h.reportService.PreviewHTML(id, claims.UserID)
+	if err != nil {
+		reportError(c, err, "보고서를 만들지 못했습니다.")
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", html)
+}
+
+// GET /dashboard/reports/:id/pdf - 지금 발송하면 첨부할 PDF
+func (h *ReportHandler) PDF(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	pdf, err := h.reportService.PreviewPDF(id, claims.UserID)
+	if err != nil {
+		reportError(c, err, "보고서를 만들지 못했습니다.")
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"report-%d.pdf\"", id))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func reportError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "보고서를 찾을 수 없습니다."})
+	case errors.Is(err, services.ErrDashboardNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "대시보드를 찾을 수 없습니다."})
+	case errors.Is(err, services.ErrInvalidCron):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTimezone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "알 수 없는 시간대입니다."})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionHandler 데이터 보관 정책 API
+type RetentionHandler struct {
+	retentionService *services.RetentionService
+}
+
+func NewRetentionHandler(retentionService *services.RetentionService) *RetentionHandler {
+	return &RetentionHandler{retentionService: retentionService}
+}
+
+// GET /dashboard/retention - 보관 정책 목록
+func (h *RetentionHandler) List(c *gin.Context) {
+	policies, err := h.retentionService.List()
+	if err != nil {
+		retentionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+// POST /dashboard/retention - 보관 정책 생성
+func (h *RetentionHandler) Create(c *gin.Context) {
+	var req models.RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.retentionService.Create(&req)
+	if err != nil {
+		retentionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+// PUT /dashboard/retention/:id - 보관 정책 수정
+func (h *RetentionHandler) Update(c *gin.Context) {
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.retentionService.Update(id, &req)
+	if err != nil {
+		retentionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// DELETE /dashboard/retention/:id - 보관 정책 삭제 (이후 데이터는 기본 정책 또는 계속 보관)
+func (h *RetentionHandler) Delete(c *gin.Context) {
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.retentionService.Delete(id); err != nil {
+		retentionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /dashboard/retention/preview - 지금 실행하면 지워질 행 수 (아무것도 지우지 않음)
+func (h *RetentionHandler) Preview(c *gin.Context) {
+	report, err := h.retentionService.Enforce(c.Request.Context(), time.Now(), true)
+	if err != nil {
+		retentionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func retentionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRetention):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "롤업은 원본보다 짧게 보관할 수 없습니다 (원본 ≤ 시간 롤업 ≤ 일 롤업, 0은 계속 보관)."})
+	case errors.Is(err, services.ErrRetentionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "이미 보관 정책이 있는 카테고리입니다."})
+	case errors.Is(err, services.ErrRetentionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "보관 정책을 찾을 수 없습니다."})
+	default:
+		log.Printf("Retention error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "요청을 처리하지 못했습니다."})
+	}
+}
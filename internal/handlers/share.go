@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidShareRequest = errors.New("invalid share request")
+
+// 공개 화면에서 요청으로 받는 기간 조건 (위젯에 저장된 값이 있으면 그것을 사용)
+var shareRangeParams = []string{"from", "to", "tz", "bucket", "agg"}
+
+// 공개 차트 이미지에서 요청으로 받는 표시 옵션
+var shareImageParams = []string{"theme", "width", "height", "scale", "title"}
+
+// ShareHandler 공개 링크 관리와 로그인 없는 읽기 전용 화면
+type ShareHandler struct {
+	shareService     *services.ShareService
+	layoutService    *services.LayoutService
+	dashboardService *services.DashboardService
+}
+
+func NewShareHandler(shareService *services.ShareService, layoutService *services.LayoutService, dashboardService *services.DashboardService) *ShareHandler {
+	return &ShareHandler{
+		shareService:     shareService,
+		layoutService:    layoutService,
+		dashboardService: dashboardService,
+	}
+}
+
+// GET /dashboard/boards/:id/shares - 공개 링크 목록 (HTMX면 관리 partial)
+func (h *ShareHandler) List(c *gin.Context) {
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	h.renderLinks(c, id, nil)
+}
+
+// POST /dashboard/boards/:id/shares - 공개 링크 생성 (폼 또는 JSON)
+func (h *ShareHandler) Create(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ShareLinkRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.shareError(c, id, errInvalidShareRequest)
+		return
+	}
+
+	link, err := h.shareService.Create(id, claims.UserID, &req)
+	if err != nil {
+		h.shareError(c, id, err)
+		return
+	}
+	if isHTMX(c) {
+		h.renderLinks(c, id, nil)
+		return
+	}
+	c.JSON(http.StatusCreated, link)
+}
+
+// DELETE /dashboard/boards/:id/shares/:shareID - 공개 링크 폐기
+func (h *ShareHandler) Revoke(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	shareID, ok := uintParam(c, "shareID")
+	if !ok {
+		return
+	}
+
+	if err := h.shareService.Revoke(id, shareID, claims.UserID); err != nil {
+		h.shareError(c, id, err)
+		return
+	}
+	if isHTMX(c) {
+		h.renderLinks(c, id, nil)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// renderLinks formError가 있으면 생성 폼 위에 표시
+func (h *ShareHandler) renderLinks(c *gin.Context, dashboardID uint, formError error) {
+	claims := middleware.GetCurrentUser(c)
+	links, err := h.shareService.List(dashboardID, claims.UserID)
+	if err != nil {
+		layoutError(c, err)
+		return
+	}
+	if !isHTMX(c) {
+		c.JSON(http.StatusOK, links)
+		return
+	}
+	board, err := h.layoutService.Get(dashboardID, claims.UserID)
+	if err != nil {
+		layoutError(c, err)
+		return
+	}
+
+	data := gin.H{
+		"board":  board,
+		"links":  links,
+		"origin": requestOrigin(c),
+		"now":    time.Now(),
+	}
+	if formError != nil {
+		data["error"] = shareErrorMessage(formError)
+	}
+	c.HTML(http.StatusOK, "dashboard/partials/share_links.html", data)
+}
+
+// shareError HTMX 폼이면 목록과 함께 오류를 보여 주고, 아니면 JSON
+func (h *ShareHandler) shareError(c *gin.Context, dashboardID uint, err error) {
+	switch {
+	case errors.Is(err, errInvalidShareRequest), errors.Is(err, services.ErrInvalidEmbedOrigin), errors.Is(err, services.ErrWidgetNotFound):
+		if isHTMX(c) {
+			h.renderLinks(c, dashboardID, err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": shareErrorMessage(err)})
+	case errors.Is(err, services.ErrShareLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "공개 링크를 찾을 수 없습니다."})
+	default:
+		layoutError(c, err)
+	}
+}
+
+func shareErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, services.ErrInvalidEmbedOrigin):
+		return "iframe 허용 사이트는 https://intra.example.com 형식이어야 합니다."
+	case errors.Is(err, services.ErrWidgetNotFound):
+		return "공개할 차트를 찾을 수 없습니다."
+	}
+	return "만료 기간은 1~365일, 비밀번호는 4~72자여야 합니다."
+}
+
+// GET /share/:token - 로그인 없이 보는 읽기 전용 대시보드
+func (h *ShareHandler) Page(c *gin.Context) {
+	h.renderPage(c, false)
+}
+
+// GET /embed/:token - iframe용 (메뉴·머리글 없음, ?theme=dark|light)
+func (h *ShareHandler) Embed(c *gin.Context) {
+	h.renderPage(c, true)
+}
+
+func (h *ShareHandler) renderPage(c *gin.Context, embed bool) {
+	token := c.Param("token")
+	link, ok := h.resolve(c, token, embed)
+	if !ok {
+		return
+	}
+	setShareHeaders(c, link, embed)
+
+	if !h.shareService.Unlocked(link, unlockCookie(c, link), time.Now()) {
+		renderSharePassword(c, token, embed, "", http.StatusOK)
+		return
+	}
+
+	view, err := h.shareService.View(link)
+	if err != nil {
+		renderShareUnavailable(c, err, embed)
+		return
+	}
+
+	// 페이지 주소의 기간 조건을 차트 요청에 그대로 전달
+	rangeQuery := url.Values{}
+	for _, key := range shareRangeParams {
+		if v := c.Query(key); v != "" {
+			rangeQuery.Set(key, v)
+		}
+	}
+	widgets := make([]services.WidgetView, len(view.Widgets))
+	for i, w := range view.Widgets {
+		chartURL := "/share/" + token + "/charts/" + strconv.FormatUint(uint64(w.ID), 10)
+		if len(rangeQuery) > 0 {
+			chartURL += "?" + rangeQuery.Encode()
+		}
+		widgets[i] = services.WidgetView{Widget: w, ChartURL: chartURL}
+	}
+
+	theme := c.Query("theme")
+	if theme != "dark" && theme != "light" {
+		theme = ""
+	}
+	c.HTML(http.StatusOK, "share/view.html", gin.H{
+		"title":     view.Dashboard.Name,
+		"dashboard": view.Dashboard,
+		"widgets":   widgets,
+		"single":    link.WidgetID != nil,
+		"embed":     embed,
+		"theme":     theme,
+		"expiresAt": link.ExpiresAt,
+	})
+}
+
+// POST /share/:token/unlock - 비밀번호 확인 후 쿠키를 남기고 원래 화면으로
+func (h *ShareHandler) Unlock(c *gin.Context) {
+	token := c.Param("token")
+	embed := c.PostForm("embed") == "true"
+	link, ok := h.resolve(c, token, embed)
+	if !ok {
+		return
+	}
+	setShareHeaders(c, link, embed)
+
+	if !h.shareService.CheckPassword(link, c.PostForm("password")) {
+		renderSharePassword(c, token, embed, "비밀번호가 올바르지 않습니다.", http.StatusUnauthorized)
+		return
+	}
+
+	// 다른 사이트의 iframe 안에서도 쿠키가 전달되도록 HTTPS에서는 SameSite=None
+	secure := requestScheme(c) == "https"
+	sameSite := http.SameSiteLaxMode
+	if secure {
+		sameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     shareCookieName(link),
+		Value:    h.shareService.UnlockToken(link, time.Now()),
+		Path:     "/",
+		MaxAge:   int(services.ShareUnlockTTL / time.Second),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+	})
+
+	location := "/share/" + token
+	if embed {
+		location = "/embed/" + token
+	}
+	c.Redirect(http.StatusSeeOther, location)
+}
+
+// GET /share/:token/charts/:widget - 링크 범위 안의 위젯 차트 (뒤에 .svg, .png를 붙이면 이미지).
+// 차트 종류와 옵션은 저장된 위젯 설정을 쓰고 요청에서는 기간과 이미지 표시 옵션만 받음
+func (h *ShareHandler) Chart(c *gin.Context) {
+	token := c.Param("token")
+	link, err := h.shareService.Resolve(token)
+	if err != nil {
+		c.AbortWithStatus(shareStatus(err))
+		return
+	}
+	if !h.shareService.Unlocked(link, unlockCookie(c, link), time.Now()) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	param := c.Param("widget")
+	ext := path.Ext(param)
+	if ext != "" && ext != ".svg" && ext != ".png" {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	widgetID, err := strconv.ParseUint(strings.TrimSuffix(param, ext), 10, 64)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	w, err := h.shareService.Widget(link, uint(widgetID))
+	if err != nil {
+		c.AbortWithStatus(shareStatus(err))
+		return
+	}
+
+	q := services.WidgetChartQuery(*w)
+	for _, key := range shareRangeParams {
+		if v := c.Query(key); v != "" && q.Get(key) == "" {
+			q.Set(key, v)
+		}
+	}
+	if ext != "" {
+		for _, key := range shareImageParams {
+			if v := c.Query(key); v != "" {
+				q.Set(key, v)
+			}
+		}
+	}
+	c.Request.URL.RawQuery = q.Encode()
+
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	renderChart(c, h.dashboardService, w.DataSource+ext, link.DashboardID, false)
+}
+
+// resolve 만료·폐기·잘못된 토큰이면 안내 화면을 그리고 false
+func (h *ShareHandler) resolve(c *gin.Context, token string, embed bool) (*models.ShareLink, bool) {
+	link, err := h.shareService.Resolve(token)
+	if err != nil {
+		c.Header("Referrer-Policy", "no-referrer")
+		renderShareUnavailable(c, err, embed)
+		return nil, false
+	}
+	return link, true
+}
+
+// setShareHeaders 주소의 토큰이 외부(CDN 등)로 새지 않도록 Referer를 보내지 않고,
+// iframe은 embed 화면에서 링크에 지정한 출처만 허용
+func setShareHeaders(c *gin.Context, link *models.ShareLink, embed bool) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	if embed {
+		c.Header("Content-Security-Policy", "frame-ancestors "+link.FrameAncestors())
+		return
+	}
+	c.Header("Content-Security-Policy", "frame-ancestors 'none'")
+	c.Header("X-Frame-Options", "DENY")
+}
+
+func renderSharePassword(c *gin.Context, token string, embed bool, message string, status int) {
+	c.HTML(status, "share/password.html", gin.H{
+		"title": "비밀번호 확인",
+		"token": token,
+		"embed": embed,
+		"error": message,
+	})
+}
+
+func renderShareUnavailable(c *gin.Context, err error, embed bool) {
+	status := shareStatus(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("Share link error: %v", err)
+	}
+	message := "링크를 찾을 수 없거나 더 이상 공유되지 않습니다."
+	if status == http.StatusGone {
+		message = "공유 기간이 끝난 링크입니다."
+	}
+	c.HTML(status, "share/unavailable.html", gin.H{
+		"title":   "공유 링크",
+		"message": message,
+		"embed":   embed,
+	})
+}
+
+func shareStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrShareLinkExpired):
+		return http.StatusGone
+	case errors.Is(err, services.ErrShareLinkNotFound), errors.Is(err, services.ErrWidgetNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func shareCookieName(link *models.ShareLink) string {
+	return "commet_share_" + strconv.FormatUint(uint64(link.ID), 10)
+}
+
+func unlockCookie(c *gin.Context, link *models.ShareLink) string {
+	v, _ := c.Cookie(shareCookieName(link))
+	return v
+}
+
+// requestScheme TLS 종료 프록시 뒤에서는 X-Forwarded-Proto
+func requestScheme(c *gin.Context) string {
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		return "https"
+	}
+	return "http"
+}
+
+// requestOrigin 공개 링크 전체 주소를 만들 때 사용
+func requestOrigin(c *gin.Context) string {
+	return requestScheme(c) + "://" + c.Request.Host
+}
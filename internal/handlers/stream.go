@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 프록시가 유휴 연결을 끊지 않도록 보내는 주석 줄 간격
+	streamHeartbeat = 15 * time.Second
+	// 브라우저 EventSource 재연결 대기 (ms)
+	streamRetryMillis = 3000
+	// 재연결 시 다시 보내는 최대 이벤트 수. 넘으면 reset 이벤트로 차트 전체를 다시 조회하게 함
+	streamReplayLimit   = 500
+	maxStreamCategories = 20
+	maxIngestBatch      = 1000
+)
+
+type StreamHandler struct {
+	dashboardService *services.DashboardService
+}
+
+func NewStreamHandler(dashboardService *services.DashboardService) *StreamHandler {
+	return &StreamHandler{dashboardService: dashboardService}
+}
+
+// POST /dashboard/data - 데이터 포인트 수집 (JSON 배열). 저장 후 실시간 구독자에게 전달
+func (h *StreamHandler) Ingest(c *gin.Context) {
+	var reqs []models.DataPointRequest
+	if err := c.ShouldBindJSON(&reqs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(reqs) > maxIngestBatch {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "한 번에 최대 1000개까지 보낼 수 있습니다."})
+		return
+	}
+
+	data, err := h.dashboardService.Ingest(reqs)
+	if errors.Is(err, services.ErrValueOutOfRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "value는 절댓값 1억 미만이어야 합니다."})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to ingest data: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "데이터를 저장하지 못했습니다."})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"count": len(data)})
+}
+
+// GET /dashboard/stream?category=sales&category=traffic - 새 데이터 포인트 SSE 스트림
+// 재연결 시 Last-Event-ID (또는 lastEventId 파라미터) 이후 누락분부터 전송
+func (h *StreamHandler) Stream(c *gin.Context) {
+	categories := c.QueryArray("category")
+	if len(categories) == 0 || len(categories) > maxStreamCategories {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category 파라미터가 필요합니다."})
+		return
+	}
+	lastID := lastEventID(c)
+
+	// 누락분 조회 중에 들어오는 이벤트도 놓치지 않도록 먼저 구독
+	sub := h.dashboardService.Subscribe(categories)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis); err != nil {
+		return
+	}
+
+	// replayed 누락분으로 보낸 ID. 구독 버퍼에 같은 이벤트가 있으면 건너뜀
+	// (여러 요청이 동시에 저장되면 ID 순서대로 발행되지 않으므로 ID 크기로 거르지 않음)
+	replayed := map[uint]bool{}
+	if lastID > 0 {
+		events, err := h.dashboardService.EventsSince(lastID, categories, streamReplayLimit+1)
+		if err != nil {
+			log.Printf("Failed to replay stream events: %v", err)
+			events = nil
+		}
+		if len(events) > streamReplayLimit {
+			for _, ev := range events {
+				replayed[ev.ID] = true
+			}
+			if writeSSE(w, "reset", events[len(events)-1].ID, struct{}{}) != nil {
+				return
+			}
+		} else {
+			for _, ev := range events {
+				replayed[ev.ID] = true
+				if writeSSE(w, "data", ev.ID, ev) != nil {
+					return
+				}
+			}
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-sub.Done():
+			// 버퍼가 넘쳐 끊긴 경우 브라우저가 Last-Event-ID로 다시 연결해 이어 받음
+			if sub.Lagged() {
+				log.Printf("Stream subscriber lagged behind, disconnecting")
+			}
+			return
+		case ev := <-sub.Events():
+			if replayed[ev.ID] {
+				continue
+			}
+			if writeSSE(w, "data", ev.ID, ev) != nil {
+				return
+			}
+			w.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+func lastEventID(c *gin.Context) uint {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("lastEventId")
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+func writeSSE(w io.Writer, event string, id uint, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// 가져오기 문서 최대 크기
+const maxImportBytes = 1 << 20
+
+var errImportFileMissing = errors.New("import file missing")
+
+// TransferHandler 대시보드 JSON 내보내기/가져오기
+type TransferHandler struct {
+	transferService *services.DashboardTransferService
+}
+
+func NewTransferHandler(transferService *services.DashboardTransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+// GET /dashboard/boards/:id/export - JSON 파일 다운로드 (?kpis=false 이면 KPI 정의 제외)
+func (h *TransferHandler) Export(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	doc, err := h.transferService.Export(id, claims.UserID, c.Query("kpis") != "false")
+	if err != nil {
+		layoutError(c, err)
+		return
+	}
+	filename := "dashboard-" + strconv.FormatUint(uint64(id), 10) + ".json"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.IndentedJSON(http.StatusOK, doc)
+}
+
+// POST /dashboard/import - JSON 본문 또는 multipart "file".
+// ?dry_run=true 이면 저장하지 않고 결과만, ?on_conflict=error|skip|overwrite|rename (폼 필드도 가능)
+func (h *TransferHandler) Import(c *gin.Context) {
+	claims := middleware.GetCurrentUser(c)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	mode, err := services.ParseConflictMode(formOrQuery(c, "on_conflict"))
+	if err != nil {
+		h.importError(c, err, nil)
+		return
+	}
+	opts := services.ImportOptions{
+		DryRun:     formOrQuery(c, "dry_run") == "true",
+		Conflict:   mode,
+		Name:       strings.TrimSpace(formOrQuery(c, "name")),
+		ManageKPIs: middleware.IsAdmin(c),
+	}
+
+	body, err := importBody(c)
+	if err != nil {
+		h.importError(c, err, nil)
+		return
+	}
+	defer body.Close()
+
+	doc, err := services.ParseDashboardDocument(body)
+	if err != nil {
+		h.importError(c, err, nil)
+		return
+	}
+	result, err := h.transferService.Import(claims.UserID, doc, opts)
+	if err != nil {
+		h.importError(c, err, result)
+		return
+	}
+
+	if isHTMX(c) {
+		c.HTML(http.StatusOK, "dashboard/partials/import_result.html", gin.H{"result": result})
+		return
+	}
+	status := http.StatusOK
+	if !result.DryRun && result.Dashboard.Action == services.ImportCreate {
+		status = http.StatusCreated
+	}
+	c.JSON(status, result)
+}
+
+// importBody multipart 요청이면 업로드한 파일, 아니면 요청 본문
+func importBody(c *gin.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, nil
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var mberr *http.MaxBytesError
+		if errors.As(err, &mberr) {
+			return nil, err
+		}
+		return nil, errImportFileMissing
+	}
+	return fh.Open()
+}
+
+func formOrQuery(c *gin.Context, key string) string {
+	if v, ok := c.GetPostForm(key); ok {
+		return v
+	}
+	return c.Query(key)
+}
+
+// importError HTMX면 결과 partial에 오류를 표시 (충돌이면 항목별 결과도 함께)
+func (h *TransferHandler) importError(c *gin.Context, err error, result *services.ImportResult) {
+	var verr *services.ImportValidationError
+	var mberr *http.MaxBytesError
+	status, msg := http.StatusBadRequest, ""
+	var problems []string
+	switch {
+	case errors.As(err, &verr):
+		status, msg, problems = http.StatusUnprocessableEntity, "문서 내용이 올바르지 않습니다.", verr.Problems
+	case errors.Is(err, services.ErrImportConflict):
+		status, msg = http.StatusConflict, "이미 있는 항목과 충돌합니다. 충돌 처리 방식을 선택하세요."
+	case errors.Is(err, services.ErrUnsupportedDocument):
+		msg = "지원하지 않는 문서 형식 또는 버전입니다."
+	case errors.Is(err, services.ErrInvalidConflictMode):
+		msg = "충돌 처리 방식이 올바르지 않습니다."
+	case errors.Is(err, errImportFileMissing):
+		msg = "가져올 파일을 선택하세요."
+	case errors.As(err, &mberr):
+		status, msg = http.StatusRequestEntityTooLarge, "파일이 너무 큽니다. (최대 1MB)"
+	default:
+		log.Printf("Dashboard import error: %v", err)
+		status, msg = http.StatusInternalServerError, "가져오기를 처리하지 못했습니다."
+	}
+
+	if isHTMX(c) {
+		c.HTML(http.StatusOK, "dashboard/partials/import_result.html", gin.H{
+			"result":   result,
+			"error":    msg,
+			"problems": problems,
+		})
+		return
+	}
+	resp := gin.H{"error": msg}
+	if problems != nil {
+		resp["problems"] = problems
+	}
+	if result != nil {
+		resp["result"] = result
+	}
+	c.JSON(status, resp)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/baltop/commet/internal/middleware"
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler 조직 웹훅 엔드포인트 관리와 전송 기록
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	orgService     *services.OrganizationService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService, orgService *services.OrganizationService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService, orgService: orgService}
+}
+
+// webhookToggleRequest 엔드포인트 활성/비활성 (폼 또는 JSON)
+type webhookToggleRequest struct {
+	Enabled *bool `json:"enabled" form:"enabled" binding:"required"`
+}
+
+// GET /dashboard/webhooks - 웹훅 관리 페이지
+func (h *WebhookHandler) Page(c *gin.Context) {
+	org, ok := h.currentOrg(c)
+	if !ok {
+		return
+	}
+
+	endpoints, err := h.webhookService.List(org.ID)
+	if err != nil {
+		log.Printf("Failed to list webhooks: %v", err)
+	}
+	events := make([]string, 0, len(models.WebhookEvents))
+	for _, event := range models.WebhookEvents {
+		if !models.AdminOnlyEvent(event) || middleware.IsAdmin(c) {
+			events = append(events, event)
+		}
+	}
+	c.HTML(http.StatusOK, "dashboard/webhooks.html", gin.H{
+		"title":     "웹훅",
+		"user":      middleware.GetCurrentUser(c),
+		"org":       org,
+		"endpoints": endpoints,
+		"events":    events,
+	})
+}
+
+// POST /dashboard/webhooks - 엔드포인트 등록. 서명 키는 응답(JSON)과 관리 페이지에서 확인
+func (h *WebhookHandler) Create(c *gin.Context) {
+	org, ok := h.currentOrg(c)
+	if !ok {
+		return
+	}
+
+	var req models.WebhookEndpointRequest
+	if err := c.ShouldBind(&req); err != nil {
+		if isHTMX(c) {
+			c.Header("HX-Retarget", "#webhook-form-errors")
+			c.Header("HX-Reswap", "innerHTML")
+			c.HTML(http.StatusOK, "dashboard/partials/form_errors.html", gin.H{
+				"errors": map[string]string{"request": "URL과 구독할 이벤트를 확인해주세요."},
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	endpoint, err := h.webhookService.Create(org.ID, middleware.IsAdmin(c), &req)
+	if errors.Is(err, services.ErrUnsafeURL) && isHTMX(c) {
+		c.Header("HX-Retarget", "#webhook-form-errors")
+		c.Header("HX-Reswap", "innerHTML")
+		c.HTML(http.StatusOK, "dashboard/partials/form_errors.html", gin.H{
+			"errors": map[string]string{"url": "공인 주소의 http(s) URL만 등록할 수 있습니다."},
+		})
+		return
+	}
+	if err != nil {
+		webhookError(c, err, "웹훅을 등록하지 못했습니다.")
+		return
+	}
+	if isHTMX(c) {
+		redirect(c, "/dashboard/webhooks")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"endpoint": endpoint, "secret": endpoint.Secret})
+}
+
+// PUT /dashboard/webhooks/:id - 활성/비활성 전환 (다시 켜면 연속 실패 기록 초기화)
+func (h *WebhookHandler) Update(c *gin.Context) {
+	org, ok := h.currentOrg(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req webhookToggleRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	endpoint, err := h.webhookService.SetEnabled(org.ID, id, *req.Enabled)
+	if err != nil {
+		webhookError(c, err, "웹훅을 변경하지 못했습니다.")
+		return
+	}
+	if isHTMX(c) {
+		redirect(c, "/dashboard/webhooks")
+		return
+	}
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DELETE /dashboard/webhooks/:id - 엔드포인트와 전송 기록 삭제
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	org, ok := h.currentOrg(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(org.ID, id); err != nil {
+		webhookError(c, err, "웹훅을 삭제하지 못했습니다.")
+		return
+	}
+	if isHTMX(c) {
+		redirect(c, "/dashboard/webhooks")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /dashboard/webhooks/:id/deliveries - 최근 전송 기록 (HTMX partial)
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	org, ok := h.currentOrg(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	h.renderDeliveries(c, org.ID, id)
+}
+
+// POST /dashboard/webhooks/:id/deliveries/:deliveryID/redeliver - 같은 이벤트를 다시 전송
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	org, ok := h.currentOrg(c)
+	if !ok {
+		return
+	}
+	id, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := uintParam(c, "deliveryID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(org.ID, id, deliveryID)
+	if err != nil {
+		webhookError(c, err, "재전송을 예약하지 못했습니다.")
+		return
+	}
+	if isHTMX(c) {
+		h.renderDeliveries(c, org.ID, id)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) renderDeliveries(c *gin.Context, orgID, id uint) {
+	deliveries, err := h.webhookService.Deliveries(orgID, id)
+	if err != nil {
+		webhookError(c, err, "전송 기록을 불러오지 못했습니다.")
+		return
+	}
+	c.HTML(http.StatusOK, "dashboard/partials/webhook_deliveries.html", gin.H{
+		"endpointID": id,
+		"deliveries": deliveries,
+	})
+}
+
+func (h *WebhookHandler) currentOrg(c *gin.Context) (*models.Organization, bool) {
+	claims := middleware.GetCurrentUser(c)
+	org, err := h.orgService.Current(claims.UserID, claims.Name)
+	if err != nil {
+		log.Printf("Failed to load organization: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "조직 정보를 불러오지 못했습니다."})
+		return nil, false
+	}
+	return org, true
+}
+
+func webhookError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "웹훅을 찾을 수 없습니다."})
+	case errors.Is(err, services.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "전송 기록을 찾을 수 없습니다."})
+	case errors.Is(err, services.ErrUnsafeURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "공인 주소의 http(s) URL만 등록할 수 있습니다."})
+	case errors.Is(err, services.ErrWebhookEventForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "user.registered 이벤트는 관리자만 구독할 수 있습니다."})
+	default:
+		log.Printf("Webhook error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
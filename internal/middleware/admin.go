@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const AdminContextKey = "admin"
+
+// AdminMiddleware 현재 사용자가 ADMIN_EMAILS에 있는지 컨텍스트에 기록. AuthMiddleware 뒤에 사용
+func AdminMiddleware(emails []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		admins[email] = true
+	}
+	return func(c *gin.Context) {
+		user := GetCurrentUser(c)
+		c.Set(AdminContextKey, user != nil && admins[user.Email])
+		c.Next()
+	}
+}
+
+// RequireAdmin 전역 설정을 바꾸는 라우트용. 관리자가 아니면 403
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "관리자만 변경할 수 있습니다."})
+			return
+		}
+		c.Next()
+	}
+}
+
+// IsAdmin AdminMiddleware가 기록한 관리자 여부
+func IsAdmin(c *gin.Context) bool {
+	return c.GetBool(AdminContextKey)
+}
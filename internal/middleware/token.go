@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IngestTokenMiddleware 로그인 쿠키 없이 수집하는 엔드포인트(Prometheus remote-write 등) 인증.
+// "Authorization: Bearer <토큰>" 또는 Basic 인증 비밀번호로 토큰을 받음 (사용자 이름은 무시)
+func IngestTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := "", false
+		if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			got, ok = strings.TrimPrefix(auth, "Bearer "), true
+		} else {
+			_, got, ok = c.Request.BasicAuth()
+		}
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="commet"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
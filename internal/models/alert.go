@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// 알림 규칙 상태
+const (
+	AlertStateOK       = "ok"
+	AlertStatePending  = "pending"
+	AlertStateFiring   = "firing"
+	AlertStateResolved = "resolved"
+)
+
+// AlertRule 지표가 임계값을 넘으면 알리는 규칙.
+// WindowMinutes 동안의 집계값을 Comparator로 Threshold와 비교하고, 조건이 ForMinutes 이상 유지되면 firing.
+// Aggregation이 anomalies면 그 기간에 탐지된 이상치 수를 비교
+type AlertRule struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	OwnerID       uint    `gorm:"index;not null" json:"owner_id"`
+	Name          string  `gorm:"size:100;not null" json:"name"`
+	Category      string  `gorm:"size:50;not null" json:"category"`
+	Label         string  `gorm:"size:100" json:"label,omitempty"`
+	Aggregation   string  `gorm:"size:10;not null" json:"aggregation"`
+	WindowMinutes int     `gorm:"not null" json:"window_minutes"`
+	Comparator    string  `gorm:"size:3;not null" json:"comparator"`
+	Threshold     float64 `gorm:"not null" json:"threshold"`
+	ForMinutes    int     `gorm:"not null;default:0" json:"for_minutes"`
+	Enabled       bool    `gorm:"not null;default:true" json:"enabled"`
+
+	// 알림 채널. 비어 있으면 사용하지 않음
+	NotifyEmail   string `gorm:"size:255" json:"notify_email,omitempty"`
+	NotifyWebhook string `gorm:"size:500" json:"notify_webhook,omitempty"`
+	NotifyInApp   bool   `gorm:"not null;default:true" json:"notify_in_app"`
+
+	// 평가 상태 (ok, pending, firing)
+	State           string     `gorm:"size:10;not null;default:'ok'" json:"state"`
+	StateSince      *time.Time `json:"state_since,omitempty"`
+	LastValue       *float64   `json:"last_value,omitempty"`
+	LastEvaluatedAt *time.Time `json:"last_evaluated_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AlertEvent 규칙 상태 변경 이력 (pending, firing, resolved)
+type AlertEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	RuleID    uint      `gorm:"index;not null" json:"rule_id"`
+	Rule      AlertRule `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	State     string    `gorm:"size:10;not null" json:"state"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// Notification 화면 상단 알림 목록에 표시되는 사용자 알림
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	Title     string     `gorm:"size:200;not null" json:"title"`
+	Body      string     `gorm:"size:1000" json:"body"`
+	Level     string     `gorm:"size:10;not null;default:'info'" json:"level"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+// 알림 규칙 생성/수정 요청 DTO
+type AlertRuleRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Category      string   `json:"category" binding:"required,max=50"`
+	Label         string   `json:"label" binding:"max=100"`
+	Aggregation   string   `json:"aggregation" binding:"required,oneof=sum avg min max count anomalies"`
+	WindowMinutes int      `json:"window_minutes" binding:"required,min=1,max=44640"`
+	Comparator    string   `json:"comparator" binding:"required,oneof=gt gte lt lte"`
+	Threshold     *float64 `json:"threshold" binding:"required"`
+	ForMinutes    int      `json:"for_minutes" binding:"min=0,max=10080"`
+	Enabled       *bool    `json:"enabled"`
+	NotifyEmail   string   `json:"notify_email" binding:"omitempty,email,max=255"`
+	NotifyWebhook string   `json:"notify_webhook" binding:"omitempty,url,max=500"`
+	NotifyInApp   *bool    `json:"notify_in_app"`
+}
+
+func (r *AlertRuleRequest) Apply(rule *AlertRule) {
+	rule.Name = r.Name
+	rule.Category = r.Category
+	rule.Label = r.Label
+	rule.Aggregation = r.Aggregation
+	rule.WindowMinutes = r.WindowMinutes
+	rule.Comparator = r.Comparator
+	rule.Threshold = *r.Threshold
+	rule.ForMinutes = r.ForMinutes
+	rule.Enabled = r.Enabled == nil || *r.Enabled
+	rule.NotifyEmail = r.NotifyEmail
+	rule.NotifyWebhook = r.NotifyWebhook
+	rule.NotifyInApp = r.NotifyInApp == nil || *r.NotifyInApp
+}
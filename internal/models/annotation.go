@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Annotation 시계열 차트에 표시하는 이벤트 (예: 캠페인 시작). EndsAt이 있으면 기간으로 음영 표시
+type Annotation struct {
+	ID       uint `gorm:"primaryKey" json:"id"`
+	AuthorID uint `gorm:"index;not null" json:"author_id"`
+	Author   User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	// DashboardID 지정하면 그 대시보드의 차트에만, 비어 있으면 모든 대시보드에 표시
+	DashboardID *uint      `gorm:"index" json:"dashboard_id,omitempty"`
+	Dashboard   *Dashboard `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	// Category 지정하면 그 카테고리 차트에만, 비어 있으면 모든 카테고리에 표시
+	Category  string     `gorm:"size:50;index" json:"category,omitempty"`
+	StartsAt  time.Time  `gorm:"index;not null" json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Text      string     `gorm:"size:200;not null" json:"text"`
+	Color     string     `gorm:"size:20;not null;default:'orange'" json:"color"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// AnnotationResponse 목록 API 응답 (작성자 이름 포함)
+type AnnotationResponse struct {
+	Annotation
+	AuthorName string `json:"author_name"`
+}
+
+func (a *Annotation) ToResponse() AnnotationResponse {
+	return AnnotationResponse{Annotation: *a, AuthorName: a.Author.Name}
+}
+
+// 주석 생성/수정 요청 DTO. EndsAt이 없으면 시점 표시
+type AnnotationRequest struct {
+	Text        string     `json:"text" binding:"required,max=200"`
+	Color       string     `json:"color" binding:"omitempty,oneof=blue green purple orange red gray"`
+	Category    string     `json:"category" binding:"max=50"`
+	DashboardID *uint      `json:"dashboard_id"`
+	StartsAt    time.Time  `json:"starts_at" binding:"required"`
+	EndsAt      *time.Time `json:"ends_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// 이상치 방향
+const (
+	AnomalyHigh = "high"
+	AnomalyLow  = "low"
+)
+
+// Anomaly 수집된 데이터 포인트 중 최근 기록에서 크게 벗어난 값.
+// Expected는 탐지 방법이 예상한 값, Score는 벗어난 정도 (표준편차 단위)
+type Anomaly struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	DataID     uint      `gorm:"uniqueIndex;not null" json:"data_id"`
+	Category   string    `gorm:"size:50;not null;index:idx_anomalies_category_time,priority:1" json:"category"`
+	Label      string    `gorm:"size:100" json:"label"`
+	Value      float64   `gorm:"not null" json:"value"`
+	Expected   float64   `gorm:"not null" json:"expected"`
+	Score      float64   `gorm:"not null" json:"score"`
+	Method     string    `gorm:"size:20;not null" json:"method"`
+	Direction  string    `gorm:"size:4;not null" json:"direction"`
+	RecordedAt time.Time `gorm:"not null;index:idx_anomalies_category_time,priority:2" json:"recorded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
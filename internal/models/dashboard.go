@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Dashboard 사용자가 구성한 위젯 배치. Shared이면 다른 사용자도 읽기 전용으로 볼 수 있음
+type Dashboard struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OwnerID   uint      `gorm:"index;not null" json:"owner_id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Shared    bool      `gorm:"not null;default:false" json:"shared"`
+	IsDefault bool      `gorm:"not null;default:false" json:"is_default"`
+	Widgets   []Widget  `gorm:"constraint:OnDelete:CASCADE" json:"widgets,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Widget 대시보드에 배치된 차트 하나
+type Widget struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	DashboardID uint      `gorm:"index;not null" json:"dashboard_id"`
+	Type        string    `gorm:"size:30;not null" json:"type"`
+	DataSource  string    `gorm:"size:50;not null" json:"data_source"`
+	Title       string    `gorm:"size:100;not null" json:"title"`
+	Subtitle    string    `gorm:"size:200" json:"subtitle,omitempty"`
+	Width       int       `gorm:"not null;default:2" json:"width"`
+	Height      int       `gorm:"not null;default:2" json:"height"`
+	Position    int       `gorm:"not null;default:0" json:"position"`
+	Options     JSON      `gorm:"type:jsonb" json:"options,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WidgetOptions Widget.Options에 저장되는 차트 옵션
+type WidgetOptions struct {
+	Label  string  `json:"label,omitempty"`
+	Unit   string  `json:"unit,omitempty"`
+	Suffix string  `json:"suffix,omitempty"`
+	Legend string  `json:"legend,omitempty"`
+	Max    float64 `json:"max,omitempty"`
+	Series string  `json:"series,omitempty"`
+	Bucket string  `json:"bucket,omitempty"`
+	Agg    string  `json:"agg,omitempty"`
+	// Forecast 선 차트 뒤에 이어 그릴 예측 모델 (auto, linear, holt_winters)
+	Forecast string `json:"forecast,omitempty"`
+	// GroupBy 값별로 계열을 나눌 태그 이름, Tags는 태그 필터 ("region:eu,channel:web")
+	GroupBy string `json:"group_by,omitempty"`
+	Tags    string `json:"tags,omitempty"`
+	// Split label이면 시계열을 라벨별 계열로, Fill은 빈 버킷 채우기 (zero, null, previous)
+	Split string `json:"split,omitempty"`
+	Fill  string `json:"fill,omitempty"`
+}
+
+// 대시보드 생성/수정 요청 DTO
+type DashboardRequest struct {
+	Name   string `form:"name" json:"name" binding:"required,max=100"`
+	Shared bool   `form:"shared" json:"shared"`
+}
+
+// 위젯 생성/수정 요청 DTO
+type WidgetRequest struct {
+	Type       string `form:"type" json:"type"`
+	DataSource string `form:"data_source" json:"data_source"`
+	Title      string `form:"title" json:"title"`
+	Subtitle   string `form:"subtitle" json:"subtitle"`
+	Width      int    `form:"width" json:"width"`
+	Height     int    `form:"height" json:"height"`
+	Options    JSON   `form:"-" json:"options"`
+}
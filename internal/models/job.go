@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// 백그라운드 작업 상태
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	// JobDead 재시도를 모두 실패했거나 다시 시도해도 소용없는 작업 (수동으로 pending으로 되돌려 재실행)
+	JobDead = "dead"
+)
+
+// Job Postgres에 저장되는 백그라운드 작업. 여러 인스턴스가 FOR UPDATE SKIP LOCKED로 나눠 가져감
+type Job struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Queue   string `gorm:"size:50;not null;index:idx_jobs_due,priority:1" json:"queue"`
+	Type    string `gorm:"size:100;not null" json:"type"`
+	Payload JSON   `gorm:"type:jsonb;not null" json:"payload"`
+	// UniqueKey 같은 키의 작업은 한 번만 등록 (주기 작업의 실행 구간 등)
+	UniqueKey *string `gorm:"size:200;uniqueIndex" json:"unique_key,omitempty"`
+
+	Status      string    `gorm:"size:20;not null;index:idx_jobs_due,priority:2" json:"status"`
+	Attempts    int       `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int       `gorm:"not null" json:"max_attempts"`
+	RunAt       time.Time `gorm:"not null;index:idx_jobs_due,priority:3" json:"run_at"`
+	// LockedUntil 실행 중인 인스턴스가 이 시각까지 결과를 남기지 못하면 다른 인스턴스가 다시 가져감
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	LastError   string     `gorm:"size:1000" json:"last_error,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
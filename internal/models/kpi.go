@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// KPIDefinition 대시보드 요약 카드 정의 (어떤 카테고리를 어떤 방식으로 집계할지)
+type KPIDefinition struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Key         string    `gorm:"uniqueIndex;size:50;not null" json:"key"`
+	Title       string    `gorm:"size:100;not null" json:"title"`
+	Category    string    `gorm:"size:50;not null" json:"category"`
+	Label       string    `gorm:"size:100" json:"label,omitempty"`
+	Aggregation string    `gorm:"size:10;not null" json:"aggregation"`
+	WindowDays  int       `gorm:"not null;default:30" json:"window_days"`
+	Format      string    `gorm:"size:20;not null;default:'number'" json:"format"`
+	Icon        string    `gorm:"size:20" json:"icon"`
+	Color       string    `gorm:"size:20" json:"color"`
+	Position    int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// KPI 정의 생성/수정 요청 DTO
+type KPIRequest struct {
+	Key         string `json:"key" binding:"required,max=50"`
+	Title       string `json:"title" binding:"required,max=100"`
+	Category    string `json:"category" binding:"required,max=50"`
+	Label       string `json:"label" binding:"max=100"`
+	Aggregation string `json:"aggregation" binding:"required,oneof=sum avg min max count"`
+	WindowDays  int    `json:"window_days" binding:"required,min=1,max=366"`
+	Format      string `json:"format" binding:"required,oneof=number currency percent"`
+	Icon        string `json:"icon" binding:"omitempty,oneof=users revenue orders conversion chart"`
+	Color       string `json:"color" binding:"omitempty,oneof=blue green purple orange"`
+	Position    int    `json:"position"`
+}
+
+func (r *KPIRequest) Apply(def *KPIDefinition) {
+	def.Key = r.Key
+	def.Title = r.Title
+	def.Category = r.Category
+	def.Label = r.Label
+	def.Aggregation = r.Aggregation
+	def.WindowDays = r.WindowDays
+	def.Format = r.Format
+	def.Icon = r.Icon
+	def.Color = r.Color
+	def.Position = r.Position
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleMember = "member"
+)
+
+// Organization 웹훅 등 팀 단위 설정의 소유자. 사용자마다 개인 조직이 하나 생김
+type Organization struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID uint         `gorm:"primaryKey" json:"organization_id"`
+	UserID         uint         `gorm:"primaryKey;index" json:"user_id"`
+	Role           string       `gorm:"size:20;not null" json:"role"`
+	Organization   Organization `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
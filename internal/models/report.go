@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Report 대시보드를 정해진 일정(cron)마다 HTML 메일과 PDF로 보내는 예약 보고서
+type Report struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OwnerID     uint      `gorm:"index;not null" json:"owner_id"`
+	DashboardID uint      `gorm:"index;not null" json:"dashboard_id"`
+	Dashboard   Dashboard `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	// Cron 5필드 cron 식 (Timezone 기준, 예: "0 9 * * 1" 매주 월요일 9시)
+	Cron     string `gorm:"size:100;not null" json:"cron"`
+	Timezone string `gorm:"size:64;not null" json:"timezone"`
+	// RangeDays 보고 기간. 실행일 0시 기준 직전 N일
+	RangeDays int `gorm:"not null" json:"range_days"`
+	// Recipients 쉼표로 구분한 받는 사람
+	Recipients string `gorm:"size:1000;not null" json:"recipients"`
+	AttachPDF  bool   `gorm:"not null;default:true" json:"attach_pdf"`
+	Enabled    bool   `gorm:"not null;default:true" json:"enabled"`
+
+	NextRunAt  *time.Time `gorm:"index" json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `gorm:"size:20" json:"last_status,omitempty"`
+	LastError  string     `gorm:"size:500" json:"last_error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *Report) RecipientList() []string {
+	var list []string
+	for _, addr := range strings.Split(r.Recipients, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			list = append(list, addr)
+		}
+	}
+	return list
+}
+
+// 예약 보고서 생성/수정 요청 DTO
+type ReportRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	DashboardID uint     `json:"dashboard_id" binding:"required"`
+	Cron        string   `json:"cron" binding:"required,max=100"`
+	Timezone    string   `json:"timezone" binding:"required,max=64"`
+	RangeDays   int      `json:"range_days" binding:"required,min=1,max=366"`
+	Recipients  []string `json:"recipients" binding:"required,min=1,max=20,dive,email"`
+	AttachPDF   *bool    `json:"attach_pdf"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+func (r *ReportRequest) Apply(report *Report) {
+	report.Name = r.Name
+	report.DashboardID = r.DashboardID
+	report.Cron = r.Cron
+	report.Timezone = r.Timezone
+	report.RangeDays = r.RangeDays
+	report.Recipients = strings.Join(r.Recipients, ",")
+	report.AttachPDF = r.AttachPDF == nil || *r.AttachPDF
+	report.Enabled = r.Enabled == nil || *r.Enabled
+}
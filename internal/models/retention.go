@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// RetentionPolicy 카테고리별 데이터 보관 기간. 0이면 계속 보관.
+// Category가 비어 있으면 자기 정책이 없는 모든 카테고리에 적용
+type RetentionPolicy struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Category string `gorm:"uniqueIndex;size:50;not null;default:''" json:"category"`
+	// RawDays 원본 데이터 포인트(와 탐지된 이상치) 보관 일수
+	RawDays int `gorm:"not null;default:0" json:"raw_days"`
+	// HourlyDays 시간 롤업 보관 일수
+	HourlyDays int `gorm:"not null;default:0" json:"hourly_days"`
+	// DailyMonths 일 롤업 보관 개월 수
+	DailyMonths int       `gorm:"not null;default:0" json:"daily_months"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// 보관 정책 생성/수정 요청 DTO
+type RetentionPolicyRequest struct {
+	Category    string `json:"category" binding:"max=50"`
+	RawDays     int    `json:"raw_days" binding:"min=0,max=3650"`
+	HourlyDays  int    `json:"hourly_days" binding:"min=0,max=3650"`
+	DailyMonths int    `json:"daily_months" binding:"min=0,max=1200"`
+}
+
+func (r *RetentionPolicyRequest) Apply(p *RetentionPolicy) {
+	p.Category = r.Category
+	p.RawDays = r.RawDays
+	p.HourlyDays = r.HourlyDays
+	p.DailyMonths = r.DailyMonths
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// RollupBucket 원본 데이터를 카테고리·라벨·UTC 시간 구간별로 미리 모은 값 (평균은 Sum/Count)
+type RollupBucket struct {
+	Category string    `gorm:"primaryKey;size:50" json:"category"`
+	Bucket   time.Time `gorm:"primaryKey" json:"bucket"`
+	Label    string    `gorm:"primaryKey;size:100" json:"label"`
+	Count    int64     `gorm:"column:value_count;not null" json:"count"`
+	Sum      float64   `gorm:"column:value_sum;type:numeric;not null" json:"sum"`
+	Min      float64   `gorm:"column:value_min;type:decimal(10,2);not null" json:"min"`
+	Max      float64   `gorm:"column:value_max;type:decimal(10,2);not null" json:"max"`
+}
+
+// HourlyRollup 시간 단위 롤업
+type HourlyRollup struct {
+	RollupBucket `gorm:"embedded"`
+}
+
+func (HourlyRollup) TableName() string { return "dashboard_data_hourly" }
+
+// DailyRollup 일 단위(UTC) 롤업
+type DailyRollup struct {
+	RollupBucket `gorm:"embedded"`
+}
+
+func (DailyRollup) TableName() string { return "dashboard_data_daily" }
+
+// RollupState 롤업에 반영한 원본 데이터 위치.
+// LastID까지 반영했고 Horizon까지 반영할 수 있음. PendingID는 그때 본 최대 ID로,
+// 트랜잭션 ID가 PendingXID보다 작은 트랜잭션이 모두 끝나면 Horizon이 됨
+type RollupState struct {
+	Name       string    `gorm:"primaryKey;size:50" json:"name"`
+	LastID     uint      `gorm:"not null;default:0" json:"last_id"`
+	Horizon    uint      `gorm:"not null;default:0" json:"horizon"`
+	PendingID  uint      `gorm:"not null;default:0" json:"pending_id"`
+	PendingXID uint64    `gorm:"column:pending_xid;not null;default:0" json:"pending_xid"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
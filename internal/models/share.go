@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// ShareLink 로그인 없이 대시보드(또는 차트 하나)를 읽기 전용으로 보는 공개 링크.
+// 링크 주소의 토큰은 ID와 만료 시각을 서명한 값이라 저장하지 않음
+type ShareLink struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OwnerID     uint      `gorm:"index;not null" json:"owner_id"`
+	DashboardID uint      `gorm:"index;not null" json:"dashboard_id"`
+	Dashboard   Dashboard `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	// WidgetID 지정하면 이 위젯 차트 하나만 공개
+	WidgetID *uint `json:"widget_id,omitempty"`
+	// PasswordHash 비어 있으면 비밀번호 없이 열림
+	PasswordHash string `gorm:"size:100" json:"-"`
+	// EmbedOrigins iframe으로 넣을 수 있는 출처 (공백 구분, CSP frame-ancestors 값). 비어 있으면 iframe 불가
+	EmbedOrigins string     `gorm:"size:500" json:"embed_origins"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (l *ShareLink) HasPassword() bool {
+	return l.PasswordHash != ""
+}
+
+// Active 폐기되지 않았고 만료 전
+func (l *ShareLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// FrameAncestors Content-Security-Policy frame-ancestors 값
+func (l *ShareLink) FrameAncestors() string {
+	if l.EmbedOrigins == "" {
+		return "'none'"
+	}
+	return l.EmbedOrigins
+}
+
+// 공개 링크 생성 요청 DTO (폼 또는 JSON). WidgetID가 없거나 0이면 대시보드 전체
+type ShareLinkRequest struct {
+	WidgetID      *uint  `form:"widget_id" json:"widget_id"`
+	ExpiresInDays int    `form:"expires_in_days" json:"expires_in_days" binding:"required,min=1,max=365"`
+	Password      string `form:"password" json:"password" binding:"omitempty,min=4,max=72"`
+	EmbedOrigins  string `form:"embed_origins" json:"embed_origins" binding:"max=500"`
+}
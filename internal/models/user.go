@@ -19,10 +19,21 @@ type User struct {
 type DashboardData struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
 	UserID     *uint     `gorm:"index" json:"user_id,omitempty"`
-	Category   string    `gorm:"size:50;not null" json:"category"`
+	Category   string    `gorm:"size:50;not null;index:idx_dashboard_data_category_time,priority:1" json:"category"`
 	Label      string    `gorm:"size:100" json:"label"`
 	Value      float64   `gorm:"type:decimal(10,2);not null" json:"value"`
-	RecordedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"recorded_at"`
+	RecordedAt time.Time `gorm:"default:CURRENT_TIMESTAMP;index:idx_dashboard_data_category_time,priority:2" json:"recorded_at"`
+	// Tags 지역·채널 같은 문자열 키·값 차원 (OTLP 리소스 속성 등). 차트 필터·그룹 기준, 없으면 NULL
+	Tags JSON `gorm:"type:jsonb;index:idx_dashboard_data_tags,type:gin" json:"tags,omitempty"`
+}
+
+// 데이터 수집 요청 DTO. RecordedAt이 없으면 수신 시각
+type DataPointRequest struct {
+	Category   string            `json:"category" binding:"required,max=50"`
+	Label      string            `json:"label" binding:"max=100"`
+	Value      *float64          `json:"value" binding:"required"`
+	RecordedAt *time.Time        `json:"recorded_at"`
+	Tags       map[string]string `json:"tags,omitempty" binding:"max=20,dive,keys,max=100,endkeys,max=200"`
 }
 
 // 회원가입 요청 DTO
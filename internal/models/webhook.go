@@ -0,0 +1,99 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// 웹훅으로 보내는 이벤트 종류
+const (
+	EventUserRegistered = "user.registered"
+	EventMetricIngested = "metric.ingested"
+	EventAlertFired     = "alert.fired"
+	EventAlertResolved  = "alert.resolved"
+)
+
+// WebhookEvents 구독할 수 있는 이벤트 목록 (화면 표시 순서)
+var WebhookEvents = []string{EventUserRegistered, EventMetricIngested, EventAlertFired, EventAlertResolved}
+
+// AdminOnlyEvent 다른 사용자 정보가 담겨 관리자가 소유한 조직만 받는 이벤트
+func AdminOnlyEvent(event string) bool {
+	return event == EventUserRegistered
+}
+
+// 웹훅 전송 상태
+const (
+	DeliveryPending    = "pending"
+	DeliveryDelivering = "delivering"
+	DeliveryRetrying   = "retrying"
+	DeliverySucceeded  = "succeeded"
+	DeliveryFailed     = "failed"
+)
+
+// WebhookEndpoint 조직이 등록한 이벤트 수신 URL
+type WebhookEndpoint struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	OrganizationID uint   `gorm:"index;not null" json:"organization_id"`
+	URL            string `gorm:"size:500;not null" json:"url"`
+	Description    string `gorm:"size:200" json:"description,omitempty"`
+	// Secret 서명(HMAC-SHA256) 키
+	Secret string `gorm:"size:100;not null" json:"-"`
+	// Events 쉼표로 구분한 구독 이벤트
+	Events  string `gorm:"size:500;not null" json:"events"`
+	Enabled bool   `gorm:"not null;default:true" json:"enabled"`
+
+	// 연속 실패 횟수가 한도를 넘으면 자동으로 비활성화
+	ConsecutiveFailures int        `gorm:"not null;default:0" json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `gorm:"size:200" json:"disabled_reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (e *WebhookEndpoint) EventList() []string {
+	if e.Events == "" {
+		return nil
+	}
+	return strings.Split(e.Events, ",")
+}
+
+func (e *WebhookEndpoint) Subscribed(event string) bool {
+	for _, ev := range e.EventList() {
+		if ev == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 이벤트 한 건을 엔드포인트 하나로 보내는 작업과 결과 기록
+type WebhookDelivery struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	EndpointID uint            `gorm:"index;not null" json:"endpoint_id"`
+	Endpoint   WebhookEndpoint `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	EventID    string          `gorm:"size:40;not null" json:"event_id"`
+	EventType  string          `gorm:"size:50;not null" json:"event_type"`
+	Payload    JSON            `gorm:"type:jsonb;not null" json:"payload"`
+
+	Status        string    `gorm:"size:20;not null;index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+
+	// 마지막 시도 결과
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `gorm:"size:1000" json:"response_body,omitempty"`
+	Error          string     `gorm:"size:500" json:"error,omitempty"`
+	DurationMillis int64      `json:"duration_ms,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 웹훅 엔드포인트 생성/수정 요청 DTO (폼 또는 JSON)
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" form:"url" binding:"required,url,max=500"`
+	Description string   `json:"description" form:"description" binding:"max=200"`
+	Events      []string `json:"events" form:"events" binding:"required,min=1,dive,oneof=user.registered metric.ingested alert.fired alert.resolved"`
+}
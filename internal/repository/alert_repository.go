@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+type AlertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+func (r *AlertRepository) ListRules(ownerID uint) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := r.db.Where("owner_id = ?", ownerID).Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// ListEnabled 평가 대상 규칙 (전체 사용자)
+func (r *AlertRepository) ListEnabled() ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := r.db.Where("enabled = ?", true).Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *AlertRepository) FindRule(id uint) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *AlertRepository) CreateRule(rule *models.AlertRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *AlertRepository) UpdateRule(rule *models.AlertRule) error {
+	return r.db.Save(rule).Error
+}
+
+// DeleteRule 규칙과 상태 이력 삭제
+func (r *AlertRepository) DeleteRule(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", id).Delete(&models.AlertEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.AlertRule{}, id).Error
+	})
+}
+
+// SaveEvaluation 평가 결과(상태 컬럼)와 상태 변경 이력을 함께 저장. event가 nil이면 상태만 갱신
+func (r *AlertRepository) SaveEvaluation(rule *models.AlertRule, event *models.AlertEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(rule).Select("state", "state_since", "last_value", "last_evaluated_at").Updates(rule).Error
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			return nil
+		}
+		return tx.Create(event).Error
+	})
+}
+
+// ListEvents 규칙의 최근 상태 변경 이력
+func (r *AlertRepository) ListEvents(ruleID uint, limit int) ([]models.AlertEvent, error) {
+	var events []models.AlertEvent
+	err := r.db.Where("rule_id = ?", ruleID).Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// 여러 인스턴스 중 한 곳에서만 규칙을 평가하도록 잡는 advisory lock 키
+const alertEvaluationLockKey = 7301001
+
+// WithEvaluationLock 트랜잭션 advisory lock을 잡은 동안 fn 실행.
+// 다른 인스턴스가 이미 평가 중이면 fn을 실행하지 않고 false 반환
+func (r *AlertRepository) WithEvaluationLock(fn func() error) (bool, error) {
+	acquired := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", alertEvaluationLockKey).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		return fn()
+	})
+	return acquired, err
+}
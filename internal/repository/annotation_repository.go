@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+// 차트 하나에 불러오는 최대 주석 수
+const maxAnnotations = 500
+
+// AnnotationFilter DashboardID가 nil이면 전체 대시보드용 주석만, 있으면 그 대시보드 주석도 포함.
+// Category도 같은 방식 (빈 값이면 카테고리 지정 없는 주석만)
+type AnnotationFilter struct {
+	DashboardID *uint
+	Category    string
+	From        *time.Time
+	To          *time.Time
+}
+
+// AnnotationRepository 차트 주석 저장소
+type AnnotationRepository struct {
+	db *gorm.DB
+}
+
+func NewAnnotationRepository(db *gorm.DB) *AnnotationRepository {
+	return &AnnotationRepository{db: db}
+}
+
+// List 기간과 겹치는 주석 (시작 시각 순, 작성자 포함)
+func (r *AnnotationRepository) List(f AnnotationFilter) ([]models.Annotation, error) {
+	query := r.db.Preload("Author")
+	if f.DashboardID != nil {
+		query = query.Where("dashboard_id IS NULL OR dashboard_id = ?", *f.DashboardID)
+	} else {
+		query = query.Where("dashboard_id IS NULL")
+	}
+	if f.Category != "" {
+		query = query.Where("category = '' OR category = ?", f.Category)
+	} else {
+		query = query.Where("category = ''")
+	}
+	if f.From != nil {
+		query = query.Where("COALESCE(ends_at, starts_at) >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("starts_at < ?", *f.To)
+	}
+
+	var anns []models.Annotation
+	err := query.Order("starts_at ASC, id ASC").Limit(maxAnnotations).Find(&anns).Error
+	return anns, err
+}
+
+func (r *AnnotationRepository) Find(id uint) (*models.Annotation, error) {
+	var a models.Annotation
+	if err := r.db.Preload("Author").First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *AnnotationRepository) Create(a *models.Annotation) error {
+	return r.db.Create(a).Error
+}
+
+func (r *AnnotationRepository) Update(a *models.Annotation) error {
+	return r.db.Model(a).Select("dashboard_id", "category", "starts_at", "ends_at", "text", "color").Updates(a).Error
+}
+
+func (r *AnnotationRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Annotation{}, id).Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 한 번에 조회하는 최대 이상치 수
+const maxAnomalies = 1000
+
+// AnomalyFilter Label이 비어 있으면 카테고리 전체 (From 포함, To 미포함)
+type AnomalyFilter struct {
+	Category string
+	Label    string
+	From     time.Time
+	To       time.Time
+}
+
+// AnomalyRepository 이상치 탐지 결과 저장소
+type AnomalyRepository struct {
+	db *gorm.DB
+}
+
+func NewAnomalyRepository(db *gorm.DB) *AnomalyRepository {
+	return &AnomalyRepository{db: db}
+}
+
+func (r *AnomalyRepository) filtered(f AnomalyFilter) *gorm.DB {
+	query := r.db.Model(&models.Anomaly{}).
+		Where("category = ? AND recorded_at >= ? AND recorded_at < ?", f.Category, f.From, f.To)
+	if f.Label != "" {
+		query = query.Where("label = ?", f.Label)
+	}
+	return query
+}
+
+// List 기간 안의 이상치 (기록 시각 순)
+func (r *AnomalyRepository) List(f AnomalyFilter) ([]models.Anomaly, error) {
+	var anomalies []models.Anomaly
+	err := r.filtered(f).Order("recorded_at ASC, id ASC").Limit(maxAnomalies).Find(&anomalies).Error
+	return anomalies, err
+}
+
+// Count 기간 안의 이상치 수 (알림 규칙 평가용)
+func (r *AnomalyRepository) Count(f AnomalyFilter) (int64, error) {
+	var n int64
+	err := r.filtered(f).Count(&n).Error
+	return n, err
+}
+
+// Save 같은 데이터 포인트는 한 번만 저장 (작업이 재시도되어도 중복 없음)
+func (r *AnomalyRepository) Save(anomalies []models.Anomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "data_id"}}, DoNothing: true}).
+		Create(&anomalies).Error
+}
@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+type DashboardRepository struct {
+	db *gorm.DB
+	// rollups true면 시계열 조회에 롤업 테이블 사용 (PlanRollup)
+	rollups bool
+}
+
+func NewDashboardRepository(db *gorm.DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+// UseRollups 시계열 조회에서 온전히 포함되는 시·일 구간을 롤업 테이블로 집계
+func (r *DashboardRepository) UseRollups() {
+	r.rollups = true
+}
+
+// DataFilter 대시보드 데이터 조회 조건 (From 포함, To 미포함)
+type DataFilter struct {
+	Category string
+	From     *time.Time
+	To       *time.Time
+	// Tags 모든 태그가 일치하는 데이터만
+	Tags map[string]string
+}
+
+// tagsCondition 태그 포함 조건 (jsonb @>, GIN 인덱스 사용)
+func tagsCondition(tags map[string]string) (string, string) {
+	b, _ := json.Marshal(tags)
+	return "tags @> ?::jsonb", string(b)
+}
+
+func (r *DashboardRepository) GetDataByCategory(category string) ([]models.DashboardData, error) {
+	var data []models.DashboardData
+	err := r.db.Where("category = ?", category).Order("id ASC").Find(&data).Error
+	return data, err
+}
+
+func (r *DashboardRepository) GetAllCategories() ([]string, error) {
+	var categories []string
+	err := r.db.Model(&models.DashboardData{}).Distinct("category").Pluck("category", &categories).Error
+	return categories, err
+}
+
+// CreateData 수집된 데이터 일괄 저장. 저장 후 ID가 채워짐.
+// 많으면 1000개씩 나눠 넣되 한 트랜잭션으로 (일부만 저장되지 않음)
+func (r *DashboardRepository) CreateData(data []models.DashboardData) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&data, 1000).Error
+	})
+}
+
+// GetDataSince afterID 이후에 저장된 데이터 (실시간 스트림 재연결 시 누락분 재전송).
+// categories가 비어 있으면 전체 카테고리
+func (r *DashboardRepository) GetDataSince(afterID uint, categories []string, limit int) ([]models.DashboardData, error) {
+	query := r.db.Where("id > ?", afterID)
+	if len(categories) > 0 {
+		query = query.Where("category IN ?", categories)
+	}
+
+	var data []models.DashboardData
+	err := query.Order("id ASC").Limit(limit).Find(&data).Error
+	return data, err
+}
+
+// MaxDataID 가장 최근에 저장된 데이터 ID (없으면 0)
+func (r *DashboardRepository) MaxDataID() (uint, error) {
+	var id uint
+	err := r.db.Model(&models.DashboardData{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// StreamData 조건에 맞는 데이터를 한 행씩 fn에 전달 (대용량 내보내기용)
+func (r *DashboardRepository) StreamData(filter DataFilter, fn func(models.DashboardData) error) error {
+	rows, err := r.filtered(filter).Model(&models.DashboardData{}).Order("recorded_at ASC, id ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.DashboardData
+		if err := r.db.ScanRows(rows, &d); err != nil {
+			return err
+		}
+		if err := fn(d); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *DashboardRepository) filtered(filter DataFilter) *gorm.DB {
+	q := r.db.Where("category = ?", filter.Category)
+	if filter.From != nil {
+		q = q.Where("recorded_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("recorded_at < ?", *filter.To)
+	}
+	if len(filter.Tags) > 0 {
+		q = q.Where(tagsCondition(filter.Tags))
+	}
+	return q
+}
+
+// Bucket 시계열 집계 단위 (PostgreSQL date_trunc 필드명과 동일)
+type Bucket string
+
+const (
+	BucketHour  Bucket = "hour"
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+// Aggregation 버킷/라벨 단위 집계 함수
+type Aggregation string
+
+const (
+	AggSum   Aggregation = "sum"
+	AggAvg   Aggregation = "avg"
+	AggMin   Aggregation = "min"
+	AggMax   Aggregation = "max"
+	AggCount Aggregation = "count"
+)
+
+// SQL 식은 이 목록에서만 가져오므로 사용자 입력이 쿼리에 직접 들어가지 않음
+var aggregationSQL = map[Aggregation]string{
+	AggSum:   "COALESCE(SUM(value), 0)",
+	AggAvg:   "COALESCE(AVG(value), 0)",
+	AggMin:   "COALESCE(MIN(value), 0)",
+	AggMax:   "COALESCE(MAX(value), 0)",
+	AggCount: "COUNT(*)",
+}
+
+func (a Aggregation) Valid() bool {
+	_, ok := aggregationSQL[a]
+	return ok
+}
+
+func (b Bucket) Valid() bool {
+	switch b {
+	case BucketHour, BucketDay, BucketWeek, BucketMonth:
+		return true
+	}
+	return false
+}
+
+// TimeSeriesQuery 기간 내 데이터를 버킷 단위로 집계하는 조회 조건
+// 버킷 경계는 Location 기준 (예: Asia/Seoul 자정)
+type TimeSeriesQuery struct {
+	Category    string
+	Label       string
+	From        time.Time
+	To          time.Time
+	Bucket      Bucket
+	Aggregation Aggregation
+	Location    *time.Location
+	// Tags 모든 태그가 일치하는 데이터만 (롤업에는 태그가 없어 원본에서 집계)
+	Tags map[string]string
+}
+
+type TimeSeriesPoint struct {
+	Bucket time.Time
+	Value  float64
+}
+
+// GroupedTimeSeriesPoint 라벨이나 태그 값별 버킷 집계 결과. 태그가 없는 데이터의 Group은 빈 문자열
+type GroupedTimeSeriesPoint struct {
+	Group  string
+	Bucket time.Time
+	Value  float64
+}
+
+// LabelValue 라벨별 집계 결과
+type LabelValue struct {
+	Label string
+	Value float64
+}
+
+func (r *DashboardRepository) GetTimeSeries(q TimeSeriesQuery) ([]TimeSeriesPoint, error) {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	tz := loc.String()
+
+	var plan RollupPlan
+	useRollup := false
+	if r.rollups && len(q.Tags) == 0 {
+		plan, useRollup = PlanRollup(q)
+	}
+
+	var points []TimeSeriesPoint
+	var err error
+	if useRollup {
+		err = r.rollupTimeSeries(q, plan, tz, false).Scan(&points).Error
+	} else {
+		bucketExpr := "date_trunc(?, recorded_at AT TIME ZONE ?) AT TIME ZONE ?"
+		query := r.db.Model(&models.DashboardData{}).
+			Select(bucketExpr+" AS bucket, "+aggregationSQL[q.Aggregation]+" AS value", string(q.Bucket), tz, tz).
+			Where("category = ? AND recorded_at >= ? AND recorded_at < ?", q.Category, q.From, q.To)
+		if q.Label != "" {
+			query = query.Where("label = ?", q.Label)
+		}
+		if len(q.Tags) > 0 {
+			query = query.Where(tagsCondition(q.Tags))
+		}
+		err = query.Group("bucket").Order("bucket ASC").Scan(&points).Error
+	}
+	for i := range points {
+		points[i].Bucket = points[i].Bucket.In(loc)
+	}
+	return points, err
+}
+
+// GetGroupedTimeSeries 태그 key의 값별로 나눈 시계열. key가 비어 있으면 라벨별 (태그 조건이 없으면 롤업 사용)
+func (r *DashboardRepository) GetGroupedTimeSeries(q TimeSeriesQuery, key string) ([]GroupedTimeSeriesPoint, error) {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	tz := loc.String()
+
+	var plan RollupPlan
+	useRollup := false
+	if r.rollups && key == "" && len(q.Tags) == 0 {
+		plan, useRollup = PlanRollup(q)
+	}
+
+	var points []GroupedTimeSeriesPoint
+	var err error
+	if useRollup {
+		err = r.rollupTimeSeries(q, plan, tz, true).Scan(&points).Error
+	} else {
+		// 롤업과 같이 라벨이 없는 행은 빈 문자열 그룹으로
+		groupExpr, groupArgs := "COALESCE(label, '')", []any{}
+		if key != "" {
+			groupExpr, groupArgs = "COALESCE(tags->>?, '')", []any{key}
+		}
+		query := r.db.Model(&models.DashboardData{}).
+			Select(groupExpr+` AS "group", date_trunc(?, recorded_at AT TIME ZONE ?) AT TIME ZONE ? AS bucket, `+aggregationSQL[q.Aggregation]+" AS value",
+				append(groupArgs, string(q.Bucket), tz, tz)...).
+			Where("category = ? AND recorded_at >= ? AND recorded_at < ?", q.Category, q.From, q.To)
+		if q.Label != "" {
+			query = query.Where("label = ?", q.Label)
+		}
+		if len(q.Tags) > 0 {
+			query = query.Where(tagsCondition(q.Tags))
+		}
+		err = query.Group(`"group", bucket`).Order(`"group" ASC, bucket ASC`).Scan(&points).Error
+	}
+	for i := range points {
+		points[i].Bucket = points[i].Bucket.In(loc)
+	}
+	return points, err
+}
+
+// 롤업 행(건수·합계·최소·최대)을 다시 모으는 집계 식
+var rollupAggregationSQL = map[Aggregation]string{
+	AggSum:   "COALESCE(SUM(value_sum), 0)",
+	AggAvg:   "COALESCE(SUM(value_sum) / NULLIF(SUM(value_count), 0), 0)",
+	AggMin:   "COALESCE(MIN(value_min), 0)",
+	AggMax:   "COALESCE(MAX(value_max), 0)",
+	AggCount: "COALESCE(SUM(value_count), 0)",
+}
+
+// rollupTimeSeries 롤업 구간은 롤업 테이블에서, 기간 앞뒤의 남는 부분과 아직 롤업에 반영되지 않은 행은 원본에서 읽어 함께 집계.
+// 반영 위치(last_id)를 같은 문장에서 읽으므로 갱신 중에도 한 행이 두 번 세어지거나 빠지지 않음. byLabel이면 라벨별로 ("group" 열).
+// 롤업은 라벨이 없는 행을 빈 문자열로 모으므로 원본도 같게 맞춤
+func (r *DashboardRepository) rollupTimeSeries(q TimeSeriesQuery, plan RollupPlan, tz string, byLabel bool) *gorm.DB {
+	labelCond := ""
+	rollupArgs := []any{q.Category, plan.From, plan.To}
+	rawArgs := []any{q.Category, q.From, q.To}
+	if q.Label != "" {
+		labelCond = " AND label = ?"
+		rollupArgs = append(rollupArgs, q.Label)
+		rawArgs = append(rawArgs, q.Label)
+	}
+	rawArgs = append(rawArgs, plan.From, plan.To, rollupStateName)
+
+	groupCol, groupBy := "", "1"
+	if byLabel {
+		groupCol, groupBy = `label AS "group", `, "1, 2"
+	}
+
+	sql := `SELECT ` + groupCol + `date_trunc(?, bucket AT TIME ZONE ?) AT TIME ZONE ? AS bucket, ` + rollupAggregationSQL[q.Aggregation] + ` AS value
+FROM (
+	SELECT bucket, label, value_count, value_sum, value_min, value_max FROM ` + plan.Table + `
+	WHERE category = ? AND bucket >= ? AND bucket < ?` + labelCond + `
+	UNION ALL
+	SELECT recorded_at, COALESCE(label, ''), 1, value, value, value FROM dashboard_data
+	WHERE category = ? AND recorded_at >= ? AND recorded_at < ?` + labelCond + `
+		AND (recorded_at < ? OR recorded_at >= ? OR id > (SELECT COALESCE(MAX(last_id), 0) FROM rollup_states WHERE name = ?))
+) AS src
+GROUP BY ` + groupBy + `
+ORDER BY ` + groupBy
+	args := append([]any{string(q.Bucket), tz, tz}, rollupArgs...)
+	return r.db.Raw(sql, append(args, rawArgs...)...)
+}
+
+// GetLabelTotals 라벨별 집계 (바/파이 차트용). 라벨이 처음 등장한 순서 유지
+func (r *DashboardRepository) GetLabelTotals(filter DataFilter, agg Aggregation) ([]LabelValue, error) {
+	var values []LabelValue
+	err := r.filtered(filter).Model(&models.DashboardData{}).
+		Select("label, " + aggregationSQL[agg] + " AS value").
+		Group("label").
+		Order("MIN(id) ASC").
+		Scan(&values).Error
+	return values, err
+}
+
+// GetTagTotals 태그 key의 값별 집계 (태그가 없는 데이터는 빈 문자열). 값이 처음 등장한 순서 유지
+func (r *DashboardRepository) GetTagTotals(filter DataFilter, key string, agg Aggregation) ([]LabelValue, error) {
+	var values []LabelValue
+	err := r.filtered(filter).Model(&models.DashboardData{}).
+		Select("COALESCE(tags->>?, '') AS label, "+aggregationSQL[agg]+" AS value", key).
+		Group("1").
+		Order("MIN(id) ASC").
+		Scan(&values).Error
+	return values, err
+}
+
+// GetTagKeys since 이후 데이터에 쓰인 태그 이름 (category가 비어 있으면 전체, 최대 limit개)
+func (r *DashboardRepository) GetTagKeys(category string, since time.Time, limit int) ([]string, error) {
+	query := r.db.Model(&models.DashboardData{}).
+		Where("recorded_at >= ? AND tags IS NOT NULL", since)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var keys []string
+	err := r.db.Table("(?) AS t", query.Select("DISTINCT jsonb_object_keys(tags) AS key")).
+		Order("key ASC").Limit(limit).Pluck("key", &keys).Error
+	return keys, err
+}
+
+// Aggregate 기간 [from, to) 전체를 하나의 값으로 집계 (KPI 카드용)
+func (r *DashboardRepository) Aggregate(category, label string, from, to time.Time, agg Aggregation) (float64, error) {
+	query := r.db.Model(&models.DashboardData{}).
+		Where("category = ? AND recorded_at >= ? AND recorded_at < ?", category, from, to)
+	if label != "" {
+		query = query.Where("label = ?", label)
+	}
+
+	var value float64
+	err := query.Select(aggregationSQL[agg]).Scan(&value).Error
+	return value, err
+}
+
+// GetDataByIDs ID로 데이터 조회 (기록 시각 순)
+func (r *DashboardRepository) GetDataByIDs(ids []uint) ([]models.DashboardData, error) {
+	var data []models.DashboardData
+	err := r.db.Where("id IN ?", ids).Order("recorded_at ASC, id ASC").Find(&data).Error
+	return data, err
+}
+
+// GetHistory before 이전(from 이후)의 카테고리·라벨 데이터 중 최근 limit개 (기록 시각 순)
+func (r *DashboardRepository) GetHistory(category, label string, from, before time.Time, limit int) ([]models.DashboardData, error) {
+	var data []models.DashboardData
+	err := r.db.Where("category = ? AND label = ? AND recorded_at >= ? AND recorded_at < ?", category, label, from, before).
+		Order("recorded_at DESC, id DESC").Limit(limit).Find(&data).Error
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+	return data, err
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepositoryInterface 작업 큐 저장소
+type JobRepositoryInterface interface {
+	Enqueue(job *models.Job) error
+	Claim(queue string, types []string, now time.Time, lease time.Duration, limit int) ([]models.Job, error)
+	Complete(id uint, now time.Time) error
+	Retry(id uint, runAt time.Time, lastError string) error
+	Bury(id uint, now time.Time, lastError string) error
+	Release(id uint) error
+	DeleteSucceeded(before time.Time) (int64, error)
+}
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+var _ JobRepositoryInterface = (*JobRepository)(nil)
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Enqueue 작업 등록. UniqueKey가 이미 있는 작업은 건너뜀
+func (r *JobRepository) Enqueue(job *models.Job) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(job).Error
+}
+
+// Claim 큐에서 실행할 차례가 된 작업을 가져와 lease 동안 잠금.
+// lease가 끝났는데 running으로 남은 작업(실행 중 서버가 죽은 경우)도 다시 가져감
+func (r *JobRepository) Claim(queue string, types []string, now time.Time, lease time.Duration, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("queue = ? AND type IN ?", queue, types).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+				models.JobPending, now, models.JobRunning, now).
+			Order("run_at ASC, id ASC").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		lockedUntil := now.Add(lease)
+		ids := make([]uint, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = models.JobRunning
+			jobs[i].Attempts++
+			jobs[i].LockedUntil = &lockedUntil
+		}
+		return tx.Model(&models.Job{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":       models.JobRunning,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"locked_until": lockedUntil,
+		}).Error
+	})
+	return jobs, err
+}
+
+// Complete 성공 처리
+func (r *JobRepository) Complete(id uint, now time.Time) error {
+	return r.running(id).Updates(map[string]any{
+		"status":       models.JobSucceeded,
+		"locked_until": nil,
+		"last_error":   "",
+		"finished_at":  now,
+	}).Error
+}
+
+// Retry runAt에 다시 실행하도록 대기 상태로 되돌림
+func (r *JobRepository) Retry(id uint, runAt time.Time, lastError string) error {
+	return r.running(id).Updates(map[string]any{
+		"status":       models.JobPending,
+		"run_at":       runAt,
+		"locked_until": nil,
+		"last_error":   lastError,
+	}).Error
+}
+
+// Bury 더 이상 재시도하지 않는 dead 상태로 보관
+func (r *JobRepository) Bury(id uint, now time.Time, lastError string) error {
+	return r.running(id).Updates(map[string]any{
+		"status":       models.JobDead,
+		"locked_until": nil,
+		"last_error":   lastError,
+		"finished_at":  now,
+	}).Error
+}
+
+// Release 종료 중에 끝내지 못한 작업을 시도 횟수를 되돌려 바로 다시 실행할 수 있게 함
+func (r *JobRepository) Release(id uint) error {
+	return r.running(id).Updates(map[string]any{
+		"status":       models.JobPending,
+		"attempts":     gorm.Expr("attempts - 1"),
+		"locked_until": nil,
+	}).Error
+}
+
+// DeleteSucceeded before 이전에 끝난 성공 작업 삭제
+func (r *JobRepository) DeleteSucceeded(before time.Time) (int64, error) {
+	result := r.db.Where("status = ? AND finished_at < ?", models.JobSucceeded, before).Delete(&models.Job{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *JobRepository) running(id uint) *gorm.DB {
+	return r.db.Model(&models.Job{}).Where("id = ? AND status = ?", id, models.JobRunning)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+// KPIRepositoryInterface KPI 정의 저장소
+type KPIRepositoryInterface interface {
+	List() ([]models.KPIDefinition, error)
+	FindByID(id uint) (*models.KPIDefinition, error)
+	Create(def *models.KPIDefinition) error
+	Update(def *models.KPIDefinition) error
+	Delete(id uint) error
+}
+
+type KPIRepository struct {
+	db *gorm.DB
+}
+
+var _ KPIRepositoryInterface = (*KPIRepository)(nil)
+
+func NewKPIRepository(db *gorm.DB) *KPIRepository {
+	return &KPIRepository{db: db}
+}
+
+func (r *KPIRepository) List() ([]models.KPIDefinition, error) {
+	var defs []models.KPIDefinition
+	err := r.db.Order("position ASC, id ASC").Find(&defs).Error
+	return defs, err
+}
+
+func (r *KPIRepository) FindByID(id uint) (*models.KPIDefinition, error) {
+	var def models.KPIDefinition
+	if err := r.db.First(&def, id).Error; err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+func (r *KPIRepository) Create(def *models.KPIDefinition) error {
+	return r.db.Create(def).Error
+}
+
+func (r *KPIRepository) Update(def *models.KPIDefinition) error {
+	return r.db.Save(def).Error
+}
+
+func (r *KPIRepository) Delete(id uint) error {
+	return r.db.Delete(&models.KPIDefinition{}, id).Error
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrWidgetMismatch = errors.New("widget does not belong to dashboard")
+
+// LayoutRepository 사용자 대시보드와 위젯 저장소
+type LayoutRepository struct {
+	db *gorm.DB
+}
+
+func NewLayoutRepository(db *gorm.DB) *LayoutRepository {
+	return &LayoutRepository{db: db}
+}
+
+// FindDashboard 위젯을 위치 순서대로 함께 조회
+func (r *LayoutRepository) FindDashboard(id uint) (*models.Dashboard, error) {
+	var d models.Dashboard
+	err := r.db.Preload("Widgets", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	}).First(&d, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *LayoutRepository) FindDefault(ownerID uint) (*models.Dashboard, error) {
+	var d models.Dashboard
+	err := r.db.Where("owner_id = ? AND is_default = ?", ownerID, true).First(&d).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.FindDashboard(d.ID)
+}
+
+// ListVisible 본인 대시보드와 다른 사용자가 공유한 대시보드
+func (r *LayoutRepository) ListVisible(userID uint) ([]models.Dashboard, error) {
+	var ds []models.Dashboard
+	err := r.db.Where("owner_id = ? OR shared = ?", userID, true).
+		Order("is_default DESC, name ASC, id ASC").
+		Find(&ds).Error
+	return ds, err
+}
+
+// CreateDashboard 위젯이 있으면 함께 생성
+func (r *LayoutRepository) CreateDashboard(d *models.Dashboard) error {
+	return r.db.Create(d).Error
+}
+
+func (r *LayoutRepository) UpdateDashboard(d *models.Dashboard) error {
+	return r.db.Model(d).Select("name", "shared").Updates(d).Error
+}
+
+func (r *LayoutRepository) DeleteDashboard(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("dashboard_id = ?", id).Delete(&models.Widget{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Dashboard{}, id).Error
+	})
+}
+
+func (r *LayoutRepository) FindWidget(dashboardID, widgetID uint) (*models.Widget, error) {
+	var w models.Widget
+	err := r.db.Where("dashboard_id = ?", dashboardID).First(&w, widgetID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// CreateWidget 대시보드 맨 뒤에 추가
+func (r *LayoutRepository) CreateWidget(w *models.Widget) error {
+	var maxPos *int
+	if err := r.db.Model(&models.Widget{}).Where("dashboard_id = ?", w.DashboardID).
+		Select("MAX(position)").Scan(&maxPos).Error; err != nil {
+		return err
+	}
+	if maxPos != nil {
+		w.Position = *maxPos + 1
+	}
+	return r.db.Create(w).Error
+}
+
+func (r *LayoutRepository) UpdateWidget(w *models.Widget) error {
+	return r.db.Save(w).Error
+}
+
+func (r *LayoutRepository) DeleteWidget(dashboardID, widgetID uint) error {
+	return r.db.Where("dashboard_id = ?", dashboardID).Delete(&models.Widget{}, widgetID).Error
+}
+
+// UpdatePositions ids 순서대로 위치 저장. 다른 대시보드의 위젯이 섞여 있으면 전체 취소
+func (r *LayoutRepository) UpdatePositions(dashboardID uint, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for pos, id := range ids {
+			res := tx.Model(&models.Widget{}).
+				Where("id = ? AND dashboard_id = ?", id, dashboardID).
+				Update("position", pos)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrWidgetMismatch
+			}
+		}
+		return nil
+	})
+}
+
+// ImportDashboard 가져온 대시보드와 KPI 정의를 한 트랜잭션으로 저장.
+// d가 nil이면 KPI만 저장, d.ID가 있으면 이름·공유 여부를 바꾸고 위젯을 모두 교체. KPI는 ID가 있으면 수정
+func (r *LayoutRepository) ImportDashboard(d *models.Dashboard, kpis []models.KPIDefinition) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		switch {
+		case d == nil:
+		case d.ID == 0:
+			if err := tx.Create(d).Error; err != nil {
+				return err
+			}
+		default:
+			if err := tx.Model(d).Select("name", "shared").Updates(d).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("dashboard_id = ?", d.ID).Delete(&models.Widget{}).Error; err != nil {
+				return err
+			}
+			for i := range d.Widgets {
+				d.Widgets[i].DashboardID = d.ID
+			}
+			if len(d.Widgets) > 0 {
+				if err := tx.Create(&d.Widgets).Error; err != nil {
+					return err
+				}
+			}
+		}
+		for i := range kpis {
+			if err := tx.Save(&kpis[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
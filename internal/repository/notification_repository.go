@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(n *models.Notification) error {
+	return r.db.Create(n).Error
+}
+
+func (r *NotificationRepository) ListRecent(userID uint, limit int) ([]models.Notification, error) {
+	var list []models.Notification
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *NotificationRepository) MarkAllRead(userID uint, at time.Time) error {
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at).Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+// OrganizationRepositoryInterface 조직과 멤버십 저장소
+type OrganizationRepositoryInterface interface {
+	ListForUser(userID uint) ([]models.Organization, error)
+	MemberOrgIDs(userID uint) ([]uint, error)
+	OwnedOrgIDs(emails []string) ([]uint, error)
+	CreateWithOwner(org *models.Organization, ownerID uint) error
+}
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+var _ OrganizationRepositoryInterface = (*OrganizationRepository)(nil)
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// ListForUser 사용자가 속한 조직 (가입 순)
+func (r *OrganizationRepository) ListForUser(userID uint) ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := r.db.Joins("JOIN organization_members m ON m.organization_id = organizations.id").
+		Where("m.user_id = ?", userID).
+		Order("m.created_at ASC, organizations.id ASC").
+		Find(&orgs).Error
+	return orgs, err
+}
+
+// MemberOrgIDs 사용자가 속한 조직 ID 목록
+func (r *OrganizationRepository) MemberOrgIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.OrganizationMember{}).Where("user_id = ?", userID).Pluck("organization_id", &ids).Error
+	return ids, err
+}
+
+// OwnedOrgIDs emails 중 한 사용자가 소유자인 조직 ID 목록
+func (r *OrganizationRepository) OwnedOrgIDs(emails []string) ([]uint, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	var ids []uint
+	err := r.db.Model(&models.OrganizationMember{}).
+		Joins("JOIN users u ON u.id = organization_members.user_id AND u.deleted_at IS NULL").
+		Where("organization_members.role = ? AND u.email IN ?", models.OrgRoleOwner, emails).
+		Distinct().Pluck("organization_members.organization_id", &ids).Error
+	return ids, err
+}
+
+// CreateWithOwner 조직과 소유자 멤버십을 함께 생성
+func (r *OrganizationRepository) CreateWithOwner(org *models.Organization, ownerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         ownerID,
+			Role:           models.OrgRoleOwner,
+		}).Error
+	})
+}
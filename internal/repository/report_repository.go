@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+func (r *ReportRepository) List(ownerID uint) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.db.Where("owner_id = ?", ownerID).Order("id ASC").Find(&reports).Error
+	return reports, err
+}
+
+func (r *ReportRepository) Find(id uint) (*models.Report, error) {
+	var report models.Report
+	if err := r.db.First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *ReportRepository) Create(report *models.Report) error {
+	return r.db.Create(report).Error
+}
+
+func (r *ReportRepository) Update(report *models.Report) error {
+	return r.db.Save(report).Error
+}
+
+func (r *ReportRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Report{}, id).Error
+}
+
+// ClaimDue 실행 시각이 된 보고서마다 schedule을 호출해 다음 실행 시각을 저장.
+// 행을 잠근 채 처리하므로 여러 인스턴스가 같은 실행을 중복으로 가져가지 않음
+func (r *ReportRepository) ClaimDue(now time.Time, limit int, schedule func(report *models.Report) error) (int, error) {
+	claimed := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var reports []models.Report
+		err := tx.Where("enabled AND next_run_at <= ?", now).
+			Order("next_run_at ASC").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&reports).Error
+		if err != nil {
+			return err
+		}
+		for i := range reports {
+			if err := schedule(&reports[i]); err != nil {
+				return err
+			}
+			if err := tx.Model(&reports[i]).Update("next_run_at", reports[i].NextRunAt).Error; err != nil {
+				return err
+			}
+		}
+		claimed = len(reports)
+		return nil
+	})
+	return claimed, err
+}
+
+// SaveResult 마지막 실행 결과
+func (r *ReportRepository) SaveResult(id uint, at time.Time, status, lastError string) error {
+	return r.db.Model(&models.Report{}).Where("id = ?", id).Updates(map[string]any{
+		"last_run_at": at,
+		"last_status": status,
+		"last_error":  lastError,
+	}).Error
+}
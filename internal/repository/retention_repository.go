@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+// RetentionData 보관 정책이 정리하는 데이터 종류
+type RetentionData string
+
+const (
+	RetainRaw       RetentionData = "raw"
+	RetainHourly    RetentionData = "hourly"
+	RetainDaily     RetentionData = "daily"
+	RetainAnomalies RetentionData = "anomalies"
+)
+
+// retentionTables 데이터 종류별 테이블과 시각 컬럼
+var retentionTables = map[RetentionData][2]string{
+	RetainRaw:       {"dashboard_data", "recorded_at"},
+	RetainHourly:    {models.HourlyRollup{}.TableName(), "bucket"},
+	RetainDaily:     {models.DailyRollup{}.TableName(), "bucket"},
+	RetainAnomalies: {"anomalies", "recorded_at"},
+}
+
+// RetentionTarget 지울 행 조건: Before 이전 행 중 Category (Default면 Exclude에 없는 모든 카테고리)
+type RetentionTarget struct {
+	Data     RetentionData
+	Category string
+	Default  bool
+	Exclude  []string
+	Before   time.Time
+	// RolledUpOnly 롤업에 이미 반영된 원본 행만 (롤업을 쓰면 반영 전 행을 지우지 않음)
+	RolledUpOnly bool
+}
+
+// RetentionRepositoryInterface 보관 정책과 오래된 데이터 정리
+type RetentionRepositoryInterface interface {
+	List() ([]models.RetentionPolicy, error)
+	FindByID(id uint) (*models.RetentionPolicy, error)
+	Create(p *models.RetentionPolicy) error
+	Update(p *models.RetentionPolicy) error
+	Delete(id uint) error
+	Count(t RetentionTarget) (int64, error)
+	DeleteBatch(t RetentionTarget, limit int) (int64, error)
+}
+
+// RetentionRepository 보관 정책과 오래된 데이터 정리
+type RetentionRepository struct {
+	db *gorm.DB
+}
+
+var _ RetentionRepositoryInterface = (*RetentionRepository)(nil)
+
+func NewRetentionRepository(db *gorm.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+func (r *RetentionRepository) List() ([]models.RetentionPolicy, error) {
+	var policies []models.RetentionPolicy
+	err := r.db.Order("category ASC").Find(&policies).Error
+	return policies, err
+}
+
+func (r *RetentionRepository) FindByID(id uint) (*models.RetentionPolicy, error) {
+	var p models.RetentionPolicy
+	if err := r.db.First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *RetentionRepository) Create(p *models.RetentionPolicy) error {
+	return r.db.Create(p).Error
+}
+
+func (r *RetentionRepository) Update(p *models.RetentionPolicy) error {
+	return r.db.Save(p).Error
+}
+
+func (r *RetentionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.RetentionPolicy{}, id).Error
+}
+
+// where 대상 테이블과 조건 (테이블·컬럼 이름은 retentionTables에서만 가져옴)
+func (t RetentionTarget) where() (table, cond string, args []any) {
+	tc := retentionTables[t.Data]
+	conds := []string{tc[1] + " < ?"}
+	args = []any{t.Before}
+	switch {
+	case !t.Default:
+		conds = append(conds, "category = ?")
+		args = append(args, t.Category)
+	case len(t.Exclude) > 0:
+		conds = append(conds, "category NOT IN ?")
+		args = append(args, t.Exclude)
+	}
+	if t.RolledUpOnly && t.Data == RetainRaw {
+		conds = append(conds, "id <= (SELECT COALESCE(MAX(last_id), 0) FROM rollup_states WHERE name = ?)")
+		args = append(args, rollupStateName)
+	}
+	return tc[0], strings.Join(conds, " AND "), args
+}
+
+// Count 지워질 행 수 (미리보기용)
+func (r *RetentionRepository) Count(t RetentionTarget) (int64, error) {
+	table, cond, args := t.where()
+	var n int64
+	err := r.db.Raw("SELECT COUNT(*) FROM "+table+" WHERE "+cond, args...).Scan(&n).Error
+	return n, err
+}
+
+// DeleteBatch 조건에 맞는 행을 최대 limit개 삭제. 짧은 문장으로 나눠 지워 잠금을 오래 잡지 않음
+func (r *RetentionRepository) DeleteBatch(t RetentionTarget, limit int) (int64, error) {
+	table, cond, args := t.where()
+	res := r.db.Exec("DELETE FROM "+table+" WHERE ctid IN (SELECT ctid FROM "+table+" WHERE "+cond+" LIMIT ?)", append(args, limit)...)
+	return res.RowsAffected, res.Error
+}
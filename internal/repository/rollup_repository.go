@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rollup 시계열 조회에 쓰는 미리 모은 테이블
+type Rollup string
+
+const (
+	RollupHourly Rollup = "hourly"
+	RollupDaily  Rollup = "daily"
+)
+
+// rollupStateName dashboard_data 롤업 진행 상태의 이름
+const rollupStateName = "dashboard_data"
+
+// rollupTables 롤업별 테이블과 구간 (date_trunc 필드명)
+var rollupTables = []struct {
+	rollup Rollup
+	table  string
+	unit   string
+	size   time.Duration
+}{
+	// 굵은 단위부터 (PlanRollup이 먼저 맞는 것을 고름)
+	{RollupDaily, models.DailyRollup{}.TableName(), "day", 24 * time.Hour},
+	{RollupHourly, models.HourlyRollup{}.TableName(), "hour", time.Hour},
+}
+
+// RollupPlan 시계열 조회 중 [From, To)는 롤업 테이블에서, 나머지는 원본에서 집계
+type RollupPlan struct {
+	Rollup Rollup
+	Table  string
+	From   time.Time
+	To     time.Time
+}
+
+// PlanRollup 요청 버킷을 만들 수 있는 가장 굵은 롤업. 버킷보다 굵은 롤업은 쓰지 않고,
+// 시간대 오프셋이 롤업 구간(UTC 시·일)의 배수가 아니거나 기간 안에 온전한 구간이 없으면 false
+func PlanRollup(q TimeSeriesQuery) (RollupPlan, bool) {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, t := range rollupTables {
+		if t.rollup == RollupDaily && q.Bucket == BucketHour {
+			continue
+		}
+		if !rollupAligned(loc, q.From, q.To, t.size) {
+			continue
+		}
+		from := q.From.Truncate(t.size)
+		if from.Before(q.From) {
+			from = from.Add(t.size)
+		}
+		to := q.To.Truncate(t.size)
+		if !from.Before(to) {
+			continue
+		}
+		return RollupPlan{Rollup: t.rollup, Table: t.table, From: from, To: to}, true
+	}
+	return RollupPlan{}, false
+}
+
+// rollupAligned 기간 동안 loc의 UTC 오프셋이 size의 배수인지 (하루 간격과 양 끝에서 확인)
+func rollupAligned(loc *time.Location, from, to time.Time, size time.Duration) bool {
+	for t := from; ; t = t.Add(24 * time.Hour) {
+		if t.After(to) {
+			t = to
+		}
+		_, offset := t.In(loc).Zone()
+		if (time.Duration(offset)*time.Second)%size != 0 {
+			return false
+		}
+		if !t.Before(to) {
+			return true
+		}
+	}
+}
+
+// RollupRepositoryInterface 롤업 테이블 갱신
+type RollupRepositoryInterface interface {
+	Advance(batch uint) (done bool, err error)
+}
+
+// RollupRepository 롤업 테이블 갱신
+type RollupRepository struct {
+	db *gorm.DB
+}
+
+var _ RollupRepositoryInterface = (*RollupRepository)(nil)
+
+func NewRollupRepository(db *gorm.DB) *RollupRepository {
+	return &RollupRepository{db: db}
+}
+
+// RollupSnapshot 한 문장에서 본 원본 데이터의 최대 ID와 트랜잭션 스냅숏 (pg_current_snapshot)
+type RollupSnapshot struct {
+	MaxID uint
+	Xmin  uint64 // 아직 진행 중인 가장 오래된 트랜잭션
+	Xmax  uint64 // 아직 시작하지 않은 첫 트랜잭션
+}
+
+// AdvanceHorizon Horizon까지 반영했을 때 다음 끝을 정함. 본 최대 ID를 바로 끝으로 쓰면 그때 진행 중이던 트랜잭션이
+// 더 작은 ID를 늦게 커밋할 때 놓치므로, 최대 ID는 보류했다가 그 시점의 트랜잭션이 모두 끝난 뒤(Xmin ≥ 보류한 Xmax)에 끝으로 삼음.
+// 새로 반영할 행이 있으면 true
+func AdvanceHorizon(state *models.RollupState, snap RollupSnapshot) bool {
+	if state.PendingXID != 0 {
+		if snap.Xmin < state.PendingXID {
+			return false
+		}
+		state.Horizon = max(state.Horizon, state.PendingID)
+	}
+	state.PendingID, state.PendingXID = snap.MaxID, snap.Xmax
+	return state.Horizon > state.LastID
+}
+
+// rollupSnapshotSQL 최대 ID와 스냅숏을 같은 문장에서 읽음 (보이지 않는 더 작은 ID는 스냅숏의 진행 중 트랜잭션 것)
+const rollupSnapshotSQL = `SELECT (SELECT COALESCE(MAX(id), 0) FROM dashboard_data) AS max_id,
+	pg_snapshot_xmin(pg_current_snapshot())::text::bigint AS xmin,
+	pg_snapshot_xmax(pg_current_snapshot())::text::bigint AS xmax`
+
+// Advance 반영하지 않은 원본 데이터를 최대 batch행 롤업 테이블에 더함. 상태 행을 잠그므로 여러 인스턴스가 동시에 실행해도 한 번씩만 반영.
+// Horizon까지 모두 반영했으면 AdvanceHorizon으로 다음 끝을 정하고, 더 반영할 행이 없으면 done을 반환
+func (r *RollupRepository) Advance(batch uint) (done bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		state := models.RollupState{Name: rollupStateName}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&state).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&state, "name = ?", rollupStateName).Error; err != nil {
+			return err
+		}
+
+		if state.LastID >= state.Horizon {
+			var snap RollupSnapshot
+			if err := tx.Raw(rollupSnapshotSQL).Scan(&snap).Error; err != nil {
+				return err
+			}
+			done = !AdvanceHorizon(&state, snap)
+			return tx.Model(&state).Updates(map[string]any{
+				"horizon":     state.Horizon,
+				"pending_id":  state.PendingID,
+				"pending_xid": state.PendingXID,
+			}).Error
+		}
+
+		to := min(state.Horizon, state.LastID+batch)
+		for _, t := range rollupTables {
+			if err := tx.Exec(fmt.Sprintf(rollupUpsertSQL, t.table, t.unit), state.LastID, to).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&state).Update("last_id", to).Error
+	})
+	return done, err
+}
+
+// rollupUpsertSQL ID 구간 (?, ?]의 원본 데이터를 UTC 구간별로 모아 기존 값에 더함 (테이블, date_trunc 필드)
+const rollupUpsertSQL = `INSERT INTO %[1]s AS r (category, bucket, label, value_count, value_sum, value_min, value_max)
+SELECT category, date_trunc('%[2]s', recorded_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC', COALESCE(label, ''),
+	COUNT(*), SUM(value), MIN(value), MAX(value)
+FROM dashboard_data
+WHERE id > ? AND id <= ?
+GROUP BY 1, 2, 3
+ON CONFLICT (category, bucket, label) DO UPDATE SET
+	value_count = r.value_count + EXCLUDED.value_count,
+	value_sum = r.value_sum + EXCLUDED.value_sum,
+	value_min = LEAST(r.value_min, EXCLUDED.value_min),
+	value_max = GREATEST(r.value_max, EXCLUDED.value_max)`
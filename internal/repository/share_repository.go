@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+)
+
+// ShareRepository 대시보드 공개 링크 저장소
+type ShareRepository struct {
+	db *gorm.DB
+}
+
+func NewShareRepository(db *gorm.DB) *ShareRepository {
+	return &ShareRepository{db: db}
+}
+
+// ListByDashboard 최근에 만든 링크부터
+func (r *ShareRepository) ListByDashboard(dashboardID uint) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := r.db.Where("dashboard_id = ?", dashboardID).Order("id DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *ShareRepository) Find(id uint) (*models.ShareLink, error) {
+	var link models.ShareLink
+	if err := r.db.First(&link, id).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ShareRepository) Create(link *models.ShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *ShareRepository) Revoke(link *models.ShareLink) error {
+	return r.db.Model(link).Update("revoked_at", link.RevokedAt).Error
+}
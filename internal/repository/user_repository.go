@@ -52,23 +52,3 @@ func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
 	err := r.db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error
 	return count > 0, err
 }
-
-type DashboardRepository struct {
-	db *gorm.DB
-}
-
-func NewDashboardRepository(db *gorm.DB) *DashboardRepository {
-	return &DashboardRepository{db: db}
-}
-
-func (r *DashboardRepository) GetDataByCategory(category string) ([]models.DashboardData, error) {
-	var data []models.DashboardData
-	err := r.db.Where("category = ?", category).Order("id ASC").Find(&data).Error
-	return data, err
-}
-
-func (r *DashboardRepository) GetAllCategories() ([]string, error) {
-	var categories []string
-	err := r.db.Model(&models.DashboardData{}).Distinct("category").Pluck("category", &categories).Error
-	return categories, err
-}
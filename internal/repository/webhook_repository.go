@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepositoryInterface 웹훅 엔드포인트와 전송 기록 저장소
+type WebhookRepositoryInterface interface {
+	ListEndpoints(orgID uint) ([]models.WebhookEndpoint, error)
+	ListEnabled(orgIDs []uint) ([]models.WebhookEndpoint, error)
+	FindEndpoint(orgID, id uint) (*models.WebhookEndpoint, error)
+	CreateEndpoint(endpoint *models.WebhookEndpoint) error
+	UpdateEndpoint(endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(id uint) error
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	ListDeliveries(endpointID uint, limit int) ([]models.WebhookDelivery, error)
+	FindDelivery(endpointID, id uint) (*models.WebhookDelivery, error)
+	ListUnfinished(endpointID uint) ([]models.WebhookDelivery, error)
+	StartAttempt(id uint, now time.Time, lease time.Duration) (delivery *models.WebhookDelivery, busy bool, err error)
+	AbortAttempt(delivery *models.WebhookDelivery) error
+	SaveAttempt(delivery *models.WebhookDelivery, succeeded bool, disableAfter int, reason string) (disabled bool, err error)
+	EnableEndpoint(endpoint *models.WebhookEndpoint) error
+}
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+var _ WebhookRepositoryInterface = (*WebhookRepository)(nil)
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) ListEndpoints(orgID uint) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.Where("organization_id = ?", orgID).Order("id ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// ListEnabled 활성 엔드포인트. orgIDs가 nil이면 전체 조직
+func (r *WebhookRepository) ListEnabled(orgIDs []uint) ([]models.WebhookEndpoint, error) {
+	query := r.db.Where("enabled = ?", true)
+	if orgIDs != nil {
+		query = query.Where("organization_id IN ?", orgIDs)
+	}
+	var endpoints []models.WebhookEndpoint
+	err := query.Order("id ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *WebhookRepository) FindEndpoint(orgID, id uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := r.db.Where("organization_id = ?", orgID).First(&endpoint, id).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Create(endpoint).Error
+}
+
+func (r *WebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Save(endpoint).Error
+}
+
+// DeleteEndpoint 엔드포인트와 전송 기록 삭제
+func (r *WebhookRepository) DeleteEndpoint(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookEndpoint{}, id).Error
+	})
+}
+
+func (r *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+func (r *WebhookRepository) ListDeliveries(endpointID uint, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("endpoint_id = ?", endpointID).Order("id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *WebhookRepository) FindDelivery(endpointID, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.Where("endpoint_id = ?", endpointID).First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListUnfinished 아직 보내지 못한 전송 (엔드포인트를 다시 켤 때 대기열에 다시 넣음)
+func (r *WebhookRepository) ListUnfinished(endpointID uint) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("endpoint_id = ? AND status IN ?", endpointID,
+		[]string{models.DeliveryPending, models.DeliveryRetrying}).Order("id ASC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// StartAttempt 전송을 delivering으로 표시하고 엔드포인트와 함께 반환 (Status는 표시 전 상태).
+// 이미 끝났거나 없거나 엔드포인트가 꺼져 있으면 nil, 다른 작업이 보내는 중이면 busy.
+// 보내는 중 서버가 죽어 lease보다 오래 delivering으로 남은 전송은 다시 가져감
+func (r *WebhookRepository) StartAttempt(id uint, now time.Time, lease time.Duration) (delivery *models.WebhookDelivery, busy bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var d models.WebhookDelivery
+		err := tx.Preload("Endpoint").Clauses(clause.Locking{Strength: "UPDATE"}).First(&d, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.Status == models.DeliverySucceeded, d.Status == models.DeliveryFailed, !d.Endpoint.Enabled:
+			return nil
+		case d.Status == models.DeliveryDelivering && d.UpdatedAt.After(now.Add(-lease)):
+			busy = true
+			return nil
+		}
+		if err := tx.Model(&d).Update("status", models.DeliveryDelivering).Error; err != nil {
+			return err
+		}
+		delivery = &d
+		return nil
+	})
+	return delivery, busy, err
+}
+
+// AbortAttempt 결과 없이 끝난 시도 (종료 중 취소). StartAttempt 이전 상태로 되돌림
+func (r *WebhookRepository) AbortAttempt(delivery *models.WebhookDelivery) error {
+	return r.db.Model(delivery).Update("status", delivery.Status).Error
+}
+
+// SaveAttempt 전송 결과 저장. 실패하면 엔드포인트의 연속 실패 횟수를 올리고
+// disableAfter에 닿으면 비활성화 (동시에 여러 전송이 끝나도 횟수가 어긋나지 않도록 SQL에서 계산)
+func (r *WebhookRepository) SaveAttempt(delivery *models.WebhookDelivery, succeeded bool, disableAfter int, reason string) (disabled bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(delivery).Select("status", "attempts", "next_attempt_at", "last_attempt_at",
+			"response_status", "response_body", "error", "duration_millis").Updates(delivery).Error
+		if err != nil {
+			return err
+		}
+
+		endpoints := tx.Model(&models.WebhookEndpoint{}).Where("id = ?", delivery.EndpointID)
+		if succeeded {
+			return endpoints.Update("consecutive_failures", 0).Error
+		}
+		if err := endpoints.Update("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.WebhookEndpoint{}).
+			Where("id = ? AND enabled AND consecutive_failures >= ?", delivery.EndpointID, disableAfter).
+			Updates(map[string]any{
+				"enabled":         false,
+				"disabled_at":     time.Now(),
+				"disabled_reason": reason,
+			})
+		disabled = result.RowsAffected > 0
+		return result.Error
+	})
+	return disabled, err
+}
+
+// EnableEndpoint 다시 활성화할 때 실패 기록 초기화
+func (r *WebhookRepository) EnableEndpoint(endpoint *models.WebhookEndpoint) error {
+	endpoint.Enabled = true
+	endpoint.ConsecutiveFailures = 0
+	endpoint.DisabledAt = nil
+	endpoint.DisabledReason = ""
+	return r.db.Model(endpoint).Select("enabled", "consecutive_failures", "disabled_at", "disabled_reason").Updates(endpoint).Error
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+)
+
+// AlertNotice 규칙 상태가 firing 또는 resolved로 바뀐 알림 내용
+type AlertNotice struct {
+	Rule  models.AlertRule
+	State string
+	Value float64
+	At    time.Time
+}
+
+// Summary 사람이 읽는 한 줄 요약 (예: sales/온라인 sum 60분 = 90 < 100)
+func (n AlertNotice) Summary() string {
+	source := n.Rule.Category
+	if n.Rule.Label != "" {
+		source += "/" + n.Rule.Label
+	}
+	return fmt.Sprintf("%s %s %d분 = %s %s %s", source, n.Rule.Aggregation, n.Rule.WindowMinutes,
+		formatAlertValue(n.Value), comparatorSymbols[n.Rule.Comparator], formatAlertValue(n.Rule.Threshold))
+}
+
+func (n AlertNotice) Title() string {
+	if n.State == models.AlertStateResolved {
+		return "알림 해제: " + n.Rule.Name
+	}
+	return "알림 발생: " + n.Rule.Name
+}
+
+func formatAlertValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// AlertNotifier 알림 채널 (이메일, 웹훅, 앱 내 알림)
+type AlertNotifier interface {
+	Name() string
+	// Enabled 규칙에 이 채널이 설정되어 있는지
+	Enabled(rule models.AlertRule) bool
+	Notify(ctx context.Context, n AlertNotice) error
+}
+
+type EmailNotifier struct {
+	mailer Mailer
+}
+
+func NewEmailNotifier(mailer Mailer) *EmailNotifier {
+	return &EmailNotifier{mailer: mailer}
+}
+
+func (e *EmailNotifier) Name() string                       { return "email" }
+func (e *EmailNotifier) Enabled(rule models.AlertRule) bool { return rule.NotifyEmail != "" }
+
+func (e *EmailNotifier) Notify(ctx context.Context, n AlertNotice) error {
+	return e.mailer.Send(Mail{
+		To:      []string{n.Rule.NotifyEmail},
+		Subject: "[commet] " + n.Title(),
+		Text:    n.Summary() + "\n시각: " + n.At.Format(time.RFC3339) + "\n",
+	})
+}
+
+// WebhookNotifier 규칙에 설정된 URL로 JSON POST
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = newOutboundClient(10 * time.Second)
+	}
+	return &WebhookNotifier{client: client}
+}
+
+func (w *WebhookNotifier) Name() string                       { return "webhook" }
+func (w *WebhookNotifier) Enabled(rule models.AlertRule) bool { return rule.NotifyWebhook != "" }
+
+// alertWebhookPayload 웹훅 본문
+type alertWebhookPayload struct {
+	RuleID    uint      `json:"rule_id"`
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Category  string    `json:"category"`
+	Label     string    `json:"label,omitempty"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Summary   string    `json:"summary"`
+	At        time.Time `json:"at"`
+}
+
+func (n AlertNotice) payload() alertWebhookPayload {
+	return alertWebhookPayload{
+		RuleID:    n.Rule.ID,
+		Name:      n.Rule.Name,
+		State:     n.State,
+		Category:  n.Rule.Category,
+		Label:     n.Rule.Label,
+		Value:     n.Value,
+		Threshold: n.Rule.Threshold,
+		Summary:   n.Summary(),
+		At:        n.At,
+	}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n AlertNotice) error {
+	body, err := json.Marshal(n.payload())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Rule.NotifyWebhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// InAppNotifier 규칙 소유자의 알림 목록에 저장
+type InAppNotifier struct {
+	notificationRepo *repository.NotificationRepository
+}
+
+func NewInAppNotifier(notificationRepo *repository.NotificationRepository) *InAppNotifier {
+	return &InAppNotifier{notificationRepo: notificationRepo}
+}
+
+func (i *InAppNotifier) Name() string                       { return "in_app" }
+func (i *InAppNotifier) Enabled(rule models.AlertRule) bool { return rule.NotifyInApp }
+
+func (i *InAppNotifier) Notify(ctx context.Context, n AlertNotice) error {
+	level := "warning"
+	if n.State == models.AlertStateResolved {
+		level = "info"
+	}
+	return i.notificationRepo.Create(&models.Notification{
+		UserID: n.Rule.OwnerID,
+		Title:  n.Title(),
+		Body:   n.Summary(),
+		Level:  level,
+	})
+}
+
+// OrgWebhookNotifier 규칙 소유자가 속한 조직의 웹훅으로 alert.fired / alert.resolved 이벤트 발행
+type OrgWebhookNotifier struct {
+	webhookService *WebhookService
+}
+
+func NewOrgWebhookNotifier(webhookService *WebhookService) *OrgWebhookNotifier {
+	return &OrgWebhookNotifier{webhookService: webhookService}
+}
+
+func (o *OrgWebhookNotifier) Name() string                  { return "org_webhook" }
+func (o *OrgWebhookNotifier) Enabled(models.AlertRule) bool { return true }
+
+func (o *OrgWebhookNotifier) Notify(ctx context.Context, n AlertNotice) error {
+	eventType := models.EventAlertFired
+	if n.State == models.AlertStateResolved {
+		eventType = models.EventAlertResolved
+	}
+	return o.webhookService.EmitForUser(eventType, n.Rule.OwnerID, n.payload())
+}
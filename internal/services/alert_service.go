@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+// 상태 이력 조회 최대 건수
+const alertHistoryLimit = 100
+
+var comparatorSymbols = map[string]string{
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+// AlertAggAnomalies 값 대신 기간 안에 탐지된 이상치 수를 임계값과 비교
+const AlertAggAnomalies = "anomalies"
+
+type AlertService struct {
+	alertRepo     *repository.AlertRepository
+	dashboardRepo *repository.DashboardRepository
+	notifiers     []AlertNotifier
+	// anomalyRepo 있으면 이상치 수 규칙(AlertAggAnomalies)을 평가
+	anomalyRepo *repository.AnomalyRepository
+}
+
+func NewAlertService(alertRepo *repository.AlertRepository, dashboardRepo *repository.DashboardRepository, notifiers ...AlertNotifier) *AlertService {
+	return &AlertService{alertRepo: alertRepo, dashboardRepo: dashboardRepo, notifiers: notifiers}
+}
+
+// UseAnomalies 이상치 탐지 결과를 알림 규칙에 사용
+func (s *AlertService) UseAnomalies(anomalyRepo *repository.AnomalyRepository) {
+	s.anomalyRepo = anomalyRepo
+}
+
+func (s *AlertService) List(ownerID uint) ([]models.AlertRule, error) {
+	return s.alertRepo.ListRules(ownerID)
+}
+
+func (s *AlertService) Create(ownerID uint, req *models.AlertRuleRequest) (*models.AlertRule, error) {
+	if err := checkAlertWebhook(req); err != nil {
+		return nil, err
+	}
+	rule := &models.AlertRule{OwnerID: ownerID, State: models.AlertStateOK}
+	req.Apply(rule)
+	if err := s.alertRepo.CreateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Update 조건이 바뀌므로 평가 상태를 초기화 (firing 중이었다면 resolved 알림 없이 ok로)
+func (s *AlertService) Update(id, ownerID uint, req *models.AlertRuleRequest) (*models.AlertRule, error) {
+	if err := checkAlertWebhook(req); err != nil {
+		return nil, err
+	}
+	rule, err := s.owned(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	req.Apply(rule)
+	rule.State = models.AlertStateOK
+	rule.StateSince = nil
+	if err := s.alertRepo.UpdateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// checkAlertWebhook 알림 웹훅 URL도 조직 웹훅처럼 공인 주소만 허용
+func checkAlertWebhook(req *models.AlertRuleRequest) error {
+	if req.NotifyWebhook == "" {
+		return nil
+	}
+	return CheckOutboundURL(context.Background(), req.NotifyWebhook)
+}
+
+func (s *AlertService) Delete(id, ownerID uint) error {
+	if _, err := s.owned(id, ownerID); err != nil {
+		return err
+	}
+	return s.alertRepo.DeleteRule(id)
+}
+
+// History 규칙의 최근 상태 변경 이력 (최신순)
+func (s *AlertService) History(id, ownerID uint) ([]models.AlertEvent, error) {
+	if _, err := s.owned(id, ownerID); err != nil {
+		return nil, err
+	}
+	return s.alertRepo.ListEvents(id, alertHistoryLimit)
+}
+
+func (s *AlertService) owned(id, ownerID uint) (*models.AlertRule, error) {
+	rule, err := s.alertRepo.FindRule(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlertRuleNotFound
+		}
+		return nil, err
+	}
+	if rule.OwnerID != ownerID {
+		return nil, ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
+
+// Run ctx가 끝날 때까지 interval마다 규칙 평가. 진행 중인 평가가 끝난 뒤 반환
+func (s *AlertService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Evaluate(ctx, time.Now()); err != nil {
+			log.Printf("Alert evaluation failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate 활성 규칙을 now 기준으로 한 번 평가. 다른 인스턴스가 평가 중이면 건너뜀
+func (s *AlertService) Evaluate(ctx context.Context, now time.Time) error {
+	_, err := s.alertRepo.WithEvaluationLock(func() error {
+		rules, err := s.alertRepo.ListEnabled()
+		if err != nil {
+			return err
+		}
+		for i := range rules {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := s.evaluate(ctx, &rules[i], now); err != nil {
+				log.Printf("Alert rule %d: %v", rules[i].ID, err)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *AlertService) evaluate(ctx context.Context, rule *models.AlertRule, now time.Time) error {
+	from := now.Add(-time.Duration(rule.WindowMinutes) * time.Minute)
+	value, err := s.ruleValue(rule, from, now)
+	if err != nil {
+		return err
+	}
+
+	state := transitionAlert(rule, compareThreshold(rule.Comparator, value, rule.Threshold), now)
+	rule.LastValue = &value
+	rule.LastEvaluatedAt = &now
+
+	var event *models.AlertEvent
+	if state != "" {
+		event = &models.AlertEvent{RuleID: rule.ID, State: state, Value: value, Threshold: rule.Threshold, CreatedAt: now}
+	}
+	if err := s.alertRepo.SaveEvaluation(rule, event); err != nil {
+		return err
+	}
+	if state == models.AlertStateFiring || state == models.AlertStateResolved {
+		s.notify(ctx, AlertNotice{Rule: *rule, State: state, Value: value, At: now})
+	}
+	return nil
+}
+
+// ruleValue 규칙 기간 [from, now)의 집계값 또는 이상치 수
+func (s *AlertService) ruleValue(rule *models.AlertRule, from, now time.Time) (float64, error) {
+	if rule.Aggregation == AlertAggAnomalies {
+		if s.anomalyRepo == nil {
+			return 0, ErrInvalidAggregation
+		}
+		n, err := s.anomalyRepo.Count(repository.AnomalyFilter{Category: rule.Category, Label: rule.Label, From: from, To: now})
+		return float64(n), err
+	}
+	agg := repository.Aggregation(rule.Aggregation)
+	if !agg.Valid() {
+		return 0, ErrInvalidAggregation
+	}
+	return s.dashboardRepo.Aggregate(rule.Category, rule.Label, from, now, agg)
+}
+
+// notify 설정된 채널로 전송. 한 채널이 실패해도 나머지는 보냄
+func (s *AlertService) notify(ctx context.Context, n AlertNotice) {
+	for _, notifier := range s.notifiers {
+		if !notifier.Enabled(n.Rule) {
+			continue
+		}
+		if err := notifier.Notify(ctx, n); err != nil {
+			log.Printf("Alert rule %d: %s notification failed: %v", n.Rule.ID, notifier.Name(), err)
+		}
+	}
+}
+
+func compareThreshold(comparator string, value, threshold float64) bool {
+	switch comparator {
+	case "gt":
+		return value > threshold
+	case "gte":
+		return value >= threshold
+	case "lt":
+		return value < threshold
+	case "lte":
+		return value <= threshold
+	}
+	return false
+}
+
+// transitionAlert 평가 결과로 규칙 상태를 바꾸고, 이력에 남길 상태를 반환 (변경 없으면 "")
+//
+//	ok      → pending (조건 충족, ForMinutes > 0) 또는 firing (ForMinutes == 0)
+//	pending → firing  (조건이 ForMinutes 이상 유지) 또는 ok (조건 해소, 이력 없음)
+//	firing  → ok      (조건 해소, resolved 기록)
+func transitionAlert(rule *models.AlertRule, breached bool, now time.Time) string {
+	set := func(state string) {
+		rule.State = state
+		rule.StateSince = &now
+	}
+
+	switch rule.State {
+	case models.AlertStatePending:
+		if !breached {
+			set(models.AlertStateOK)
+			return ""
+		}
+		if rule.StateSince == nil || now.Sub(*rule.StateSince) >= time.Duration(rule.ForMinutes)*time.Minute {
+			set(models.AlertStateFiring)
+			return models.AlertStateFiring
+		}
+		return ""
+	case models.AlertStateFiring:
+		if breached {
+			return ""
+		}
+		set(models.AlertStateOK)
+		return models.AlertStateResolved
+	default:
+		if !breached {
+			return ""
+		}
+		if rule.ForMinutes == 0 {
+			set(models.AlertStateFiring)
+			return models.AlertStateFiring
+		}
+		set(models.AlertStatePending)
+		return models.AlertStatePending
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareThreshold(t *testing.T) {
+	assert.True(t, compareThreshold("gt", 101, 100))
+	assert.False(t, compareThreshold("gt", 100, 100))
+	assert.True(t, compareThreshold("gte", 100, 100))
+	assert.True(t, compareThreshold("lt", 99, 100))
+	assert.True(t, compareThreshold("lte", 100, 100))
+	assert.False(t, compareThreshold("eq", 100, 100))
+}
+
+func TestTransitionAlert_ForDuration(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	rule := &models.AlertRule{State: models.AlertStateOK, ForMinutes: 5}
+
+	assert.Equal(t, models.AlertStatePending, transitionAlert(rule, true, start))
+	assert.Equal(t, models.AlertStatePending, rule.State)
+
+	// 유지 시간이 지나기 전에는 pending 그대로
+	assert.Equal(t, "", transitionAlert(rule, true, start.Add(4*time.Minute)))
+	assert.Equal(t, models.AlertStatePending, rule.State)
+
+	assert.Equal(t, models.AlertStateFiring, transitionAlert(rule, true, start.Add(5*time.Minute)))
+	assert.Equal(t, "", transitionAlert(rule, true, start.Add(6*time.Minute)))
+
+	assert.Equal(t, models.AlertStateResolved, transitionAlert(rule, false, start.Add(7*time.Minute)))
+	assert.Equal(t, models.AlertStateOK, rule.State)
+	assert.Equal(t, start.Add(7*time.Minute), *rule.StateSince)
+}
+
+func TestTransitionAlert_PendingRecovers(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	rule := &models.AlertRule{State: models.AlertStateOK, ForMinutes: 10}
+
+	transitionAlert(rule, true, now)
+	// pending에서 조건이 풀리면 이력 없이 ok
+	assert.Equal(t, "", transitionAlert(rule, false, now.Add(time.Minute)))
+	assert.Equal(t, models.AlertStateOK, rule.State)
+
+	// 유지 시간이 0이면 바로 firing
+	rule.ForMinutes = 0
+	assert.Equal(t, models.AlertStateFiring, transitionAlert(rule, true, now.Add(2*time.Minute)))
+}
+
+func TestAlertNotice_Summary(t *testing.T) {
+	n := AlertNotice{
+		Rule:  models.AlertRule{Name: "매출 감소", Category: "sales", Label: "온라인", Aggregation: "sum", WindowMinutes: 60, Comparator: "lt", Threshold: 100},
+		State: models.AlertStateFiring,
+		Value: 92.5,
+	}
+	assert.Equal(t, "sales/온라인 sum 60분 = 92.5 < 100", n.Summary())
+	assert.Equal(t, "알림 발생: 매출 감소", n.Title())
+
+	n.State = models.AlertStateResolved
+	assert.Equal(t, "알림 해제: 매출 감소", n.Title())
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got alertWebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	notifier := NewWebhookNotifier(srv.Client())
+	rule := models.AlertRule{ID: 7, Name: "트래픽 급증", Category: "traffic", Aggregation: "count", WindowMinutes: 5, Comparator: "gt", Threshold: 1000, NotifyWebhook: srv.URL}
+	require.True(t, notifier.Enabled(rule))
+	require.NoError(t, notifier.Notify(context.Background(), AlertNotice{Rule: rule, State: models.AlertStateFiring, Value: 1500}))
+
+	assert.Equal(t, uint(7), got.RuleID)
+	assert.Equal(t, "firing", got.State)
+	assert.Equal(t, 1500.0, got.Value)
+	assert.Equal(t, 1000.0, got.Threshold)
+}
+
+func TestAlertRule_RejectsPrivateWebhook(t *testing.T) {
+	s := NewAlertService(nil, nil)
+	_, err := s.Create(1, &models.AlertRuleRequest{NotifyWebhook: "http://10.0.0.8/hook"})
+	assert.ErrorIs(t, err, ErrUnsafeURL)
+	_, err = s.Update(1, 1, &models.AlertRuleRequest{NotifyWebhook: "http://localhost:9000/"})
+	assert.ErrorIs(t, err, ErrUnsafeURL)
+
+	// 기본 클라이언트는 보낼 때도 내부 주소를 막음
+	n := NewWebhookNotifier(nil)
+	err = n.Notify(context.Background(), AlertNotice{Rule: models.AlertRule{NotifyWebhook: "http://127.0.0.1:1/"}})
+	assert.ErrorIs(t, err, ErrUnsafeURL)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAnnotationNotFound = errors.New("annotation not found")
+	ErrInvalidAnnotation  = errors.New("invalid annotation")
+)
+
+// annotationColors 주석 색 이름별 표시 색 (Tailwind 500)
+var annotationColors = map[string]string{
+	"blue":   "#3B82F6",
+	"green":  "#10B981",
+	"purple": "#8B5CF6",
+	"orange": "#F59E0B",
+	"red":    "#EF4444",
+	"gray":   "#6B7280",
+}
+
+const defaultAnnotationColor = "orange"
+
+// ChartAnnotation 차트에 그릴 주석. X는 x축 항목 번호이며 기간이면 X~X2 구간을 음영 표시
+type ChartAnnotation struct {
+	X      float64  `json:"x"`
+	X2     *float64 `json:"x2,omitempty"`
+	Text   string   `json:"text"`
+	Color  string   `json:"color"`
+	Time   string   `json:"time"`
+	Author string   `json:"author,omitempty"`
+}
+
+type AnnotationService struct {
+	annotationRepo *repository.AnnotationRepository
+	layoutService  *LayoutService
+}
+
+func NewAnnotationService(annotationRepo *repository.AnnotationRepository, layoutService *LayoutService) *AnnotationService {
+	return &AnnotationService{
+		annotationRepo: annotationRepo,
+		layoutService:  layoutService,
+	}
+}
+
+// List 대시보드를 지정하면 볼 수 있는 대시보드인지 먼저 확인
+func (s *AnnotationService) List(userID uint, f repository.AnnotationFilter) ([]models.AnnotationResponse, error) {
+	if f.DashboardID != nil {
+		if _, err := s.layoutService.Get(*f.DashboardID, userID); err != nil {
+			return nil, err
+		}
+	}
+	anns, err := s.annotationRepo.List(f)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]models.AnnotationResponse, len(anns))
+	for i := range anns {
+		resp[i] = anns[i].ToResponse()
+	}
+	return resp, nil
+}
+
+func (s *AnnotationService) Create(userID uint, req *models.AnnotationRequest) (*models.Annotation, error) {
+	a := &models.Annotation{AuthorID: userID}
+	if err := s.apply(a, userID, req); err != nil {
+		return nil, err
+	}
+	if err := s.annotationRepo.Create(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Update 작성자만 수정할 수 있음
+func (s *AnnotationService) Update(id, userID uint, req *models.AnnotationRequest) (*models.Annotation, error) {
+	a, err := s.authored(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(a, userID, req); err != nil {
+		return nil, err
+	}
+	if err := s.annotationRepo.Update(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *AnnotationService) Delete(id, userID uint) error {
+	if _, err := s.authored(id, userID); err != nil {
+		return err
+	}
+	return s.annotationRepo.Delete(id)
+}
+
+func (s *AnnotationService) authored(id, userID uint) (*models.Annotation, error) {
+	a, err := s.annotationRepo.Find(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAnnotationNotFound
+		}
+		return nil, err
+	}
+	if a.AuthorID != userID {
+		return nil, ErrForbidden
+	}
+	return a, nil
+}
+
+// apply 대시보드를 지정하면 볼 수 있는 대시보드여야 함. 기간은 끝이 시작보다 뒤여야 함
+func (s *AnnotationService) apply(a *models.Annotation, userID uint, req *models.AnnotationRequest) error {
+	if req.EndsAt != nil && !req.EndsAt.After(req.StartsAt) {
+		return ErrInvalidAnnotation
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		return ErrInvalidAnnotation
+	}
+	dashboardID := req.DashboardID
+	if dashboardID != nil && *dashboardID == 0 {
+		dashboardID = nil
+	}
+	if dashboardID != nil {
+		if _, err := s.layoutService.Get(*dashboardID, userID); err != nil {
+			return err
+		}
+	}
+
+	a.DashboardID = dashboardID
+	a.Category = req.Category
+	a.StartsAt = req.StartsAt
+	a.EndsAt = req.EndsAt
+	a.Text = strings.TrimSpace(req.Text)
+	a.Color = req.Color
+	if a.Color == "" {
+		a.Color = defaultAnnotationColor
+	}
+	return nil
+}
+
+// placeAnnotations 주석 시각을 시계열 차트의 x축 항목 번호로 변환.
+// 값이 없어 생략된 버킷에 걸린 시점 표시는 다음 항목에, 기간은 안쪽 항목까지로 맞추고 항목이 없으면 제외
+func placeAnnotations(anns []models.Annotation, data *ChartData, q ChartQuery) []ChartAnnotation {
+	if len(anns) == 0 || len(data.Labels) == 0 {
+		return nil
+	}
+	starts, err := bucketStarts(*q.From, *q.To, q.Bucket, q.Location)
+	if err != nil || len(starts) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(data.Labels))
+	for i, label := range data.Labels {
+		index[label] = i
+	}
+	pos := make([]int, len(starts))
+	for i, t := range starts {
+		pos[i] = -1
+		if j, ok := index[bucketLabel(t, q.Bucket)]; ok {
+			pos[i] = j
+		}
+	}
+	bucketOf := func(t time.Time) int {
+		return max(sort.Search(len(starts), func(i int) bool { return starts[i].After(t) })-1, 0)
+	}
+
+	var placed []ChartAnnotation
+	for _, a := range anns {
+		first, last := bucketOf(a.StartsAt), bucketOf(a.StartsAt)
+		if a.EndsAt != nil {
+			// 끝 시각은 구간에 포함하지 않음 (1일 0시까지 → 전날 버킷까지)
+			last = bucketOf(a.EndsAt.Add(-time.Nanosecond))
+		}
+		if a.EndsAt == nil {
+			for last < len(pos)-1 && pos[last] < 0 {
+				last++
+			}
+			first = last
+		}
+		for first <= last && pos[first] < 0 {
+			first++
+		}
+		for last >= first && pos[last] < 0 {
+			last--
+		}
+		if first > last {
+			continue
+		}
+
+		color, ok := annotationColors[a.Color]
+		if !ok {
+			color = annotationColors[defaultAnnotationColor]
+		}
+		ca := ChartAnnotation{
+			X:      float64(pos[first]),
+			Text:   a.Text,
+			Color:  color,
+			Time:   annotationTime(a, q.Location),
+			Author: a.Author.Name,
+		}
+		if a.EndsAt != nil {
+			x2 := float64(pos[last]) + 0.5
+			ca.X -= 0.5
+			ca.X2 = &x2
+		}
+		placed = append(placed, ca)
+	}
+	return placed
+}
+
+func annotationTime(a models.Annotation, loc *time.Location) string {
+	const layout = "2006-01-02 15:04"
+	s := a.StartsAt.In(loc).Format(layout)
+	if a.EndsAt != nil {
+		s += " ~ " + a.EndsAt.In(loc).Format(layout)
+	}
+	return s
+}
+
+// parseHexColor "#RRGGBB" (잘못된 값이면 회색)
+func parseHexColor(s string) uint32 {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 7 {
+		return 0x6B7280
+	}
+	return uint32(v)
+}
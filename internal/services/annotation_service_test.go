@@ -0,0 +1,88 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceAnnotations(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+	q := ChartQuery{From: &from, To: &to, Bucket: repository.BucketDay, Aggregation: repository.AggSum, Location: time.UTC}
+	day := func(d, h int) time.Time { return time.Date(2024, 3, d, h, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	// 합계: 7일 모두 항목이 있음
+	data := &ChartData{Labels: []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04", "2024-03-05", "2024-03-06", "2024-03-07"}}
+	anns := []models.Annotation{
+		{Text: "캠페인 시작", StartsAt: day(3, 15), Color: "red", Author: models.User{Name: "kim"}},
+		{Text: "할인 기간", StartsAt: day(4, 0), EndsAt: ptr(day(6, 0)), Color: "unknown"},
+		{Text: "이전부터", StartsAt: day(1, 0).AddDate(0, 0, -3), EndsAt: ptr(day(2, 12))},
+	}
+	placed := placeAnnotations(anns, data, q)
+	require.Len(t, placed, 3)
+
+	assert.Equal(t, 2.0, placed[0].X)
+	assert.Nil(t, placed[0].X2)
+	assert.Equal(t, "#EF4444", placed[0].Color)
+	assert.Equal(t, "2024-03-03 15:00", placed[0].Time)
+	assert.Equal(t, "kim", placed[0].Author)
+
+	// 끝 시각(6일 0시)은 포함하지 않으므로 4~5일 두 칸
+	assert.Equal(t, 2.5, placed[1].X)
+	require.NotNil(t, placed[1].X2)
+	assert.Equal(t, 4.5, *placed[1].X2)
+	assert.Equal(t, annotationColors[defaultAnnotationColor], placed[1].Color)
+
+	// 기간 시작 전부터 이어진 주석은 첫 항목부터
+	assert.Equal(t, -0.5, placed[2].X)
+	assert.Equal(t, 1.5, *placed[2].X2)
+}
+
+func TestPlaceAnnotationsSkippedBuckets(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	q := ChartQuery{From: &from, To: &to, Bucket: repository.BucketDay, Aggregation: repository.AggAvg, Location: time.UTC}
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 9, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	// 평균: 2, 3, 5일은 값이 없어 생략됨
+	data := &ChartData{Labels: []string{"2024-03-01", "2024-03-04"}}
+	anns := []models.Annotation{
+		{Text: "다음 항목으로", StartsAt: day(2)},
+		{Text: "빈 구간만", StartsAt: day(2), EndsAt: ptr(day(3))},
+		{Text: "뒤에 항목 없음", StartsAt: day(5)},
+		{Text: "안쪽만", StartsAt: day(1), EndsAt: ptr(day(5))},
+	}
+	placed := placeAnnotations(anns, data, q)
+	require.Len(t, placed, 2)
+	assert.Equal(t, "다음 항목으로", placed[0].Text)
+	assert.Equal(t, 1.0, placed[0].X)
+	assert.Equal(t, "안쪽만", placed[1].Text)
+	assert.Equal(t, -0.5, placed[1].X)
+	assert.Equal(t, 1.5, *placed[1].X2)
+
+	assert.Nil(t, placeAnnotations(nil, data, q))
+	assert.Nil(t, placeAnnotations(anns, &ChartData{}, q))
+}
+
+func TestRenderChartSVGAnnotations(t *testing.T) {
+	x2 := 2.5
+	data := &ChartData{Labels: []string{"a", "b", "c", "d"}, Values: []float64{1, 3, 2, 4}}
+	svg, err := RenderChartSVG(data, ChartImageOptions{Type: "line", Annotations: []ChartAnnotation{
+		{X: 1, Text: "launch <v2>", Color: "#EF4444"},
+		{X: 0.5, X2: &x2, Text: strings.Repeat("긴", 30), Color: "#3B82F6"},
+	}})
+	require.NoError(t, err)
+	out := string(svg)
+	assert.Contains(t, out, "launch &lt;v2&gt;")
+	assert.Contains(t, out, strings.Repeat("긴", 23)+"…")
+	assert.Contains(t, out, `fill="#ef4444"`)
+	assert.Contains(t, out, `fill="#3b82f6" fill-opacity=`)
+}
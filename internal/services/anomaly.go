@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+)
+
+var ErrInvalidAnomalyMethod = errors.New("invalid anomaly method")
+
+// AnomalyMethod 이상치 탐지 방법
+type AnomalyMethod string
+
+const (
+	AnomalyOff AnomalyMethod = "off"
+	// AnomalyZScore 최근 값들의 평균·표준편차 기준
+	AnomalyZScore AnomalyMethod = "zscore"
+	// AnomalyMAD 최근 값들의 중앙값·중앙절대편차 기준 (이상치 자체에 덜 흔들림)
+	AnomalyMAD AnomalyMethod = "mad"
+	// AnomalySeasonal 시간대별 계절 성분과 추세를 뺀 잔차 기준 (기록이 부족하면 MAD)
+	AnomalySeasonal AnomalyMethod = "seasonal"
+)
+
+const (
+	defaultAnomalyThreshold = 3.5
+	defaultAnomalyWindow    = 100
+	// 이보다 기록이 적으면 판단하지 않음
+	minAnomalyHistory = 20
+	// 편차가 0인 기록에서 벗어난 값의 점수 (무한대 대신)
+	maxAnomalyScore = 100
+	// 계절 분해에 쓰는 기록 (최근 2주, 시간 단위)
+	seasonalLookback  = 14 * 24 * time.Hour
+	seasonalMaxPoints = 20000
+	seasonalPeriod    = 24
+	// 정규분포에서 MAD를 표준편차로 바꾸는 계수
+	madScale = 1.4826
+	// 작업 하나가 맡는 데이터 포인트 수
+	anomalyJobBatch = 500
+)
+
+// AnomalyOptions 탐지 설정. 0이면 기본값
+type AnomalyOptions struct {
+	Method AnomalyMethod
+	// Threshold 점수(표준편차 단위) 절댓값이 이 이상이면 이상치
+	Threshold float64
+	// Window zscore/mad가 비교하는 최근 값 수
+	Window int
+}
+
+// ParseAnomalyMethod 설정 값 확인 (빈 값은 mad)
+func ParseAnomalyMethod(s string) (AnomalyMethod, error) {
+	switch m := AnomalyMethod(s); m {
+	case "":
+		return AnomalyMAD, nil
+	case AnomalyOff, AnomalyZScore, AnomalyMAD, AnomalySeasonal:
+		return m, nil
+	}
+	return "", ErrInvalidAnomalyMethod
+}
+
+func (o AnomalyOptions) withDefaults() AnomalyOptions {
+	if o.Method == "" {
+		o.Method = AnomalyMAD
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = defaultAnomalyThreshold
+	}
+	if o.Window < minAnomalyHistory {
+		o.Window = defaultAnomalyWindow
+	}
+	return o
+}
+
+// JobDetectAnomalies 수집된 데이터 포인트의 이상치를 찾는 작업 (default 큐)
+const JobDetectAnomalies = "anomaly.detect"
+
+type anomalyDetectJob struct {
+	DataIDs []uint `json:"data_ids"`
+}
+
+type AnomalyService struct {
+	anomalyRepo   *repository.AnomalyRepository
+	dashboardRepo *repository.DashboardRepository
+	jobs          *JobQueue
+	opts          AnomalyOptions
+}
+
+// NewAnomalyService jobs의 default 큐에 탐지 작업을 등록
+func NewAnomalyService(anomalyRepo *repository.AnomalyRepository, dashboardRepo *repository.DashboardRepository, jobs *JobQueue, opts AnomalyOptions) *AnomalyService {
+	s := &AnomalyService{anomalyRepo: anomalyRepo, dashboardRepo: dashboardRepo, jobs: jobs, opts: opts.withDefaults()}
+	jobs.Register(JobDetectAnomalies, "default", HandleJob(s.detectJob))
+	return s
+}
+
+// Enabled ANOMALY_METHOD=off면 탐지하지 않음 (기존 기록 조회는 가능)
+func (s *AnomalyService) Enabled() bool {
+	return s.opts.Method != AnomalyOff
+}
+
+// Enqueue 저장된 데이터 포인트를 나눠 탐지 작업으로 등록
+func (s *AnomalyService) Enqueue(data []models.DashboardData) error {
+	if !s.Enabled() {
+		return nil
+	}
+	for start := 0; start < len(data); start += anomalyJobBatch {
+		end := min(start+anomalyJobBatch, len(data))
+		job := anomalyDetectJob{DataIDs: make([]uint, 0, end-start)}
+		for _, d := range data[start:end] {
+			job.DataIDs = append(job.DataIDs, d.ID)
+		}
+		if err := s.jobs.Enqueue(JobDetectAnomalies, job, EnqueueOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AnomalyService) detectJob(ctx context.Context, job anomalyDetectJob) error {
+	if len(job.DataIDs) == 0 {
+		return nil
+	}
+	data, err := s.dashboardRepo.GetDataByIDs(job.DataIDs)
+	if err != nil {
+		return err
+	}
+	_, err = s.Detect(data)
+	return err
+}
+
+// Detect 카테고리·라벨 계열마다 이전 기록과 비교해 이상치를 찾아 저장.
+// 같은 요청의 값들은 시각 순으로 차례로 기록에 더하며 판단
+func (s *AnomalyService) Detect(data []models.DashboardData) ([]models.Anomaly, error) {
+	type seriesKey struct{ category, label string }
+	groups := map[seriesKey][]models.DashboardData{}
+	var keys []seriesKey
+	for _, d := range data {
+		k := seriesKey{d.Category, d.Label}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], d)
+	}
+
+	var found []models.Anomaly
+	for _, k := range keys {
+		points := groups[k]
+		sort.SliceStable(points, func(i, j int) bool { return points[i].RecordedAt.Before(points[j].RecordedAt) })
+		history, err := s.history(k.category, k.label, points[0].RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range points {
+			if a, ok := detectAnomaly(history, p, s.opts); ok {
+				found = append(found, a)
+			}
+			history = append(history, p)
+		}
+	}
+	if err := s.anomalyRepo.Save(found); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// history before 이전 기록. 계절 분해는 최근 2주, 그 외는 최근 Window개
+func (s *AnomalyService) history(category, label string, before time.Time) ([]models.DashboardData, error) {
+	if s.opts.Method == AnomalySeasonal {
+		return s.dashboardRepo.GetHistory(category, label, before.Add(-seasonalLookback), before, seasonalMaxPoints)
+	}
+	return s.dashboardRepo.GetHistory(category, label, time.Time{}, before, s.opts.Window)
+}
+
+// List 기간 안의 이상치 (라벨이 비어 있으면 카테고리 전체)
+func (s *AnomalyService) List(category, label string, q ChartQuery) ([]models.Anomaly, error) {
+	q = withRangeDefaults(q)
+	return s.anomalyRepo.List(repository.AnomalyFilter{Category: category, Label: label, From: *q.From, To: *q.To})
+}
+
+// detectAnomaly 기록으로 예상값과 편차를 구해 p의 점수를 매김. 기록이 부족하거나 임계값 미만이면 false
+func detectAnomaly(history []models.DashboardData, p models.DashboardData, opts AnomalyOptions) (models.Anomaly, bool) {
+	method := opts.Method
+	var expected, spread float64
+	ok := false
+	if method == AnomalySeasonal {
+		expected, spread, ok = seasonalBaseline(history, p.RecordedAt)
+	}
+	if !ok {
+		if method == AnomalySeasonal {
+			method = AnomalyMAD
+		}
+		if len(history) > opts.Window {
+			history = history[len(history)-opts.Window:]
+		}
+		if len(history) < minAnomalyHistory {
+			return models.Anomaly{}, false
+		}
+		values := make([]float64, len(history))
+		for i, d := range history {
+			values[i] = d.Value
+		}
+		if method == AnomalyZScore {
+			expected, spread = meanStd(values)
+		} else {
+			expected, spread = medianMAD(values)
+		}
+	}
+
+	score := anomalyScore(p.Value, expected, spread)
+	if math.Abs(score) < opts.Threshold {
+		return models.Anomaly{}, false
+	}
+	direction := models.AnomalyHigh
+	if score < 0 {
+		direction = models.AnomalyLow
+	}
+	return models.Anomaly{
+		DataID:     p.ID,
+		Category:   p.Category,
+		Label:      p.Label,
+		Value:      p.Value,
+		Expected:   math.Round(expected*100) / 100,
+		Score:      math.Round(score*100) / 100,
+		Method:     string(method),
+		Direction:  direction,
+		RecordedAt: p.RecordedAt,
+	}, true
+}
+
+// anomalyScore 예상값에서 벗어난 정도. 편차가 0이면 같은 값은 0, 다른 값은 최대 점수
+func anomalyScore(value, expected, spread float64) float64 {
+	if spread > 0 {
+		return math.Max(-maxAnomalyScore, math.Min(maxAnomalyScore, (value-expected)/spread))
+	}
+	switch {
+	case value > expected:
+		return maxAnomalyScore
+	case value < expected:
+		return -maxAnomalyScore
+	}
+	return 0
+}
+
+func meanStd(values []float64) (mean, std float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(values)))
+}
+
+// medianMAD 중앙값과 정규분포 표준편차로 환산한 중앙절대편차
+func medianMAD(values []float64) (median, mad float64) {
+	median = medianOf(values)
+	dev := make([]float64, len(values))
+	for i, v := range values {
+		dev[i] = math.Abs(v - median)
+	}
+	return median, madScale * medianOf(dev)
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// seasonalBaseline 기록을 시간 단위 평균으로 모아 24시간 중심 이동평균(추세)과 시간대별 계절 성분으로 분해.
+// 예상값은 최근 하루의 계절 성분을 뺀 수준 + at 시간대의 계절 성분, 편차는 원래 값 잔차의 MAD.
+// 시간 단위 기록이 이틀 미만이면 false
+func seasonalBaseline(history []models.DashboardData, at time.Time) (expected, spread float64, ok bool) {
+	if len(history) < minAnomalyHistory {
+		return 0, 0, false
+	}
+	start := history[0].RecordedAt.Truncate(time.Hour)
+	end := history[len(history)-1].RecordedAt.Truncate(time.Hour)
+	if end.Sub(start) < 2*seasonalPeriod*time.Hour {
+		return 0, 0, false
+	}
+
+	// 시간 단위 평균 (빈 시간은 앞뒤 값으로 보간)
+	sums := map[int64]float64{}
+	counts := map[int64]int{}
+	for _, d := range history {
+		k := d.RecordedAt.Truncate(time.Hour).Unix()
+		sums[k] += d.Value
+		counts[k]++
+	}
+	points := make([]repository.TimeSeriesPoint, 0, len(sums))
+	for k, sum := range sums {
+		points = append(points, repository.TimeSeriesPoint{Bucket: time.Unix(k, 0), Value: sum / float64(counts[k])})
+	}
+	var starts []time.Time
+	for t := start; !t.After(end); t = t.Add(time.Hour) {
+		starts = append(starts, t)
+	}
+	hourly, _ := bucketHistory(points, starts, repository.AggAvg)
+	n := len(hourly)
+	hourOf := func(i int) int { return starts[i].UTC().Hour() }
+
+	// 추세: 2×24 중심 이동평균
+	half := seasonalPeriod / 2
+	trend := make([]float64, n)
+	for i := half; i < n-half; i++ {
+		sum := (hourly[i-half] + hourly[i+half]) / 2
+		for j := i - half + 1; j < i+half; j++ {
+			sum += hourly[j]
+		}
+		trend[i] = sum / seasonalPeriod
+	}
+
+	// 계절 성분: 시간대별 (값 - 추세) 평균을 합이 0이 되도록 맞춤
+	var seasonal [seasonalPeriod]float64
+	var seen [seasonalPeriod]int
+	for i := half; i < n-half; i++ {
+		seasonal[hourOf(i)] += hourly[i] - trend[i]
+		seen[hourOf(i)]++
+	}
+	var mean float64
+	for h := range seasonal {
+		if seen[h] > 0 {
+			seasonal[h] /= float64(seen[h])
+		}
+		mean += seasonal[h]
+	}
+	for h := range seasonal {
+		seasonal[h] -= mean / seasonalPeriod
+	}
+
+	// 수준: 최근 하루 값에서 계절 성분을 뺀 평균
+	var level float64
+	for i := n - seasonalPeriod; i < n; i++ {
+		level += hourly[i] - seasonal[hourOf(i)]
+	}
+	level /= seasonalPeriod
+
+	var residuals []float64
+	for _, d := range history {
+		i := int(d.RecordedAt.Truncate(time.Hour).Sub(start) / time.Hour)
+		if i < half || i >= n-half {
+			continue
+		}
+		residuals = append(residuals, d.Value-trend[i]-seasonal[hourOf(i)])
+	}
+	if len(residuals) < minAnomalyHistory {
+		return 0, 0, false
+	}
+	_, spread = medianMAD(residuals)
+	return level + seasonal[at.UTC().Hour()], spread, true
+}
+
+// ChartAnomaly 시계열 차트 항목(X)에 표시할 이상치. 한 버킷에 여럿이면 점수가 가장 큰 값
+type ChartAnomaly struct {
+	X        int     `json:"x"`
+	Count    int     `json:"count"`
+	Value    float64 `json:"value"`
+	Expected float64 `json:"expected"`
+	Score    float64 `json:"score"`
+	Time     string  `json:"time"`
+}
+
+// placeAnomalies 이상치를 기록 시각이 속한 버킷의 x축 항목 번호로 모음. 항목이 없는 버킷은 제외
+func placeAnomalies(anomalies []models.Anomaly, data *ChartData, q ChartQuery) []ChartAnomaly {
+	if len(anomalies) == 0 || len(data.Labels) == 0 {
+		return nil
+	}
+	index := make(map[string]int, len(data.Labels))
+	for i, label := range data.Labels {
+		index[label] = i
+	}
+
+	byX := map[int]int{}
+	var placed []ChartAnomaly
+	for _, a := range anomalies {
+		x, ok := index[bucketLabel(truncateTime(a.RecordedAt, q.Bucket, q.Location), q.Bucket)]
+		if !ok {
+			continue
+		}
+		i, seen := byX[x]
+		if !seen {
+			i = len(placed)
+			byX[x] = i
+			placed = append(placed, ChartAnomaly{X: x})
+		}
+		ca := &placed[i]
+		ca.Count++
+		if ca.Count == 1 || math.Abs(a.Score) > math.Abs(ca.Score) {
+			ca.Value, ca.Expected, ca.Score = a.Value, a.Expected, a.Score
+			ca.Time = a.RecordedAt.In(q.Location).Format("2006-01-02 15:04")
+		}
+	}
+	sort.Slice(placed, func(i, j int) bool { return placed[i].X < placed[j].X })
+	return placed
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// anomalySeries start부터 step 간격으로 value(i) 값을 가진 기록
+func anomalySeries(start time.Time, step time.Duration, n int, value func(i int) float64) []models.DashboardData {
+	data := make([]models.DashboardData, n)
+	for i := range data {
+		data[i] = models.DashboardData{ID: uint(i + 1), Category: "cpu", Label: "web", Value: value(i), RecordedAt: start.Add(time.Duration(i) * step)}
+	}
+	return data
+}
+
+func TestParseAnomalyMethod(t *testing.T) {
+	m, err := ParseAnomalyMethod("")
+	require.NoError(t, err)
+	assert.Equal(t, AnomalyMAD, m)
+
+	m, err = ParseAnomalyMethod("seasonal")
+	require.NoError(t, err)
+	assert.Equal(t, AnomalySeasonal, m)
+
+	_, err = ParseAnomalyMethod("iforest")
+	assert.ErrorIs(t, err, ErrInvalidAnomalyMethod)
+}
+
+func TestDetectAnomalyMAD(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// 50 근처에서 ±2로 흔들리는 기록
+	history := anomalySeries(start, time.Minute, 40, func(i int) float64 { return 50 + float64(i%5) - 2 })
+	opts := AnomalyOptions{Method: AnomalyMAD}.withDefaults()
+	at := start.Add(time.Hour)
+
+	_, ok := detectAnomaly(history, models.DashboardData{ID: 99, Value: 52, RecordedAt: at}, opts)
+	assert.False(t, ok)
+
+	a, ok := detectAnomaly(history, models.DashboardData{ID: 99, Category: "cpu", Label: "web", Value: 80, RecordedAt: at}, opts)
+	require.True(t, ok)
+	assert.Equal(t, uint(99), a.DataID)
+	assert.Equal(t, models.AnomalyHigh, a.Direction)
+	assert.Equal(t, "mad", a.Method)
+	assert.Equal(t, 50.0, a.Expected)
+	assert.Greater(t, a.Score, opts.Threshold)
+
+	a, ok = detectAnomaly(history, models.DashboardData{Value: 20, RecordedAt: at}, opts)
+	require.True(t, ok)
+	assert.Equal(t, models.AnomalyLow, a.Direction)
+	assert.Less(t, a.Score, -opts.Threshold)
+
+	// 기록이 부족하면 판단하지 않음
+	_, ok = detectAnomaly(history[:minAnomalyHistory-1], models.DashboardData{Value: 1000, RecordedAt: at}, opts)
+	assert.False(t, ok)
+}
+
+func TestDetectAnomalyZScore(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	history := anomalySeries(start, time.Minute, 30, func(i int) float64 { return float64(10 + i%3) })
+	opts := AnomalyOptions{Method: AnomalyZScore, Threshold: 3}.withDefaults()
+
+	a, ok := detectAnomaly(history, models.DashboardData{Value: 20, RecordedAt: start.Add(time.Hour)}, opts)
+	require.True(t, ok)
+	assert.Equal(t, "zscore", a.Method)
+	assert.InDelta(t, 11, a.Expected, 0.01)
+}
+
+func TestDetectAnomalyConstantHistory(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	history := anomalySeries(start, time.Minute, 30, func(int) float64 { return 7 })
+	opts := AnomalyOptions{}.withDefaults()
+
+	_, ok := detectAnomaly(history, models.DashboardData{Value: 7}, opts)
+	assert.False(t, ok)
+	a, ok := detectAnomaly(history, models.DashboardData{Value: 8}, opts)
+	require.True(t, ok)
+	assert.Equal(t, float64(maxAnomalyScore), a.Score)
+}
+
+func TestDetectAnomalySeasonal(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// 낮(9~18시)에는 100, 밤에는 20 근처인 일주일 기록 (시간당 2건)
+	daily := func(h int) float64 {
+		if h >= 9 && h < 18 {
+			return 100
+		}
+		return 20
+	}
+	history := anomalySeries(start, 30*time.Minute, 7*48, func(i int) float64 {
+		return daily(i/2%24) + float64(i%3) - 1
+	})
+	opts := AnomalyOptions{Method: AnomalySeasonal}.withDefaults()
+
+	// 낮의 100은 정상, 새벽 3시의 100은 이상치
+	noon := start.AddDate(0, 0, 7).Add(12 * time.Hour)
+	_, ok := detectAnomaly(history, models.DashboardData{Value: 100, RecordedAt: noon}, opts)
+	assert.False(t, ok)
+
+	night := start.AddDate(0, 0, 7).Add(3 * time.Hour)
+	a, ok := detectAnomaly(history, models.DashboardData{Value: 100, RecordedAt: night}, opts)
+	require.True(t, ok)
+	assert.Equal(t, "seasonal", a.Method)
+	assert.InDelta(t, 20, a.Expected, 2)
+
+	// 이틀이 안 되는 기록은 MAD로 대신 판단
+	_, _, ok = seasonalBaseline(history[:60], night)
+	assert.False(t, ok)
+	a, ok = detectAnomaly(history[:60], models.DashboardData{Value: 500, RecordedAt: night}, opts)
+	require.True(t, ok)
+	assert.Equal(t, "mad", a.Method)
+}
+
+func TestMedianMAD(t *testing.T) {
+	median, mad := medianMAD([]float64{1, 2, 3, 4, 100})
+	assert.Equal(t, 3.0, median)
+	assert.InDelta(t, madScale*1, mad, 1e-9)
+
+	mean, std := meanStd([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	assert.Equal(t, 5.0, mean)
+	assert.Equal(t, 2.0, std)
+	assert.Equal(t, 0.0, anomalyScore(5, 5, 0))
+	assert.Equal(t, float64(-maxAnomalyScore), anomalyScore(-math.MaxFloat64, 0, 1))
+}
+
+func TestPlaceAnomalies(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	q := ChartQuery{From: &from, To: &to, Bucket: repository.BucketDay, Aggregation: repository.AggAvg, Location: time.UTC}
+	at := func(d, h int) time.Time { return time.Date(2024, 3, d, h, 0, 0, 0, time.UTC) }
+
+	// 평균: 3일은 값이 없어 생략됨
+	data := &ChartData{Labels: []string{"2024-03-01", "2024-03-02", "2024-03-04"}}
+	anomalies := []models.Anomaly{
+		{Value: 90, Expected: 50, Score: 4, RecordedAt: at(4, 8)},
+		{Value: 10, Expected: 50, Score: -6, RecordedAt: at(2, 9)},
+		{Value: 80, Expected: 50, Score: 5, RecordedAt: at(2, 15)},
+		{Value: 70, Expected: 50, Score: 4, RecordedAt: at(3, 1)},
+	}
+	placed := placeAnomalies(anomalies, data, q)
+	require.Len(t, placed, 2)
+
+	// 같은 버킷의 이상치는 점수가 가장 큰 값으로 합침
+	assert.Equal(t, ChartAnomaly{X: 1, Count: 2, Value: 10, Expected: 50, Score: -6, Time: "2024-03-02 09:00"}, placed[0])
+	assert.Equal(t, 2, placed[1].X)
+	assert.Equal(t, 1, placed[1].Count)
+}
+
+func TestRenderChartSVGAnomalies(t *testing.T) {
+	data := &ChartData{Labels: []string{"a", "b", "c"}, Values: []float64{1, 9, 2}}
+	plain, err := RenderChartSVG(data, ChartImageOptions{Type: "line"})
+	require.NoError(t, err)
+	marked, err := RenderChartSVG(data, ChartImageOptions{Type: "line", Anomalies: []ChartAnomaly{{X: 1, Count: 1}, {X: 7}}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(plain), "#ef4444")
+	assert.Contains(t, string(marked), "#ef4444")
+}
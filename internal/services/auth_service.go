@@ -18,8 +18,9 @@ var (
 )
 
 type AuthService struct {
-	userRepo  repository.UserRepositoryInterface
-	jwtConfig config.JWTConfig
+	userRepo     repository.UserRepositoryInterface
+	jwtConfig    config.JWTConfig
+	onRegistered func(*models.User)
 }
 
 func NewAuthService(userRepo repository.UserRepositoryInterface, jwtConfig config.JWTConfig) *AuthService {
@@ -62,9 +63,17 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.User, error
 		return nil, err
 	}
 
+	if s.onRegistered != nil {
+		s.onRegistered(user)
+	}
 	return user, nil
 }
 
+// OnRegistered 회원가입 완료 후 호출할 함수 등록 (웹훅 이벤트 발행 등)
+func (s *AuthService) OnRegistered(fn func(*models.User)) {
+	s.onRegistered = fn
+}
+
 func (s *AuthService) Login(req *models.LoginRequest) (*models.User, string, error) {
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
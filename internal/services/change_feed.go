@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	changeFeedMinBackoff = time.Second
+	changeFeedMaxBackoff = 30 * time.Second
+	// 재연결 후 누락분을 나눠 읽는 단위
+	changeFeedResyncBatch = 1000
+)
+
+// ChangeFeed Postgres LISTEN/NOTIFY로 모든 인스턴스에서 저장된 데이터를 받아 이 인스턴스의 허브에 전달.
+// 로드 밸런서 뒤에 여러 서버가 떠 있어도 각자 자기 SSE 구독자에게 이벤트를 나눠 줌
+type ChangeFeed struct {
+	dsn           string
+	channel       string
+	dashboardRepo *repository.DashboardRepository
+	hub           *EventHub
+
+	started bool
+	lastID  uint
+	// resynced 누락분으로 이미 발행한 ID. 같은 행의 NOTIFY가 뒤늦게 오면 건너뜀
+	resynced map[uint]bool
+}
+
+func NewChangeFeed(dsn, channel string, dashboardRepo *repository.DashboardRepository, hub *EventHub) *ChangeFeed {
+	return &ChangeFeed{
+		dsn:           dsn,
+		channel:       channel,
+		dashboardRepo: dashboardRepo,
+		hub:           hub,
+	}
+}
+
+// Run ctx가 끝날 때까지 LISTEN. 연결이 끊기면 백오프 후 다시 연결하고, 끊긴 동안 저장된 행을 DB에서 읽어 발행
+func (f *ChangeFeed) Run(ctx context.Context) {
+	attempt := 0
+	for {
+		connected, err := f.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			attempt = 0
+		}
+		delay := changeFeedBackoff(attempt)
+		attempt++
+		log.Printf("Change feed disconnected: %v (reconnecting in %s)", err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// listen 연결 한 번의 수명. LISTEN까지 성공했으면 connected가 true
+func (f *ChangeFeed) listen(ctx context.Context) (connected bool, err error) {
+	conn, err := pgx.Connect(ctx, f.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{f.channel}.Sanitize()); err != nil {
+		return false, err
+	}
+	// LISTEN 이후에 읽어야 그 사이에 저장된 행을 놓치지 않음
+	if err := f.resync(); err != nil {
+		return true, err
+	}
+	log.Printf("Change feed listening on %s", f.channel)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		ev, err := parseChangePayload(n.Payload)
+		if err != nil {
+			log.Printf("Change feed: invalid payload: %v", err)
+			continue
+		}
+		if f.resynced[ev.ID] {
+			continue
+		}
+		f.deliver(ev)
+	}
+}
+
+// resync 처음 연결하면 현재 마지막 ID부터 시작, 재연결이면 그 뒤에 저장된 행을 모두 발행
+func (f *ChangeFeed) resync() error {
+	if !f.started {
+		id, err := f.dashboardRepo.MaxDataID()
+		if err != nil {
+			return err
+		}
+		f.started = true
+		f.lastID = id
+		return nil
+	}
+
+	f.resynced = map[uint]bool{}
+	for {
+		data, err := f.dashboardRepo.GetDataSince(f.lastID, nil, changeFeedResyncBatch)
+		if err != nil {
+			return err
+		}
+		for _, d := range data {
+			f.resynced[d.ID] = true
+			f.deliver(NewDataEvent(d))
+		}
+		if len(data) < changeFeedResyncBatch {
+			if len(data) > 0 {
+				log.Printf("Change feed resynced up to id %d", f.lastID)
+			}
+			return nil
+		}
+	}
+}
+
+func (f *ChangeFeed) deliver(ev DataEvent) {
+	if ev.ID > f.lastID {
+		f.lastID = ev.ID
+	}
+	f.hub.Publish(ev)
+}
+
+// parseChangePayload 트리거가 보낸 JSON (database.installChangeTrigger)
+func parseChangePayload(payload string) (DataEvent, error) {
+	var d models.DashboardData
+	if err := json.Unmarshal([]byte(payload), &d); err != nil {
+		return DataEvent{}, err
+	}
+	return NewDataEvent(d), nil
+}
+
+// changeFeedBackoff 1초부터 두 배씩, 최대 30초
+func changeFeedBackoff(attempt int) time.Duration {
+	delay := changeFeedMinBackoff
+	for i := 0; i < attempt && delay < changeFeedMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > changeFeedMaxBackoff {
+		delay = changeFeedMaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChangePayload(t *testing.T) {
+	ev, err := parseChangePayload(`{"id":42,"category":"sales","label":"온라인","value":1250.50,"recorded_at":"2024-03-01T09:30:00+09:00"}`)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), ev.ID)
+	assert.Equal(t, "sales", ev.Category)
+	assert.Equal(t, "온라인", ev.Label)
+	assert.Equal(t, 1250.5, ev.Value)
+	assert.True(t, ev.RecordedAt.Equal(time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC)))
+
+	_, err = parseChangePayload(`not json`)
+	assert.Error(t, err)
+}
+
+func TestChangeFeedBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, changeFeedBackoff(0))
+	assert.Equal(t, 2*time.Second, changeFeedBackoff(1))
+	assert.Equal(t, 16*time.Second, changeFeedBackoff(4))
+	assert.Equal(t, 30*time.Second, changeFeedBackoff(5))
+	assert.Equal(t, 30*time.Second, changeFeedBackoff(100))
+}
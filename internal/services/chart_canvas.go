@@ -0,0 +1,359 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+type chartPoint struct{ X, Y float64 }
+
+type textAnchor string
+
+const (
+	anchorStart  textAnchor = "start"
+	anchorMiddle textAnchor = "middle"
+	anchorEnd    textAnchor = "end"
+)
+
+// chartCanvas 차트 이미지 그리기 대상 (SVG, PNG). 좌표는 왼쪽 위가 (0, 0)
+type chartCanvas interface {
+	Rect(x, y, w, h float64, fill color.NRGBA)
+	Polygon(pts []chartPoint, fill color.NRGBA)
+	Polyline(pts []chartPoint, width float64, stroke color.NRGBA)
+	Circle(cx, cy, r float64, fill color.NRGBA)
+	// Text y는 글자 기준선 위치
+	Text(x, y, size float64, fill color.NRGBA, anchor textAnchor, s string)
+	TextWidth(s string, size float64) float64
+	// Covers 글꼴에 s의 모든 글자가 있는지
+	Covers(s string) bool
+}
+
+// svgCanvas 텍스트는 브라우저 글꼴로 그리므로 너비는 근사값
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func newSVGCanvas(w, h int) *svgCanvas {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', 'Apple SD Gothic Neo', 'Malgun Gothic', sans-serif">`+"\n", w, h, w, h)
+	return c
+}
+
+func (c *svgCanvas) Rect(x, y, w, h float64, fill color.NRGBA) {
+	fmt.Fprintf(&c.buf, `<rect x="%s" y="%s" width="%s" height="%s"%s/>`+"\n", svgNum(x), svgNum(y), svgNum(w), svgNum(h), svgPaint("fill", fill))
+}
+
+func (c *svgCanvas) Polygon(pts []chartPoint, fill color.NRGBA) {
+	fmt.Fprintf(&c.buf, `<polygon points="%s"%s/>`+"\n", svgPoints(pts), svgPaint("fill", fill))
+}
+
+func (c *svgCanvas) Polyline(pts []chartPoint, width float64, stroke color.NRGBA) {
+	fmt.Fprintf(&c.buf, `<polyline points="%s" fill="none" stroke-width="%s" stroke-linejoin="round" stroke-linecap="round"%s/>`+"\n",
+		svgPoints(pts), svgNum(width), svgPaint("stroke", stroke))
+}
+
+func (c *svgCanvas) Circle(cx, cy, r float64, fill color.NRGBA) {
+	fmt.Fprintf(&c.buf, `<circle cx="%s" cy="%s" r="%s"%s/>`+"\n", svgNum(cx), svgNum(cy), svgNum(r), svgPaint("fill", fill))
+}
+
+func (c *svgCanvas) Text(x, y, size float64, fill color.NRGBA, anchor textAnchor, s string) {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(s))
+	fmt.Fprintf(&c.buf, `<text x="%s" y="%s" font-size="%s" text-anchor="%s"%s>%s</text>`+"\n",
+		svgNum(x), svgNum(y), svgNum(size), anchor, svgPaint("fill", fill), escaped.String())
+}
+
+// TextWidth 숫자·라틴 문자는 0.6em, 그 외(한글 등)는 1em으로 근사
+func (c *svgCanvas) TextWidth(s string, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		if r < 0x80 {
+			w += 0.6
+		} else {
+			w++
+		}
+	}
+	return w * size
+}
+
+func (c *svgCanvas) Covers(string) bool { return true }
+
+func (c *svgCanvas) Bytes() []byte {
+	return append(c.buf.Bytes(), "</svg>\n"...)
+}
+
+func svgPaint(attr string, c color.NRGBA) string {
+	s := fmt.Sprintf(` %s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A < 255 {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, svgNum(float64(c.A)/255))
+	}
+	return s
+}
+
+func svgPoints(pts []chartPoint) string {
+	parts := make([]string, len(pts))
+	for i, p := range pts {
+		parts[i] = svgNum(p.X) + "," + svgNum(p.Y)
+	}
+	return strings.Join(parts, " ")
+}
+
+func svgNum(v float64) string {
+	return pdfNum(v)
+}
+
+// rasterCanvas 안티앨리어싱 래스터라이저로 그리는 PNG용 캔버스. 좌표는 scale배 픽셀로 그림
+type rasterCanvas struct {
+	img   *image.RGBA
+	z     *vector.Rasterizer
+	scale float64
+	font  *sfnt.Font
+	faces map[float64]font.Face
+}
+
+func newRasterCanvas(w, h int, scale float64) *rasterCanvas {
+	pw, ph := int(math.Round(float64(w)*scale)), int(math.Round(float64(h)*scale))
+	return &rasterCanvas{
+		img:   image.NewRGBA(image.Rect(0, 0, pw, ph)),
+		z:     vector.NewRasterizer(pw, ph),
+		scale: scale,
+		font:  chartFont(),
+		faces: map[float64]font.Face{},
+	}
+}
+
+// fill 도형들의 경계 상자만 래스터화해 한 색으로 채움
+func (c *rasterCanvas) fill(paths [][]chartPoint, col color.NRGBA) {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	scaled := make([][]chartPoint, len(paths))
+	for i, pts := range paths {
+		scaled[i] = make([]chartPoint, len(pts))
+		for j, p := range pts {
+			p = chartPoint{p.X * c.scale, p.Y * c.scale}
+			scaled[i][j] = p
+			minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+			maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+		}
+	}
+	r := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).Intersect(c.img.Bounds())
+	if r.Empty() {
+		return
+	}
+
+	c.z.Reset(r.Dx(), r.Dy())
+	ox, oy := float64(r.Min.X), float64(r.Min.Y)
+	for _, pts := range scaled {
+		if len(pts) < 3 {
+			continue
+		}
+		// 겹치는 도형의 면적이 상쇄되지 않도록 모두 같은 방향으로
+		if polygonArea(pts) < 0 {
+			pts = reversePoints(pts)
+		}
+		c.z.MoveTo(float32(pts[0].X-ox), float32(pts[0].Y-oy))
+		for _, p := range pts[1:] {
+			c.z.LineTo(float32(p.X-ox), float32(p.Y-oy))
+		}
+		c.z.ClosePath()
+	}
+	c.z.Draw(c.img, r, image.NewUniform(col), image.Point{})
+}
+
+func (c *rasterCanvas) Rect(x, y, w, h float64, fill color.NRGBA) {
+	c.fill([][]chartPoint{{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}}, fill)
+}
+
+func (c *rasterCanvas) Polygon(pts []chartPoint, fill color.NRGBA) {
+	c.fill([][]chartPoint{pts}, fill)
+}
+
+// Polyline 선분마다 사각형, 꺾이는 점마다 원을 채워 둥근 연결로 그림
+func (c *rasterCanvas) Polyline(pts []chartPoint, width float64, stroke color.NRGBA) {
+	half := width / 2
+	var paths [][]chartPoint
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		dx, dy := b.X-a.X, b.Y-a.Y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		nx, ny := -dy/l*half, dx/l*half
+		paths = append(paths, []chartPoint{{a.X + nx, a.Y + ny}, {b.X + nx, b.Y + ny}, {b.X - nx, b.Y - ny}, {a.X - nx, a.Y - ny}})
+	}
+	for _, p := range pts {
+		paths = append(paths, circlePoints(p.X, p.Y, half))
+	}
+	c.fill(paths, stroke)
+}
+
+func (c *rasterCanvas) Circle(cx, cy, r float64, fill color.NRGBA) {
+	c.fill([][]chartPoint{circlePoints(cx, cy, r)}, fill)
+}
+
+func (c *rasterCanvas) face(size float64) font.Face {
+	if f, ok := c.faces[size]; ok {
+		return f
+	}
+	f, err := opentype.NewFace(c.font, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		// 기본 글꼴은 항상 열리므로 사용자 글꼴 문제일 때만 발생
+		f, _ = opentype.NewFace(defaultChartFont(), &opentype.FaceOptions{Size: size, DPI: 72})
+	}
+	c.faces[size] = f
+	return f
+}
+
+func (c *rasterCanvas) Text(x, y, size float64, fill color.NRGBA, anchor textAnchor, s string) {
+	switch anchor {
+	case anchorMiddle:
+		x -= c.TextWidth(s, size) / 2
+	case anchorEnd:
+		x -= c.TextWidth(s, size)
+	}
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(fill),
+		Face: c.face(size * c.scale),
+		Dot:  fixed.Point26_6{X: fixed.Int26_6(x * c.scale * 64), Y: fixed.Int26_6(y * c.scale * 64)},
+	}
+	d.DrawString(s)
+}
+
+// TextWidth 배율을 적용하기 전 좌표 기준 너비
+func (c *rasterCanvas) TextWidth(s string, size float64) float64 {
+	return float64(font.MeasureString(c.face(size*c.scale), s)) / 64 / c.scale
+}
+
+func (c *rasterCanvas) Covers(s string) bool {
+	return fontCovers(c.font, s)
+}
+
+func circlePoints(cx, cy, r float64) []chartPoint {
+	n := int(math.Max(12, math.Min(64, r*4)))
+	pts := make([]chartPoint, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = chartPoint{cx + r*math.Cos(a), cy + r*math.Sin(a)}
+	}
+	return pts
+}
+
+// polygonArea 부호 있는 면적 (화면 좌표에서 시계 방향이 양수)
+func polygonArea(pts []chartPoint) float64 {
+	var a float64
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		a += pts[i].X*pts[j].Y - pts[j].X*pts[i].Y
+	}
+	return a / 2
+}
+
+func reversePoints(pts []chartPoint) []chartPoint {
+	out := make([]chartPoint, len(pts))
+	for i, p := range pts {
+		out[len(pts)-1-i] = p
+	}
+	return out
+}
+
+// PNG 텍스트 글꼴. 기본은 Go 글꼴(라틴 문자만), LoadChartFont로 한글 글꼴 지정
+var chartFonts struct {
+	sync.RWMutex
+	custom   *sfnt.Font
+	fallback *sfnt.Font
+	// pdf 보고서 PDF에 부분 포함할 글꼴 (custom이 TrueType일 때만)
+	pdf *trueTypeSource
+}
+
+// LoadChartFont PNG 차트와 보고서 PDF에 쓸 TrueType/OpenType 글꼴 (.ttf, .otf, .ttc는 첫 글꼴).
+// PDF에는 TrueType 윤곽만 포함할 수 있어 CFF 글꼴(.otf 대부분)이면 뷰어 내장 글꼴을 씀
+func LoadChartFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		coll, cerr := opentype.ParseCollection(data)
+		if cerr != nil {
+			return fmt.Errorf("parse chart font %s: %w", path, err)
+		}
+		if f, err = coll.Font(0); err != nil {
+			return fmt.Errorf("parse chart font %s: %w", path, err)
+		}
+	}
+	pdf, err := parseTrueType(data, f)
+	if err != nil {
+		log.Printf("Chart font %s: %v; report PDFs use the viewer's Korean font instead", path, err)
+	}
+	chartFonts.Lock()
+	chartFonts.custom = f
+	chartFonts.pdf = pdf
+	chartFonts.Unlock()
+	return nil
+}
+
+// chartPDFFont 보고서 PDF에 포함할 글꼴. 없으면 nil
+func chartPDFFont() *trueTypeSource {
+	chartFonts.RLock()
+	defer chartFonts.RUnlock()
+	return chartFonts.pdf
+}
+
+func chartFont() *sfnt.Font {
+	chartFonts.RLock()
+	custom := chartFonts.custom
+	chartFonts.RUnlock()
+	if custom != nil {
+		return custom
+	}
+	return defaultChartFont()
+}
+
+func defaultChartFont() *sfnt.Font {
+	chartFonts.Lock()
+	defer chartFonts.Unlock()
+	if chartFonts.fallback == nil {
+		f, err := opentype.Parse(goregular.TTF)
+		if err != nil {
+			panic("chart: parse Go font: " + err.Error())
+		}
+		chartFonts.fallback = f
+	}
+	return chartFonts.fallback
+}
+
+// ChartFontCovers PNG 차트 글꼴로 s를 그릴 수 있는지 (한글 글꼴이 없으면 false)
+func ChartFontCovers(s string) bool {
+	return fontCovers(chartFont(), s)
+}
+
+func fontCovers(f *sfnt.Font, s string) bool {
+	var buf sfnt.Buffer
+	for _, r := range s {
+		if r == ' ' {
+			continue
+		}
+		if idx, err := f.GlyphIndex(&buf, r); err != nil || idx == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+var ErrUnsupportedChartImage = errors.New("chart type cannot be rendered as image")
+
+// 이미지 크기 제한 (px)
+const (
+	ChartImageMinSize = 100
+	ChartImageMaxSize = 2000
+)
+
+// ChartImageOptions 서버에서 그리는 차트 이미지 옵션
+type ChartImageOptions struct {
+	// Type line, area, scatter는 선, bar, stacked_bar는 막대, pie, doughnut은 원형
+	Type   string
+	Width  int
+	Height int
+	// Scale PNG 픽셀 배율 (고해상도 화면·메일용으로 2). SVG는 무시
+	Scale  float64
+	Theme  ChartTheme
+	Title  string
+	Unit   string
+	Suffix string
+	// Annotations 선·막대 차트에 그릴 주석 (ChartMeta.Annotations)
+	Annotations []ChartAnnotation
+	// Anomalies 선·막대 차트 값에 표시할 이상치 (ChartMeta.Anomalies)
+	Anomalies []ChartAnomaly
+}
+
+// ChartTheme 배경, 글자, 격자와 계열 색 (static/js/charts.js와 같은 팔레트)
+type ChartTheme struct {
+	Background color.NRGBA
+	Text       color.NRGBA
+	Muted      color.NRGBA
+	Grid       color.NRGBA
+	Palette    []color.NRGBA
+}
+
+var (
+	LightChartTheme = ChartTheme{
+		Background: hexColor(0xFFFFFF),
+		Text:       hexColor(0x111827),
+		Muted:      hexColor(0x9CA3AF),
+		Grid:       hexColor(0xF3F4F6),
+		Palette: []color.NRGBA{
+			hexColor(0x6366F1), hexColor(0x8B5CF6), hexColor(0xEC4899), hexColor(0xF59E0B),
+			hexColor(0x10B981), hexColor(0x0EA5E9), hexColor(0xF43F5E), hexColor(0x84CC16),
+		},
+	}
+	DarkChartTheme = ChartTheme{
+		Background: hexColor(0x1F2937),
+		Text:       hexColor(0xF9FAFB),
+		Muted:      hexColor(0x9CA3AF),
+		Grid:       hexColor(0x374151),
+		Palette: []color.NRGBA{
+			hexColor(0x818CF8), hexColor(0xA78BFA), hexColor(0xF472B6), hexColor(0xFBBF24),
+			hexColor(0x34D399), hexColor(0x38BDF8), hexColor(0xFB7185), hexColor(0xA3E635),
+		},
+	}
+)
+
+// LookupChartTheme "dark"가 아니면 밝은 테마
+func LookupChartTheme(name string) ChartTheme {
+	if name == "dark" {
+		return DarkChartTheme
+	}
+	return LightChartTheme
+}
+
+func hexColor(v uint32) color.NRGBA {
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+}
+
+func withAlpha(c color.NRGBA, a uint8) color.NRGBA {
+	c.A = a
+	return c
+}
+
+type chartImageKind int
+
+const (
+	chartImageLine chartImageKind = iota
+	chartImageArea
+	chartImageScatter
+	chartImageBar
+	chartImagePie
+	chartImageDoughnut
+)
+
+func lookupChartImageKind(chartType string) (chartImageKind, error) {
+	switch chartType {
+	case "line":
+		return chartImageLine, nil
+	case "area":
+		return chartImageArea, nil
+	case "scatter":
+		return chartImageScatter, nil
+	case "bar", "stacked_bar":
+		return chartImageBar, nil
+	case "pie":
+		return chartImagePie, nil
+	case "doughnut":
+		return chartImageDoughnut, nil
+	}
+	return 0, ErrUnsupportedChartImage
+}
+
+// RenderChartSVG 차트 데이터를 SVG로 그림
+func RenderChartSVG(data *ChartData, opts ChartImageOptions) ([]byte, error) {
+	opts, kind, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+	c := newSVGCanvas(opts.Width, opts.Height)
+	drawChartImage(c, kind, data, opts)
+	return c.Bytes(), nil
+}
+
+// RenderChartPNG 차트 데이터를 PNG로 그림. 한글 라벨은 LoadChartFont로 한글 글꼴을 지정해야 표시됨
+func RenderChartPNG(data *ChartData, opts ChartImageOptions) ([]byte, error) {
+	opts, kind, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+	c := newRasterCanvas(opts.Width, opts.Height, opts.Scale)
+	drawChartImage(c, kind, data, opts)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (o ChartImageOptions) normalize() (ChartImageOptions, chartImageKind, error) {
+	kind, err := lookupChartImageKind(o.Type)
+	if err != nil {
+		return o, 0, err
+	}
+	if o.Width == 0 {
+		o.Width = 800
+	}
+	if o.Height == 0 {
+		o.Height = 400
+	}
+	o.Width = min(max(o.Width, ChartImageMinSize), ChartImageMaxSize)
+	o.Height = min(max(o.Height, ChartImageMinSize), ChartImageMaxSize)
+	if o.Scale < 1 || o.Scale > 3 {
+		o.Scale = 1
+	}
+	if len(o.Theme.Palette) == 0 {
+		o.Theme = LightChartTheme
+	}
+	return o, kind, nil
+}
+
+// 레이아웃 (px)
+const (
+	chartPad       = 16.0
+	chartTitleSize = 15.0
+	chartTickSize  = 11.0
+	chartTicks     = 5
+)
+
+type chartArea struct{ X, Y, W, H float64 }
+
+func drawChartImage(c chartCanvas, kind chartImageKind, data *ChartData, opts ChartImageOptions) {
+	theme := opts.Theme
+	w, h := float64(opts.Width), float64(opts.Height)
+	c.Rect(0, 0, w, h, theme.Background)
+
+	top := chartPad
+	if opts.Title != "" {
+		c.Text(chartPad, chartPad+chartTitleSize, chartTitleSize, theme.Text, anchorStart, opts.Title)
+		top += chartTitleSize + 12
+	}
+	area := chartArea{X: chartPad, Y: top, W: w - 2*chartPad, H: h - top - chartPad}
+
+	if data == nil || (len(data.Values) == 0 && len(data.Series) == 0) || (kind >= chartImagePie && sumPositive(data.Values) == 0) {
+		noData := "데이터 없음"
+		if !c.Covers(noData) {
+			noData = "No data"
+		}
+		c.Text(w/2, area.Y+area.H/2, 13, theme.Muted, anchorMiddle, noData)
+		return
+	}
+
+	format := func(v float64) string {
+		return opts.Unit + LookupLocale("ko-KR").FormatNumber(v) + opts.Suffix
+	}
+	if kind >= chartImagePie {
+		drawPieImage(c, kind == chartImageDoughnut, data, area, theme, format)
+		return
+	}
+	if len(data.Series) > 0 {
+		area = drawSeriesLegend(c, data.Series, area, theme)
+	}
+	drawAxesImage(c, kind, data, area, theme, format, opts.Annotations, opts.Anomalies)
+}
+
+// drawAxesImage 값 축(0 포함)과 격자, 라벨 축을 그린 뒤 선/막대
+func drawAxesImage(c chartCanvas, kind chartImageKind, data *ChartData, area chartArea, theme ChartTheme, format func(float64) string, anns []ChartAnnotation, anomalies []ChartAnomaly) {
+	lo, hi := 0.0, 0.0
+	for _, v := range data.Values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	for _, series := range data.Series {
+		for _, v := range series.Values {
+			if v != nil {
+				lo, hi = math.Min(lo, *v), math.Max(hi, *v)
+			}
+		}
+	}
+	// 비교 계열은 선 차트에만 그림
+	var compare []*float64
+	if data.Compare != nil && (kind == chartImageLine || kind == chartImageArea) {
+		compare = data.Compare.Values
+	}
+	for _, v := range compare {
+		if v != nil {
+			lo, hi = math.Min(lo, *v), math.Max(hi, *v)
+		}
+	}
+	ticks := niceTicks(lo, hi, chartTicks)
+	bottom, top := ticks[0], ticks[len(ticks)-1]
+
+	var axisW float64
+	tickLabels := make([]string, len(ticks))
+	for i, t := range ticks {
+		tickLabels[i] = format(t)
+		axisW = math.Max(axisW, c.TextWidth(tickLabels[i], chartTickSize))
+	}
+	plot := chartArea{X: area.X + axisW + 8, Y: area.Y + chartTickSize/2, W: area.W - axisW - 8, H: area.H - chartTickSize/2 - chartTickSize - 10}
+	yOf := func(v float64) float64 { return plot.Y + plot.H*(top-v)/(top-bottom) }
+
+	for i, t := range ticks {
+		y := yOf(t)
+		c.Rect(plot.X, y-0.5, plot.W, 1, theme.Grid)
+		c.Text(plot.X-8, y+chartTickSize/2-1, chartTickSize, theme.Muted, anchorEnd, tickLabels[i])
+	}
+
+	n := len(data.Values)
+	if len(data.Series) > 0 {
+		n = len(data.Labels)
+	}
+	// xAt 항목 번호(주석은 소수 가능)의 x 좌표. 그림 영역 밖은 가장자리로
+	xAt := func(i float64) float64 {
+		var x float64
+		switch {
+		case kind == chartImageBar:
+			x = plot.X + plot.W*(i+0.5)/float64(n)
+		case n == 1:
+			x = plot.X + plot.W/2 + plot.W*i
+		default:
+			x = plot.X + plot.W*i/float64(n-1)
+		}
+		return math.Min(math.Max(x, plot.X), plot.X+plot.W)
+	}
+	xOf := func(i int) float64 { return xAt(float64(i)) }
+
+	// 기간 주석은 데이터 아래에 음영으로
+	for _, a := range anns {
+		if a.X2 != nil {
+			x1, x2 := xAt(a.X), xAt(*a.X2)
+			c.Rect(x1, plot.Y, math.Max(x2-x1, 1), plot.H, withAlpha(hexColor(parseHexColor(a.Color)), 0x24))
+		}
+	}
+
+	// 라벨이 겹치지 않도록 간격을 두고 표시
+	var labelW float64
+	for i := 0; i < n && i < len(data.Labels); i++ {
+		labelW = math.Max(labelW, c.TextWidth(data.Labels[i], chartTickSize))
+	}
+	slot := plot.W / float64(max(n, 1))
+	step := max(1, int(math.Ceil((labelW+12)/slot)))
+	for i := 0; i < n && i < len(data.Labels); i += step {
+		c.Text(xOf(i), plot.Y+plot.H+chartTickSize+8, chartTickSize, theme.Muted, anchorMiddle, data.Labels[i])
+	}
+
+	zero := yOf(0)
+	lineColor := theme.Palette[0]
+	switch kind {
+	case chartImageBar:
+		barW := math.Min(slot*0.7, 50)
+		for i, v := range data.Values {
+			y := math.Min(yOf(v), zero)
+			c.Rect(xOf(i)-barW/2, y, barW, math.Abs(yOf(v)-zero), theme.Palette[i%len(theme.Palette)])
+		}
+	case chartImageScatter:
+		for i, v := range data.Values {
+			c.Circle(xOf(i), yOf(v), 3.5, lineColor)
+		}
+		for k, series := range data.Series {
+			for i, v := range series.Values {
+				if v != nil {
+					c.Circle(xOf(i), yOf(*v), 3.5, theme.Palette[k%len(theme.Palette)])
+				}
+			}
+		}
+	case chartImageLine, chartImageArea:
+		if len(data.Series) > 0 {
+			drawSeriesLines(c, data.Series, xOf, yOf, slot >= 12, theme)
+			break
+		}
+		fallthrough
+	default:
+		// 비교 계열은 현재 계열 아래에 흐린 선으로, 값이 없는 항목에서 끊음
+		var seg []chartPoint
+		for i := 0; i <= len(compare) && i <= n; i++ {
+			if i < len(compare) && i < n && compare[i] != nil {
+				seg = append(seg, chartPoint{xOf(i), yOf(*compare[i])})
+				continue
+			}
+			if len(seg) > 1 {
+				c.Polyline(seg, 1.5, withAlpha(lineColor, 0x80))
+			}
+			seg = nil
+		}
+
+		pts := make([]chartPoint, n)
+		for i, v := range data.Values {
+			pts[i] = chartPoint{xOf(i), yOf(v)}
+		}
+		if kind == chartImageArea && n > 1 {
+			fill := append([]chartPoint{{pts[0].X, zero}}, pts...)
+			fill = append(fill, chartPoint{pts[n-1].X, zero})
+			c.Polygon(fill, withAlpha(lineColor, 0x40))
+		}
+		c.Polyline(pts, 2.5, lineColor)
+		// 점이 촘촘하면 선만 표시
+		if slot >= 12 {
+			for _, p := range pts {
+				c.Circle(p.X, p.Y, 3.5, lineColor)
+				c.Circle(p.X, p.Y, 1.8, theme.Background)
+			}
+		}
+	}
+
+	// 이상치는 값 위치(막대는 끝)에 빨간 고리
+	anomalyColor := hexColor(0xEF4444)
+	for _, a := range anomalies {
+		if kind == chartImageScatter || a.X < 0 || a.X >= n {
+			continue
+		}
+		x, y := xOf(a.X), yOf(data.Values[a.X])
+		c.Circle(x, y, 6, anomalyColor)
+		c.Circle(x, y, 3.5, theme.Background)
+		c.Circle(x, y, 2, anomalyColor)
+	}
+
+	// 시점 주석은 데이터 위에 세로선, 주석 글은 그림 영역 위쪽에
+	for _, a := range anns {
+		col := hexColor(parseHexColor(a.Color))
+		x := xAt(a.X)
+		if a.X2 == nil {
+			c.Rect(x-0.75, plot.Y, 1.5, plot.H, col)
+		}
+		if c.Covers(a.Text) {
+			c.Text(x+4, plot.Y+chartTickSize, chartTickSize, col, anchorStart, truncateRunes(a.Text, 24))
+		}
+	}
+}
+
+// drawSeriesLegend 위쪽에 계열 색과 이름을 한 줄로 (넘치면 …) 그리고 남은 영역 반환
+func drawSeriesLegend(c chartCanvas, series []ChartSeries, area chartArea, theme ChartTheme) chartArea {
+	const rowH, swatch = 20.0, 10.0
+	x := area.X
+	for i, s := range series {
+		name := truncateRunes(s.Name, 20)
+		w := swatch + 6 + c.TextWidth(name, 12)
+		if x+w > area.X+area.W {
+			c.Text(x, area.Y+rowH/2+4, 12, theme.Muted, anchorStart, "…")
+			break
+		}
+		c.Rect(x, area.Y+(rowH-swatch)/2, swatch, swatch, theme.Palette[i%len(theme.Palette)])
+		c.Text(x+swatch+6, area.Y+rowH/2+4, 12, theme.Text, anchorStart, name)
+		x += w + 16
+	}
+	return chartArea{X: area.X, Y: area.Y + rowH + 8, W: area.W, H: area.H - rowH - 8}
+}
+
+// drawSeriesLines 계열마다 다른 색의 선. 값이 없는 항목은 건너뛰고 이어 그림 (브라우저의 spanGaps와 같음)
+func drawSeriesLines(c chartCanvas, series []ChartSeries, xOf func(int) float64, yOf func(float64) float64, dots bool, theme ChartTheme) {
+	for k, s := range series {
+		col := theme.Palette[k%len(theme.Palette)]
+		var pts []chartPoint
+		for i, v := range s.Values {
+			if v != nil {
+				pts = append(pts, chartPoint{xOf(i), yOf(*v)})
+			}
+		}
+		if len(pts) > 1 {
+			c.Polyline(pts, 2, col)
+		}
+		if dots || len(pts) == 1 {
+			for _, p := range pts {
+				c.Circle(p.X, p.Y, 3, col)
+			}
+		}
+	}
+}
+
+// truncateRunes n글자가 넘으면 잘라서 … 을 붙임
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// drawPieImage 왼쪽에 원, 오른쪽에 범례 (라벨, 값, 비율). 음수 값은 제외
+func drawPieImage(c chartCanvas, doughnut bool, data *ChartData, area chartArea, theme ChartTheme, format func(float64) string) {
+	total := sumPositive(data.Values)
+	r := math.Min(area.H, area.W*0.45) / 2
+	cx, cy := area.X+r, area.Y+area.H/2
+
+	angle := -math.Pi / 2
+	var edges []float64
+	for i, v := range data.Values {
+		if v <= 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		steps := max(2, int(math.Ceil(sweep/(math.Pi/90))))
+		pts := []chartPoint{{cx, cy}}
+		for s := 0; s <= steps; s++ {
+			a := angle + sweep*float64(s)/float64(steps)
+			pts = append(pts, chartPoint{cx + r*math.Cos(a), cy + r*math.Sin(a)})
+		}
+		c.Polygon(pts, theme.Palette[i%len(theme.Palette)])
+		edges = append(edges, angle)
+		angle += sweep
+	}
+	// 조각 사이 경계선
+	if len(edges) > 1 {
+		for _, a := range edges {
+			c.Polyline([]chartPoint{{cx, cy}, {cx + r*math.Cos(a), cy + r*math.Sin(a)}}, 2, theme.Background)
+		}
+	}
+	if doughnut {
+		c.Circle(cx, cy, r*0.62, theme.Background)
+	}
+
+	const rowH, swatch = 22.0, 10.0
+	x := cx + r + 28
+	rows := int(area.H / rowH)
+	y := cy - math.Min(float64(len(data.Values)), float64(rows))*rowH/2
+	for i, v := range data.Values {
+		if i >= rows {
+			break
+		}
+		label := ""
+		if i < len(data.Labels) {
+			label = data.Labels[i]
+		}
+		if i == rows-1 && len(data.Values) > rows {
+			label, v = "…", 0
+		}
+		c.Rect(x, y+(rowH-swatch)/2, swatch, swatch, theme.Palette[i%len(theme.Palette)])
+		c.Text(x+swatch+8, y+rowH/2+4, 12, theme.Text, anchorStart, label)
+		if label != "…" {
+			share := math.Max(v, 0) / total * 100
+			c.Text(area.X+area.W, y+rowH/2+4, 12, theme.Muted, anchorEnd,
+				format(v)+" ("+LookupLocale("ko-KR").FormatNumber(math.Round(share*10)/10)+"%)")
+		}
+		y += rowH
+	}
+}
+
+func sumPositive(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		if v > 0 {
+			sum += v
+		}
+	}
+	return sum
+}
+
+// niceTicks lo~hi를 포함하는 1·2·5 단위 눈금 (n개 안팎)
+func niceTicks(lo, hi float64, n int) []float64 {
+	if hi <= lo {
+		hi = lo + 1
+	}
+	raw := (hi - lo) / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	var step float64
+	switch f := raw / mag; {
+	case f <= 1:
+		step = mag
+	case f <= 2:
+		step = 2 * mag
+	case f <= 5:
+		step = 5 * mag
+	default:
+		step = 10 * mag
+	}
+
+	start, end := math.Floor(lo/step), math.Ceil(hi/step)
+	ticks := make([]float64, 0, int(end-start)+1)
+	for i := start; i <= end; i++ {
+		// 부동소수점 오차 제거
+		ticks = append(ticks, math.Round(i*step/mag*1e6)/(1e6/mag))
+	}
+	return ticks
+}
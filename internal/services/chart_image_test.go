@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testChartData() *ChartData {
+	return &ChartData{Labels: []string{"Jan", "Feb", "<Mar>"}, Values: []float64{12, 30, 18}}
+}
+
+func TestRenderChartSVG(t *testing.T) {
+	for _, typ := range []string{"line", "area", "bar", "stacked_bar", "scatter", "pie", "doughnut"} {
+		svg, err := RenderChartSVG(testChartData(), ChartImageOptions{Type: typ, Title: "A & B"})
+		require.NoError(t, err, typ)
+
+		// 라벨과 제목이 이스케이프되어 올바른 XML
+		dec := xml.NewDecoder(bytes.NewReader(svg))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, typ)
+		}
+		assert.Contains(t, string(svg), `width="800" height="400"`, typ)
+		assert.Contains(t, string(svg), "A &amp; B", typ)
+		assert.NotContains(t, string(svg), "<Mar>", typ)
+	}
+}
+
+func TestRenderChartSVGCompare(t *testing.T) {
+	a, b, c := 10.0, 40.0, 5.0
+	data := testChartData()
+	data.Compare = &CompareSeries{Name: "이전 기간", Values: []*float64{&a, &b, nil}}
+
+	svg, err := RenderChartSVG(data, ChartImageOptions{Type: "line"})
+	require.NoError(t, err)
+	// 현재 계열 + 비교 계열(3번째 항목에서 끊김)
+	assert.Equal(t, 2, strings.Count(string(svg), "<polyline"))
+	// 비교 값 40이 축 범위에 포함됨
+	assert.Contains(t, string(svg), ">40<")
+
+	// 항목 하나만 남으면 선을 그리지 않음
+	data.Compare.Values = []*float64{nil, &c, nil}
+	svg, err = RenderChartSVG(data, ChartImageOptions{Type: "line"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(svg), "<polyline"))
+}
+
+func TestRenderChartSVGSeries(t *testing.T) {
+	a, b, c := 10.0, 60.0, 5.0
+	data := &ChartData{Labels: []string{"Jan", "Feb", "Mar"}, Values: []float64{},
+		Series: []ChartSeries{{Name: "eu", Values: []*float64{&a, nil, &b}}, {Name: "us", Values: []*float64{nil, &c, nil}}}}
+
+	svg, err := RenderChartSVG(data, ChartImageOptions{Type: "line"})
+	require.NoError(t, err)
+	// 값이 둘 이상인 계열만 선, 범례에 계열 이름
+	assert.Equal(t, 1, strings.Count(string(svg), "<polyline"))
+	assert.Contains(t, string(svg), ">eu<")
+	assert.Contains(t, string(svg), ">us<")
+	assert.Contains(t, string(svg), ">60<")
+}
+
+func TestRenderChartPNG(t *testing.T) {
+	raw, err := RenderChartPNG(testChartData(), ChartImageOptions{Type: "bar", Width: 300, Height: 200})
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 200, img.Bounds().Dy())
+
+	// 배율은 픽셀 크기만 키움
+	raw, err = RenderChartPNG(testChartData(), ChartImageOptions{Type: "line", Width: 300, Height: 200, Scale: 2})
+	require.NoError(t, err)
+	img, err = png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, 600, img.Bounds().Dx())
+	assert.Equal(t, 400, img.Bounds().Dy())
+
+	// 크기 제한
+	raw, err = RenderChartPNG(testChartData(), ChartImageOptions{Type: "pie", Width: 10, Height: 5000})
+	require.NoError(t, err)
+	img, err = png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, ChartImageMinSize, img.Bounds().Dx())
+	assert.Equal(t, ChartImageMaxSize, img.Bounds().Dy())
+}
+
+func TestRenderChartPNGTheme(t *testing.T) {
+	corner := func(theme ChartTheme) [4]uint32 {
+		raw, err := RenderChartPNG(testChartData(), ChartImageOptions{Type: "line", Width: 200, Height: 100, Theme: theme})
+		require.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(raw))
+		require.NoError(t, err)
+		r, g, b, a := img.At(0, 0).RGBA()
+		return [4]uint32{r >> 8, g >> 8, b >> 8, a >> 8}
+	}
+	assert.Equal(t, [4]uint32{0xFF, 0xFF, 0xFF, 0xFF}, corner(LightChartTheme))
+	assert.Equal(t, [4]uint32{0x1F, 0x29, 0x37, 0xFF}, corner(DarkChartTheme))
+	assert.Equal(t, DarkChartTheme.Background, LookupChartTheme("dark").Background)
+	assert.Equal(t, LightChartTheme.Background, LookupChartTheme("unknown").Background)
+}
+
+func TestRenderChartImageUnsupported(t *testing.T) {
+	_, err := RenderChartSVG(testChartData(), ChartImageOptions{Type: "gauge"})
+	assert.ErrorIs(t, err, ErrUnsupportedChartImage)
+	_, err = RenderChartPNG(testChartData(), ChartImageOptions{Type: "gauge"})
+	assert.ErrorIs(t, err, ErrUnsupportedChartImage)
+}
+
+func TestNiceTicks(t *testing.T) {
+	assert.Equal(t, []float64{0, 20, 40, 60, 80, 100}, niceTicks(0, 97, 5))
+	assert.Equal(t, []float64{-0.5, 0, 0.5, 1, 1.5}, niceTicks(-0.3, 1.2, 4))
+	assert.Equal(t, []float64{0, 0.2, 0.4, 0.6, 0.8, 1}, niceTicks(0, 0, 5))
+}
+
+func TestChartFontCovers(t *testing.T) {
+	assert.True(t, fontCovers(defaultChartFont(), "Revenue 1,234%"))
+	assert.False(t, fontCovers(defaultChartFont(), "매출"))
+
+	// 한글 라벨은 기본 글꼴로 그릴 수 없으므로 보고서 메일에서는 표로 표시
+	assert.Nil(t, renderReportChartPNG(ReportChart{Type: "bar", Labels: []string{"서울"}, Values: []float64{1}}))
+	assert.NotNil(t, renderReportChartPNG(ReportChart{Type: "bar", Labels: []string{"Seoul"}, Values: []float64{1}}))
+	assert.Nil(t, renderReportChartPNG(ReportChart{Type: "bar"}))
+}
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+)
+
+var ErrUnknownChartType = errors.New("unknown chart type")
+
+// ChartOptions 차트 표시 옵션 (쿼리 파라미터로 전달)
+type ChartOptions struct {
+	// Label 데이터셋 이름 (툴팁/범례)
+	Label string
+	// Unit 값 앞에 붙는 단위 (예: ₩), Suffix 값 뒤에 붙는 단위 (예: %)
+	Unit   string
+	Suffix string
+	// Max 게이지 최댓값. 0이면 값에 맞춰 자동 계산
+	Max float64
+	// Legend 범례 위치 (none, top, side). 비어 있으면 차트 종류 기본값
+	Legend string
+}
+
+// ChartMeta 브라우저에서 테마/콜백을 적용할 때 필요한 정보 (static/js/charts.js)
+type ChartMeta struct {
+	Type string `json:"type"`
+	// ColorMode perPoint: 데이터 항목마다 색, perDataset: 데이터셋마다 색
+	ColorMode string    `json:"colorMode"`
+	Gradient  bool      `json:"gradient,omitempty"`
+	Legend    string    `json:"legend"`
+	Unit      string    `json:"unit,omitempty"`
+	Suffix    string    `json:"suffix,omitempty"`
+	XLabels   []string  `json:"xLabels,omitempty"`
+	GaugeText string    `json:"gaugeText,omitempty"`
+	Live      *LiveMeta `json:"live,omitempty"`
+	// Annotations 시계열 차트의 이벤트 표시
+	Annotations []ChartAnnotation `json:"annotations,omitempty"`
+	// Anomalies 시계열 차트에 표시할 이상치
+	Anomalies []ChartAnomaly `json:"anomalies,omitempty"`
+	// Compare 비교 계열 이름, CompareLabels는 항목별 비교 기간 라벨
+	Compare       string   `json:"compare,omitempty"`
+	CompareLabels []string `json:"compareLabels,omitempty"`
+	// Forecast 예측에 쓴 모델, Confidence는 예측구간 신뢰수준
+	Forecast   ForecastModel `json:"forecast,omitempty"`
+	Confidence float64       `json:"confidence,omitempty"`
+	// Series 여러 계열 차트의 계열 수 (옆 범례를 계열 목록으로 표시)
+	Series int `json:"series,omitempty"`
+}
+
+// 시계열 보조 데이터셋 구분 (Chart.js 데이터셋의 commetRole, static/js/charts.js)
+const (
+	roleCompare      = "compare"
+	roleForecast     = "forecast"
+	roleForecastBand = "forecastBand"
+)
+
+// 실시간 이벤트 반영 방식
+const (
+	// LiveRaw 기간 없이 행 단위로 그린 차트: 새 포인트를 끝에 추가
+	LiveRaw = "raw"
+	// LiveLabel 라벨별 집계: 같은 라벨 값에 누적
+	LiveLabel = "label"
+	// LiveTime 시계열: 해당 버킷 값에 누적
+	LiveTime = "time"
+	// LiveRefresh 태그 필터·그룹 차트: 기간 안의 이벤트가 오면 다시 조회
+	LiveRefresh = "refresh"
+)
+
+// LiveMeta 브라우저가 SSE 이벤트를 차트에 직접 반영하기 위한 정보 (시각은 unix ms)
+type LiveMeta struct {
+	Category    string  `json:"category"`
+	Mode        string  `json:"mode"`
+	Aggregation string  `json:"agg,omitempty"`
+	From        int64   `json:"from,omitempty"`
+	To          int64   `json:"to,omitempty"`
+	Buckets     []int64 `json:"buckets,omitempty"`
+}
+
+// ChartRenderer 차트 종류별 Chart.js 설정 생성기
+type ChartRenderer interface {
+	// Name 쿼리 파라미터 type 값
+	Name() string
+	// TimeSeries 기간이 지정되었을 때 시간 축으로 그리는지 여부
+	TimeSeries() bool
+	Render(data *ChartData, opts ChartOptions) (config map[string]any, meta ChartMeta)
+}
+
+var (
+	chartRenderersMu sync.RWMutex
+	chartRenderers   = map[string]ChartRenderer{}
+)
+
+// RegisterChartRenderer 차트 종류 등록. 같은 이름은 덮어씀
+func RegisterChartRenderer(r ChartRenderer) {
+	chartRenderersMu.Lock()
+	defer chartRenderersMu.Unlock()
+	chartRenderers[r.Name()] = r
+}
+
+func LookupChartRenderer(name string) (ChartRenderer, error) {
+	chartRenderersMu.RLock()
+	defer chartRenderersMu.RUnlock()
+	r, ok := chartRenderers[name]
+	if !ok {
+		return nil, ErrUnknownChartType
+	}
+	return r, nil
+}
+
+// ChartTypes 등록된 차트 종류 이름 (정렬됨)
+func ChartTypes() []string {
+	chartRenderersMu.RLock()
+	defer chartRenderersMu.RUnlock()
+	names := make([]string, 0, len(chartRenderers))
+	for name := range chartRenderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterChartRenderer(lineRenderer{name: "line"})
+	RegisterChartRenderer(lineRenderer{name: "area", fill: true})
+	RegisterChartRenderer(barRenderer{})
+	RegisterChartRenderer(stackedBarRenderer{})
+	RegisterChartRenderer(pieRenderer{name: "pie", chartType: "pie"})
+	RegisterChartRenderer(pieRenderer{name: "doughnut", chartType: "doughnut", cutout: "65%"})
+	RegisterChartRenderer(scatterRenderer{})
+	RegisterChartRenderer(gaugeRenderer{})
+}
+
+func baseMeta(name, colorMode, legend string, opts ChartOptions) ChartMeta {
+	if opts.Legend != "" {
+		legend = opts.Legend
+	}
+	return ChartMeta{
+		Type:      name,
+		ColorMode: colorMode,
+		Legend:    legend,
+		Unit:      opts.Unit,
+		Suffix:    opts.Suffix,
+	}
+}
+
+func valueScales(stacked bool) map[string]any {
+	return map[string]any{
+		"x": map[string]any{"stacked": stacked, "grid": map[string]any{"display": false}},
+		"y": map[string]any{"stacked": stacked, "beginAtZero": true, "border": map[string]any{"display": false}},
+	}
+}
+
+type lineRenderer struct {
+	name string
+	fill bool
+}
+
+func (r lineRenderer) Name() string     { return r.name }
+func (r lineRenderer) TimeSeries() bool { return true }
+
+func (r lineRenderer) Render(data *ChartData, opts ChartOptions) (map[string]any, ChartMeta) {
+	meta := baseMeta(r.name, "perDataset", "none", opts)
+	meta.Gradient = r.fill
+	dataset := func(label string, values any) map[string]any {
+		return map[string]any{
+			"label":            label,
+			"data":             values,
+			"borderWidth":      3,
+			"tension":          0.4,
+			"fill":             r.fill,
+			"pointBorderWidth": 2,
+			"pointRadius":      4,
+			"pointHoverRadius": 6,
+		}
+	}
+	datasets := []map[string]any{dataset(opts.Label, data.Values)}
+	// 태그 값별 계열은 계열마다 데이터셋 (값이 없는 버킷은 건너뛰고 이어 그림)
+	if len(data.Series) > 0 {
+		datasets = make([]map[string]any, len(data.Series))
+		for i, series := range data.Series {
+			datasets[i] = dataset(series.Name, series.Values)
+			datasets[i]["spanGaps"] = true
+		}
+		meta.Series = len(data.Series)
+	}
+	labels := data.Labels
+	// 비교 계열은 채우지 않은 점선으로 현재 계열 뒤에 그림
+	if data.Compare != nil {
+		datasets = append(datasets, map[string]any{
+			"commetRole":       roleCompare,
+			"label":            data.Compare.Name,
+			"data":             data.Compare.Values,
+			"borderWidth":      2,
+			"borderDash":       []int{6, 4},
+			"tension":          0.4,
+			"fill":             false,
+			"spanGaps":         true,
+			"pointRadius":      0,
+			"pointHoverRadius": 4,
+			"order":            1,
+		})
+		meta.Compare = data.Compare.Name
+		meta.CompareLabels = data.Compare.Labels
+	}
+	// 예측은 마지막 값에서 이어지는 점선과 신뢰구간 음영 (하한~상한 사이를 채움)
+	if data.Forecast != nil {
+		var values, lower, upper []*float64
+		labels, values, lower, upper = forecastSeries(data)
+		band := func(label string, values []*float64, fill any) map[string]any {
+			return map[string]any{
+				"commetRole":  roleForecastBand,
+				"label":       label,
+				"data":        values,
+				"borderWidth": 0,
+				"pointRadius": 0,
+				"tension":     0.4,
+				"fill":        fill,
+				"order":       2,
+			}
+		}
+		datasets = append(datasets,
+			map[string]any{
+				"commetRole":       roleForecast,
+				"label":            "예측",
+				"data":             values,
+				"borderWidth":      2,
+				"borderDash":       []int{6, 4},
+				"tension":          0.4,
+				"fill":             false,
+				"pointRadius":      0,
+				"pointHoverRadius": 4,
+				"order":            1,
+			},
+			band("예측 하한", lower, false),
+			band("예측 상한", upper, "-1"),
+		)
+		meta.Forecast = data.Forecast.Model
+		meta.Confidence = data.Forecast.Confidence
+	}
+	if (data.Compare != nil || data.Forecast != nil || len(data.Series) > 0) && opts.Legend == "" {
+		meta.Legend = "top"
+	}
+	return map[string]any{
+		"type": "line",
+		"data": map[string]any{
+			"labels":   labels,
+			"datasets": datasets,
+		},
+		"options": map[string]any{
+			"interaction": map[string]any{"intersect": false, "mode": "index"},
+			"scales":      valueScales(false),
+		},
+	}, meta
+}
+
+// forecastSeries 예측 라벨까지 늘린 x축과 그 축에 맞춘 예측값·하한·상한.
+// 진행 중인 마지막 버킷처럼 이미 있는 라벨은 겹쳐 그리고, 예측 직전 항목의 실제 값에서 선을 이어 시작
+func forecastSeries(data *ChartData) (labels []string, values, lower, upper []*float64) {
+	f := data.Forecast
+	labels = append([]string(nil), data.Labels...)
+	offset := len(labels)
+	for i, label := range labels {
+		if len(f.Labels) > 0 && label == f.Labels[0] {
+			offset = i
+			break
+		}
+	}
+	for i, label := range f.Labels {
+		if offset+i >= len(labels) {
+			labels = append(labels, label)
+		}
+	}
+
+	values = make([]*float64, len(labels))
+	lower = make([]*float64, len(labels))
+	upper = make([]*float64, len(labels))
+	if offset > 0 && offset-1 < len(data.Values) {
+		anchor := &data.Values[offset-1]
+		values[offset-1], lower[offset-1], upper[offset-1] = anchor, anchor, anchor
+	}
+	for i := range f.Values {
+		values[offset+i], lower[offset+i], upper[offset+i] = &f.Values[i], &f.Lower[i], &f.Upper[i]
+	}
+	return labels, values, lower, upper
+}
+
+type barRenderer struct{}
+
+func (barRenderer) Name() string     { return "bar" }
+func (barRenderer) TimeSeries() bool { return false }
+
+func (barRenderer) Render(data *ChartData, opts ChartOptions) (map[string]any, ChartMeta) {
+	meta := baseMeta("bar", "perPoint", "none", opts)
+	meta.Gradient = true
+	return map[string]any{
+		"type": "bar",
+		"data": map[string]any{
+			"labels": data.Labels,
+			"datasets": []map[string]any{{
+				"label":           opts.Label,
+				"data":            data.Values,
+				"borderRadius":    8,
+				"borderSkipped":   false,
+				"barThickness":    40,
+				"maxBarThickness": 50,
+			}},
+		},
+		"options": map[string]any{"scales": valueScales(false)},
+	}, meta
+}
+
+// stackedBarRenderer 라벨별 값을 한 막대에 쌓아 구성비를 표시
+type stackedBarRenderer struct{}
+
+func (stackedBarRenderer) Name() string     { return "stacked_bar" }
+func (stackedBarRenderer) TimeSeries() bool { return false }
+
+func (stackedBarRenderer) Render(data *ChartData, opts ChartOptions) (map[string]any, ChartMeta) {
+	datasets := make([]map[string]any, len(data.Labels))
+	for i, label := range data.Labels {
+		datasets[i] = map[string]any{
+			"label":         label,
+			"data":          []float64{data.Values[i]},
+			"borderSkipped": false,
+			"barThickness":  48,
+		}
+	}
+	return map[string]any{
+		"type": "bar",
+		"data": map[string]any{
+			"labels":   []string{opts.Label},
+			"datasets": datasets,
+		},
+		"options": map[string]any{
+			"indexAxis": "y",
+			"scales":    valueScales(true),
+		},
+	}, baseMeta("stacked_bar", "perDataset", "top", opts)
+}
+
+type pieRenderer struct {
+	name      string
+	chartType string
+	cutout    string
+}
+
+func (r pieRenderer) Name() string     { return r.name }
+func (r pieRenderer) TimeSeries() bool { return false }
+
+func (r pieRenderer) Render(data *ChartData, opts ChartOptions) (map[string]any, ChartMeta) {
+	options := map[string]any{
+		"maintainAspectRatio": true,
+		"animation":           map[string]any{"animateRotate": true, "animateScale": true},
+	}
+	if r.cutout != "" {
+		options["cutout"] = r.cutout
+	}
+	return map[string]any{
+		"type": r.chartType,
+		"data": map[string]any{
+			"labels": data.Labels,
+			"datasets": []map[string]any{{
+				"label":       opts.Label,
+				"data":        data.Values,
+				"borderWidth": 0,
+				"hoverOffset": 8,
+			}},
+		},
+		"options": options,
+	}, baseMeta(r.name, "perPoint", "side", opts)
+}
+
+// scatterRenderer x는 항목 순서, 눈금은 라벨로 표시
+type scatterRenderer struct{}
+
+func (scatterRenderer) Name() string     { return "scatter" }
+func (scatterRenderer) TimeSeries() bool { return true }
+
+func (scatterRenderer) Render(data *ChartData, opts ChartOptions) (map[string]any, ChartMeta) {
+	dataset := func(label string, points []map[string]float64) map[string]any {
+		return map[string]any{
+			"label":            label,
+			"data":             points,
+			"pointRadius":      5,
+			"pointHoverRadius": 7,
+		}
+	}
+	points := make([]map[string]float64, len(data.Values))
+	for i, v := range data.Values {
+		points[i] = map[string]float64{"x": float64(i), "y": v}
+	}
+	meta := baseMeta("scatter", "perDataset", "none", opts)
+	meta.XLabels = data.Labels
+	datasets := []map[string]any{dataset(opts.Label, points)}
+	if len(data.Series) > 0 {
+		datasets = make([]map[string]any, len(data.Series))
+		for i, series := range data.Series {
+			points := []map[string]float64{}
+			for x, v := range series.Values {
+				if v != nil {
+					points = append(points, map[string]float64{"x": float64(x), "y": *v})
+				}
+			}
+			datasets[i] = dataset(series.Name, points)
+		}
+		meta.Series = len(data.Series)
+		if opts.Legend == "" {
+			meta.Legend = "top"
+		}
+	}
+	return map[string]any{
+		"type": "scatter",
+		"data": map[string]any{
+			"datasets": datasets,
+		},
+		"options": map[string]any{
+			"scales": map[string]any{
+				"x": map[string]any{"type": "linear", "ticks": map[string]any{"stepSize": 1}, "grid": map[string]any{"display": false}},
+				"y": map[string]any{"beginAtZero": true, "border": map[string]any{"display": false}},
+			},
+		},
+	}, meta
+}
+
+// gaugeRenderer 전체 합계를 반원 게이지로 표시
+type gaugeRenderer struct{}
+
+func (gaugeRenderer) Name() string     { return "gauge" }
+func (gaugeRenderer) TimeSeries() bool { return false }
+
+func (gaugeRenderer) Render(data *ChartData, opts ChartOptions) (map[string]any, ChartMeta) {
+	var total float64
+	for _, v := range data.Values {
+		total += v
+	}
+	max := opts.Max
+	if max <= 0 {
+		max = gaugeMax(total)
+	}
+
+	meta := baseMeta("gauge", "gauge", "none", opts)
+	meta.GaugeText = opts.Unit + LookupLocale("ko-KR").FormatNumber(total) + opts.Suffix
+	return map[string]any{
+		"type": "doughnut",
+		"data": map[string]any{
+			"labels": []string{opts.Label, ""},
+			"datasets": []map[string]any{{
+				"data":        []float64{math.Min(total, max), math.Max(max-total, 0)},
+				"borderWidth": 0,
+			}},
+		},
+		"options": map[string]any{
+			"rotation":            -90,
+			"circumference":       180,
+			"cutout":              "75%",
+			"maintainAspectRatio": false,
+		},
+	}, meta
+}
+
+// gaugeMax 값보다 큰 1, 2, 5 × 10^n 단위의 최댓값
+func gaugeMax(v float64) float64 {
+	if v <= 0 {
+		return 100
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if step*mag >= v*1.1 {
+			return step * mag
+		}
+	}
+	return 10 * mag
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartTypes_Registered(t *testing.T) {
+	assert.Equal(t, []string{"area", "bar", "doughnut", "gauge", "line", "pie", "scatter", "stacked_bar"}, ChartTypes())
+
+	_, err := LookupChartRenderer("radar")
+	assert.Equal(t, ErrUnknownChartType, err)
+}
+
+func TestChartRenderer_Meta(t *testing.T) {
+	data := &ChartData{Labels: []string{"A", "B"}, Values: []float64{30, 70}}
+
+	r, _ := LookupChartRenderer("doughnut")
+	config, meta := r.Render(data, ChartOptions{Label: "traffic", Suffix: "%"})
+	assert.Equal(t, "doughnut", config["type"])
+	assert.Equal(t, "side", meta.Legend)
+	assert.Equal(t, "perPoint", meta.ColorMode)
+
+	// 범례 위치는 옵션으로 덮어씀
+	_, meta = r.Render(data, ChartOptions{Legend: "none"})
+	assert.Equal(t, "none", meta.Legend)
+
+	r, _ = LookupChartRenderer("scatter")
+	config, meta = r.Render(data, ChartOptions{})
+	assert.Equal(t, []string{"A", "B"}, meta.XLabels)
+	datasets := config["data"].(map[string]any)["datasets"].([]map[string]any)
+	assert.Equal(t, []map[string]float64{{"x": 0, "y": 30}, {"x": 1, "y": 70}}, datasets[0]["data"])
+}
+
+func TestStackedBarRenderer_DatasetPerLabel(t *testing.T) {
+	r, _ := LookupChartRenderer("stacked_bar")
+	config, _ := r.Render(&ChartData{Labels: []string{"A", "B", "C"}, Values: []float64{1, 2, 3}}, ChartOptions{Label: "total"})
+
+	datasets := config["data"].(map[string]any)["datasets"].([]map[string]any)
+	assert.Len(t, datasets, 3)
+	assert.Equal(t, "B", datasets[1]["label"])
+	assert.Equal(t, []float64{2}, datasets[1]["data"])
+}
+
+func TestGaugeRenderer(t *testing.T) {
+	r, _ := LookupChartRenderer("gauge")
+	config, meta := r.Render(&ChartData{Labels: []string{"a", "b"}, Values: []float64{300, 420}}, ChartOptions{Unit: "₩"})
+
+	assert.Equal(t, "₩720", meta.GaugeText)
+	datasets := config["data"].(map[string]any)["datasets"].([]map[string]any)
+	assert.Equal(t, []float64{720, 280}, datasets[0]["data"])
+}
+
+func TestGaugeMax(t *testing.T) {
+	assert.Equal(t, 100.0, gaugeMax(0))
+	assert.Equal(t, 2.0, gaugeMax(1))
+	assert.Equal(t, 1000.0, gaugeMax(720))
+	assert.Equal(t, 5000.0, gaugeMax(3200))
+}
+
+func TestLineRenderer_Compare(t *testing.T) {
+	prev := 5.0
+	data := &ChartData{Labels: []string{"A", "B"}, Values: []float64{30, 70},
+		Compare: &CompareSeries{Name: "전년 동기", Labels: []string{"a", "b"}, Values: []*float64{&prev, nil}}}
+
+	r, _ := LookupChartRenderer("area")
+	config, meta := r.Render(data, ChartOptions{Label: "sales"})
+	datasets := config["data"].(map[string]any)["datasets"].([]map[string]any)
+	assert.Len(t, datasets, 2)
+	assert.Equal(t, "전년 동기", datasets[1]["label"])
+	assert.Equal(t, false, datasets[1]["fill"])
+	assert.Equal(t, "전년 동기", meta.Compare)
+	assert.Equal(t, []string{"a", "b"}, meta.CompareLabels)
+	assert.Equal(t, "top", meta.Legend)
+}
+
+func TestLineRenderer_Series(t *testing.T) {
+	v := 3.0
+	data := &ChartData{Labels: []string{"A", "B"}, Values: []float64{},
+		Series: []ChartSeries{{Name: "eu", Values: []*float64{&v, nil}}, {Name: "us", Values: []*float64{nil, &v}}}}
+
+	r, _ := LookupChartRenderer("line")
+	config, meta := r.Render(data, ChartOptions{Label: "sales"})
+	datasets := config["data"].(map[string]any)["datasets"].([]map[string]any)
+	require.Len(t, datasets, 2)
+	assert.Equal(t, "us", datasets[1]["label"])
+	assert.Equal(t, true, datasets[1]["spanGaps"])
+	assert.Equal(t, "top", meta.Legend)
+	assert.Equal(t, 2, meta.Series)
+
+	r, _ = LookupChartRenderer("scatter")
+	config, _ = r.Render(data, ChartOptions{})
+	datasets = config["data"].(map[string]any)["datasets"].([]map[string]any)
+	assert.Equal(t, []map[string]float64{{"x": 1, "y": 3}}, datasets[1]["data"])
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+)
+
+var ErrInvalidCompare = errors.New("invalid compare mode")
+
+// CompareMode 시계열 비교 기준
+type CompareMode string
+
+const (
+	CompareNone CompareMode = ""
+	// ComparePrevious 조회 기간 바로 앞의 같은 길이 기간
+	ComparePrevious CompareMode = "previous"
+	// CompareYear 1년 전 같은 기간
+	CompareYear CompareMode = "year"
+	// CompareOffset 사용자 지정 간격 전 (예: 7d, 2w, 3m)
+	CompareOffset CompareMode = "offset"
+)
+
+// offsetUnits 사용자 지정 간격 단위와 표시 이름
+var offsetUnits = map[byte]string{
+	'h': "시간",
+	'd': "일",
+	'w': "주",
+	'm': "개월",
+	'y': "년",
+}
+
+// Comparison 비교 계열 조건. Amount/Unit은 CompareOffset에서만 사용
+type Comparison struct {
+	Mode   CompareMode
+	Amount int
+	Unit   byte
+}
+
+// ParseComparison ?compare= 값 ("", previous, year, 또는 숫자+단위 h/d/w/m/y)
+func ParseComparison(s string) (Comparison, error) {
+	switch CompareMode(s) {
+	case CompareNone, "none":
+		return Comparison{}, nil
+	case ComparePrevious, CompareYear:
+		return Comparison{Mode: CompareMode(s)}, nil
+	}
+	if len(s) < 2 {
+		return Comparison{}, ErrInvalidCompare
+	}
+	unit := s[len(s)-1]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if _, ok := offsetUnits[unit]; !ok || err != nil || n < 1 || n > 999 {
+		return Comparison{}, ErrInvalidCompare
+	}
+	return Comparison{Mode: CompareOffset, Amount: n, Unit: unit}, nil
+}
+
+func (c Comparison) Enabled() bool {
+	return c.Mode != CompareNone
+}
+
+// String ParseComparison으로 되읽을 수 있는 값
+func (c Comparison) String() string {
+	if c.Mode == CompareOffset {
+		return strconv.Itoa(c.Amount) + string(c.Unit)
+	}
+	return string(c.Mode)
+}
+
+// Label 범례·KPI 카드에 표시할 비교 기준 이름
+func (c Comparison) Label() string {
+	switch c.Mode {
+	case ComparePrevious:
+		return "이전 기간"
+	case CompareYear:
+		return "전년 동기"
+	case CompareOffset:
+		return strconv.Itoa(c.Amount) + offsetUnits[c.Unit] + " 전"
+	}
+	return ""
+}
+
+// Shift 조회 기간 [from, to)의 시각 t에 대응하는 비교 시각
+func (c Comparison) Shift(t, from, to time.Time) time.Time {
+	switch c.Mode {
+	case ComparePrevious:
+		return t.Add(-to.Sub(from))
+	case CompareYear:
+		return t.AddDate(-1, 0, 0)
+	case CompareOffset:
+		n := c.Amount
+		switch c.Unit {
+		case 'h':
+			return t.Add(-time.Duration(n) * time.Hour)
+		case 'd':
+			return t.AddDate(0, 0, -n)
+		case 'w':
+			return t.AddDate(0, 0, -7*n)
+		case 'm':
+			return t.AddDate(0, -n, 0)
+		case 'y':
+			return t.AddDate(-n, 0, 0)
+		}
+	}
+	return t
+}
+
+// CompareSeries 현재 계열의 항목마다 맞춘 비교 계열. 값이 없는 항목은 null
+type CompareSeries struct {
+	Name   string     `json:"name"`
+	Labels []string   `json:"labels"`
+	Values []*float64 `json:"values"`
+}
+
+// alignComparison 비교 기간의 집계를 현재 계열(data)에 남은 버킷 순서대로 맞춤.
+// 각 버킷 시작 시각을 옮긴 뒤 그 시각이 속한 버킷 값을 사용하고, 합계/건수는 없는 버킷을 0으로 채움
+func alignComparison(points []repository.TimeSeriesPoint, starts []time.Time, data *ChartData, q ChartQuery) *CompareSeries {
+	byBucket := make(map[int64]float64, len(points))
+	for _, p := range points {
+		byBucket[p.Bucket.Unix()] = p.Value
+	}
+
+	fillZero := q.Aggregation == repository.AggSum || q.Aggregation == repository.AggCount
+	series := &CompareSeries{
+		Name:   q.Compare.Label(),
+		Labels: make([]string, 0, len(data.Labels)),
+		Values: make([]*float64, 0, len(data.Labels)),
+	}
+	j := 0
+	for _, t := range starts {
+		if j == len(data.Labels) {
+			break
+		}
+		if bucketLabel(t, q.Bucket) != data.Labels[j] {
+			continue
+		}
+		j++
+		shifted := truncateTime(q.Compare.Shift(t, *q.From, *q.To), q.Bucket, q.Location)
+		series.Labels = append(series.Labels, bucketLabel(shifted, q.Bucket))
+		v, ok := byBucket[shifted.Unix()]
+		if !ok && !fillZero {
+			series.Values = append(series.Values, nil)
+			continue
+		}
+		series.Values = append(series.Values, &v)
+	}
+	return series
+}
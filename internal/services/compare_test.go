@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComparison(t *testing.T) {
+	for s, want := range map[string]Comparison{
+		"":         {},
+		"none":     {},
+		"previous": {Mode: ComparePrevious},
+		"year":     {Mode: CompareYear},
+		"14d":      {Mode: CompareOffset, Amount: 14, Unit: 'd'},
+		"3m":       {Mode: CompareOffset, Amount: 3, Unit: 'm'},
+	} {
+		c, err := ParseComparison(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, c, s)
+	}
+	for _, s := range []string{"d", "0d", "-1d", "7x", "1000d", "last"} {
+		_, err := ParseComparison(s)
+		assert.ErrorIs(t, err, ErrInvalidCompare, s)
+	}
+
+	c, _ := ParseComparison("2w")
+	assert.Equal(t, "2w", c.String())
+	assert.Equal(t, "2주 전", c.Label())
+}
+
+func TestComparisonShift(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	shift := func(s string) time.Time {
+		c, err := ParseComparison(s)
+		require.NoError(t, err)
+		return c.Shift(at, from, to)
+	}
+
+	assert.Equal(t, time.Date(2024, 2, 27, 12, 0, 0, 0, time.UTC), shift("previous"))
+	assert.Equal(t, time.Date(2023, 3, 5, 12, 0, 0, 0, time.UTC), shift("year"))
+	assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), shift("12h"))
+	assert.Equal(t, time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC), shift("2w"))
+	assert.Equal(t, time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC), shift("2m"))
+	assert.Equal(t, at, Comparison{}.Shift(at, from, to))
+}
+
+func TestAlignComparison(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	from, to := day(3, 1), day(3, 5)
+	starts, err := bucketStarts(from, to, repository.BucketDay, time.UTC)
+	require.NoError(t, err)
+	points := []repository.TimeSeriesPoint{{Bucket: day(2, 26), Value: 10}, {Bucket: day(2, 28), Value: 20}}
+
+	// 합계: 비교 값이 없는 날은 0
+	q := ChartQuery{From: &from, To: &to, Bucket: repository.BucketDay, Aggregation: repository.AggSum, Location: time.UTC,
+		Compare: Comparison{Mode: ComparePrevious}}
+	data := &ChartData{Labels: []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04"}, Values: []float64{1, 2, 3, 4}}
+	series := alignComparison(points, starts, data, q)
+	assert.Equal(t, "이전 기간", series.Name)
+	assert.Equal(t, []string{"2024-02-26", "2024-02-27", "2024-02-28", "2024-02-29"}, series.Labels)
+	require.Len(t, series.Values, 4)
+	assert.Equal(t, 10.0, *series.Values[0])
+	assert.Equal(t, 0.0, *series.Values[1])
+	assert.Equal(t, 20.0, *series.Values[2])
+
+	// 평균: 현재 계열에서 생략된 날은 건너뛰고, 비교 값이 없으면 null
+	q.Aggregation = repository.AggAvg
+	data = &ChartData{Labels: []string{"2024-03-01", "2024-03-03"}, Values: []float64{1, 3}}
+	series = alignComparison(points, starts, data, q)
+	assert.Equal(t, []string{"2024-02-26", "2024-02-28"}, series.Labels)
+	assert.Equal(t, 10.0, *series.Values[0])
+	assert.Equal(t, 20.0, *series.Values[1])
+
+	data = &ChartData{Labels: []string{"2024-03-02"}, Values: []float64{2}}
+	series = alignComparison(points, starts, data, q)
+	assert.Equal(t, []*float64{nil}, series.Values)
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	require.NoError(t, err)
+	base := time.Date(2024, 3, 1, 10, 30, 0, 0, seoul) // 금요일
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 3, 1, 10, 45, 0, 0, seoul)},
+		{"0 9 * * *", time.Date(2024, 3, 2, 9, 0, 0, 0, seoul)},
+		{"0 9 * * mon", time.Date(2024, 3, 4, 9, 0, 0, 0, seoul)},
+		{"0 9 * * 1-5", time.Date(2024, 3, 4, 9, 0, 0, 0, seoul)},
+		{"30 10 * * 5", time.Date(2024, 3, 8, 10, 30, 0, 0, seoul)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, seoul)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, seoul)},
+		{"@weekly", time.Date(2024, 3, 3, 0, 0, 0, 0, seoul)},
+		{"0 12 * * 7", time.Date(2024, 3, 3, 12, 0, 0, 0, seoul)},
+		// 일과 요일이 모두 지정되면 둘 중 하나만 맞아도 실행
+		{"0 8 15 * mon", time.Date(2024, 3, 4, 8, 0, 0, 0, seoul)},
+	}
+	for _, tc := range cases {
+		c, err := ParseCron(tc.expr)
+		require.NoError(t, err, tc.expr)
+		assert.Equal(t, tc.want, c.Next(base), tc.expr)
+	}
+}
+
+func TestCronNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 2024-03-10 02:00~03:00은 존재하지 않는 시각이므로 다음 날로 넘어감
+	c, err := ParseCron("30 2 * * *")
+	require.NoError(t, err)
+	next := c.Next(time.Date(2024, 3, 9, 12, 0, 0, 0, ny))
+	assert.Equal(t, time.Date(2024, 3, 11, 2, 30, 0, 0, ny), next)
+
+	c, err = ParseCron("0 9 * * *")
+	require.NoError(t, err)
+	next = c.Next(time.Date(2024, 3, 9, 12, 0, 0, 0, ny))
+	assert.Equal(t, time.Date(2024, 3, 10, 9, 0, 0, 0, ny), next)
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "* * * * funday"} {
+		_, err := ParseCron(expr)
+		assert.ErrorIs(t, err, ErrInvalidCron, expr)
+	}
+}
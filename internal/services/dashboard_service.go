@@ -1,55 +1,440 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
 	"github.com/baltop/commet/internal/models"
 	"github.com/baltop/commet/internal/repository"
 )
 
 type DashboardService struct {
 	dashboardRepo *repository.DashboardRepository
+	hub           *EventHub
+	// publishOnIngest false면 이벤트는 변경 피드(ChangeFeed)로만 들어옴
+	publishOnIngest bool
+	onIngested      func([]models.DashboardData)
+	// annotationRepo 있으면 시계열 차트에 주석 표시
+	annotationRepo *repository.AnnotationRepository
+	// anomalyRepo 있으면 시계열 차트에 이상치 표시
+	anomalyRepo *repository.AnomalyRepository
+}
+
+func NewDashboardService(dashboardRepo *repository.DashboardRepository, hub *EventHub) *DashboardService {
+	return &DashboardService{dashboardRepo: dashboardRepo, hub: hub, publishOnIngest: true}
+}
+
+// UseAnnotations 시계열 차트에 주석(이벤트 표시)을 함께 그림
+func (s *DashboardService) UseAnnotations(annotationRepo *repository.AnnotationRepository) {
+	s.annotationRepo = annotationRepo
+}
+
+// UseAnomalies 시계열 차트에 탐지된 이상치를 함께 표시
+func (s *DashboardService) UseAnomalies(anomalyRepo *repository.AnomalyRepository) {
+	s.anomalyRepo = anomalyRepo
 }
 
-func NewDashboardService(dashboardRepo *repository.DashboardRepository) *DashboardService {
-	return &DashboardService{dashboardRepo: dashboardRepo}
+// UseChangeFeed 저장한 데이터를 직접 발행하지 않고 Postgres NOTIFY를 거쳐 받도록 전환 (중복 발행 방지)
+func (s *DashboardService) UseChangeFeed() {
+	s.publishOnIngest = false
 }
 
 type ChartData struct {
 	Labels []string  `json:"labels"`
 	Values []float64 `json:"values"`
+	// Compare 비교 기간 계열 (시계열 + ChartQuery.Compare)
+	Compare *CompareSeries `json:"compare,omitempty"`
+	// Forecast 마지막 버킷 이후 예측 (시계열 + ChartQuery.Forecast)
+	Forecast *Forecast `json:"forecast,omitempty"`
+	// Series 라벨·태그 값별로 나눈 계열 (시계열 + ChartQuery.MultiSeries). 있으면 Values는 비어 있음
+	Series []ChartSeries `json:"series,omitempty"`
+}
+
+// SeriesMode 차트 x축 구성 방식
+type SeriesMode string
+
+const (
+	// SeriesAuto 기간이 지정되고 차트가 시간 축을 지원하면 시계열, 아니면 라벨별
+	SeriesAuto  SeriesMode = ""
+	SeriesTime  SeriesMode = "time"
+	SeriesLabel SeriesMode = "label"
+)
+
+// ChartRequest 카테고리와 차트 종류만으로 차트를 구성하는 요청
+type ChartRequest struct {
+	Category string
+	Type     string
+	Series   SeriesMode
+	Options  ChartOptions
+	Query    ChartQuery
+	// Dashboard 차트가 놓인 대시보드 (그 대시보드의 주석도 표시). 0이면 전체 대시보드용 주석만
+	Dashboard uint
 }
 
-func (s *DashboardService) GetSalesData() (*ChartData, error) {
-	data, err := s.dashboardRepo.GetDataByCategory("sales")
+// RenderedChart Chart.js 설정과 브라우저용 메타 정보
+type RenderedChart struct {
+	Config map[string]any
+	Meta   ChartMeta
+	Data   *ChartData
+	// Mode 실제로 그린 x축 방식 (SeriesTime 또는 SeriesLabel)
+	Mode SeriesMode
+}
+
+// RenderChart 등록된 렌더러로 차트 설정 생성. 새 카테고리도 코드 변경 없이 사용 가능
+func (s *DashboardService) RenderChart(req ChartRequest) (*RenderedChart, error) {
+	renderer, err := LookupChartRenderer(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := req.Series
+	if mode == SeriesAuto {
+		mode = SeriesLabel
+		if req.Query.HasRange() && renderer.TimeSeries() {
+			mode = SeriesTime
+		}
+	}
+
+	data, err := s.GetChartData(req.Category, mode, req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	config, meta := renderer.Render(data, req.Options)
+	meta.Live, err = liveMeta(req.Category, mode, req.Query)
 	if err != nil {
 		return nil, err
 	}
-	return toChartData(data), nil
+	if mode == SeriesTime {
+		if meta.Annotations, err = s.chartAnnotations(req, data); err != nil {
+			return nil, err
+		}
+		// 이상치는 태그 구분 없이 탐지하고 첫 계열에 표시하므로 필터·여러 계열 차트에는 표시하지 않음
+		if !req.Query.Dimensional() && !req.Query.MultiSeries() {
+			if meta.Anomalies, err = s.chartAnomalies(req, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &RenderedChart{Config: config, Meta: meta, Data: data, Mode: mode}, nil
 }
 
-func (s *DashboardService) GetProductsData() (*ChartData, error) {
-	data, err := s.dashboardRepo.GetDataByCategory("products")
+// chartAnnotations 차트 기간과 겹치는 주석을 x축 위치로 변환
+func (s *DashboardService) chartAnnotations(req ChartRequest, data *ChartData) ([]ChartAnnotation, error) {
+	if s.annotationRepo == nil {
+		return nil, nil
+	}
+	q := withRangeDefaults(req.Query)
+	f := repository.AnnotationFilter{Category: req.Category, From: q.From, To: q.To}
+	if req.Dashboard != 0 {
+		f.DashboardID = &req.Dashboard
+	}
+	anns, err := s.annotationRepo.List(f)
 	if err != nil {
 		return nil, err
 	}
-	return toChartData(data), nil
+	return placeAnnotations(anns, data, q), nil
 }
 
-func (s *DashboardService) GetTrafficData() (*ChartData, error) {
-	data, err := s.dashboardRepo.GetDataByCategory("traffic")
+// chartAnomalies 차트 기간의 이상치를 x축 위치로 변환
+func (s *DashboardService) chartAnomalies(req ChartRequest, data *ChartData) ([]ChartAnomaly, error) {
+	if s.anomalyRepo == nil {
+		return nil, nil
+	}
+	q := withRangeDefaults(req.Query)
+	anomalies, err := s.anomalyRepo.List(repository.AnomalyFilter{Category: req.Category, From: *q.From, To: *q.To})
 	if err != nil {
 		return nil, err
 	}
-	return toChartData(data), nil
+	return placeAnomalies(anomalies, data, q), nil
 }
 
-func (s *DashboardService) GetSummaryStats() map[string]interface{} {
-	// 샘플 요약 통계
-	return map[string]interface{}{
-		"totalUsers":    1234,
-		"totalRevenue":  45678.90,
-		"totalOrders":   567,
-		"conversionRate": 3.2,
+// liveMeta 실시간 이벤트를 차트에 반영할 때 필요한 조회 조건
+func liveMeta(category string, mode SeriesMode, q ChartQuery) (*LiveMeta, error) {
+	live := &LiveMeta{Category: category, Mode: LiveRaw}
+	if !q.HasRange() && !q.Dimensional() {
+		return live, nil
 	}
+
+	q = withRangeDefaults(q)
+	live.Mode = LiveLabel
+	live.Aggregation = string(q.Aggregation)
+	live.From = q.From.UnixMilli()
+	live.To = q.To.UnixMilli()
+	// 실시간 이벤트에는 태그가 없고, 여러 계열은 브라우저에서 계열별로 반영하지 않음
+	if q.Dimensional() || (mode == SeriesTime && q.MultiSeries()) {
+		live.Mode = LiveRefresh
+		return live, nil
+	}
+	if mode != SeriesTime {
+		return live, nil
+	}
+
+	live.Mode = LiveTime
+	// 빈 버킷을 생략하거나 직전 값으로 채우면 라벨 위치·값을 알 수 없으므로 버킷을 보내지 않음 (브라우저가 다시 조회)
+	if (q.Aggregation != repository.AggSum && q.Aggregation != repository.AggCount) || resolveFill(q.Fill, q.Aggregation) != FillZero {
+		return live, nil
+	}
+	starts, err := bucketStarts(*q.From, *q.To, q.Bucket, q.Location)
+	if err != nil {
+		return nil, err
+	}
+	live.Buckets = make([]int64, len(starts))
+	for i, t := range starts {
+		live.Buckets[i] = t.UnixMilli()
+	}
+	return live, nil
+}
+
+// maxDataValue dashboard_data.value 컬럼(decimal(10,2))에 반올림해도 들어가는 절댓값 상한
+const maxDataValue = 1e8 - 0.005
+
+// storableValue 유한하고 value 컬럼 범위 안인 값. 외부 수집기는 범위를 벗어난 값을 버림 (하나 때문에 요청 전체가 실패하지 않도록)
+func storableValue(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0) && math.Abs(v) < maxDataValue
+}
+
+// ErrValueOutOfRange value 컬럼에 저장할 수 없는 값 (직접 수집 요청은 버리지 않고 거부)
+var ErrValueOutOfRange = errors.New("value out of range")
+
+// Ingest 데이터 저장 후 구독자에게 발행 (변경 피드를 쓰면 트리거가 발행)
+func (s *DashboardService) Ingest(reqs []models.DataPointRequest) ([]models.DashboardData, error) {
+	now := time.Now()
+	data := make([]models.DashboardData, len(reqs))
+	for i, req := range reqs {
+		if !storableValue(*req.Value) {
+			return nil, ErrValueOutOfRange
+		}
+		data[i] = models.DashboardData{
+			Category:   req.Category,
+			Label:      req.Label,
+			Value:      *req.Value,
+			RecordedAt: now,
+		}
+		if req.RecordedAt != nil {
+			data[i].RecordedAt = *req.RecordedAt
+		}
+		if len(req.Tags) > 0 {
+			tags, err := json.Marshal(req.Tags)
+			if err != nil {
+				return nil, err
+			}
+			data[i].Tags = models.JSON(tags)
+		}
+	}
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	if err := s.dashboardRepo.CreateData(data); err != nil {
+		return nil, err
+	}
+	if s.onIngested != nil {
+		s.onIngested(data)
+	}
+	if !s.publishOnIngest {
+		return data, nil
+	}
+	for _, d := range data {
+		s.hub.Publish(NewDataEvent(d))
+	}
+	return data, nil
+}
+
+// OnIngested 수집 요청을 받은 인스턴스에서 저장 직후 한 번 호출할 함수 등록 (웹훅 이벤트 발행 등)
+func (s *DashboardService) OnIngested(fn func([]models.DashboardData)) {
+	s.onIngested = fn
+}
+
+// Subscribe 카테고리 실시간 이벤트 구독
+func (s *DashboardService) Subscribe(categories []string) *Subscription {
+	return s.hub.Subscribe(categories)
+}
+
+// EventsSince afterID 이후 이벤트 (재연결한 클라이언트의 누락분)
+func (s *DashboardService) EventsSince(afterID uint, categories []string, limit int) ([]DataEvent, error) {
+	data, err := s.dashboardRepo.GetDataSince(afterID, categories, limit)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]DataEvent, len(data))
+	for i, d := range data {
+		events[i] = NewDataEvent(d)
+	}
+	return events, nil
+}
+
+// GetChartData 시계열 또는 라벨별 차트 데이터
+func (s *DashboardService) GetChartData(category string, mode SeriesMode, q ChartQuery) (*ChartData, error) {
+	if mode == SeriesTime {
+		if q.MultiSeries() {
+			return s.getGroupedTimeSeries(category, q)
+		}
+		return s.GetTimeSeries(category, "", q)
+	}
+	return s.getCategoryData(category, q)
+}
+
+// getGroupedTimeSeries 태그 값별(GroupBy가 없으면 라벨별) 시계열을 같은 버킷 축에 맞춘 차트 데이터
+func (s *DashboardService) getGroupedTimeSeries(category string, q ChartQuery) (*ChartData, error) {
+	q = withRangeDefaults(q)
+
+	starts, err := bucketStarts(*q.From, *q.To, q.Bucket, q.Location)
+	if err != nil {
+		return nil, err
+	}
+	points, err := s.dashboardRepo.GetGroupedTimeSeries(repository.TimeSeriesQuery{
+		Category:    category,
+		From:        *q.From,
+		To:          *q.To,
+		Bucket:      q.Bucket,
+		Aggregation: q.Aggregation,
+		Location:    q.Location,
+		Tags:        q.Tags,
+	}, q.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	return alignGroupedSeries(points, starts, q.Bucket, resolveFill(q.Fill, q.Aggregation)), nil
+}
+
+// GetTimeSeries 카테고리(라벨) 데이터를 기간/버킷 단위로 집계해 빈 버킷까지 채운 차트 데이터 반환
+func (s *DashboardService) GetTimeSeries(category, label string, q ChartQuery) (*ChartData, error) {
+	q = withRangeDefaults(q)
+
+	starts, err := bucketStarts(*q.From, *q.To, q.Bucket, q.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	tsq := repository.TimeSeriesQuery{
+		Category:    category,
+		Label:       label,
+		From:        *q.From,
+		To:          *q.To,
+		Bucket:      q.Bucket,
+		Aggregation: q.Aggregation,
+		Location:    q.Location,
+		Tags:        q.Tags,
+	}
+	points, err := s.dashboardRepo.GetTimeSeries(tsq)
+	if err != nil {
+		return nil, err
+	}
+	data := alignTimeSeries(points, starts, q.Bucket, resolveFill(q.Fill, q.Aggregation))
+	if q.Forecast.Enabled() {
+		// 기록이 부족하면 예측 없이 그림
+		data.Forecast, err = forecastTimeSeries(points, starts, q)
+		if err != nil && err != ErrNotEnoughHistory {
+			return nil, err
+		}
+	}
+	if !q.Compare.Enabled() {
+		return data, nil
+	}
+
+	// 비교 계열: 같은 조건으로 옮긴 기간을 집계해 현재 항목마다 맞춤
+	tsq.From = q.Compare.Shift(*q.From, *q.From, *q.To)
+	tsq.To = q.Compare.Shift(*q.To, *q.From, *q.To)
+	points, err = s.dashboardRepo.GetTimeSeries(tsq)
+	if err != nil {
+		return nil, err
+	}
+	data.Compare = alignComparison(points, starts, data, q)
+	return data, nil
+}
+
+// Forecast 카테고리(라벨)의 조회 기간 기록으로 이후 버킷을 예측 (모델이 비어 있으면 auto)
+func (s *DashboardService) Forecast(category, label string, q ChartQuery) (*Forecast, error) {
+	q = withRangeDefaults(q)
+	if !q.Forecast.Enabled() {
+		q.Forecast.Model = ForecastAuto
+	}
+
+	starts, err := bucketStarts(*q.From, *q.To, q.Bucket, q.Location)
+	if err != nil {
+		return nil, err
+	}
+	points, err := s.dashboardRepo.GetTimeSeries(repository.TimeSeriesQuery{
+		Category:    category,
+		Label:       label,
+		From:        *q.From,
+		To:          *q.To,
+		Bucket:      q.Bucket,
+		Aggregation: q.Aggregation,
+		Location:    q.Location,
+		Tags:        q.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return forecastTimeSeries(points, starts, q)
+}
+
+// getCategoryData 라벨별 차트 데이터. 기간이나 태그 조건이 있으면 라벨(그룹 태그 값) 단위로 집계
+func (s *DashboardService) getCategoryData(category string, q ChartQuery) (*ChartData, error) {
+	if !q.HasRange() && !q.Dimensional() {
+		data, err := s.dashboardRepo.GetDataByCategory(category)
+		if err != nil {
+			return nil, err
+		}
+		return toChartData(data), nil
+	}
+
+	q = withRangeDefaults(q)
+	filter := repository.DataFilter{
+		Category: category,
+		From:     q.From,
+		To:       q.To,
+		Tags:     q.Tags,
+	}
+	var totals []repository.LabelValue
+	var err error
+	if q.GroupBy != "" {
+		totals, err = s.dashboardRepo.GetTagTotals(filter, q.GroupBy, q.Aggregation)
+		for i := range totals {
+			totals[i].Label = groupName(totals[i].Label)
+		}
+	} else {
+		totals, err = s.dashboardRepo.GetLabelTotals(filter, q.Aggregation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chartData := &ChartData{
+		Labels: make([]string, len(totals)),
+		Values: make([]float64, len(totals)),
+	}
+	for i, t := range totals {
+		chartData.Labels[i] = t.Label
+		chartData.Values[i] = t.Value
+	}
+	return chartData, nil
+}
+
+// withRangeDefaults 비어 있는 조건 채우기 (종료: 현재, 시작: 종료 30일 전, 일 단위 합계)
+func withRangeDefaults(q ChartQuery) ChartQuery {
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+	if q.Bucket == "" {
+		q.Bucket = repository.BucketDay
+	}
+	if q.Aggregation == "" {
+		q.Aggregation = repository.AggSum
+	}
+	if q.To == nil {
+		to := time.Now()
+		q.To = &to
+	}
+	if q.From == nil {
+		from := q.To.AddDate(0, 0, -30)
+		q.From = &from
+	}
+	return q
 }
 
 func toChartData(data []models.DashboardData) *ChartData {
@@ -63,3 +448,13 @@ func toChartData(data []models.DashboardData) *ChartData {
 	}
 	return chartData
 }
+
+// GetCategories 데이터가 있는 카테고리 목록 (위젯 데이터 소스 선택용)
+func (s *DashboardService) GetCategories() ([]string, error) {
+	return s.dashboardRepo.GetAllCategories()
+}
+
+// GetTagKeys 최근 30일 데이터에 쓰인 태그 이름 (위젯 그룹 기준 선택용, category가 비어 있으면 전체)
+func (s *DashboardService) GetTagKeys(category string) ([]string, error) {
+	return s.dashboardRepo.GetTagKeys(category, time.Now().AddDate(0, 0, -30), 100)
+}
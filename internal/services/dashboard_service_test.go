@@ -0,0 +1,19 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIngestRejectsOutOfRangeValue(t *testing.T) {
+	// decimal(10,2) 범위를 넘는 값은 저장하기 전에 거부 (저장소까지 가지 않음)
+	service := NewDashboardService(nil, NewEventHub(0))
+	ok, big := 12.5, 1e8
+	_, err := service.Ingest([]models.DataPointRequest{
+		{Category: "sales", Value: &ok},
+		{Category: "sales", Value: &big},
+	})
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+}
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// 내보내기 문서 형식. 호환되지 않게 바뀌면 버전을 올리고 이전 버전 변환을 추가
+const (
+	DashboardDocumentKind    = "commet.dashboard"
+	DashboardDocumentVersion = 1
+)
+
+// 문서 하나에 담을 수 있는 최대 개수 (잘못된 파일로 대량 생성되는 것을 막기 위해)
+const (
+	maxImportWidgets = 100
+	maxImportKPIs    = 100
+)
+
+var (
+	ErrUnsupportedDocument = errors.New("unsupported dashboard document")
+	ErrImportConflict      = errors.New("import conflicts with existing data")
+	ErrInvalidConflictMode = errors.New("invalid conflict mode")
+)
+
+// DashboardDocument 내보내기/가져오기 JSON. 위젯 순서가 곧 배치 순서
+type DashboardDocument struct {
+	Kind       string              `json:"kind"`
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Dashboard  DashboardSpec       `json:"dashboard"`
+	KPIs       []models.KPIRequest `json:"kpis,omitempty"`
+}
+
+type DashboardSpec struct {
+	Name    string                 `json:"name"`
+	Shared  bool                   `json:"shared"`
+	Widgets []models.WidgetRequest `json:"widgets"`
+}
+
+// ImportValidationError 문서 검증 실패. 메시지 앞에 문서 안의 위치를 붙임 (예: dashboard.widgets[2].title)
+type ImportValidationError struct {
+	Problems []string
+}
+
+func (e *ImportValidationError) Error() string {
+	return "invalid dashboard document: " + strings.Join(e.Problems, "; ")
+}
+
+// ConflictMode 같은 이름의 대시보드나 같은 키의 KPI가 이미 있을 때 처리 방법
+type ConflictMode string
+
+const (
+	ConflictError     ConflictMode = "error"
+	ConflictSkip      ConflictMode = "skip"
+	ConflictOverwrite ConflictMode = "overwrite"
+	ConflictRename    ConflictMode = "rename"
+)
+
+func ParseConflictMode(s string) (ConflictMode, error) {
+	switch m := ConflictMode(s); m {
+	case "":
+		return ConflictError, nil
+	case ConflictError, ConflictSkip, ConflictOverwrite, ConflictRename:
+		return m, nil
+	}
+	return "", ErrInvalidConflictMode
+}
+
+// ImportOptions Name이 있으면 문서의 대시보드 이름 대신 사용.
+// KPI 정의는 전역이므로 ManageKPIs(관리자)일 때만 추가하거나 덮어씀
+type ImportOptions struct {
+	DryRun     bool
+	Conflict   ConflictMode
+	Name       string
+	ManageKPIs bool
+}
+
+// ImportAction 항목별 처리 결과 (미리보기에서는 예정된 처리)
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"
+	ImportOverwrite ImportAction = "overwrite"
+	ImportSkip      ImportAction = "skip"
+	ImportUnchanged ImportAction = "unchanged"
+	ImportConflict  ImportAction = "conflict"
+)
+
+type ImportItem struct {
+	Name   string       `json:"name"`
+	Action ImportAction `json:"action"`
+	Note   string       `json:"note,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun      bool         `json:"dry_run"`
+	Conflict    ConflictMode `json:"on_conflict"`
+	DashboardID uint         `json:"dashboard_id,omitempty"`
+	Dashboard   ImportItem   `json:"dashboard"`
+	Widgets     int          `json:"widgets"`
+	KPIs        []ImportItem `json:"kpis"`
+}
+
+// HasConflicts ConflictError 모드에서 가져오기를 막는 항목이 있는지
+func (r *ImportResult) HasConflicts() bool {
+	if r.Dashboard.Action == ImportConflict {
+		return true
+	}
+	for _, k := range r.KPIs {
+		if k.Action == ImportConflict {
+			return true
+		}
+	}
+	return false
+}
+
+type DashboardTransferService struct {
+	layoutService *LayoutService
+	layoutRepo    *repository.LayoutRepository
+	kpiRepo       *repository.KPIRepository
+}
+
+func NewDashboardTransferService(layoutService *LayoutService, layoutRepo *repository.LayoutRepository, kpiRepo *repository.KPIRepository) *DashboardTransferService {
+	return &DashboardTransferService{
+		layoutService: layoutService,
+		layoutRepo:    layoutRepo,
+		kpiRepo:       kpiRepo,
+	}
+}
+
+// Export 볼 수 있는 대시보드면 내보낼 수 있음. KPI 정의는 전역이므로 선택적으로 포함
+func (s *DashboardTransferService) Export(dashboardID, userID uint, includeKPIs bool) (*DashboardDocument, error) {
+	v, err := s.layoutService.Get(dashboardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var kpis []models.KPIDefinition
+	if includeKPIs {
+		if kpis, err = s.kpiRepo.List(); err != nil {
+			return nil, err
+		}
+	}
+	return buildDashboardDocument(v.Dashboard, kpis, time.Now()), nil
+}
+
+// Import 검증 → 충돌 판정 → (미리보기가 아니면) 한 트랜잭션으로 저장.
+// ConflictError 모드에서 충돌이 있으면 결과와 함께 ErrImportConflict를 반환
+func (s *DashboardTransferService) Import(userID uint, doc *DashboardDocument, opts ImportOptions) (*ImportResult, error) {
+	if opts.Name != "" {
+		doc.Dashboard.Name = opts.Name
+	}
+	if err := ValidateDashboardDocument(doc); err != nil {
+		return nil, err
+	}
+
+	visible, err := s.layoutRepo.ListVisible(userID)
+	if err != nil {
+		return nil, err
+	}
+	var owned []models.Dashboard
+	for _, d := range visible {
+		if d.OwnerID == userID {
+			owned = append(owned, d)
+		}
+	}
+	existing, err := s.kpiRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planImport(doc, opts, owned, existing)
+	if plan.result.HasConflicts() {
+		return plan.result, ErrImportConflict
+	}
+	if opts.DryRun || (plan.dashboard == nil && len(plan.kpis) == 0) {
+		return plan.result, nil
+	}
+
+	if plan.dashboard != nil {
+		plan.dashboard.OwnerID = userID
+	}
+	if err := s.layoutRepo.ImportDashboard(plan.dashboard, plan.kpis); err != nil {
+		return nil, err
+	}
+	if plan.dashboard != nil {
+		plan.result.DashboardID = plan.dashboard.ID
+	}
+	return plan.result, nil
+}
+
+// ParseDashboardDocument 종류와 버전을 먼저 확인한 뒤 알 수 없는 필드를 거부하며 읽음
+func ParseDashboardDocument(r io.Reader) (*DashboardDocument, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Kind    string `json:"kind"`
+		Version int    `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, &ImportValidationError{Problems: []string{"JSON 형식이 올바르지 않습니다."}}
+	}
+	if header.Kind != DashboardDocumentKind {
+		return nil, fmt.Errorf("%w: kind %q", ErrUnsupportedDocument, header.Kind)
+	}
+	if header.Version < 1 || header.Version > DashboardDocumentVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedDocument, header.Version)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var doc DashboardDocument
+	if err := dec.Decode(&doc); err != nil {
+		return nil, &ImportValidationError{Problems: []string{"문서 구조가 올바르지 않습니다: " + err.Error()}}
+	}
+	return &doc, nil
+}
+
+// ValidateDashboardDocument 위젯은 ValidateWidget, KPI는 KPIRequest의 binding 규칙과 같은 기준으로 검사
+func ValidateDashboardDocument(doc *DashboardDocument) error {
+	var problems []string
+	add := func(path, msg string) {
+		problems = append(problems, path+": "+msg)
+	}
+
+	name := strings.TrimSpace(doc.Dashboard.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		add("dashboard.name", "대시보드 이름은 1~100자여야 합니다.")
+	}
+	if len(doc.Dashboard.Widgets) > maxImportWidgets {
+		add("dashboard.widgets", "위젯은 "+strconv.Itoa(maxImportWidgets)+"개까지 가져올 수 있습니다.")
+	}
+	for i := range doc.Dashboard.Widgets {
+		err := ValidateWidget(&doc.Dashboard.Widgets[i])
+		var verr *WidgetValidationError
+		if errors.As(err, &verr) {
+			for field, msg := range verr.Fields {
+				add("dashboard.widgets["+strconv.Itoa(i)+"]."+field, msg)
+			}
+		}
+	}
+
+	if len(doc.KPIs) > maxImportKPIs {
+		add("kpis", "KPI는 "+strconv.Itoa(maxImportKPIs)+"개까지 가져올 수 있습니다.")
+	}
+	keys := map[string]bool{}
+	for i := range doc.KPIs {
+		prefix := "kpis[" + strconv.Itoa(i) + "]."
+		for field, msg := range validateKPIRequest(&doc.KPIs[i]) {
+			add(prefix+field, msg)
+		}
+		if key := doc.KPIs[i].Key; key != "" {
+			if keys[key] {
+				add(prefix+"key", "같은 키가 문서에 두 번 있습니다.")
+			}
+			keys[key] = true
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return &ImportValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// kpiFieldErrors KPIRequest 필드별 검증 실패 메시지 (키는 JSON 필드 이름)
+var kpiFieldErrors = map[string][2]string{
+	"Key":         {"key", "키는 1~50자여야 합니다."},
+	"Title":       {"title", "제목은 1~100자여야 합니다."},
+	"Category":    {"category", "카테고리는 1~50자여야 합니다."},
+	"Label":       {"label", "라벨은 100자 이하여야 합니다."},
+	"Aggregation": {"aggregation", "집계 함수가 올바르지 않습니다."},
+	"WindowDays":  {"window_days", "기간은 1~366일이어야 합니다."},
+	"Format":      {"format", "표시 형식이 올바르지 않습니다."},
+	"Icon":        {"icon", "아이콘이 올바르지 않습니다."},
+	"Color":       {"color", "색상이 올바르지 않습니다."},
+}
+
+// validateKPIRequest 화면에서 KPI를 만들 때와 같은 binding 규칙으로 검사
+func validateKPIRequest(req *models.KPIRequest) map[string]string {
+	fields := map[string]string{}
+	err := binding.Validator.ValidateStruct(req)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		if err != nil {
+			fields["kpi"] = "KPI 정의가 올바르지 않습니다."
+		}
+		return fields
+	}
+	for _, fe := range verrs {
+		if e, ok := kpiFieldErrors[fe.StructField()]; ok {
+			fields[e[0]] = e[1]
+		} else {
+			fields[strings.ToLower(fe.StructField())] = "값이 올바르지 않습니다."
+		}
+	}
+	return fields
+}
+
+func buildDashboardDocument(d models.Dashboard, kpis []models.KPIDefinition, now time.Time) *DashboardDocument {
+	doc := &DashboardDocument{
+		Kind:       DashboardDocumentKind,
+		Version:    DashboardDocumentVersion,
+		ExportedAt: now.UTC().Truncate(time.Second),
+		Dashboard: DashboardSpec{
+			Name:    d.Name,
+			Shared:  d.Shared,
+			Widgets: make([]models.WidgetRequest, len(d.Widgets)),
+		},
+	}
+	for i, w := range d.Widgets {
+		doc.Dashboard.Widgets[i] = models.WidgetRequest{
+			Type:       w.Type,
+			DataSource: w.DataSource,
+			Title:      w.Title,
+			Subtitle:   w.Subtitle,
+			Width:      w.Width,
+			Height:     w.Height,
+			Options:    w.Options,
+		}
+	}
+	for _, k := range kpis {
+		doc.KPIs = append(doc.KPIs, kpiRequestOf(k))
+	}
+	return doc
+}
+
+func kpiRequestOf(def models.KPIDefinition) models.KPIRequest {
+	return models.KPIRequest{
+		Key:         def.Key,
+		Title:       def.Title,
+		Category:    def.Category,
+		Label:       def.Label,
+		Aggregation: def.Aggregation,
+		WindowDays:  def.WindowDays,
+		Format:      def.Format,
+		Icon:        def.Icon,
+		Color:       def.Color,
+		Position:    def.Position,
+	}
+}
+
+// importPlan 저장할 대시보드(nil이면 건너뜀)와 KPI 정의, 사용자에게 보여줄 결과
+type importPlan struct {
+	result    *ImportResult
+	dashboard *models.Dashboard
+	kpis      []models.KPIDefinition
+}
+
+// planImport 사용자의 대시보드(owned)와 기존 KPI 정의를 기준으로 무엇을 만들고 덮어쓸지 결정
+func planImport(doc *DashboardDocument, opts ImportOptions, owned []models.Dashboard, existing []models.KPIDefinition) importPlan {
+	mode := opts.Conflict
+	if mode == "" {
+		mode = ConflictError
+	}
+	name := strings.TrimSpace(doc.Dashboard.Name)
+	plan := importPlan{result: &ImportResult{
+		DryRun:    opts.DryRun,
+		Conflict:  mode,
+		Dashboard: ImportItem{Name: name, Action: ImportCreate},
+		Widgets:   len(doc.Dashboard.Widgets),
+		KPIs:      []ImportItem{},
+	}}
+
+	d := &models.Dashboard{Name: name, Shared: doc.Dashboard.Shared}
+	names := map[string]bool{}
+	var same *models.Dashboard
+	for i := range owned {
+		names[owned[i].Name] = true
+		if owned[i].Name == name && same == nil {
+			same = &owned[i]
+		}
+	}
+	if same != nil {
+		item := &plan.result.Dashboard
+		switch mode {
+		case ConflictError:
+			item.Action, item.Note = ImportConflict, "같은 이름의 대시보드가 있습니다."
+		case ConflictSkip:
+			item.Action, item.Note = ImportSkip, "같은 이름의 대시보드가 있어 건너뜁니다."
+			d = nil
+		case ConflictOverwrite:
+			item.Action, item.Note = ImportOverwrite, "기존 위젯을 모두 교체합니다."
+			d.ID = same.ID
+			plan.result.DashboardID = same.ID
+		case ConflictRename:
+			item.Name = uniqueName(name, names)
+			item.Note = "같은 이름이 있어 이름을 바꿉니다."
+			d.Name = item.Name
+		}
+	}
+	if d != nil {
+		d.Widgets = make([]models.Widget, len(doc.Dashboard.Widgets))
+		for i := range doc.Dashboard.Widgets {
+			w := &d.Widgets[i]
+			_ = applyWidgetRequest(w, &doc.Dashboard.Widgets[i])
+			w.Position = i
+		}
+		plan.dashboard = d
+	}
+
+	byKey := make(map[string]models.KPIDefinition, len(existing))
+	for _, k := range existing {
+		byKey[k.Key] = k
+	}
+	for _, req := range doc.KPIs {
+		item := ImportItem{Name: req.Key, Action: ImportCreate}
+		def, found := byKey[req.Key]
+		switch {
+		case !opts.ManageKPIs && (!found || kpiRequestOf(def) != req):
+			item.Action, item.Note = ImportSkip, "KPI 정의는 관리자만 추가하거나 바꿀 수 있습니다."
+		case !found:
+			def = models.KPIDefinition{}
+			req.Apply(&def)
+			plan.kpis = append(plan.kpis, def)
+		case kpiRequestOf(def) == req:
+			item.Action = ImportUnchanged
+		case mode == ConflictOverwrite:
+			item.Action, item.Note = ImportOverwrite, "기존 정의를 덮어씁니다."
+			req.Apply(&def)
+			plan.kpis = append(plan.kpis, def)
+		case mode == ConflictError:
+			item.Action, item.Note = ImportConflict, "같은 키의 다른 정의가 있습니다."
+		default:
+			// KPI는 모든 대시보드가 함께 쓰므로 rename 모드에서도 복제하지 않고 기존 정의를 유지
+			item.Action, item.Note = ImportSkip, "기존 정의를 유지합니다."
+		}
+		plan.result.KPIs = append(plan.result.KPIs, item)
+	}
+	return plan
+}
+
+// uniqueName "이름 (2)", "이름 (3)" … 중 쓰이지 않은 첫 이름 (100자 제한 유지)
+func uniqueName(name string, taken map[string]bool) string {
+	for n := 2; ; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		base := []rune(name)
+		if max := 100 - utf8.RuneCountInString(suffix); len(base) > max {
+			base = base[:max]
+		}
+		if candidate := string(base) + suffix; !taken[candidate] {
+			return candidate
+		}
+	}
+}
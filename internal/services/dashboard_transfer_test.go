@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleDashboard() models.Dashboard {
+	return models.Dashboard{ID: 7, OwnerID: 1, Name: "매출", Shared: true, Widgets: defaultWidgets()}
+}
+
+func sampleKPI() models.KPIDefinition {
+	return models.KPIDefinition{ID: 3, Key: "revenue", Title: "매출", Category: "sales", Aggregation: "sum",
+		WindowDays: 30, Format: "currency", Icon: "revenue", Color: "green", Position: 1}
+}
+
+func TestDashboardDocumentRoundTrip(t *testing.T) {
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	doc := buildDashboardDocument(sampleDashboard(), []models.KPIDefinition{sampleKPI()}, now)
+
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(doc))
+	parsed, err := ParseDashboardDocument(&buf)
+	require.NoError(t, err)
+	require.NoError(t, ValidateDashboardDocument(parsed))
+
+	assert.Equal(t, DashboardDocumentKind, parsed.Kind)
+	assert.Equal(t, "매출", parsed.Dashboard.Name)
+	require.Len(t, parsed.Dashboard.Widgets, 3)
+	assert.Equal(t, "월별 매출 추이", parsed.Dashboard.Widgets[0].Title)
+	assert.JSONEq(t, `{"label":"매출","unit":"₩"}`, string(parsed.Dashboard.Widgets[0].Options))
+	require.Len(t, parsed.KPIs, 1)
+	assert.Equal(t, kpiRequestOf(sampleKPI()), parsed.KPIs[0])
+}
+
+func TestParseDashboardDocumentRejects(t *testing.T) {
+	cases := map[string]string{
+		"kind":    `{"kind":"other","version":1}`,
+		"version": `{"kind":"commet.dashboard","version":2}`,
+		"zero":    `{"kind":"commet.dashboard"}`,
+	}
+	for name, body := range cases {
+		_, err := ParseDashboardDocument(strings.NewReader(body))
+		assert.ErrorIs(t, err, ErrUnsupportedDocument, name)
+	}
+
+	var verr *ImportValidationError
+	_, err := ParseDashboardDocument(strings.NewReader(`not json`))
+	assert.ErrorAs(t, err, &verr)
+	_, err = ParseDashboardDocument(strings.NewReader(`{"kind":"commet.dashboard","version":1,"extra":true}`))
+	assert.ErrorAs(t, err, &verr)
+}
+
+func TestValidateDashboardDocument(t *testing.T) {
+	doc := buildDashboardDocument(sampleDashboard(), []models.KPIDefinition{sampleKPI(), sampleKPI()}, time.Now())
+	doc.Dashboard.Name = " "
+	doc.Dashboard.Widgets[1].Type = "radar3d"
+	doc.Dashboard.Widgets[2].Options = models.JSON(`{"color":"red"}`)
+	doc.KPIs[0].Aggregation = "median"
+	doc.KPIs[1].WindowDays = 400
+	doc.KPIs[1].Color = "red"
+
+	err := ValidateDashboardDocument(doc)
+	var verr *ImportValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []string{
+		"dashboard.name: 대시보드 이름은 1~100자여야 합니다.",
+		"dashboard.widgets[1].type: 지원하지 않는 차트 종류입니다.",
+		"dashboard.widgets[2].options: 옵션 형식이 올바르지 않습니다.",
+		"kpis[0].aggregation: 집계 함수가 올바르지 않습니다.",
+		"kpis[1].color: 색상이 올바르지 않습니다.",
+		"kpis[1].key: 같은 키가 문서에 두 번 있습니다.",
+		"kpis[1].window_days: 기간은 1~366일이어야 합니다.",
+	}, verr.Problems)
+}
+
+func TestPlanImport(t *testing.T) {
+	doc := buildDashboardDocument(sampleDashboard(), []models.KPIDefinition{sampleKPI()}, time.Now())
+	changed := sampleKPI()
+	changed.Key = "orders"
+	changed.Aggregation = "count"
+	doc.KPIs = append(doc.KPIs, kpiRequestOf(changed))
+	added := sampleKPI()
+	added.Key = "visits"
+	doc.KPIs = append(doc.KPIs, kpiRequestOf(added))
+
+	ownedDashboards := []models.Dashboard{{ID: 7, Name: "매출"}, {ID: 8, Name: "매출 (2)"}}
+	existingOrders := sampleKPI()
+	existingOrders.ID, existingOrders.Key = 4, "orders"
+	existing := []models.KPIDefinition{sampleKPI(), existingOrders}
+
+	actions := func(p importPlan) []ImportAction {
+		out := []ImportAction{p.result.Dashboard.Action}
+		for _, k := range p.result.KPIs {
+			out = append(out, k.Action)
+		}
+		return out
+	}
+
+	t.Run("error", func(t *testing.T) {
+		p := planImport(doc, ImportOptions{ManageKPIs: true}, ownedDashboards, existing)
+		assert.True(t, p.result.HasConflicts())
+		assert.Equal(t, []ImportAction{ImportConflict, ImportUnchanged, ImportConflict, ImportCreate}, actions(p))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		p := planImport(doc, ImportOptions{Conflict: ConflictSkip, ManageKPIs: true}, ownedDashboards, existing)
+		assert.False(t, p.result.HasConflicts())
+		assert.Equal(t, []ImportAction{ImportSkip, ImportUnchanged, ImportSkip, ImportCreate}, actions(p))
+		assert.Nil(t, p.dashboard)
+		require.Len(t, p.kpis, 1)
+		assert.Equal(t, "visits", p.kpis[0].Key)
+		assert.Zero(t, p.kpis[0].ID)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		p := planImport(doc, ImportOptions{Conflict: ConflictOverwrite, ManageKPIs: true}, ownedDashboards, existing)
+		assert.Equal(t, []ImportAction{ImportOverwrite, ImportUnchanged, ImportOverwrite, ImportCreate}, actions(p))
+		require.NotNil(t, p.dashboard)
+		assert.Equal(t, uint(7), p.dashboard.ID)
+		require.Len(t, p.dashboard.Widgets, 3)
+		assert.Equal(t, 2, p.dashboard.Widgets[2].Position)
+		require.Len(t, p.kpis, 2)
+		assert.Equal(t, uint(4), p.kpis[0].ID)
+		assert.Equal(t, "count", p.kpis[0].Aggregation)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		p := planImport(doc, ImportOptions{Conflict: ConflictRename, ManageKPIs: true}, ownedDashboards, existing)
+		assert.Equal(t, []ImportAction{ImportCreate, ImportUnchanged, ImportSkip, ImportCreate}, actions(p))
+		require.NotNil(t, p.dashboard)
+		assert.Zero(t, p.dashboard.ID)
+		assert.Equal(t, "매출 (3)", p.dashboard.Name)
+		assert.Equal(t, "매출 (3)", p.result.Dashboard.Name)
+	})
+
+	t.Run("no conflict", func(t *testing.T) {
+		p := planImport(doc, ImportOptions{ManageKPIs: true}, []models.Dashboard{{ID: 9, Name: "다른 대시보드"}}, nil)
+		assert.False(t, p.result.HasConflicts())
+		assert.Equal(t, []ImportAction{ImportCreate, ImportCreate, ImportCreate, ImportCreate}, actions(p))
+		assert.Len(t, p.kpis, 3)
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		p := planImport(doc, ImportOptions{Conflict: ConflictOverwrite}, ownedDashboards, existing)
+		assert.False(t, p.result.HasConflicts())
+		assert.Equal(t, []ImportAction{ImportOverwrite, ImportUnchanged, ImportSkip, ImportSkip}, actions(p))
+		require.NotNil(t, p.dashboard)
+		assert.Empty(t, p.kpis)
+	})
+}
+
+func TestUniqueName(t *testing.T) {
+	assert.Equal(t, "a (2)", uniqueName("a", map[string]bool{"a": true}))
+	assert.Equal(t, "a (4)", uniqueName("a", map[string]bool{"a": true, "a (2)": true, "a (3)": true}))
+
+	long := strings.Repeat("가", 100)
+	name := uniqueName(long, map[string]bool{long: true})
+	assert.Equal(t, 100, len([]rune(name)))
+	assert.True(t, strings.HasSuffix(name, " (2)"))
+}
+
+func TestParseConflictMode(t *testing.T) {
+	m, err := ParseConflictMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ConflictError, m)
+	m, err = ParseConflictMode("rename")
+	require.NoError(t, err)
+	assert.Equal(t, ConflictRename, m)
+	_, err = ParseConflictMode("merge")
+	assert.ErrorIs(t, err, ErrInvalidConflictMode)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/baltop/commet/internal/repository"
+)
+
+var ErrInvalidTagFilter = errors.New("invalid tag filter")
+
+const (
+	// 태그 그룹으로 나눈 시계열 차트에 그리는 최대 계열 수 (합계가 큰 순)
+	maxGroupSeries = 10
+	// 그룹 태그가 없는 데이터의 계열·라벨 이름
+	untaggedGroup = "(없음)"
+)
+
+// ChartSeries 공통 x축에 맞춘 이름 있는 계열. 값이 없는 항목은 null
+type ChartSeries struct {
+	Name   string     `json:"name"`
+	Values []*float64 `json:"values"`
+}
+
+// ParseTagFilter ?tags= 값 ("region:eu,channel:web"). 값에는 쉼표를 쓸 수 없음
+func ParseTagFilter(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	tags := map[string]string{}
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || utf8.RuneCountInString(key) > maxDataTagKey || utf8.RuneCountInString(value) > maxDataTagValue {
+			return nil, ErrInvalidTagFilter
+		}
+		if _, dup := tags[key]; dup {
+			return nil, ErrInvalidTagFilter
+		}
+		tags[key] = strings.TrimSpace(value)
+	}
+	if len(tags) > maxDataTags {
+		return nil, ErrInvalidTagFilter
+	}
+	return tags, nil
+}
+
+// ParseGroupBy ?group_by= 태그 이름 (비어 있으면 나누지 않음)
+func ParseGroupBy(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) > maxDataTagKey || strings.ContainsAny(s, ",:") {
+		return "", ErrInvalidTagFilter
+	}
+	return s, nil
+}
+
+func groupName(group string) string {
+	if group == "" {
+		return untaggedGroup
+	}
+	return group
+}
+
+// alignGroupedSeries 라벨·태그 값별 집계를 버킷 축에 맞춘 계열로.
+// 빈 버킷은 fill(FillAuto 제외) 방식으로 채우고 값 합계가 큰 maxGroupSeries개만 남김
+func alignGroupedSeries(points []repository.GroupedTimeSeriesPoint, starts []time.Time, b repository.Bucket, fill FillMode) *ChartData {
+	index := make(map[int64]int, len(starts))
+	data := &ChartData{Labels: make([]string, len(starts)), Values: []float64{}}
+	for i, t := range starts {
+		index[t.Unix()] = i
+		data.Labels[i] = bucketLabel(t, b)
+	}
+
+	var series []ChartSeries
+	totals := map[string]float64{}
+	byGroup := map[string]int{}
+	for _, p := range points {
+		i, ok := index[p.Bucket.Unix()]
+		if !ok {
+			continue
+		}
+		name := groupName(p.Group)
+		n, ok := byGroup[name]
+		if !ok {
+			n = len(series)
+			byGroup[name] = n
+			series = append(series, ChartSeries{Name: name, Values: make([]*float64, len(starts))})
+		}
+		v := p.Value
+		series[n].Values[i] = &v
+		totals[name] += v
+	}
+
+	sort.SliceStable(series, func(i, j int) bool {
+		return totals[series[i].Name] > totals[series[j].Name]
+	})
+	if len(series) > maxGroupSeries {
+		series = series[:maxGroupSeries]
+	}
+	for _, s := range series {
+		fillGaps(s.Values, fill)
+	}
+	data.Series = series
+	return data
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	tags, err := ParseTagFilter(" region:eu , channel: web ")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu", "channel": "web"}, tags)
+
+	tags, err = ParseTagFilter("")
+	assert.NoError(t, err)
+	assert.Nil(t, tags)
+
+	// 값의 콜론은 허용, 빈 값도 허용
+	tags, err = ParseTagFilter("url:http://x,env:")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"url": "http://x", "env": ""}, tags)
+
+	for _, s := range []string{"region", ":eu", "region:eu,region:us", "a:1,,b:2"} {
+		_, err := ParseTagFilter(s)
+		assert.ErrorIs(t, err, ErrInvalidTagFilter, s)
+	}
+
+	_, err = ParseGroupBy("region")
+	assert.NoError(t, err)
+	_, err = ParseGroupBy("region:eu")
+	assert.ErrorIs(t, err, ErrInvalidTagFilter)
+}
+
+func TestAlignGroupedSeries(t *testing.T) {
+	loc := time.UTC
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, loc) }
+	starts := []time.Time{day(1), day(2), day(3)}
+	points := []repository.GroupedTimeSeriesPoint{
+		{Group: "", Bucket: day(1), Value: 1},
+		{Group: "eu", Bucket: day(1), Value: 10},
+		{Group: "eu", Bucket: day(3), Value: 30},
+		{Group: "us", Bucket: day(2), Value: 50},
+		{Group: "us", Bucket: day(9), Value: 99},
+	}
+
+	data := alignGroupedSeries(points, starts, repository.BucketDay, FillZero)
+	assert.Equal(t, []string{"2024-03-01", "2024-03-02", "2024-03-03"}, data.Labels)
+	assert.Empty(t, data.Values)
+	require.Len(t, data.Series, 3)
+	// 합계가 큰 순, 빈 버킷은 0
+	assert.Equal(t, []string{"us", "eu", untaggedGroup}, []string{data.Series[0].Name, data.Series[1].Name, data.Series[2].Name})
+	assert.Equal(t, []float64{10, 0, 30}, derefAll(data.Series[1].Values))
+
+	data = alignGroupedSeries(points, starts, repository.BucketDay, FillNull)
+	assert.Nil(t, data.Series[1].Values[1])
+
+	// 직전 값으로 채우되 첫 값 이전은 비워 둠
+	data = alignGroupedSeries(points, starts, repository.BucketDay, FillPrevious)
+	assert.Equal(t, []float64{10, 10, 30}, derefAll(data.Series[1].Values))
+	assert.Nil(t, data.Series[0].Values[0])
+	assert.Equal(t, 50.0, *data.Series[0].Values[2])
+
+	many := make([]repository.GroupedTimeSeriesPoint, maxGroupSeries+3)
+	for i := range many {
+		many[i] = repository.GroupedTimeSeriesPoint{Group: string(rune('a' + i)), Bucket: day(1), Value: float64(i)}
+	}
+	data = alignGroupedSeries(many, starts, repository.BucketDay, FillZero)
+	assert.Len(t, data.Series, maxGroupSeries)
+	assert.Equal(t, string(rune('a'+maxGroupSeries+2)), data.Series[0].Name)
+}
+
+func derefAll(values []*float64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = *v
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+)
+
+// 구독자 한 명이 밀릴 수 있는 최대 이벤트 수
+const defaultSubscriberBuffer = 256
+
+// DataEvent 새로 수집된 데이터 포인트. ID는 DashboardData ID (Last-Event-ID로 사용)
+type DataEvent struct {
+	ID         uint      `json:"id"`
+	Category   string    `json:"category"`
+	Label      string    `json:"label"`
+	Value      float64   `json:"value"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+func NewDataEvent(d models.DashboardData) DataEvent {
+	return DataEvent{
+		ID:         d.ID,
+		Category:   d.Category,
+		Label:      d.Label,
+		Value:      d.Value,
+		RecordedAt: d.RecordedAt,
+	}
+}
+
+// EventHub 수집된 데이터를 카테고리 구독자에게 나눠 주는 프로세스 내 허브.
+// 발행은 막히지 않음: 버퍼가 가득 찬 구독자는 끊고, 클라이언트가 Last-Event-ID로 다시 받아감
+type EventHub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	buffer int
+	closed bool
+}
+
+func NewEventHub(buffer int) *EventHub {
+	if buffer <= 0 {
+		buffer = defaultSubscriberBuffer
+	}
+	return &EventHub{subs: map[*Subscription]struct{}{}, buffer: buffer}
+}
+
+// Subscription 한 클라이언트의 구독. Done이 닫히면 더 이상 이벤트가 오지 않음
+type Subscription struct {
+	hub        *EventHub
+	categories map[string]bool
+	events     chan DataEvent
+	done       chan struct{}
+	once       sync.Once
+	lagged     bool
+}
+
+func (s *Subscription) Events() <-chan DataEvent { return s.events }
+func (s *Subscription) Done() <-chan struct{}    { return s.done }
+
+// Lagged 버퍼가 넘쳐 끊긴 구독인지 여부 (Done 이후에만 의미 있음)
+func (s *Subscription) Lagged() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.lagged
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe categories 중 하나에 해당하는 이벤트 구독. 허브가 닫혔으면 바로 Done 상태
+func (h *EventHub) Subscribe(categories []string) *Subscription {
+	s := &Subscription{
+		hub:        h,
+		categories: make(map[string]bool, len(categories)),
+		events:     make(chan DataEvent, h.buffer),
+		done:       make(chan struct{}),
+	}
+	for _, c := range categories {
+		s.categories[c] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		s.once.Do(func() { close(s.done) })
+		return s
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// Publish 이벤트를 구독자 버퍼에 넣음. 느린 구독자 때문에 발행자가 기다리지 않음
+func (h *EventHub) Publish(ev DataEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs {
+		if !s.categories[ev.Category] {
+			continue
+		}
+		select {
+		case s.events <- ev:
+		default:
+			s.lagged = true
+			h.remove(s)
+		}
+	}
+}
+
+// Subscribers 현재 구독자 수
+func (h *EventHub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close 모든 구독 종료 (서버 종료 시 스트림 응답을 끝내기 위해)
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subs {
+		h.remove(s)
+	}
+}
+
+// remove h.mu를 잡은 상태에서 호출
+func (h *EventHub) remove(s *Subscription) {
+	delete(h.subs, s)
+	s.once.Do(func() { close(s.done) })
+}
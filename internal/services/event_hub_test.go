@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHub_FanOutByCategory(t *testing.T) {
+	hub := NewEventHub(4)
+	sales := hub.Subscribe([]string{"sales"})
+	both := hub.Subscribe([]string{"sales", "traffic"})
+	defer sales.Close()
+	defer both.Close()
+
+	hub.Publish(DataEvent{ID: 1, Category: "sales", Value: 10})
+	hub.Publish(DataEvent{ID: 2, Category: "traffic", Value: 20})
+
+	assert.Equal(t, uint(1), (<-sales.Events()).ID)
+	assert.Len(t, sales.Events(), 0)
+	assert.Equal(t, uint(1), (<-both.Events()).ID)
+	assert.Equal(t, uint(2), (<-both.Events()).ID)
+}
+
+func TestEventHub_DropsLaggingSubscriber(t *testing.T) {
+	hub := NewEventHub(2)
+	slow := hub.Subscribe([]string{"sales"})
+	fast := hub.Subscribe([]string{"sales"})
+
+	for i := 1; i <= 3; i++ {
+		hub.Publish(DataEvent{ID: uint(i), Category: "sales"})
+		<-fast.Events()
+	}
+
+	// 버퍼(2)를 넘긴 구독자만 끊기고 다른 구독자는 계속 받음
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow subscriber should be disconnected")
+	}
+	assert.True(t, slow.Lagged())
+	assert.Equal(t, 1, hub.Subscribers())
+
+	fast.Close()
+	assert.False(t, fast.Lagged())
+	assert.Equal(t, 0, hub.Subscribers())
+}
+
+func TestEventHub_Close(t *testing.T) {
+	hub := NewEventHub(1)
+	sub := hub.Subscribe([]string{"sales"})
+	hub.Close()
+
+	<-sub.Done()
+	sub.Close() // 중복 호출 허용
+
+	late := hub.Subscribe([]string{"sales"})
+	<-late.Done()
+	assert.Equal(t, 0, hub.Subscribers())
+}
+
+func TestLiveMeta(t *testing.T) {
+	live, err := liveMeta("sales", SeriesLabel, ChartQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, &LiveMeta{Category: "sales", Mode: LiveRaw}, live)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+	q := ChartQuery{From: &from, To: &to, Bucket: repository.BucketDay, Aggregation: repository.AggSum}
+
+	live, err = liveMeta("sales", SeriesTime, q)
+	require.NoError(t, err)
+	assert.Equal(t, LiveTime, live.Mode)
+	assert.Equal(t, from.UnixMilli(), live.From)
+	assert.Equal(t, to.UnixMilli(), live.To)
+	assert.Equal(t, []int64{from.UnixMilli(), from.AddDate(0, 0, 1).UnixMilli(), from.AddDate(0, 0, 2).UnixMilli()}, live.Buckets)
+
+	// 평균은 빈 버킷이 생략되므로 버킷 위치를 보내지 않음
+	q.Aggregation = repository.AggAvg
+	live, err = liveMeta("sales", SeriesTime, q)
+	require.NoError(t, err)
+	assert.Nil(t, live.Buckets)
+
+	live, err = liveMeta("sales", SeriesLabel, q)
+	require.NoError(t, err)
+	assert.Equal(t, LiveLabel, live.Mode)
+	assert.Equal(t, "avg", live.Aggregation)
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
+// ParseExportFormat 쿼리 파라미터의 형식 문자열 검증
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(strings.ToLower(s)); f {
+	case ExportCSV, ExportJSON, ExportXLSX:
+		return f, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportJSON:
+		return "application/json; charset=utf-8"
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// Locale 날짜/숫자 표기 규칙
+type Locale struct {
+	Tag        string
+	Decimal    string
+	Group      string
+	DateLayout string
+}
+
+var locales = map[string]Locale{
+	"ko": {Tag: "ko-KR", Decimal: ".", Group: ",", DateLayout: "2006-01-02 15:04:05"},
+	"en": {Tag: "en-US", Decimal: ".", Group: ",", DateLayout: "01/02/2006 03:04:05 PM"},
+	"ja": {Tag: "ja-JP", Decimal: ".", Group: ",", DateLayout: "2006/01/02 15:04:05"},
+	"de": {Tag: "de-DE", Decimal: ",", Group: ".", DateLayout: "02.01.2006 15:04:05"},
+	"fr": {Tag: "fr-FR", Decimal: ",", Group: " ", DateLayout: "02/01/2006 15:04:05"},
+}
+
+// LookupLocale "en-US,en;q=0.9" 같은 Accept-Language 값도 허용, 모르는 언어는 ko-KR
+func LookupLocale(tag string) Locale {
+	tag = strings.TrimSpace(strings.SplitN(tag, ",", 2)[0])
+	lang := strings.ToLower(strings.SplitN(strings.SplitN(tag, ";", 2)[0], "-", 2)[0])
+	if l, ok := locales[lang]; ok {
+		return l
+	}
+	return locales["ko"]
+}
+
+// FormatNumber 소수점 2자리까지, 로케일 구분자 적용
+func (l Locale) FormatNumber(v float64) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', 2, 64)
+	s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+
+	intPart, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	if v < 0 {
+		b.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteString(l.Decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+func (l Locale) FormatTime(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// ExportRequest 내보내기 대상과 표기 방식
+type ExportRequest struct {
+	Format   ExportFormat
+	Filter   repository.DataFilter
+	Location *time.Location
+	Locale   Locale
+}
+
+type ExportService struct {
+	dashboardRepo *repository.DashboardRepository
+}
+
+func NewExportService(dashboardRepo *repository.DashboardRepository) *ExportService {
+	return &ExportService{dashboardRepo: dashboardRepo}
+}
+
+// Export 조건에 맞는 데이터를 지정한 형식으로 w에 스트리밍
+func (s *ExportService) Export(w io.Writer, req ExportRequest) error {
+	if req.Location == nil {
+		req.Location = time.UTC
+	}
+
+	var ew exportWriter
+	switch req.Format {
+	case ExportCSV:
+		ew = newCSVExportWriter(w, req.Locale, req.Location)
+	case ExportJSON:
+		ew = newJSONExportWriter(w, req.Locale, req.Location)
+	case ExportXLSX:
+		ew = newXLSXExportWriter(w, req.Location)
+	default:
+		return ErrUnsupportedFormat
+	}
+
+	if err := ew.Begin(); err != nil {
+		return err
+	}
+	if err := s.dashboardRepo.StreamData(req.Filter, ew.Write); err != nil {
+		return err
+	}
+	return ew.End()
+}
+
+// ExportFilename 다운로드 파일명 (예: sales-20240101-20240131.csv)
+func ExportFilename(req ExportRequest) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, req.Filter.Category)
+	if req.Filter.From != nil {
+		name += "-" + req.Filter.From.In(req.Location).Format("20060102")
+	}
+	if req.Filter.To != nil {
+		name += "-" + req.Filter.To.Add(-time.Nanosecond).In(req.Location).Format("20060102")
+	}
+	return name + "." + string(req.Format)
+}
+
+type exportWriter interface {
+	Begin() error
+	Write(d models.DashboardData) error
+	End() error
+}
+
+var exportHeader = []string{"recorded_at", "category", "label", "value"}
+
+// CSV - 소수점이 쉼표인 로케일은 스프레드시트 관례대로 세미콜론 구분
+type csvExportWriter struct {
+	w      *csv.Writer
+	locale Locale
+	loc    *time.Location
+}
+
+func newCSVExportWriter(w io.Writer, locale Locale, loc *time.Location) *csvExportWriter {
+	cw := csv.NewWriter(w)
+	if locale.Decimal == "," {
+		cw.Comma = ';'
+	}
+	return &csvExportWriter{w: cw, locale: locale, loc: loc}
+}
+
+func (e *csvExportWriter) Begin() error {
+	return e.w.Write(exportHeader)
+}
+
+func (e *csvExportWriter) Write(d models.DashboardData) error {
+	return e.w.Write([]string{
+		e.locale.FormatTime(d.RecordedAt.In(e.loc)),
+		d.Category,
+		d.Label,
+		e.locale.FormatNumber(d.Value),
+	})
+}
+
+func (e *csvExportWriter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// JSON - 값은 숫자 그대로, 표시용 문자열은 *_formatted 필드로 제공
+type jsonExportWriter struct {
+	w      io.Writer
+	locale Locale
+	loc    *time.Location
+	n      int
+}
+
+type jsonExportRow struct {
+	RecordedAt          string  `json:"recorded_at"`
+	RecordedAtFormatted string  `json:"recorded_at_formatted"`
+	Category            string  `json:"category"`
+	Label               string  `json:"label"`
+	Value               float64 `json:"value"`
+	ValueFormatted      string  `json:"value_formatted"`
+}
+
+func newJSONExportWriter(w io.Writer, locale Locale, loc *time.Location) *jsonExportWriter {
+	return &jsonExportWriter{w: w, locale: locale, loc: loc}
+}
+
+func (e *jsonExportWriter) Begin() error {
+	_, err := fmt.Fprintf(e.w, `{"timezone":%q,"locale":%q,"data":[`, e.loc.String(), e.locale.Tag)
+	return err
+}
+
+func (e *jsonExportWriter) Write(d models.DashboardData) error {
+	t := d.RecordedAt.In(e.loc)
+	b, err := json.Marshal(jsonExportRow{
+		RecordedAt:          t.Format(time.RFC3339),
+		RecordedAtFormatted: e.locale.FormatTime(t),
+		Category:            d.Category,
+		Label:               d.Label,
+		Value:               d.Value,
+		ValueFormatted:      e.locale.FormatNumber(d.Value),
+	})
+	if err != nil {
+		return err
+	}
+	if e.n > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.n++
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonExportWriter) End() error {
+	_, err := io.WriteString(e.w, "]}")
+	return err
+}
+
+// XLSX - 최소 구성의 SpreadsheetML 패키지. 날짜는 엑셀 일련번호 + 표시 형식으로
+// 저장해 보는 사람의 엑셀 로케일대로 표시되도록 함
+type xlsxExportWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	loc   *time.Location
+	row   int
+}
+
+func newXLSXExportWriter(w io.Writer, loc *time.Location) *xlsxExportWriter {
+	return &xlsxExportWriter{zw: zip.NewWriter(w), loc: loc}
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="data" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+	// s="1": 날짜/시간, s="2": 천 단위 구분 숫자
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border/></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs></styleSheet>`
+)
+
+func (e *xlsxExportWriter) Begin() error {
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := e.zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := e.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	e.sheet = sheet
+	if _, err := io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+
+	e.row = 1
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="1">`)
+	for i, h := range exportHeader {
+		writeXLSXString(&b, i, 1, h)
+	}
+	b.WriteString(`</row>`)
+	_, err = io.WriteString(e.sheet, b.String())
+	return err
+}
+
+func (e *xlsxExportWriter) Write(d models.DashboardData) error {
+	e.row++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, e.row)
+	fmt.Fprintf(&b, `<c r="A%d" s="1"><v>%s</v></c>`, e.row, strconv.FormatFloat(excelSerial(d.RecordedAt.In(e.loc)), 'f', -1, 64))
+	writeXLSXString(&b, 1, e.row, d.Category)
+	writeXLSXString(&b, 2, e.row, d.Label)
+	fmt.Fprintf(&b, `<c r="D%d" s="2"><v>%s</v></c>`, e.row, strconv.FormatFloat(d.Value, 'f', -1, 64))
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(e.sheet, b.String())
+	return err
+}
+
+func (e *xlsxExportWriter) End() error {
+	if _, err := io.WriteString(e.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
+
+func writeXLSXString(b *strings.Builder, col, row int, s string) {
+	fmt.Fprintf(b, `<c r="%c%d" t="inlineStr"><is><t>`, 'A'+col, row)
+	xmlEscape(b, s)
+	b.WriteString(`</t></is></c>`)
+}
+
+func xmlEscape(b *strings.Builder, s string) {
+	for _, r := range s {
+		switch r {
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '&':
+			b.WriteString("&amp;")
+		case '"':
+			b.WriteString("&quot;")
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// excelSerial 벽시계 시각을 엑셀 날짜 일련번호(1899-12-30 기준 일수)로 변환
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return wall.Sub(epoch).Hours() / 24
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupLocale(t *testing.T) {
+	assert.Equal(t, "en-US", LookupLocale("en-US,en;q=0.9").Tag)
+	assert.Equal(t, "de-DE", LookupLocale("de").Tag)
+	assert.Equal(t, "ko-KR", LookupLocale("").Tag)
+	assert.Equal(t, "ko-KR", LookupLocale("xx-YY").Tag)
+}
+
+func TestLocale_FormatNumber(t *testing.T) {
+	ko := LookupLocale("ko-KR")
+	de := LookupLocale("de-DE")
+
+	assert.Equal(t, "1,234,567.5", ko.FormatNumber(1234567.5))
+	assert.Equal(t, "1.234.567,5", de.FormatNumber(1234567.5))
+	assert.Equal(t, "-999", ko.FormatNumber(-999))
+	assert.Equal(t, "0.01", ko.FormatNumber(0.01))
+	assert.Equal(t, "0", ko.FormatNumber(0))
+}
+
+func TestExportFilename(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, loc)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, loc)
+
+	req := ExportRequest{Format: ExportCSV, Location: loc}
+	req.Filter.Category = "sales"
+	req.Filter.From = &from
+	req.Filter.To = &to
+
+	assert.Equal(t, "sales-20240101-20240131.csv", ExportFilename(req))
+}
+
+func TestExcelSerial(t *testing.T) {
+	// 엑셀 기준 2024-01-01 00:00 = 45292
+	assert.Equal(t, 45292.0, excelSerial(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 45292.5, excelSerial(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+)
+
+var (
+	ErrInvalidForecast  = errors.New("invalid forecast option")
+	ErrNotEnoughHistory = errors.New("not enough history to forecast")
+)
+
+// ForecastModel 예측 모델
+type ForecastModel string
+
+const (
+	ForecastNone ForecastModel = ""
+	// ForecastAuto 계절 주기 두 번 이상의 기록이 있으면 Holt-Winters, 아니면 선형 추세
+	ForecastAuto ForecastModel = "auto"
+	// ForecastLinear 최소제곱 직선
+	ForecastLinear ForecastModel = "linear"
+	// ForecastHoltWinters 가법 계절 지수평활 (기록이 부족하면 선형 추세로 대체)
+	ForecastHoltWinters ForecastModel = "holt_winters"
+)
+
+const (
+	// maxForecastHorizon 예측할 수 있는 최대 버킷 수
+	maxForecastHorizon = 365
+	// 신뢰구간 95% (정규분포 z값)
+	forecastConfidence = 0.95
+	forecastZ          = 1.96
+)
+
+// ForecastOptions 시계열 차트에 이어 그릴 예측 조건. Horizon이 0이면 버킷 단위 기본값
+type ForecastOptions struct {
+	Model   ForecastModel
+	Horizon int
+}
+
+func (o ForecastOptions) Enabled() bool {
+	return o.Model != ForecastNone
+}
+
+// ParseForecastOptions ?forecast=auto|linear|holt_winters&horizon=
+func ParseForecastOptions(model, horizon string) (ForecastOptions, error) {
+	o := ForecastOptions{Model: ForecastModel(model)}
+	switch o.Model {
+	case ForecastNone, ForecastAuto, ForecastLinear, ForecastHoltWinters:
+	default:
+		return ForecastOptions{}, ErrInvalidForecast
+	}
+	if horizon == "" {
+		return o, nil
+	}
+	n, err := strconv.Atoi(horizon)
+	if err != nil || n < 1 || n > maxForecastHorizon {
+		return ForecastOptions{}, ErrInvalidForecast
+	}
+	o.Horizon = n
+	return o, nil
+}
+
+// Forecast 기록 다음 버킷부터의 예측값과 신뢰구간
+type Forecast struct {
+	Model      ForecastModel `json:"model"`
+	Confidence float64       `json:"confidence"`
+	Labels     []string      `json:"labels"`
+	Values     []float64     `json:"values"`
+	Lower      []float64     `json:"lower"`
+	Upper      []float64     `json:"upper"`
+}
+
+// seasonLength 버킷 단위별 계절 주기 (시간: 하루, 일: 한 주, 주·월: 한 해)
+func seasonLength(b repository.Bucket) int {
+	switch b {
+	case repository.BucketHour:
+		return 24
+	case repository.BucketWeek:
+		return 52
+	case repository.BucketMonth:
+		return 12
+	default:
+		return 7
+	}
+}
+
+func defaultHorizon(b repository.Bucket) int {
+	switch b {
+	case repository.BucketHour:
+		return 24
+	case repository.BucketWeek:
+		return 8
+	case repository.BucketMonth:
+		return 6
+	default:
+		return 14
+	}
+}
+
+// forecastTimeSeries 조회 기간의 버킷 집계로 모델을 맞추고 이후 버킷을 예측.
+// 조회 끝을 넘는(진행 중인) 마지막 버킷은 제외하고, 값이 없는 버킷은 합계/건수면 0, 그 외는 앞뒤 값으로 보간
+func forecastTimeSeries(points []repository.TimeSeriesPoint, starts []time.Time, q ChartQuery) (*Forecast, error) {
+	if len(starts) > 0 && nextBucket(starts[len(starts)-1], q.Bucket).After(*q.To) {
+		starts = starts[:len(starts)-1]
+	}
+	history, last := bucketHistory(points, starts, q.Aggregation)
+	if len(history) < 3 {
+		return nil, ErrNotEnoughHistory
+	}
+
+	horizon := q.Forecast.Horizon
+	if horizon == 0 {
+		horizon = defaultHorizon(q.Bucket)
+	}
+	f := fitForecast(history, q.Forecast.Model, seasonLength(q.Bucket), horizon)
+
+	f.Labels = make([]string, horizon)
+	t := starts[last]
+	for i := range f.Labels {
+		t = nextBucket(t, q.Bucket)
+		f.Labels[i] = bucketLabel(t, q.Bucket)
+	}
+	return f, nil
+}
+
+// bucketHistory 버킷마다 하나씩 값을 채운 기록과 마지막 값의 버킷 번호. 앞뒤의 빈 버킷은 버림
+func bucketHistory(points []repository.TimeSeriesPoint, starts []time.Time, agg repository.Aggregation) ([]float64, int) {
+	byBucket := make(map[int64]float64, len(points))
+	for _, p := range points {
+		byBucket[p.Bucket.Unix()] = p.Value
+	}
+	fillZero := agg == repository.AggSum || agg == repository.AggCount
+
+	var history []float64
+	last, gap := -1, 0
+	for i, t := range starts {
+		v, ok := byBucket[t.Unix()]
+		if !ok && !fillZero {
+			if len(history) > 0 {
+				gap++
+			}
+			continue
+		}
+		// 빈 버킷은 직전 값과 현재 값 사이를 직선으로 채움
+		if gap > 0 {
+			prev := history[len(history)-1]
+			for k := 1; k <= gap; k++ {
+				history = append(history, prev+(v-prev)*float64(k)/float64(gap+1))
+			}
+		}
+		gap = 0
+		history = append(history, v)
+		last = i
+	}
+	return history, last
+}
+
+// fitForecast 모델을 고르고 horizon개 예측. Holt-Winters는 계절 주기 두 번 이상의 기록이 필요
+func fitForecast(history []float64, model ForecastModel, season, horizon int) *Forecast {
+	if model != ForecastLinear && season > 1 && len(history) >= 2*season {
+		return fitHoltWinters(history, season, horizon)
+	}
+	return fitLinear(history, horizon)
+}
+
+// fitLinear 최소제곱 직선. 예측구간은 잔차 표준오차에 x가 멀어질수록 넓어지는 항을 곱함
+func fitLinear(y []float64, horizon int) *Forecast {
+	n := float64(len(y))
+	xbar := (n - 1) / 2
+	var ybar float64
+	for _, v := range y {
+		ybar += v
+	}
+	ybar /= n
+
+	var sxx, sxy float64
+	for i, v := range y {
+		dx := float64(i) - xbar
+		sxx += dx * dx
+		sxy += dx * (v - ybar)
+	}
+	slope := sxy / sxx
+	intercept := ybar - slope*xbar
+
+	var ssr float64
+	for i, v := range y {
+		r := v - (intercept + slope*float64(i))
+		ssr += r * r
+	}
+	sigma := math.Sqrt(ssr / (n - 2))
+
+	f := newForecast(ForecastLinear, horizon)
+	for h := 1; h <= horizon; h++ {
+		x := n - 1 + float64(h)
+		se := sigma * math.Sqrt(1+1/n+(x-xbar)*(x-xbar)/sxx)
+		f.set(h-1, intercept+slope*x, forecastZ*se)
+	}
+	return f
+}
+
+// holtWinters 가법 Holt-Winters 상태와 한 단계 예측 오차 제곱합
+type holtWinters struct {
+	alpha, beta, gamma float64
+	level, trend       float64
+	seasonal           []float64
+	sse                float64
+	n                  int
+}
+
+// runHoltWinters 첫 두 주기로 수준·추세·계절 초기값을 잡고 세 번째 값부터 갱신
+func runHoltWinters(y []float64, m int, alpha, beta, gamma float64) holtWinters {
+	var first, second float64
+	for i := 0; i < m; i++ {
+		first += y[i]
+		second += y[m+i]
+	}
+	first /= float64(m)
+	second /= float64(m)
+
+	hw := holtWinters{alpha: alpha, beta: beta, gamma: gamma, level: first, trend: (second - first) / float64(m)}
+	hw.seasonal = make([]float64, len(y))
+	for i := 0; i < m; i++ {
+		hw.seasonal[i] = y[i] - first
+	}
+	for t := m; t < len(y); t++ {
+		s := hw.seasonal[t-m]
+		e := y[t] - (hw.level + hw.trend + s)
+		hw.sse += e * e
+		hw.n++
+
+		level := alpha*(y[t]-s) + (1-alpha)*(hw.level+hw.trend)
+		hw.trend = beta*(level-hw.level) + (1-beta)*hw.trend
+		hw.seasonal[t] = gamma*(y[t]-level) + (1-gamma)*s
+		hw.level = level
+	}
+	return hw
+}
+
+// fitHoltWinters 평활 계수(0.1~0.9, 0.1 간격)를 오차 제곱합이 가장 작은 조합으로 고름
+func fitHoltWinters(y []float64, m, horizon int) *Forecast {
+	var best holtWinters
+	for a := 1; a <= 9; a++ {
+		for b := 1; b <= 9; b++ {
+			for g := 1; g <= 9; g++ {
+				hw := runHoltWinters(y, m, float64(a)/10, float64(b)/10, float64(g)/10)
+				if best.n == 0 || hw.sse < best.sse {
+					best = hw
+				}
+			}
+		}
+	}
+
+	sigma := math.Sqrt(best.sse / float64(best.n))
+	f := newForecast(ForecastHoltWinters, horizon)
+	n := len(y)
+	// 예측 분산: sigma² (1 + Σ c_j²), c_j = α(1 + jβ) + γ·[j가 주기의 배수]
+	variance := 1.0
+	for h := 1; h <= horizon; h++ {
+		if h > 1 {
+			j := h - 1
+			c := best.alpha * (1 + float64(j)*best.beta)
+			if j%m == 0 {
+				c += best.gamma
+			}
+			variance += c * c
+		}
+		v := best.level + float64(h)*best.trend + best.seasonal[n-m+(h-1)%m]
+		f.set(h-1, v, forecastZ*sigma*math.Sqrt(variance))
+	}
+	return f
+}
+
+func newForecast(model ForecastModel, horizon int) *Forecast {
+	return &Forecast{
+		Model:      model,
+		Confidence: forecastConfidence,
+		Values:     make([]float64, horizon),
+		Lower:      make([]float64, horizon),
+		Upper:      make([]float64, horizon),
+	}
+}
+
+func (f *Forecast) set(i int, v, margin float64) {
+	f.Values[i] = v
+	f.Lower[i] = v - margin
+	f.Upper[i] = v + margin
+}
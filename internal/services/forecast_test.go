@@ -0,0 +1,138 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForecastOptions(t *testing.T) {
+	o, err := ParseForecastOptions("", "")
+	require.NoError(t, err)
+	assert.False(t, o.Enabled())
+
+	o, err = ParseForecastOptions("holt_winters", "30")
+	require.NoError(t, err)
+	assert.Equal(t, ForecastOptions{Model: ForecastHoltWinters, Horizon: 30}, o)
+
+	for _, c := range [][2]string{{"arima", ""}, {"auto", "0"}, {"auto", "366"}, {"auto", "x"}} {
+		_, err := ParseForecastOptions(c[0], c[1])
+		assert.ErrorIs(t, err, ErrInvalidForecast, c)
+	}
+}
+
+func TestFitLinear(t *testing.T) {
+	// 오차 없는 직선은 그대로 이어지고 구간 폭이 0
+	y := []float64{1, 3, 5, 7, 9}
+	f := fitLinear(y, 2)
+	assert.Equal(t, ForecastLinear, f.Model)
+	assert.InDelta(t, 11, f.Values[0], 1e-9)
+	assert.InDelta(t, 13, f.Values[1], 1e-9)
+	assert.InDelta(t, 0, f.Upper[1]-f.Lower[1], 1e-9)
+
+	// 오차가 있으면 멀리 갈수록 구간이 넓어짐
+	f = fitLinear([]float64{10, 12, 11, 15, 14, 16, 18, 17}, 3)
+	for i := range f.Values {
+		assert.Less(t, f.Lower[i], f.Values[i])
+		assert.Greater(t, f.Upper[i], f.Values[i])
+	}
+	assert.Greater(t, f.Upper[2]-f.Lower[2], f.Upper[0]-f.Lower[0])
+}
+
+func TestFitHoltWinters(t *testing.T) {
+	// 추세 + 주간 계절 패턴 6주
+	season := []float64{5, -2, -3, 0, 1, 8, -9}
+	y := make([]float64, 42)
+	for i := range y {
+		y[i] = 100 + 0.5*float64(i) + season[i%7]
+	}
+
+	f := fitForecast(y, ForecastAuto, 7, 14)
+	assert.Equal(t, ForecastHoltWinters, f.Model)
+	require.Len(t, f.Values, 14)
+	for h := range f.Values {
+		i := len(y) + h
+		assert.InDelta(t, 100+0.5*float64(i)+season[i%7], f.Values[h], 1.0, "h=%d", h+1)
+		assert.LessOrEqual(t, f.Lower[h], f.Values[h])
+		assert.GreaterOrEqual(t, f.Upper[h], f.Values[h])
+	}
+	assert.GreaterOrEqual(t, f.Upper[13]-f.Lower[13], f.Upper[0]-f.Lower[0])
+
+	// 같은 입력이면 같은 결과
+	assert.Equal(t, f, fitForecast(y, ForecastHoltWinters, 7, 14))
+
+	// 두 주기가 안 되면 선형 추세로 대체
+	assert.Equal(t, ForecastLinear, fitForecast(y[:13], ForecastHoltWinters, 7, 3).Model)
+	assert.Equal(t, ForecastLinear, fitForecast(y, ForecastLinear, 7, 3).Model)
+}
+
+func TestBucketHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	starts := []time.Time{day(1), day(2), day(3), day(4), day(5), day(6)}
+	points := []repository.TimeSeriesPoint{{Bucket: day(2), Value: 10}, {Bucket: day(5), Value: 40}}
+
+	// 평균: 앞뒤 빈 버킷은 버리고 사이는 보간
+	history, last := bucketHistory(points, starts, repository.AggAvg)
+	assert.Equal(t, []float64{10, 20, 30, 40}, history)
+	assert.Equal(t, 4, last)
+
+	// 합계: 빈 버킷은 0
+	history, last = bucketHistory(points, starts, repository.AggSum)
+	assert.Equal(t, []float64{0, 10, 0, 0, 40, 0}, history)
+	assert.Equal(t, 5, last)
+}
+
+func TestForecastTimeSeries(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 3, d, h, 0, 0, 0, time.UTC) }
+	from, to := day(1, 0), day(6, 12)
+	starts, err := bucketStarts(from, to, repository.BucketDay, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, starts, 6)
+
+	var points []repository.TimeSeriesPoint
+	for d := 1; d <= 6; d++ {
+		points = append(points, repository.TimeSeriesPoint{Bucket: day(d, 0), Value: float64(d * 10)})
+	}
+	q := ChartQuery{From: &from, To: &to, Bucket: repository.BucketDay, Aggregation: repository.AggSum, Location: time.UTC,
+		Forecast: ForecastOptions{Model: ForecastAuto, Horizon: 3}}
+
+	// 6일은 조회 끝(12시)에서 끝나지 않으므로 기록에서 빼고 예측부터 6일
+	f, err := forecastTimeSeries(points, starts, q)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-03-06", "2024-03-07", "2024-03-08"}, f.Labels)
+	assert.InDelta(t, 60, f.Values[0], 1e-9)
+	assert.InDelta(t, 80, f.Values[2], 1e-9)
+	assert.Equal(t, 0.95, f.Confidence)
+
+	_, err = forecastTimeSeries(points[:2], starts[:2], q)
+	assert.ErrorIs(t, err, ErrNotEnoughHistory)
+}
+
+func TestForecastSeries(t *testing.T) {
+	data := &ChartData{
+		Labels:   []string{"a", "b", "c"},
+		Values:   []float64{1, 2, 3},
+		Forecast: &Forecast{Labels: []string{"c", "d"}, Values: []float64{4, 5}, Lower: []float64{3, 3}, Upper: []float64{5, 7}},
+	}
+	labels, values, lower, upper := forecastSeries(data)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, labels)
+	assert.Nil(t, values[0])
+	// 예측 직전 항목(b)의 실제 값에서 이어짐
+	assert.Equal(t, 2.0, *values[1])
+	assert.Equal(t, 2.0, *lower[1])
+	assert.Equal(t, 4.0, *values[2])
+	assert.Equal(t, 7.0, *upper[3])
+
+	r, _ := LookupChartRenderer("line")
+	config, meta := r.Render(data, ChartOptions{})
+	datasets := config["data"].(map[string]any)["datasets"].([]map[string]any)
+	require.Len(t, datasets, 4)
+	assert.Equal(t, roleForecast, datasets[1]["commetRole"])
+	assert.Equal(t, "-1", datasets[3]["fill"])
+	assert.Equal(t, "top", meta.Legend)
+	assert.False(t, math.IsNaN(meta.Confidence))
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+)
+
+const (
+	defaultJobConcurrency  = 2
+	defaultJobPollInterval = time.Second
+	defaultJobLease        = 5 * time.Minute
+	defaultJobMaxAttempts  = 5
+	defaultJobBaseBackoff  = 10 * time.Second
+	defaultJobMaxBackoff   = time.Hour
+	// 성공한 작업을 보관하는 기간 (UniqueKey 중복 방지도 이 기간 동안 유효)
+	jobRetention = 7 * 24 * time.Hour
+)
+
+// JobHandler 작업 한 건 실행. 에러를 반환하면 백오프 후 재시도하고, 횟수를 넘기면 dead
+type JobHandler func(ctx context.Context, job *models.Job) error
+
+// HandleJob 페이로드를 T로 디코딩해 넘기는 핸들러. 디코딩할 수 없는 페이로드는 재시도하지 않음
+func HandleJob[T any](fn func(ctx context.Context, payload T) error) JobHandler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return PermanentJobError(fmt.Errorf("decode payload: %w", err))
+		}
+		return fn(ctx, payload)
+	}
+}
+
+type permanentJobError struct{ err error }
+
+func (e *permanentJobError) Error() string { return e.err.Error() }
+func (e *permanentJobError) Unwrap() error { return e.err }
+
+// PermanentJobError 재시도하지 않고 바로 dead로 보낼 에러
+func PermanentJobError(err error) error {
+	return &permanentJobError{err: err}
+}
+
+type retryJobError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryJobError) Error() string { return e.err.Error() }
+func (e *retryJobError) Unwrap() error { return e.err }
+
+// RetryJobAfter 큐의 백오프 대신 after 뒤에 재시도
+func RetryJobAfter(err error, after time.Duration) error {
+	return &retryJobError{err: err, after: after}
+}
+
+// QueueOptions 큐별 실행 설정. 0이면 기본값
+type QueueOptions struct {
+	// Concurrency 인스턴스 하나에서 동시에 실행하는 작업 수
+	Concurrency  int
+	PollInterval time.Duration
+	// Lease 작업 한 건의 최대 실행 시간. 넘기면 취소되고 다른 인스턴스가 다시 가져감
+	Lease       time.Duration
+	MaxAttempts int
+	// Backoff attempt번째 실패 후 대기 시간
+	Backoff func(attempt int) time.Duration
+}
+
+func (o QueueOptions) withDefaults() QueueOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultJobConcurrency
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultJobPollInterval
+	}
+	if o.Lease <= 0 {
+		o.Lease = defaultJobLease
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = defaultJobMaxAttempts
+	}
+	if o.Backoff == nil {
+		o.Backoff = defaultJobBackoff
+	}
+	return o
+}
+
+// EnqueueOptions 작업 등록 옵션
+type EnqueueOptions struct {
+	// RunAt 이 시각 이후에 실행 (Delay보다 우선)
+	RunAt time.Time
+	Delay time.Duration
+	// MaxAttempts 0이면 큐 기본값
+	MaxAttempts int
+	// UniqueKey 같은 키로 이미 등록된 작업이 있으면 등록하지 않음
+	UniqueKey string
+}
+
+type jobQueueState struct {
+	name    string
+	opts    QueueOptions
+	types   []string
+	wake    chan struct{}
+	running int
+}
+
+type jobSchedule struct {
+	jobType  string
+	interval time.Duration
+	payload  any
+}
+
+// JobQueue Postgres 작업 큐. 작업 종류마다 큐와 핸들러를 등록하고 Run으로 실행
+type JobQueue struct {
+	jobRepo      repository.JobRepositoryInterface
+	drainTimeout time.Duration
+
+	mu        sync.Mutex
+	queues    map[string]*jobQueueState
+	handlers  map[string]JobHandler
+	jobQueues map[string]string
+	schedules []jobSchedule
+}
+
+// NewJobQueue drainTimeout: 종료 시 실행 중인 작업을 기다리는 최대 시간. 넘기면 취소하고 대기 상태로 되돌림
+func NewJobQueue(jobRepo repository.JobRepositoryInterface, drainTimeout time.Duration) *JobQueue {
+	return &JobQueue{
+		jobRepo:      jobRepo,
+		drainTimeout: drainTimeout,
+		queues:       map[string]*jobQueueState{},
+		handlers:     map[string]JobHandler{},
+		jobQueues:    map[string]string{},
+	}
+}
+
+// AddQueue 큐 추가. Register 전에 호출
+func (q *JobQueue) AddQueue(name string, opts QueueOptions) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queues[name] = &jobQueueState{name: name, opts: opts.withDefaults(), wake: make(chan struct{}, 1)}
+}
+
+// Register 작업 종류를 큐에 연결. 없는 큐거나 이미 등록된 종류면 panic (시작 시 설정 오류)
+func (q *JobQueue) Register(jobType, queue string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	state, ok := q.queues[queue]
+	if !ok {
+		panic("jobs: unknown queue " + queue)
+	}
+	if _, dup := q.handlers[jobType]; dup {
+		panic("jobs: duplicate handler for " + jobType)
+	}
+	q.handlers[jobType] = handler
+	q.jobQueues[jobType] = queue
+	state.types = append(state.types, jobType)
+}
+
+// Every interval마다 작업 등록. 구간마다 UniqueKey를 붙여 여러 인스턴스에서도 한 번만 실행
+func (q *JobQueue) Every(jobType string, interval time.Duration, payload any) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.schedules = append(q.schedules, jobSchedule{jobType: jobType, interval: interval, payload: payload})
+}
+
+// Enqueue 작업 등록. 같은 인스턴스의 워커는 폴링을 기다리지 않고 바로 가져감
+func (q *JobQueue) Enqueue(jobType string, payload any, opts EnqueueOptions) error {
+	q.mu.Lock()
+	queueName, ok := q.jobQueues[jobType]
+	state := q.queues[queueName]
+	q.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("jobs: no handler registered for %s", jobType)
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	runAt := opts.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now().Add(opts.Delay)
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = state.opts.MaxAttempts
+	}
+	job := &models.Job{
+		Queue:       queueName,
+		Type:        jobType,
+		Payload:     models.JSON(raw),
+		Status:      models.JobPending,
+		MaxAttempts: maxAttempts,
+		RunAt:       runAt,
+	}
+	if opts.UniqueKey != "" {
+		job.UniqueKey = &opts.UniqueKey
+	}
+	if err := q.jobRepo.Enqueue(job); err != nil {
+		return err
+	}
+	if !runAt.After(time.Now()) {
+		select {
+		case state.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Run ctx가 끝날 때까지 큐마다 작업을 가져와 실행. 종료 시 새 작업은 가져가지 않고
+// 실행 중인 작업을 drainTimeout까지 기다린 뒤 반환
+func (q *JobQueue) Run(ctx context.Context) {
+	// 작업은 ctx와 분리된 컨텍스트로 실행해 종료 신호에 바로 끊기지 않게 함
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	var inflight, loops sync.WaitGroup
+	q.mu.Lock()
+	for _, state := range q.queues {
+		if len(state.types) == 0 {
+			continue
+		}
+		loops.Add(1)
+		go func(state *jobQueueState) {
+			defer loops.Done()
+			q.poll(ctx, jobCtx, state, &inflight)
+		}(state)
+	}
+	for _, s := range q.schedules {
+		loops.Add(1)
+		go func(s jobSchedule) {
+			defer loops.Done()
+			q.schedule(ctx, s)
+		}(s)
+	}
+	q.mu.Unlock()
+
+	loops.Add(1)
+	go func() {
+		defer loops.Done()
+		q.cleanup(ctx)
+	}()
+
+	loops.Wait()
+
+	drained := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(q.drainTimeout):
+		log.Printf("Jobs: drain timeout, cancelling running jobs")
+		cancelJobs()
+		<-drained
+	}
+}
+
+// poll 빈 자리만큼 작업을 가져와 실행. 자리가 없거나 가져올 작업이 없으면 다음 폴링/깨움/완료까지 대기
+func (q *JobQueue) poll(ctx, jobCtx context.Context, state *jobQueueState, inflight *sync.WaitGroup) {
+	done := make(chan struct{}, state.opts.Concurrency)
+	ticker := time.NewTicker(state.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		free := state.opts.Concurrency - state.running
+		if free > 0 {
+			jobs, err := q.jobRepo.Claim(state.name, state.types, time.Now(), state.opts.Lease, free)
+			if err != nil {
+				log.Printf("Jobs: claim from %s failed: %v", state.name, err)
+			}
+			for i := range jobs {
+				state.running++
+				inflight.Add(1)
+				go func(job *models.Job) {
+					defer inflight.Done()
+					q.execute(jobCtx, state.opts, job)
+					done <- struct{}{}
+				}(&jobs[i])
+			}
+			if len(jobs) == free {
+				// 더 있을 수 있으니 자리가 나면 바로 다시 가져감
+				select {
+				case <-ctx.Done():
+					return
+				case <-done:
+					state.running--
+				}
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			state.running--
+		case <-state.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *JobQueue) execute(ctx context.Context, opts QueueOptions, job *models.Job) {
+	q.mu.Lock()
+	handler := q.handlers[job.Type]
+	q.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(ctx, opts.Lease)
+	defer cancel()
+	err := runJobHandler(runCtx, handler, job)
+
+	now := time.Now()
+	if err != nil && ctx.Err() != nil {
+		// 종료 중에 취소된 작업은 실패로 세지 않음
+		if err := q.jobRepo.Release(job.ID); err != nil {
+			log.Printf("Jobs: release %s#%d failed: %v", job.Type, job.ID, err)
+		}
+		return
+	}
+
+	var saveErr error
+	if err == nil {
+		saveErr = q.jobRepo.Complete(job.ID, now)
+	} else if dead, runAt := nextJobRun(job, err, opts.Backoff, now); dead {
+		log.Printf("Jobs: %s#%d dead after %d attempts: %v", job.Type, job.ID, job.Attempts, err)
+		saveErr = q.jobRepo.Bury(job.ID, now, truncate(err.Error(), 1000))
+	} else {
+		saveErr = q.jobRepo.Retry(job.ID, runAt, truncate(err.Error(), 1000))
+	}
+	if saveErr != nil {
+		log.Printf("Jobs: failed to save result of %s#%d: %v", job.Type, job.ID, saveErr)
+	}
+}
+
+// runJobHandler 핸들러의 panic을 에러로 바꿔 작업이 running으로 남지 않게 함
+func runJobHandler(ctx context.Context, handler JobHandler, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// nextJobRun 실패한 작업을 dead로 보낼지, 아니면 언제 다시 실행할지
+func nextJobRun(job *models.Job, err error, backoff func(int) time.Duration, now time.Time) (dead bool, runAt time.Time) {
+	var permanent *permanentJobError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		return true, time.Time{}
+	}
+	var retry *retryJobError
+	if errors.As(err, &retry) {
+		return false, now.Add(retry.after)
+	}
+	return false, now.Add(backoff(job.Attempts))
+}
+
+// defaultJobBackoff 10초부터 두 배씩, 최대 1시간
+func defaultJobBackoff(attempt int) time.Duration {
+	delay := defaultJobBaseBackoff
+	for i := 1; i < attempt && delay < defaultJobMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > defaultJobMaxBackoff {
+		delay = defaultJobMaxBackoff
+	}
+	return delay
+}
+
+// schedule 구간 시작 시각을 키로 등록하므로 여러 인스턴스가 같은 구간을 중복 등록하지 않음
+func (q *JobQueue) schedule(ctx context.Context, s jobSchedule) {
+	check := s.interval
+	if check > time.Minute {
+		check = time.Minute
+	}
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
+
+	for {
+		slot := time.Now().Truncate(s.interval)
+		err := q.Enqueue(s.jobType, s.payload, EnqueueOptions{
+			RunAt:     slot,
+			UniqueKey: scheduleKey(s.jobType, slot),
+		})
+		if err != nil {
+			log.Printf("Jobs: schedule %s failed: %v", s.jobType, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func scheduleKey(jobType string, slot time.Time) string {
+	return fmt.Sprintf("%s@%d", jobType, slot.Unix())
+}
+
+// cleanup 오래된 성공 작업을 주기적으로 삭제 (dead 작업은 확인할 수 있게 남김)
+func (q *JobQueue) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if _, err := q.jobRepo.DeleteSucceeded(time.Now().Add(-jobRetention)); err != nil {
+			log.Printf("Jobs: cleanup failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockJobRepository is a mock implementation of JobRepositoryInterface
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Enqueue(job *models.Job) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockJobRepository) Claim(queue string, types []string, now time.Time, lease time.Duration, limit int) ([]models.Job, error) {
+	args := m.Called(queue, types, now, lease, limit)
+	return args.Get(0).([]models.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Complete(id uint, now time.Time) error {
+	return m.Called(id, now).Error(0)
+}
+
+func (m *MockJobRepository) Retry(id uint, runAt time.Time, lastError string) error {
+	return m.Called(id, runAt, lastError).Error(0)
+}
+
+func (m *MockJobRepository) Bury(id uint, now time.Time, lastError string) error {
+	return m.Called(id, now, lastError).Error(0)
+}
+
+func (m *MockJobRepository) Release(id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockJobRepository) DeleteSucceeded(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestHandleJob_DecodesPayload(t *testing.T) {
+	var got Mail
+	handler := HandleJob(func(ctx context.Context, m Mail) error {
+		got = m
+		return nil
+	})
+
+	err := handler(context.Background(), &models.Job{Payload: models.JSON(`{"To":["a@example.com"],"Subject":"제목"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com"}, got.To)
+	assert.Equal(t, "제목", got.Subject)
+
+	// 디코딩할 수 없는 페이로드는 재시도해도 소용없으므로 바로 dead
+	err = handler(context.Background(), &models.Job{Payload: models.JSON(`[1]`)})
+	dead, _ := nextJobRun(&models.Job{Attempts: 1, MaxAttempts: 5}, err, defaultJobBackoff, time.Now())
+	assert.True(t, dead)
+}
+
+func TestNextJobRun(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	job := &models.Job{Attempts: 2, MaxAttempts: 5}
+
+	dead, runAt := nextJobRun(job, errors.New("boom"), defaultJobBackoff, now)
+	assert.False(t, dead)
+	assert.Equal(t, now.Add(20*time.Second), runAt)
+
+	dead, runAt = nextJobRun(job, RetryJobAfter(errors.New("busy"), time.Minute), defaultJobBackoff, now)
+	assert.False(t, dead)
+	assert.Equal(t, now.Add(time.Minute), runAt)
+
+	dead, _ = nextJobRun(job, PermanentJobError(errors.New("bad input")), defaultJobBackoff, now)
+	assert.True(t, dead)
+
+	job.Attempts = 5
+	dead, _ = nextJobRun(job, errors.New("boom"), defaultJobBackoff, now)
+	assert.True(t, dead)
+}
+
+func TestDefaultJobBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, defaultJobBackoff(1))
+	assert.Equal(t, 20*time.Second, defaultJobBackoff(2))
+	assert.Equal(t, 80*time.Second, defaultJobBackoff(4))
+	assert.Equal(t, time.Hour, defaultJobBackoff(20))
+}
+
+func TestRunJobHandler_RecoversPanic(t *testing.T) {
+	err := runJobHandler(context.Background(), func(ctx context.Context, job *models.Job) error {
+		panic("nil map")
+	}, &models.Job{})
+	assert.EqualError(t, err, "panic: nil map")
+}
+
+func TestJobQueue_Register(t *testing.T) {
+	q := NewJobQueue(nil, 0)
+	q.AddQueue("email", QueueOptions{})
+	noop := func(ctx context.Context, job *models.Job) error { return nil }
+
+	q.Register(JobSendEmail, "email", noop)
+	assert.Panics(t, func() { q.Register(JobSendEmail, "email", noop) })
+	assert.Panics(t, func() { q.Register("report.render", "reports", noop) })
+
+	assert.Error(t, q.Enqueue("unknown.job", nil, EnqueueOptions{}))
+	assert.Equal(t, defaultJobConcurrency, q.queues["email"].opts.Concurrency)
+	assert.Equal(t, defaultJobMaxAttempts, q.queues["email"].opts.MaxAttempts)
+}
+
+func TestScheduleKey(t *testing.T) {
+	slot := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, "rollup.hourly@1709283600", scheduleKey("rollup.hourly", slot))
+}
+
+func TestJobQueue_Enqueue(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := NewJobQueue(repo, 0)
+	q.AddQueue("email", QueueOptions{MaxAttempts: 3})
+	q.Register(JobSendEmail, "email", func(ctx context.Context, job *models.Job) error { return nil })
+
+	var saved []*models.Job
+	repo.On("Enqueue", mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).(*models.Job))
+	}).Return(nil)
+
+	runAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, q.Enqueue(JobSendEmail, Mail{Subject: "제목"}, EnqueueOptions{}))
+	require.NoError(t, q.Enqueue(JobSendEmail, nil, EnqueueOptions{RunAt: runAt, MaxAttempts: 8, UniqueKey: "once"}))
+
+	require.Len(t, saved, 2)
+	assert.Equal(t, "email", saved[0].Queue)
+	assert.Equal(t, models.JobPending, saved[0].Status)
+	assert.Equal(t, 3, saved[0].MaxAttempts)
+	assert.Contains(t, string(saved[0].Payload), `"Subject":"제목"`)
+	assert.Nil(t, saved[0].UniqueKey)
+	assert.Equal(t, runAt, saved[1].RunAt)
+	assert.Equal(t, 8, saved[1].MaxAttempts)
+	require.NotNil(t, saved[1].UniqueKey)
+	assert.Equal(t, "once", *saved[1].UniqueKey)
+}
+
+func TestJobQueue_ExecuteSavesResult(t *testing.T) {
+	opts := QueueOptions{}.withDefaults()
+	run := func(ctx context.Context, job *models.Job, result error) *MockJobRepository {
+		repo := new(MockJobRepository)
+		repo.On("Complete", job.ID, mock.Anything).Return(nil).Maybe()
+		repo.On("Retry", job.ID, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("Bury", job.ID, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("Release", job.ID).Return(nil).Maybe()
+
+		q := NewJobQueue(repo, 0)
+		q.handlers[job.Type] = func(ctx context.Context, job *models.Job) error { return result }
+		q.execute(ctx, opts, job)
+		return repo
+	}
+
+	repo := run(context.Background(), &models.Job{ID: 1, Type: "t", Attempts: 1, MaxAttempts: 5}, nil)
+	repo.AssertCalled(t, "Complete", uint(1), mock.Anything)
+
+	// 실패하면 백오프 뒤로 재시도
+	before := time.Now()
+	repo = run(context.Background(), &models.Job{ID: 2, Type: "t", Attempts: 2, MaxAttempts: 5}, errors.New("boom"))
+	repo.AssertCalled(t, "Retry", uint(2), mock.MatchedBy(func(at time.Time) bool {
+		return !at.Before(before.Add(20*time.Second)) && at.Before(time.Now().Add(20*time.Second))
+	}), "boom")
+
+	// 마지막 시도나 영구 실패는 dead
+	repo = run(context.Background(), &models.Job{ID: 3, Type: "t", Attempts: 5, MaxAttempts: 5}, errors.New("boom"))
+	repo.AssertCalled(t, "Bury", uint(3), mock.Anything, "boom")
+	repo = run(context.Background(), &models.Job{ID: 4, Type: "t", Attempts: 1, MaxAttempts: 5}, PermanentJobError(errors.New("bad")))
+	repo.AssertCalled(t, "Bury", uint(4), mock.Anything, "bad")
+	repo.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything)
+
+	// 종료 중 취소된 작업은 시도로 세지 않고 되돌림
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	repo = run(ctx, &models.Job{ID: 5, Type: "t", Attempts: 1, MaxAttempts: 5}, context.Canceled)
+	repo.AssertCalled(t, "Release", uint(5))
+	repo.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestJobQueue_RunClaimsRegisteredTypes(t *testing.T) {
+	repo := new(MockJobRepository)
+	q := NewJobQueue(repo, time.Second)
+	q.AddQueue("email", QueueOptions{Concurrency: 3, Lease: time.Minute})
+	q.AddQueue("idle", QueueOptions{})
+
+	handled := make(chan uint, 1)
+	q.Register(JobSendEmail, "email", func(ctx context.Context, job *models.Job) error {
+		handled <- job.ID
+		return nil
+	})
+
+	repo.On("DeleteSucceeded", mock.Anything).Return(int64(0), nil)
+	repo.On("Claim", "email", []string{JobSendEmail}, mock.Anything, time.Minute, 3).
+		Return([]models.Job{{ID: 7, Type: JobSendEmail, Attempts: 1, MaxAttempts: 5}}, nil).Once()
+	repo.On("Claim", "email", []string{JobSendEmail}, mock.Anything, time.Minute, mock.Anything).Return([]models.Job{}, nil)
+	completed := make(chan struct{})
+	repo.On("Complete", uint(7), mock.Anything).Run(func(mock.Arguments) { close(completed) }).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case id := <-handled:
+		assert.Equal(t, uint(7), id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not handled")
+	}
+	<-completed
+	cancel()
+	<-stopped
+
+	// 작업 종류가 없는 큐는 가져오지 않음
+	repo.AssertNotCalled(t, "Claim", "idle", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
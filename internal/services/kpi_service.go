@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrKPINotFound  = errors.New("kpi not found")
+	ErrKPIKeyExists = errors.New("kpi key already exists")
+)
+
+const (
+	KPIFormatNumber   = "number"
+	KPIFormatCurrency = "currency"
+	KPIFormatPercent  = "percent"
+)
+
+// KPIValue 계산된 요약 카드 한 장
+type KPIValue struct {
+	Definition models.KPIDefinition
+	Value      float64
+	Previous   float64
+	Formatted  string
+	// Delta 비교 기간 대비 변화 (percent 형식은 %p, 그 외는 %). 비교 값이 0이면 nil
+	Delta     *float64
+	DeltaUnit string
+	// CompareLabel 비교 기간 이름 (예: 이전 30일, 전년 동기)
+	CompareLabel string
+	// Sparkline 기간 내 일별 추이를 100x30 viewBox에 맞춘 SVG polyline 좌표
+	Sparkline string
+}
+
+func (v KPIValue) DeltaText() string {
+	if v.Delta == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(math.Abs(*v.Delta), 'f', 1, 64) + v.DeltaUnit
+}
+
+func (v KPIValue) DeltaUp() bool {
+	return v.Delta != nil && *v.Delta >= 0
+}
+
+type KPIService struct {
+	kpiRepo       repository.KPIRepositoryInterface
+	dashboardRepo *repository.DashboardRepository
+}
+
+func NewKPIService(kpiRepo repository.KPIRepositoryInterface, dashboardRepo *repository.DashboardRepository) *KPIService {
+	return &KPIService{kpiRepo: kpiRepo, dashboardRepo: dashboardRepo}
+}
+
+func (s *KPIService) List() ([]models.KPIDefinition, error) {
+	return s.kpiRepo.List()
+}
+
+func (s *KPIService) Create(req *models.KPIRequest) (*models.KPIDefinition, error) {
+	if err := s.checkKeyFree(req.Key, 0); err != nil {
+		return nil, err
+	}
+
+	def := &models.KPIDefinition{}
+	req.Apply(def)
+	if err := s.kpiRepo.Create(def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (s *KPIService) Update(id uint, req *models.KPIRequest) (*models.KPIDefinition, error) {
+	def, err := s.kpiRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKPINotFound
+		}
+		return nil, err
+	}
+	if req.Key != def.Key {
+		if err := s.checkKeyFree(req.Key, def.ID); err != nil {
+			return nil, err
+		}
+	}
+	req.Apply(def)
+	if err := s.kpiRepo.Update(def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// checkKeyFree 다른 KPI(id 제외)가 key를 쓰고 있으면 ErrKPIKeyExists
+func (s *KPIService) checkKeyFree(key string, id uint) error {
+	defs, err := s.kpiRepo.List()
+	if err != nil {
+		return err
+	}
+	for _, d := range defs {
+		if d.Key == key && d.ID != id {
+			return ErrKPIKeyExists
+		}
+	}
+	return nil
+}
+
+func (s *KPIService) Delete(id uint) error {
+	return s.kpiRepo.Delete(id)
+}
+
+// Compute 모든 KPI를 now 기준으로 계산. 일별 스파크라인은 loc 기준 자정으로 나눔.
+// cmp가 비어 있으면 직전 동일 기간과 비교
+func (s *KPIService) Compute(now time.Time, loc *time.Location, cmp Comparison) ([]KPIValue, error) {
+	defs, err := s.kpiRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]KPIValue, 0, len(defs))
+	for _, def := range defs {
+		v, err := s.compute(def, now, loc, cmp)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (s *KPIService) compute(def models.KPIDefinition, now time.Time, loc *time.Location, cmp Comparison) (KPIValue, error) {
+	agg := repository.Aggregation(def.Aggregation)
+	if !agg.Valid() {
+		return KPIValue{}, ErrInvalidAggregation
+	}
+	window := time.Duration(def.WindowDays) * 24 * time.Hour
+	from := now.Add(-window)
+	prevFrom, prevTo, compareLabel := kpiComparePeriod(def, from, now, cmp)
+
+	current, err := s.dashboardRepo.Aggregate(def.Category, def.Label, from, now, agg)
+	if err != nil {
+		return KPIValue{}, err
+	}
+	previous, err := s.dashboardRepo.Aggregate(def.Category, def.Label, prevFrom, prevTo, agg)
+	if err != nil {
+		return KPIValue{}, err
+	}
+
+	bucket := repository.BucketDay
+	if def.WindowDays > 92 {
+		bucket = repository.BucketWeek
+	}
+	starts, err := bucketStarts(from, now, bucket, loc)
+	if err != nil {
+		return KPIValue{}, err
+	}
+	points, err := s.dashboardRepo.GetTimeSeries(repository.TimeSeriesQuery{
+		Category:    def.Category,
+		Label:       def.Label,
+		From:        from,
+		To:          now,
+		Bucket:      bucket,
+		Aggregation: agg,
+		Location:    loc,
+	})
+	if err != nil {
+		return KPIValue{}, err
+	}
+
+	v := KPIValue{
+		Definition:   def,
+		Value:        current,
+		Previous:     previous,
+		Formatted:    FormatKPIValue(def.Format, current),
+		CompareLabel: compareLabel,
+		Sparkline:    sparklinePoints(alignTimeSeries(points, starts, bucket, resolveFill(FillAuto, agg)).Values),
+	}
+	v.Delta, v.DeltaUnit = kpiDelta(def.Format, current, previous)
+	return v, nil
+}
+
+// kpiComparePeriod KPI 기간 [from, to)와 비교할 기간과 그 이름
+func kpiComparePeriod(def models.KPIDefinition, from, to time.Time, cmp Comparison) (time.Time, time.Time, string) {
+	if !cmp.Enabled() || cmp.Mode == ComparePrevious {
+		return from.Add(-to.Sub(from)), from, fmt.Sprintf("이전 %d일", def.WindowDays)
+	}
+	return cmp.Shift(from, from, to), cmp.Shift(to, from, to), cmp.Label()
+}
+
+// FormatKPIValue 카드 표시용 값 (currency: ₩ 정수, percent: 소수 1자리)
+func FormatKPIValue(format string, v float64) string {
+	ko := LookupLocale("ko-KR")
+	switch format {
+	case KPIFormatCurrency:
+		return "₩" + ko.FormatNumber(math.Round(v))
+	case KPIFormatPercent:
+		return strconv.FormatFloat(v, 'f', 1, 64) + "%"
+	default:
+		return ko.FormatNumber(v)
+	}
+}
+
+// kpiDelta 비율 지표는 %p 차이, 나머지는 증감률
+func kpiDelta(format string, current, previous float64) (*float64, string) {
+	if format == KPIFormatPercent {
+		d := current - previous
+		return &d, "%p"
+	}
+	if previous == 0 {
+		return nil, "%"
+	}
+	d := (current - previous) / math.Abs(previous) * 100
+	return &d, "%"
+}
+
+func sparklinePoints(values []float64) string {
+	if len(values) < 2 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	span := hi - lo
+	if span == 0 {
+		span = 1
+	}
+
+	pts := make([]string, len(values))
+	for i, v := range values {
+		x := float64(i) / float64(len(values)-1) * 100
+		y := 28 - (v-lo)/span*26
+		pts[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return strings.Join(pts, " ")
+}
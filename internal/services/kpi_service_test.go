@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockKPIRepository is a mock implementation of KPIRepositoryInterface
+type MockKPIRepository struct {
+	mock.Mock
+}
+
+func (m *MockKPIRepository) List() ([]models.KPIDefinition, error) {
+	args := m.Called()
+	return args.Get(0).([]models.KPIDefinition), args.Error(1)
+}
+
+func (m *MockKPIRepository) FindByID(id uint) (*models.KPIDefinition, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KPIDefinition), args.Error(1)
+}
+
+func (m *MockKPIRepository) Create(def *models.KPIDefinition) error {
+	return m.Called(def).Error(0)
+}
+
+func (m *MockKPIRepository) Update(def *models.KPIDefinition) error {
+	return m.Called(def).Error(0)
+}
+
+func (m *MockKPIRepository) Delete(id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func TestFormatKPIValue(t *testing.T) {
+	assert.Equal(t, "₩45,679", FormatKPIValue(KPIFormatCurrency, 45678.9))
+	assert.Equal(t, "3.2%", FormatKPIValue(KPIFormatPercent, 3.2))
+	assert.Equal(t, "1,234", FormatKPIValue(KPIFormatNumber, 1234))
+}
+
+func TestKPIDelta(t *testing.T) {
+	d, unit := kpiDelta(KPIFormatNumber, 120, 100)
+	assert.InDelta(t, 20.0, *d, 0.0001)
+	assert.Equal(t, "%", unit)
+
+	// 직전 기간 값이 없으면 증감률을 계산하지 않음
+	d, _ = kpiDelta(KPIFormatCurrency, 50, 0)
+	assert.Nil(t, d)
+
+	// 비율 지표는 %p 차이
+	d, unit = kpiDelta(KPIFormatPercent, 3.2, 4.4)
+	assert.InDelta(t, -1.2, *d, 0.0001)
+	assert.Equal(t, "%p", unit)
+
+	v := KPIValue{Delta: d, DeltaUnit: unit}
+	assert.Equal(t, "1.2%p", v.DeltaText())
+	assert.False(t, v.DeltaUp())
+}
+
+func TestSparklinePoints(t *testing.T) {
+	assert.Equal(t, "", sparklinePoints([]float64{5}))
+	assert.Equal(t, "0.0,28.0 50.0,2.0 100.0,15.0", sparklinePoints([]float64{0, 10, 5}))
+	// 값이 모두 같으면 바닥선
+	assert.Equal(t, "0.0,28.0 100.0,28.0", sparklinePoints([]float64{7, 7}))
+}
+
+func TestKPIComparePeriod(t *testing.T) {
+	def := models.KPIDefinition{WindowDays: 30}
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -30)
+
+	// 기본은 직전 동일 기간
+	pf, pt, label := kpiComparePeriod(def, from, to, Comparison{})
+	assert.Equal(t, from.AddDate(0, 0, -30), pf)
+	assert.Equal(t, from, pt)
+	assert.Equal(t, "이전 30일", label)
+
+	pf, pt, label = kpiComparePeriod(def, from, to, Comparison{Mode: CompareYear})
+	assert.Equal(t, from.AddDate(-1, 0, 0), pf)
+	assert.Equal(t, to.AddDate(-1, 0, 0), pt)
+	assert.Equal(t, "전년 동기", label)
+}
+
+func TestKPIService_KeyConflict(t *testing.T) {
+	mockRepo := new(MockKPIRepository)
+	service := NewKPIService(mockRepo, nil)
+	defs := []models.KPIDefinition{{ID: 1, Key: "revenue"}, {ID: 2, Key: "orders"}}
+	mockRepo.On("List").Return(defs, nil)
+	mockRepo.On("FindByID", uint(2)).Return(&models.KPIDefinition{ID: 2, Key: "orders"}, nil)
+
+	_, err := service.Create(&models.KPIRequest{Key: "revenue"})
+	assert.ErrorIs(t, err, ErrKPIKeyExists)
+
+	// 다른 KPI의 키로 바꾸면 unique 인덱스까지 가지 않고 충돌
+	_, err = service.Update(2, &models.KPIRequest{Key: "revenue"})
+	assert.ErrorIs(t, err, ErrKPIKeyExists)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+	// 자기 키를 그대로 두는 수정은 허용
+	mockRepo.On("Update", mock.AnythingOfType("*models.KPIDefinition")).Return(nil)
+	def, err := service.Update(2, &models.KPIRequest{Key: "orders", Title: "주문"})
+	assert.NoError(t, err)
+	assert.Equal(t, "주문", def.Title)
+}
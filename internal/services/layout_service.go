@@ -0,0 +1,371 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDashboardNotFound = errors.New("dashboard not found")
+	ErrWidgetNotFound    = errors.New("widget not found")
+	ErrForbidden         = errors.New("forbidden")
+)
+
+// WidgetValidationError 위젯 설정 검증 실패 (필드별 메시지)
+type WidgetValidationError struct {
+	Fields map[string]string
+}
+
+func (e *WidgetValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for field, msg := range e.Fields {
+		msgs = append(msgs, field+": "+msg)
+	}
+	return "invalid widget: " + strings.Join(msgs, ", ")
+}
+
+// 그리드 열(4칸) 기준 위젯 너비, 높이 단계
+const (
+	maxWidgetWidth  = 4
+	maxWidgetHeight = 3
+)
+
+// WidgetView 템플릿에서 사용하는 위젯 + 차트 partial URL
+type WidgetView struct {
+	models.Widget
+	ChartURL string
+}
+
+// DashboardView 대시보드 화면 구성 정보
+type DashboardView struct {
+	Dashboard models.Dashboard
+	Widgets   []WidgetView
+	CanEdit   bool
+}
+
+type LayoutService struct {
+	layoutRepo *repository.LayoutRepository
+}
+
+func NewLayoutService(layoutRepo *repository.LayoutRepository) *LayoutService {
+	return &LayoutService{layoutRepo: layoutRepo}
+}
+
+// defaultWidgets 첫 방문 시 만들어지는 기본 대시보드 구성
+func defaultWidgets() []models.Widget {
+	return []models.Widget{
+		{Type: "area", DataSource: "sales", Title: "월별 매출 추이", Subtitle: "최근 12개월 매출 현황", Width: 2, Height: 2, Position: 0,
+			Options: models.JSON(`{"label":"매출","unit":"₩"}`)},
+		{Type: "bar", DataSource: "products", Title: "카테고리별 판매", Subtitle: "제품 카테고리별 판매 현황", Width: 2, Height: 2, Position: 1,
+			Options: models.JSON(`{"label":"판매량"}`)},
+		{Type: "doughnut", DataSource: "traffic", Title: "트래픽 소스", Subtitle: "방문자 유입 채널 분석", Width: 4, Height: 2, Position: 2,
+			Options: models.JSON(`{"label":"트래픽","suffix":"%"}`)},
+	}
+}
+
+// Default 사용자의 기본 대시보드. 없으면 기본 위젯으로 생성
+func (s *LayoutService) Default(userID uint) (*DashboardView, error) {
+	d, err := s.layoutRepo.FindDefault(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		d = &models.Dashboard{
+			OwnerID:   userID,
+			Name:      "내 대시보드",
+			IsDefault: true,
+			Widgets:   defaultWidgets(),
+		}
+		err = s.layoutRepo.CreateDashboard(d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.view(d, userID), nil
+}
+
+// Get 본인 또는 공유된 대시보드 조회
+func (s *LayoutService) Get(id, userID uint) (*DashboardView, error) {
+	d, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if d.OwnerID != userID && !d.Shared {
+		return nil, ErrDashboardNotFound
+	}
+	return s.view(d, userID), nil
+}
+
+func (s *LayoutService) List(userID uint) ([]models.Dashboard, error) {
+	return s.layoutRepo.ListVisible(userID)
+}
+
+func (s *LayoutService) Create(userID uint, req *models.DashboardRequest) (*models.Dashboard, error) {
+	d := &models.Dashboard{OwnerID: userID, Name: req.Name, Shared: req.Shared}
+	if err := s.layoutRepo.CreateDashboard(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *LayoutService) Update(id, userID uint, req *models.DashboardRequest) (*models.Dashboard, error) {
+	d, err := s.owned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	d.Name = req.Name
+	d.Shared = req.Shared
+	if err := s.layoutRepo.UpdateDashboard(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Delete 기본 대시보드는 삭제할 수 없음 (다음 방문 시 다시 생성되는 것을 막기 위해)
+func (s *LayoutService) Delete(id, userID uint) error {
+	d, err := s.owned(id, userID)
+	if err != nil {
+		return err
+	}
+	if d.IsDefault {
+		return ErrForbidden
+	}
+	return s.layoutRepo.DeleteDashboard(id)
+}
+
+func (s *LayoutService) AddWidget(dashboardID, userID uint, req *models.WidgetRequest) (*models.Widget, error) {
+	if _, err := s.owned(dashboardID, userID); err != nil {
+		return nil, err
+	}
+	w := &models.Widget{DashboardID: dashboardID}
+	if err := applyWidgetRequest(w, req); err != nil {
+		return nil, err
+	}
+	if err := s.layoutRepo.CreateWidget(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *LayoutService) UpdateWidget(dashboardID, widgetID, userID uint, req *models.WidgetRequest) (*models.Widget, error) {
+	if _, err := s.owned(dashboardID, userID); err != nil {
+		return nil, err
+	}
+	w, err := s.layoutRepo.FindWidget(dashboardID, widgetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWidgetNotFound
+		}
+		return nil, err
+	}
+	if err := applyWidgetRequest(w, req); err != nil {
+		return nil, err
+	}
+	if err := s.layoutRepo.UpdateWidget(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *LayoutService) RemoveWidget(dashboardID, widgetID, userID uint) error {
+	if _, err := s.owned(dashboardID, userID); err != nil {
+		return err
+	}
+	return s.layoutRepo.DeleteWidget(dashboardID, widgetID)
+}
+
+// Reorder 드래그 앤 드롭 결과 저장
+func (s *LayoutService) Reorder(dashboardID, userID uint, widgetIDs []uint) error {
+	if _, err := s.owned(dashboardID, userID); err != nil {
+		return err
+	}
+	if err := s.layoutRepo.UpdatePositions(dashboardID, widgetIDs); err != nil {
+		if errors.Is(err, repository.ErrWidgetMismatch) {
+			return ErrWidgetNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *LayoutService) find(id uint) (*models.Dashboard, error) {
+	d, err := s.layoutRepo.FindDashboard(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDashboardNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// owned 수정 권한 확인. 공유 대시보드도 소유자만 수정 가능
+func (s *LayoutService) owned(id, userID uint) (*models.Dashboard, error) {
+	d, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if d.OwnerID != userID {
+		if d.Shared {
+			return nil, ErrForbidden
+		}
+		return nil, ErrDashboardNotFound
+	}
+	return d, nil
+}
+
+func (s *LayoutService) view(d *models.Dashboard, userID uint) *DashboardView {
+	v := &DashboardView{
+		Dashboard: *d,
+		Widgets:   make([]WidgetView, len(d.Widgets)),
+		CanEdit:   d.OwnerID == userID,
+	}
+	for i, w := range d.Widgets {
+		v.Widgets[i] = WidgetView{Widget: w, ChartURL: WidgetChartURL(w)}
+	}
+	return v
+}
+
+// WidgetChartURL 위젯 설정을 범용 차트 엔드포인트 파라미터로 변환
+func WidgetChartURL(w models.Widget) string {
+	return "/dashboard/charts/" + url.PathEscape(w.DataSource) + "?" + WidgetChartQuery(w).Encode()
+}
+
+// WidgetChartQuery 위젯에 저장된 차트 종류와 옵션 (기간 조건 제외)
+func WidgetChartQuery(w models.Widget) url.Values {
+	var opts models.WidgetOptions
+	_ = w.Options.Decode(&opts)
+
+	q := url.Values{}
+	q.Set("type", w.Type)
+	set := func(key, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	set("label", opts.Label)
+	set("unit", opts.Unit)
+	set("suffix", opts.Suffix)
+	set("legend", opts.Legend)
+	set("series", opts.Series)
+	set("bucket", opts.Bucket)
+	set("agg", opts.Agg)
+	set("forecast", opts.Forecast)
+	set("group_by", opts.GroupBy)
+	set("tags", opts.Tags)
+	set("split", opts.Split)
+	set("fill", opts.Fill)
+	if opts.Max > 0 {
+		q.Set("max", strconv.FormatFloat(opts.Max, 'f', -1, 64))
+	}
+	if w.DashboardID != 0 {
+		q.Set("dashboard", strconv.FormatUint(uint64(w.DashboardID), 10))
+	}
+	return q
+}
+
+// ValidateWidget 위젯 설정 검증. 옵션 JSON은 알려진 키만 허용
+func ValidateWidget(req *models.WidgetRequest) error {
+	fields := map[string]string{}
+
+	if _, err := LookupChartRenderer(req.Type); err != nil {
+		fields["type"] = "지원하지 않는 차트 종류입니다."
+	}
+	if req.DataSource == "" || utf8.RuneCountInString(req.DataSource) > 50 || strings.ContainsAny(req.DataSource, "/?#") {
+		fields["data_source"] = "데이터 소스가 올바르지 않습니다."
+	}
+	if strings.TrimSpace(req.Title) == "" || utf8.RuneCountInString(req.Title) > 100 {
+		fields["title"] = "제목은 1~100자여야 합니다."
+	}
+	if utf8.RuneCountInString(req.Subtitle) > 200 {
+		fields["subtitle"] = "부제목은 200자 이하여야 합니다."
+	}
+	if req.Width < 1 || req.Width > maxWidgetWidth {
+		fields["width"] = "너비는 1~4여야 합니다."
+	}
+	if req.Height < 1 || req.Height > maxWidgetHeight {
+		fields["height"] = "높이는 1~3이어야 합니다."
+	}
+	if msg := validateWidgetOptions(req.Options); msg != "" {
+		fields["options"] = msg
+	}
+
+	if len(fields) > 0 {
+		return &WidgetValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateWidgetOptions(raw models.JSON) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var opts models.WidgetOptions
+	if err := dec.Decode(&opts); err != nil {
+		return "옵션 형식이 올바르지 않습니다."
+	}
+
+	switch opts.Legend {
+	case "", "none", "top", "side":
+	default:
+		return "범례 위치가 올바르지 않습니다."
+	}
+	switch SeriesMode(opts.Series) {
+	case SeriesAuto, SeriesTime, SeriesLabel:
+	default:
+		return "시리즈 방식이 올바르지 않습니다."
+	}
+	if opts.Bucket != "" && !repository.Bucket(opts.Bucket).Valid() {
+		return "집계 단위가 올바르지 않습니다."
+	}
+	if opts.Agg != "" && !repository.Aggregation(opts.Agg).Valid() {
+		return "집계 함수가 올바르지 않습니다."
+	}
+	if _, err := ParseForecastOptions(opts.Forecast, ""); err != nil {
+		return "예측 모델이 올바르지 않습니다."
+	}
+	if _, err := ParseGroupBy(opts.GroupBy); err != nil {
+		return "그룹 기준 태그 이름이 올바르지 않습니다."
+	}
+	if _, err := ParseTagFilter(opts.Tags); err != nil {
+		return "태그 필터는 이름:값을 쉼표로 이어 적어야 합니다."
+	}
+	switch {
+	case opts.Split != "" && opts.Split != "label":
+		return "계열 나누기 방식이 올바르지 않습니다."
+	case opts.Split != "" && opts.GroupBy != "":
+		return "라벨별 나누기와 태그별 나누기는 함께 쓸 수 없습니다."
+	}
+	if _, err := ParseFillMode(opts.Fill); err != nil {
+		return "빈 구간 채우기 방식이 올바르지 않습니다."
+	}
+	if opts.Max < 0 {
+		return "최댓값은 0보다 커야 합니다."
+	}
+	if utf8.RuneCountInString(opts.Label) > 50 || utf8.RuneCountInString(opts.Unit) > 5 || utf8.RuneCountInString(opts.Suffix) > 5 {
+		return "옵션 값이 너무 깁니다."
+	}
+	return ""
+}
+
+func applyWidgetRequest(w *models.Widget, req *models.WidgetRequest) error {
+	if err := ValidateWidget(req); err != nil {
+		return err
+	}
+	w.Type = req.Type
+	w.DataSource = req.DataSource
+	w.Title = strings.TrimSpace(req.Title)
+	w.Subtitle = req.Subtitle
+	w.Width = req.Width
+	w.Height = req.Height
+	w.Options = req.Options
+	return nil
+}
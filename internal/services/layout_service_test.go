@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWidget(t *testing.T) {
+	valid := models.WidgetRequest{
+		Type: "bar", DataSource: "sales", Title: "매출", Width: 2, Height: 2,
+		Options: models.JSON(`{"label":"매출","legend":"top","bucket":"week","agg":"avg"}`),
+	}
+	assert.NoError(t, ValidateWidget(&valid))
+
+	req := valid
+	req.Type = "radar"
+	req.DataSource = "a/b"
+	req.Title = " "
+	req.Width = 5
+	req.Height = 0
+	req.Options = models.JSON(`{"color":"red"}`)
+
+	var verr *WidgetValidationError
+	require.ErrorAs(t, ValidateWidget(&req), &verr)
+	for _, field := range []string{"type", "data_source", "title", "width", "height", "options"} {
+		assert.Contains(t, verr.Fields, field)
+	}
+
+	req = valid
+	req.Options = models.JSON(`{"bucket":"year"}`)
+	require.ErrorAs(t, ValidateWidget(&req), &verr)
+	assert.Equal(t, map[string]string{"options": "집계 단위가 올바르지 않습니다."}, verr.Fields)
+
+	req.Options = models.JSON(`{"forecast":"arima"}`)
+	require.ErrorAs(t, ValidateWidget(&req), &verr)
+	assert.Equal(t, map[string]string{"options": "예측 모델이 올바르지 않습니다."}, verr.Fields)
+
+	req.Options = models.JSON(`{"tags":"region"}`)
+	require.ErrorAs(t, ValidateWidget(&req), &verr)
+	assert.Equal(t, map[string]string{"options": "태그 필터는 이름:값을 쉼표로 이어 적어야 합니다."}, verr.Fields)
+
+	req.Options = models.JSON(`{"group_by":"region","tags":"channel:web"}`)
+	assert.NoError(t, ValidateWidget(&req))
+
+	req.Options = models.JSON(`{"group_by":"region","split":"label"}`)
+	require.ErrorAs(t, ValidateWidget(&req), &verr)
+	assert.Equal(t, map[string]string{"options": "라벨별 나누기와 태그별 나누기는 함께 쓸 수 없습니다."}, verr.Fields)
+
+	req.Options = models.JSON(`{"split":"label","fill":"previous"}`)
+	assert.NoError(t, ValidateWidget(&req))
+}
+
+func TestWidgetChartURL(t *testing.T) {
+	w := models.Widget{
+		Type: "gauge", DataSource: "traffic",
+		Options: models.JSON(`{"label":"트래픽","suffix":"%","max":200}`),
+	}
+	assert.Equal(t, "/dashboard/charts/traffic?label=%ED%8A%B8%EB%9E%98%ED%94%BD&max=200&suffix=%25&type=gauge", WidgetChartURL(w))
+
+	// 옵션이 없으면 type만 전달
+	assert.Equal(t, "/dashboard/charts/sales?type=line", WidgetChartURL(models.Widget{Type: "line", DataSource: "sales"}))
+	assert.Equal(t, "/dashboard/charts/sales?forecast=auto&type=line",
+		WidgetChartURL(models.Widget{Type: "line", DataSource: "sales", Options: models.JSON(`{"forecast":"auto"}`)}))
+	assert.Equal(t, "/dashboard/charts/sales?group_by=region&tags=channel%3Aweb&type=line",
+		WidgetChartURL(models.Widget{Type: "line", DataSource: "sales", Options: models.JSON(`{"group_by":"region","tags":"channel:web"}`)}))
+}
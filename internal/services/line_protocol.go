@@ -0,0 +1,382 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/baltop/commet/internal/models"
+)
+
+var ErrInvalidMetricLine = errors.New("invalid metric line")
+
+const (
+	// 집계 중인 계열(카테고리·라벨) 최대 수. 넘는 새 계열은 버림
+	maxLineSeries = 10000
+	// 타이머 계열 하나가 백분위 계산용으로 보관하는 최대 값 수 (건수·합계·최소·최대는 모두 반영)
+	maxTimerSamples = 10000
+)
+
+// timerStats 타이머 계열마다 "<이름>.<통계>" 카테고리로 저장하는 값
+var timerStats = []string{"count", "mean", "min", "max", "p50", "p95", "p99"}
+
+type lineKey struct {
+	category string
+	label    string
+}
+
+type timerAgg struct {
+	// count는 샘플링 비율을 반영한 건수, received는 실제로 받은 값 수
+	count, sum, min, max float64
+	received             int
+	samples              []float64
+}
+
+// lineAggregator StatsD·InfluxDB 라인 프로토콜 값을 flush까지 메모리에 모음.
+//
+//	카운터(c): 구간 합계 (샘플링 비율 @r이면 1/r배)
+//	게이지(g), Influx 필드: 구간의 마지막 값 (+/-로 시작하는 StatsD 게이지는 이전 값에 더함)
+//	타이머(ms, h, d): 건수·평균·최소·최대·p50·p95·p99
+//	집합(s): 서로 다른 값의 수
+type lineAggregator struct {
+	counters map[lineKey]float64
+	// gauges는 증감(+/-)을 위해 flush 후에도 값을 유지하고 dirty에 있는 것만 저장
+	gauges  map[lineKey]float64
+	dirty   map[lineKey]bool
+	timers  map[lineKey]*timerAgg
+	sets    map[lineKey]map[string]struct{}
+	series  int
+	dropped int
+}
+
+func newLineAggregator() *lineAggregator {
+	a := &lineAggregator{gauges: map[lineKey]float64{}}
+	a.reset()
+	return a
+}
+
+func (a *lineAggregator) reset() {
+	a.counters = map[lineKey]float64{}
+	a.dirty = map[lineKey]bool{}
+	a.timers = map[lineKey]*timerAgg{}
+	a.sets = map[lineKey]map[string]struct{}{}
+	a.series = 0
+	a.dropped = 0
+}
+
+// AddLines 줄바꿈으로 나뉜 여러 줄을 더함. 잘못된 줄은 세기만 하고 건너뜀
+func (a *lineAggregator) AddLines(data string) {
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := a.Add(line); err != nil {
+			a.dropped++
+		}
+	}
+}
+
+// Add 한 줄을 형식에 맞게 해석해 더함. 이름 앞부분에 ':'가 먼저 나오면 StatsD, 아니면 Influx
+func (a *lineAggregator) Add(line string) error {
+	if i := strings.IndexAny(line, ": ,="); i > 0 && line[i] == ':' {
+		return a.addStatsD(line)
+	}
+	return a.addInflux(line)
+}
+
+// addStatsD <이름>:<값>|<종류>[|@<비율>][|#<태그>:<값>,...]
+func (a *lineAggregator) addStatsD(line string) error {
+	name, rest, _ := strings.Cut(line, ":")
+	parts := strings.Split(rest, "|")
+	if len(parts) < 2 {
+		return ErrInvalidMetricLine
+	}
+	raw, kind := parts[0], parts[1]
+	rate := 1.0
+	tags := map[string]string{}
+	for _, p := range parts[2:] {
+		switch {
+		case strings.HasPrefix(p, "@"):
+			r, err := strconv.ParseFloat(p[1:], 64)
+			if err != nil || r <= 0 || r > 1 {
+				return ErrInvalidMetricLine
+			}
+			rate = r
+		case strings.HasPrefix(p, "#"):
+			for _, tag := range strings.Split(p[1:], ",") {
+				k, v, _ := strings.Cut(tag, ":")
+				tags[k] = v
+			}
+		}
+	}
+	key, err := lineSeriesKey(name, tags)
+	if err != nil {
+		return err
+	}
+
+	if kind == "s" {
+		return a.addSet(key, raw)
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return ErrInvalidMetricLine
+	}
+	switch kind {
+	case "c":
+		return a.addCounter(key, value/rate)
+	case "g":
+		if raw[0] == '+' || raw[0] == '-' {
+			return a.setGauge(key, a.gauges[key]+value)
+		}
+		return a.setGauge(key, value)
+	case "ms", "h", "d":
+		return a.addTimer(key, value, 1/rate)
+	}
+	return ErrInvalidMetricLine
+}
+
+// addInflux <측정>[,<태그>=<값>...] <필드>=<값>[,...] [<시각>]. 필드마다 게이지 하나 ("value" 필드는 측정 이름 그대로).
+// 시각은 무시하고 flush 시각으로 저장
+func (a *lineAggregator) addInflux(line string) error {
+	sections := splitUnescaped(line, ' ')
+	if len(sections) < 2 || len(sections) > 3 {
+		return ErrInvalidMetricLine
+	}
+	head := splitUnescaped(sections[0], ',')
+	measurement := unescapeLine(head[0])
+	tags := map[string]string{}
+	for _, tag := range head[1:] {
+		kv := splitUnescaped(tag, '=')
+		if len(kv) != 2 {
+			return ErrInvalidMetricLine
+		}
+		tags[unescapeLine(kv[0])] = unescapeLine(kv[1])
+	}
+
+	for _, field := range splitUnescaped(sections[1], ',') {
+		kv := splitUnescaped(field, '=')
+		if len(kv) != 2 {
+			return ErrInvalidMetricLine
+		}
+		value, ok, err := parseInfluxValue(kv[1])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		name := measurement
+		if f := unescapeLine(kv[0]); f != "value" {
+			name += "." + f
+		}
+		key, err := lineSeriesKey(name, tags)
+		if err != nil {
+			return err
+		}
+		if err := a.setGauge(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseInfluxValue 실수, 정수(i), 부호 없는 정수(u), 불리언(1/0). 문자열 필드는 ok=false
+func parseInfluxValue(raw string) (value float64, ok bool, err error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		return 0, false, nil
+	case raw == "t" || raw == "T" || raw == "true" || raw == "True" || raw == "TRUE":
+		return 1, true, nil
+	case raw == "f" || raw == "F" || raw == "false" || raw == "False" || raw == "FALSE":
+		return 0, true, nil
+	case strings.HasSuffix(raw, "i") || strings.HasSuffix(raw, "u"):
+		raw = raw[:len(raw)-1]
+	}
+	value, err = strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false, ErrInvalidMetricLine
+	}
+	return value, true, nil
+}
+
+// lineSeriesKey 이름이 카테고리, 태그 값을 태그 이름 순으로 ','로 이은 것이 라벨
+func lineSeriesKey(name string, tags map[string]string) (lineKey, error) {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = tags[k]
+	}
+	key := lineKey{category: name, label: strings.Join(values, ",")}
+	if name == "" || utf8.RuneCountInString(name) > 50 || utf8.RuneCountInString(key.label) > 100 {
+		return key, fmt.Errorf("%w: name or tags too long", ErrInvalidMetricLine)
+	}
+	return key, nil
+}
+
+// track 처음 보는 계열이면 수를 세고, 최대 수를 넘으면 거절
+func (a *lineAggregator) track(exists bool) error {
+	if exists {
+		return nil
+	}
+	if a.series >= maxLineSeries {
+		return fmt.Errorf("%w: too many series", ErrInvalidMetricLine)
+	}
+	a.series++
+	return nil
+}
+
+func (a *lineAggregator) addCounter(key lineKey, value float64) error {
+	_, ok := a.counters[key]
+	if err := a.track(ok); err != nil {
+		return err
+	}
+	a.counters[key] += value
+	return nil
+}
+
+func (a *lineAggregator) setGauge(key lineKey, value float64) error {
+	if err := a.track(a.dirty[key]); err != nil {
+		return err
+	}
+	// 유지하는 게이지 값도 계열 수를 넘지 않도록
+	if _, ok := a.gauges[key]; !ok && len(a.gauges) >= maxLineSeries {
+		a.series--
+		return fmt.Errorf("%w: too many series", ErrInvalidMetricLine)
+	}
+	a.gauges[key] = value
+	a.dirty[key] = true
+	return nil
+}
+
+func (a *lineAggregator) addTimer(key lineKey, value, weight float64) error {
+	// ".count" 등을 붙여도 카테고리 길이 제한을 넘지 않아야 함
+	if utf8.RuneCountInString(key.category)+len(".count") > 50 {
+		return fmt.Errorf("%w: timer name too long", ErrInvalidMetricLine)
+	}
+	t, ok := a.timers[key]
+	if err := a.track(ok); err != nil {
+		return err
+	}
+	if !ok {
+		t = &timerAgg{min: value, max: value}
+		a.timers[key] = t
+	}
+	t.count += weight
+	t.received++
+	t.sum += value
+	t.min = math.Min(t.min, value)
+	t.max = math.Max(t.max, value)
+	if len(t.samples) < maxTimerSamples {
+		t.samples = append(t.samples, value)
+	}
+	return nil
+}
+
+func (a *lineAggregator) addSet(key lineKey, member string) error {
+	set, ok := a.sets[key]
+	if err := a.track(ok); err != nil {
+		return err
+	}
+	if !ok {
+		set = map[string]struct{}{}
+		a.sets[key] = set
+	}
+	set[member] = struct{}{}
+	return nil
+}
+
+// Flush 모은 값을 at 시각의 데이터 포인트로 바꾸고 비움. dropped는 지난 flush 이후 버린 줄과 저장 범위 밖의 값 수
+func (a *lineAggregator) Flush(at time.Time) (points []models.DataPointRequest, dropped int) {
+	add := func(key lineKey, category string, value float64) {
+		if !storableValue(value) {
+			a.dropped++
+			return
+		}
+		v := value
+		points = append(points, models.DataPointRequest{Category: category, Label: key.label, Value: &v, RecordedAt: &at})
+	}
+	for key, v := range a.counters {
+		add(key, key.category, v)
+	}
+	for key := range a.dirty {
+		add(key, key.category, a.gauges[key])
+	}
+	for key, set := range a.sets {
+		add(key, key.category, float64(len(set)))
+	}
+	for key, t := range a.timers {
+		sort.Float64s(t.samples)
+		values := map[string]float64{
+			"count": t.count,
+			// 샘플링 비율은 건수에만 반영하므로 평균은 받은 값으로 계산
+			"mean": t.sum / float64(t.received),
+			"min":  t.min,
+			"max":  t.max,
+			"p50":  percentile(t.samples, 50),
+			"p95":  percentile(t.samples, 95),
+			"p99":  percentile(t.samples, 99),
+		}
+		for _, stat := range timerStats {
+			add(key, key.category+"."+stat, values[stat])
+		}
+	}
+
+	// 계열 순서가 실행마다 달라지지 않도록 정렬
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Category != points[j].Category {
+			return points[i].Category < points[j].Category
+		}
+		return points[i].Label < points[j].Label
+	})
+	dropped = a.dropped
+	a.reset()
+	return points, dropped
+}
+
+// percentile 정렬된 값의 nearest-rank 백분위
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// splitUnescaped 역슬래시로 이스케이프되지 않고 큰따옴표 밖에 있는 sep로 나눔
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+var lineUnescaper = strings.NewReplacer(`\ `, " ", `\,`, ",", `\=`, "=")
+
+func unescapeLine(s string) string {
+	return lineUnescaper.Replace(s)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flushValues flush 결과를 "카테고리|라벨" → 값으로
+func flushValues(t *testing.T, a *lineAggregator, at time.Time) (map[string]float64, int) {
+	t.Helper()
+	points, dropped := a.Flush(at)
+	values := map[string]float64{}
+	for _, p := range points {
+		require.True(t, at.Equal(*p.RecordedAt))
+		values[p.Category+"|"+p.Label] = *p.Value
+	}
+	return values, dropped
+}
+
+func TestLineAggregatorStatsD(t *testing.T) {
+	a := newLineAggregator()
+	a.AddLines("api.requests:1|c\napi.requests:2|c|@0.5\n\napi.requests:1|c|#host:web-1,env:prod")
+	a.AddLines("queue.depth:10|g\nqueue.depth:+5|g\nqueue.depth:-3|g")
+	a.AddLines("users.online:alice|s\nusers.online:bob|s\nusers.online:alice|s")
+	for i := 1; i <= 100; i++ {
+		a.AddLines(fmt.Sprintf("api.latency:%d|ms", i))
+	}
+	a.AddLines("broken\napi.requests:x|c\napi.requests:1|q\napi.requests:1|c|@2")
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	values, dropped := flushValues(t, a, at)
+	assert.Equal(t, 4, dropped)
+	assert.Equal(t, map[string]float64{
+		"api.requests|":           5,
+		"api.requests|prod,web-1": 1,
+		"queue.depth|":            12,
+		"users.online|":           2,
+		"api.latency.count|":      100,
+		"api.latency.mean|":       50.5,
+		"api.latency.min|":        1,
+		"api.latency.max|":        100,
+		"api.latency.p50|":        50,
+		"api.latency.p95|":        95,
+		"api.latency.p99|":        99,
+	}, values)
+
+	// 다음 구간: 카운터·타이머는 비워지고, 게이지는 바뀐 것만 저장하되 증감은 이전 값 기준
+	values, _ = flushValues(t, a, at.Add(10*time.Second))
+	assert.Empty(t, values)
+	a.AddLines("queue.depth:+1|g")
+	values, _ = flushValues(t, a, at.Add(20*time.Second))
+	assert.Equal(t, map[string]float64{"queue.depth|": 13}, values)
+}
+
+func TestLineAggregatorInflux(t *testing.T) {
+	a := newLineAggregator()
+	a.AddLines(`cpu,host=web-1,region=eu usage_idle=87.5,usage_user=10i,healthy=t 1700000000000000000`)
+	a.AddLines(`cpu,host=web-1,region=eu usage_idle=90`)
+	a.AddLines(`temperature value=21.5,note="front door, left"`)
+	a.AddLines(`disk\ io,path=/var\,log reads=3u`)
+	a.AddLines("cpu usage_idle=abc\ncpu")
+
+	values, dropped := flushValues(t, a, time.Now())
+	assert.Equal(t, 2, dropped)
+	assert.Equal(t, map[string]float64{
+		"cpu.usage_idle|web-1,eu": 90,
+		"cpu.usage_user|web-1,eu": 10,
+		"cpu.healthy|web-1,eu":    1,
+		"temperature|":            21.5,
+		"disk io.reads|/var,log":  3,
+	}, values)
+}
+
+func TestLineAggregatorLimits(t *testing.T) {
+	a := newLineAggregator()
+	for i := 0; i < maxLineSeries+5; i++ {
+		a.AddLines(fmt.Sprintf("c%d:1|c", i))
+	}
+	points, dropped := a.Flush(time.Now())
+	assert.Len(t, points, maxLineSeries)
+	assert.Equal(t, 5, dropped)
+
+	// 타이머 접미사를 붙여도 카테고리 길이 제한(50자)을 넘지 않아야 함
+	long := fmt.Sprintf("%044d", 0)
+	require.NoError(t, a.Add(long+":1|ms"))
+	assert.ErrorIs(t, a.Add(long+"0:1|ms"), ErrInvalidMetricLine)
+	require.NoError(t, a.Add(long+"000000:1|c"))
+	points, _ = a.Flush(time.Now())
+	require.Len(t, points, len(timerStats)+1)
+	for _, p := range points {
+		assert.LessOrEqual(t, len(p.Category), 50)
+	}
+}
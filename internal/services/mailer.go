@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mail 보낼 메일 한 통. HTML이 있으면 텍스트와 함께 multipart/alternative로 보냄
+type Mail struct {
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string           `json:",omitempty"`
+	Attachments []MailAttachment `json:",omitempty"`
+}
+
+// MailAttachment 첨부 파일. ContentID가 있으면 HTML 본문에서 cid:<ContentID>로 참조하는 인라인 이미지
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string `json:",omitempty"`
+	Data        []byte
+}
+
+// Mailer 메일 발송 방식 추상화
+type Mailer interface {
+	Send(m Mail) error
+}
+
+// SMTPMailer SMTP 서버로 발송. User가 비어 있으면 인증 없이 보냄
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+}
+
+func (s *SMTPMailer) Send(m Mail) error {
+	var auth smtp.Auth
+	if s.User != "" {
+		auth = smtp.PlainAuth("", s.User, s.Password, s.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, s.From, m.To, buildMessage(s.From, m, time.Now()))
+}
+
+// LogMailer SMTP 설정이 없을 때 사용. 메일 내용을 로그로만 남김
+type LogMailer struct{}
+
+func (LogMailer) Send(m Mail) error {
+	log.Printf("Mail to %s: %s\n%s", strings.Join(m.To, ", "), m.Subject, m.Text)
+	return nil
+}
+
+// FileMailer 메일을 보내지 않고 Dir에 .eml 파일로 저장 (로컬 테스트용, 메일 클라이언트로 열어 확인)
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (f *FileMailer) Send(m Mail) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102-150405"), randomHex(4))
+	return os.WriteFile(filepath.Join(f.Dir, name), buildMessage(f.From, m, now), 0o644)
+}
+
+// buildMessage RFC 5322 메시지. 한글 제목은 MIME 인코딩, 본문은 base64.
+// HTML이나 첨부가 있으면 multipart/mixed > multipart/alternative 구조이고,
+// 인라인 이미지는 HTML과 함께 multipart/related로 묶음
+func buildMessage(from string, m Mail, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if m.HTML == "" && len(m.Attachments) == 0 {
+		writeBase64Part(&buf, textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}}, []byte(m.Text))
+		return buf.Bytes()
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	var body bytes.Buffer
+	alt := multipart.NewWriter(&body)
+	writeMultipartBase64(alt, textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}}, []byte(m.Text))
+	if m.HTML != "" {
+		html := textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}}
+		var inline []MailAttachment
+		for _, a := range m.Attachments {
+			if a.ContentID != "" {
+				inline = append(inline, a)
+			}
+		}
+		if len(inline) == 0 {
+			writeMultipartBase64(alt, html, []byte(m.HTML))
+		} else {
+			var related bytes.Buffer
+			rel := multipart.NewWriter(&related)
+			writeMultipartBase64(rel, html, []byte(m.HTML))
+			for _, a := range inline {
+				writeAttachment(rel, a, "inline")
+			}
+			rel.Close()
+			part, _ := alt.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/related; boundary=" + rel.Boundary()}})
+			part.Write(related.Bytes())
+		}
+	}
+	alt.Close()
+	part, _ := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + alt.Boundary()}})
+	part.Write(body.Bytes())
+
+	for _, a := range m.Attachments {
+		if a.ContentID == "" {
+			writeAttachment(mixed, a, "attachment")
+		}
+	}
+	mixed.Close()
+	return buf.Bytes()
+}
+
+func writeAttachment(w *multipart.Writer, a MailAttachment, disposition string) {
+	filename := mime.BEncoding.Encode("UTF-8", a.Filename)
+	header := textproto.MIMEHeader{
+		"Content-Type":        {a.ContentType + "; name=\"" + filename + "\""},
+		"Content-Disposition": {disposition + "; filename=\"" + filename + "\""},
+	}
+	if a.ContentID != "" {
+		header.Set("Content-ID", "<"+a.ContentID+">")
+	}
+	writeMultipartBase64(w, header, a.Data)
+}
+
+func writeMultipartBase64(w *multipart.Writer, header textproto.MIMEHeader, data []byte) {
+	header.Set("Content-Transfer-Encoding", "base64")
+	part, _ := w.CreatePart(header)
+	var buf bytes.Buffer
+	writeBase64Lines(&buf, data)
+	part.Write(buf.Bytes())
+}
+
+// writeBase64Part 단일 파트 메시지의 나머지 헤더와 본문
+func writeBase64Part(buf *bytes.Buffer, header textproto.MIMEHeader, data []byte) {
+	fmt.Fprintf(buf, "Content-Type: %s\r\n", header.Get("Content-Type"))
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64Lines(buf, data)
+}
+
+// writeBase64Lines 76자마다 줄바꿈
+func writeBase64Lines(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+// JobSendEmail 메일 한 통을 보내는 작업 (email 큐)
+const JobSendEmail = "email.send"
+
+// QueuedMailer 작업 큐에 넣고 바로 반환. 실제 발송은 백그라운드에서 mailer로 하며 실패하면 재시도
+type QueuedMailer struct {
+	jobs *JobQueue
+}
+
+// NewQueuedMailer jobs의 email 큐에 발송 작업을 등록
+func NewQueuedMailer(jobs *JobQueue, mailer Mailer) *QueuedMailer {
+	jobs.Register(JobSendEmail, "email", HandleJob(func(ctx context.Context, m Mail) error {
+		return mailer.Send(m)
+	}))
+	return &QueuedMailer{jobs: jobs}
+}
+
+func (q *QueuedMailer) Send(m Mail) error {
+	return q.jobs.Enqueue(JobSendEmail, m, EnqueueOptions{})
+}
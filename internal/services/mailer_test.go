@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) {
+	msg := string(buildMessage("commet@example.com", Mail{To: []string{"a@example.com"}, Subject: "알림", Text: "본문"}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Contains(t, msg, "Subject: =?UTF-8?b?7JWM66a8?=\r\n")
+	assert.Contains(t, msg, "To: a@example.com\r\n")
+	assert.Contains(t, msg, "\r\n\r\n67O466y4\r\n")
+}
+
+func TestBuildMessageMultipart(t *testing.T) {
+	raw := buildMessage("commet@example.com", Mail{
+		To:          []string{"a@example.com"},
+		Subject:     "보고서",
+		Text:        "본문",
+		HTML:        "<p>본문</p>",
+		Attachments: []MailAttachment{{Filename: "report.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")}},
+	}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	body, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(body.Header.Get("Content-Type"), "multipart/alternative"))
+
+	attachment, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", attachment.FileName())
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, attachment))
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4", string(data))
+}
+
+func TestBuildMessageInlineImage(t *testing.T) {
+	raw := buildMessage("commet@example.com", Mail{
+		To:          []string{"a@example.com"},
+		Subject:     "보고서",
+		Text:        "본문",
+		HTML:        `<img src="cid:chart-1@commet">`,
+		Attachments: []MailAttachment{{Filename: "chart-1.png", ContentType: "image/png", ContentID: "chart-1@commet", Data: []byte("png")}},
+	}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	body, err := multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+	require.NoError(t, err)
+	_, params, err = mime.ParseMediaType(body.Header.Get("Content-Type"))
+	require.NoError(t, err)
+
+	// alternative 안에 text/plain, multipart/related(HTML + 이미지) 순서
+	alt := multipart.NewReader(body, params["boundary"])
+	_, err = alt.NextPart()
+	require.NoError(t, err)
+	related, err := alt.NextPart()
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(related.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/related", mediaType)
+
+	rel := multipart.NewReader(related, params["boundary"])
+	html, err := rel.NextPart()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(html.Header.Get("Content-Type"), "text/html"))
+	image, err := rel.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "<chart-1@commet>", image.Header.Get("Content-ID"))
+	assert.True(t, strings.HasPrefix(image.Header.Get("Content-Disposition"), "inline"))
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// UDP 패킷 최대 크기
+	maxMetricPacket = 64 << 10
+	// TCP 한 줄 최대 길이
+	maxMetricLine = 64 << 10
+)
+
+// MetricsListenerOptions 라인 프로토콜 수신 설정. 주소가 빈 프로토콜은 열지 않음
+type MetricsListenerOptions struct {
+	UDPAddr       string
+	TCPAddr       string
+	FlushInterval time.Duration
+}
+
+// MetricsListener UDP/TCP로 StatsD·InfluxDB 라인 프로토콜을 받아 FlushInterval마다 집계값을 저장
+type MetricsListener struct {
+	dashboardService *DashboardService
+	opts             MetricsListenerOptions
+
+	udp *net.UDPConn
+	tcp net.Listener
+
+	mu     sync.Mutex
+	agg    *lineAggregator
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+func NewMetricsListener(dashboardService *DashboardService, opts MetricsListenerOptions) *MetricsListener {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 10 * time.Second
+	}
+	return &MetricsListener{
+		dashboardService: dashboardService,
+		opts:             opts,
+		agg:              newLineAggregator(),
+		conns:            map[net.Conn]struct{}{},
+	}
+}
+
+// Listen 포트를 엶. 서버 시작 전에 호출해 주소 오류를 바로 알림
+func (l *MetricsListener) Listen() error {
+	if l.opts.UDPAddr != "" {
+		addr, err := net.ResolveUDPAddr("udp", l.opts.UDPAddr)
+		if err != nil {
+			return err
+		}
+		if l.udp, err = net.ListenUDP("udp", addr); err != nil {
+			return err
+		}
+		log.Printf("Metrics listener on udp %s", l.udp.LocalAddr())
+	}
+	if l.opts.TCPAddr != "" {
+		tcp, err := net.Listen("tcp", l.opts.TCPAddr)
+		if err != nil {
+			if l.udp != nil {
+				l.udp.Close()
+			}
+			return err
+		}
+		l.tcp = tcp
+		log.Printf("Metrics listener on tcp %s", tcp.Addr())
+	}
+	return nil
+}
+
+// Run ctx가 취소될 때까지 받고 flush. 종료 시 포트와 연결을 닫고 남은 값을 한 번 더 저장
+func (l *MetricsListener) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if l.udp != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.serveUDP()
+		}()
+	}
+	if l.tcp != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.serveTCP(&wg)
+		}()
+	}
+
+	ticker := time.NewTicker(l.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.flush()
+		case <-ctx.Done():
+			l.close()
+			wg.Wait()
+			l.flush()
+			return
+		}
+	}
+}
+
+func (l *MetricsListener) close() {
+	if l.udp != nil {
+		l.udp.Close()
+	}
+	if l.tcp != nil {
+		l.tcp.Close()
+	}
+	l.mu.Lock()
+	l.closed = true
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+}
+
+func (l *MetricsListener) serveUDP() {
+	buf := make([]byte, maxMetricPacket)
+	for {
+		n, _, err := l.udp.ReadFromUDP(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Metrics listener udp: %v", err)
+			}
+			return
+		}
+		l.add(string(buf[:n]))
+	}
+}
+
+// serveTCP 연결마다 줄 단위로 읽음. 연결 고루틴도 wg로 기다림
+func (l *MetricsListener) serveTCP(wg *sync.WaitGroup) {
+	for {
+		conn, err := l.tcp.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			// 파일 디스크립터 부족 등 일시적인 오류는 잠시 뒤 다시 받음
+			log.Printf("Metrics listener tcp: %v", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		// 닫는 중에 받은 연결은 바로 닫음 (close가 이미 연결 목록을 돌았을 수 있음)
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		l.conns[conn] = struct{}{}
+		l.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				l.mu.Lock()
+				delete(l.conns, conn)
+				l.mu.Unlock()
+				conn.Close()
+			}()
+			scanner := bufio.NewScanner(conn)
+			scanner.Buffer(make([]byte, 4096), maxMetricLine)
+			for scanner.Scan() {
+				l.add(scanner.Text())
+			}
+		}()
+	}
+}
+
+func (l *MetricsListener) add(data string) {
+	l.mu.Lock()
+	l.agg.AddLines(data)
+	l.mu.Unlock()
+}
+
+// flush 모은 값을 저장. 저장에 실패한 구간은 다시 보내지 않고 버림 (StatsD와 같음)
+func (l *MetricsListener) flush() {
+	l.mu.Lock()
+	points, dropped := l.agg.Flush(time.Now())
+	l.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("Metrics listener: dropped %d invalid lines or values", dropped)
+	}
+	if len(points) == 0 {
+		return
+	}
+	if _, err := l.dashboardService.Ingest(points); err != nil {
+		log.Printf("Metrics listener: failed to store %d points: %v", len(points), err)
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+)
+
+// 알림 목록에 보여 줄 최근 알림 수
+const notificationListLimit = 20
+
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo}
+}
+
+func (s *NotificationService) Recent(userID uint) ([]models.Notification, error) {
+	return s.notificationRepo.ListRecent(userID, notificationListLimit)
+}
+
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	return s.notificationRepo.MarkAllRead(userID, time.Now())
+}
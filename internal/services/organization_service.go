@@ -0,0 +1,31 @@
+package services
+
+import (
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+)
+
+type OrganizationService struct {
+	orgRepo *repository.OrganizationRepository
+}
+
+func NewOrganizationService(orgRepo *repository.OrganizationRepository) *OrganizationService {
+	return &OrganizationService{orgRepo: orgRepo}
+}
+
+// Current 사용자가 처음 속한 조직. 없으면 개인 조직을 만들어 반환
+func (s *OrganizationService) Current(userID uint, userName string) (*models.Organization, error) {
+	orgs, err := s.orgRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orgs) > 0 {
+		return &orgs[0], nil
+	}
+
+	org := &models.Organization{Name: userName + "의 조직"}
+	if err := s.orgRepo.CreateWithOwner(org, userID); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
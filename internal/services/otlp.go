@@ -0,0 +1,681 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/baltop/commet/internal/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var ErrInvalidOTLP = errors.New("invalid OTLP metrics request")
+
+// OTLPFormat OTLP/HTTP 본문 인코딩
+type OTLPFormat string
+
+const (
+	OTLPProtobuf OTLPFormat = "protobuf"
+	OTLPJSON     OTLPFormat = "json"
+)
+
+const (
+	// 데이터 포인트 flags: 값이 없는 점 (FLAG_NO_RECORDED_VALUE)
+	otlpNoRecordedValue = 1
+	// 리소스 속성에서 태그로 옮기는 최대 수와 길이 (DataPointRequest와 같음)
+	maxDataTags     = 20
+	maxDataTagKey   = 100
+	maxDataTagValue = 200
+)
+
+// otlpFloat OTLP/JSON의 double. 숫자 또는 "NaN", "Infinity", "-Infinity"
+type otlpFloat float64
+
+func (f *otlpFloat) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var v float64
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+		*f = otlpFloat(v)
+		return nil
+	}
+	switch s {
+	case "NaN":
+		*f = otlpFloat(math.NaN())
+	case "Infinity":
+		*f = otlpFloat(math.Inf(1))
+	case "-Infinity":
+		*f = otlpFloat(math.Inf(-1))
+	default:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		*f = otlpFloat(v)
+	}
+	return nil
+}
+
+// otlpInt OTLP/JSON의 64비트 정수. 10진수 문자열 (숫자도 받음)
+type otlpInt int64
+
+func (i *otlpInt) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var v int64
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+		*i = otlpInt(v)
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = otlpInt(v)
+	return nil
+}
+
+// ExportMetricsServiceRequest 중 쓰는 필드만. JSON 필드 이름은 OTLP/JSON(lowerCamelCase)
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpScopeMetrics struct {
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpMetric struct {
+	Name      string             `json:"name"`
+	Gauge     *otlpNumberData    `json:"gauge"`
+	Sum       *otlpNumberData    `json:"sum"`
+	Histogram *otlpHistogramData `json:"histogram"`
+	// 지원하지 않는 종류는 점 수만 셈
+	ExponentialHistogram *otlpOtherData `json:"exponentialHistogram"`
+	Summary              *otlpOtherData `json:"summary"`
+}
+
+// otlpNumberData 게이지와 합계. 합계의 누적/델타 방식은 구분하지 않고 받은 값을 그대로 저장
+type otlpNumberData struct {
+	DataPoints []otlpNumberPoint `json:"dataPoints"`
+}
+
+type otlpNumberPoint struct {
+	Attributes   []otlpKeyValue `json:"attributes"`
+	TimeUnixNano otlpInt        `json:"timeUnixNano"`
+	AsDouble     *otlpFloat     `json:"asDouble"`
+	AsInt        *otlpInt       `json:"asInt"`
+	Flags        uint32         `json:"flags"`
+}
+
+type otlpHistogramData struct {
+	DataPoints []otlpHistogramPoint `json:"dataPoints"`
+}
+
+type otlpHistogramPoint struct {
+	Attributes     []otlpKeyValue `json:"attributes"`
+	TimeUnixNano   otlpInt        `json:"timeUnixNano"`
+	Count          otlpInt        `json:"count"`
+	Sum            *otlpFloat     `json:"sum"`
+	BucketCounts   []otlpInt      `json:"bucketCounts"`
+	ExplicitBounds []otlpFloat    `json:"explicitBounds"`
+	Min            *otlpFloat     `json:"min"`
+	Max            *otlpFloat     `json:"max"`
+	Flags          uint32         `json:"flags"`
+}
+
+type otlpOtherData struct {
+	DataPoints []struct{} `json:"dataPoints"`
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string    `json:"stringValue"`
+		BoolValue   *bool      `json:"boolValue"`
+		IntValue    *otlpInt   `json:"intValue"`
+		DoubleValue *otlpFloat `json:"doubleValue"`
+	} `json:"value"`
+}
+
+// otlpAttributes 문자열로 나타낼 수 있는 속성만 (배열, 키·값 목록, 바이트는 건너뜀)
+func otlpAttributes(kvs []otlpKeyValue) map[string]string {
+	attrs := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		v := kv.Value
+		switch {
+		case v.StringValue != nil:
+			attrs[kv.Key] = *v.StringValue
+		case v.BoolValue != nil:
+			attrs[kv.Key] = strconv.FormatBool(*v.BoolValue)
+		case v.IntValue != nil:
+			attrs[kv.Key] = strconv.FormatInt(int64(*v.IntValue), 10)
+		case v.DoubleValue != nil:
+			attrs[kv.Key] = strconv.FormatFloat(float64(*v.DoubleValue), 'g', -1, 64)
+		}
+	}
+	return attrs
+}
+
+// decodeOTLP 본문을 형식에 맞게 해석
+func decodeOTLP(body []byte, format OTLPFormat) (*otlpRequest, error) {
+	req := &otlpRequest{}
+	var err error
+	if format == OTLPJSON {
+		err = json.Unmarshal(body, req)
+	} else {
+		err = consumeMessage(body, func(num protowire.Number, typ protowire.Type, v []byte) error {
+			if num != 1 || typ != protowire.BytesType {
+				return nil
+			}
+			rm, err := decodeOTLPResourceMetrics(v)
+			req.ResourceMetrics = append(req.ResourceMetrics, rm)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOTLP, err)
+	}
+	return req, nil
+}
+
+// protobuf 필드 값 읽기 (consumeMessage가 넘긴 원본 바이트에서)
+func pbFixed64(v []byte) uint64 {
+	x, _ := protowire.ConsumeFixed64(v)
+	return x
+}
+
+func pbVarint(v []byte) uint64 {
+	x, _ := protowire.ConsumeVarint(v)
+	return x
+}
+
+// pbRepeatedFixed64 packed(길이 구분) 또는 하나씩 온 fixed64 반복 필드
+func pbRepeatedFixed64(typ protowire.Type, v []byte) ([]uint64, error) {
+	if typ == protowire.Fixed64Type {
+		return []uint64{pbFixed64(v)}, nil
+	}
+	if typ != protowire.BytesType || len(v)%8 != 0 {
+		return nil, errors.New("malformed packed fixed64")
+	}
+	out := make([]uint64, 0, len(v)/8)
+	for ; len(v) > 0; v = v[8:] {
+		out = append(out, pbFixed64(v))
+	}
+	return out, nil
+}
+
+// ResourceMetrics { Resource resource = 1; repeated ScopeMetrics scope_metrics = 2; }
+func decodeOTLPResourceMetrics(b []byte) (otlpResourceMetrics, error) {
+	var rm otlpResourceMetrics
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1: // Resource { repeated KeyValue attributes = 1; }
+			return consumeMessage(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if num != 1 || typ != protowire.BytesType {
+					return nil
+				}
+				kv, err := decodeOTLPKeyValue(v)
+				rm.Resource.Attributes = append(rm.Resource.Attributes, kv)
+				return err
+			})
+		case 2: // ScopeMetrics { repeated Metric metrics = 2; }
+			var metrics []otlpMetric
+			err := consumeMessage(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if num != 2 || typ != protowire.BytesType {
+					return nil
+				}
+				m, err := decodeOTLPMetric(v)
+				metrics = append(metrics, m)
+				return err
+			})
+			rm.ScopeMetrics = append(rm.ScopeMetrics, otlpScopeMetrics{Metrics: metrics})
+			return err
+		}
+		return nil
+	})
+	return rm, err
+}
+
+// Metric { string name = 1; Gauge gauge = 5; Sum sum = 7; Histogram histogram = 9;
+// ExponentialHistogram exponential_histogram = 10; Summary summary = 11; }
+func decodeOTLPMetric(b []byte) (otlpMetric, error) {
+	var m otlpMetric
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		var err error
+		switch num {
+		case 1:
+			m.Name = string(v)
+		case 5:
+			m.Gauge, err = decodeOTLPNumberData(v)
+		case 7:
+			m.Sum, err = decodeOTLPNumberData(v)
+		case 9:
+			m.Histogram, err = decodeOTLPHistogramData(v)
+		case 10, 11:
+			other := &otlpOtherData{}
+			err = consumeMessage(v, func(num protowire.Number, typ protowire.Type, _ []byte) error {
+				if num == 1 && typ == protowire.BytesType {
+					other.DataPoints = append(other.DataPoints, struct{}{})
+				}
+				return nil
+			})
+			if num == 10 {
+				m.ExponentialHistogram = other
+			} else {
+				m.Summary = other
+			}
+		}
+		return err
+	})
+	return m, err
+}
+
+// Gauge, Sum { repeated NumberDataPoint data_points = 1; ... }
+func decodeOTLPNumberData(b []byte) (*otlpNumberData, error) {
+	d := &otlpNumberData{}
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		p, err := decodeOTLPNumberPoint(v)
+		d.DataPoints = append(d.DataPoints, p)
+		return err
+	})
+	return d, err
+}
+
+// NumberDataPoint { fixed64 time_unix_nano = 3; double as_double = 4; sfixed64 as_int = 6;
+// repeated KeyValue attributes = 7; uint32 flags = 8; }
+func decodeOTLPNumberPoint(b []byte) (otlpNumberPoint, error) {
+	var p otlpNumberPoint
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 3 && typ == protowire.Fixed64Type:
+			p.TimeUnixNano = otlpInt(pbFixed64(v))
+		case num == 4 && typ == protowire.Fixed64Type:
+			f := otlpFloat(math.Float64frombits(pbFixed64(v)))
+			p.AsDouble = &f
+		case num == 6 && typ == protowire.Fixed64Type:
+			i := otlpInt(pbFixed64(v))
+			p.AsInt = &i
+		case num == 7 && typ == protowire.BytesType:
+			kv, err := decodeOTLPKeyValue(v)
+			p.Attributes = append(p.Attributes, kv)
+			return err
+		case num == 8 && typ == protowire.VarintType:
+			p.Flags = uint32(pbVarint(v))
+		}
+		return nil
+	})
+	return p, err
+}
+
+// Histogram { repeated HistogramDataPoint data_points = 1; ... }
+func decodeOTLPHistogramData(b []byte) (*otlpHistogramData, error) {
+	d := &otlpHistogramData{}
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		p, err := decodeOTLPHistogramPoint(v)
+		d.DataPoints = append(d.DataPoints, p)
+		return err
+	})
+	return d, err
+}
+
+// HistogramDataPoint { fixed64 time_unix_nano = 3; fixed64 count = 4; optional double sum = 5;
+// repeated fixed64 bucket_counts = 6; repeated double explicit_bounds = 7; repeated KeyValue attributes = 9;
+// uint32 flags = 10; optional double min = 11; optional double max = 12; }
+func decodeOTLPHistogramPoint(b []byte) (otlpHistogramPoint, error) {
+	var p otlpHistogramPoint
+	float := func(v []byte) *otlpFloat {
+		f := otlpFloat(math.Float64frombits(pbFixed64(v)))
+		return &f
+	}
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 3 && typ == protowire.Fixed64Type:
+			p.TimeUnixNano = otlpInt(pbFixed64(v))
+		case num == 4 && typ == protowire.Fixed64Type:
+			p.Count = otlpInt(pbFixed64(v))
+		case num == 5 && typ == protowire.Fixed64Type:
+			p.Sum = float(v)
+		case num == 6 || num == 7:
+			values, err := pbRepeatedFixed64(typ, v)
+			if err != nil {
+				return err
+			}
+			for _, x := range values {
+				if num == 6 {
+					p.BucketCounts = append(p.BucketCounts, otlpInt(x))
+				} else {
+					p.ExplicitBounds = append(p.ExplicitBounds, otlpFloat(math.Float64frombits(x)))
+				}
+			}
+		case num == 9 && typ == protowire.BytesType:
+			kv, err := decodeOTLPKeyValue(v)
+			p.Attributes = append(p.Attributes, kv)
+			return err
+		case num == 10 && typ == protowire.VarintType:
+			p.Flags = uint32(pbVarint(v))
+		case num == 11 && typ == protowire.Fixed64Type:
+			p.Min = float(v)
+		case num == 12 && typ == protowire.Fixed64Type:
+			p.Max = float(v)
+		}
+		return nil
+	})
+	return p, err
+}
+
+// KeyValue { string key = 1; AnyValue value = 2; }
+// AnyValue { string string_value = 1; bool bool_value = 2; int64 int_value = 3; double double_value = 4; ... }
+func decodeOTLPKeyValue(b []byte) (otlpKeyValue, error) {
+	var kv otlpKeyValue
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			kv.Key = string(v)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeMessage(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				switch {
+				case num == 1 && typ == protowire.BytesType:
+					s := string(v)
+					kv.Value.StringValue = &s
+				case num == 2 && typ == protowire.VarintType:
+					b := pbVarint(v) != 0
+					kv.Value.BoolValue = &b
+				case num == 3 && typ == protowire.VarintType:
+					i := otlpInt(pbVarint(v))
+					kv.Value.IntValue = &i
+				case num == 4 && typ == protowire.Fixed64Type:
+					f := otlpFloat(math.Float64frombits(pbFixed64(v)))
+					kv.Value.DoubleValue = &f
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return kv, err
+}
+
+// OTLPResult 요청 하나의 처리 결과. Rejected가 있으면 응답의 partial_success로 알림
+type OTLPResult struct {
+	DataPoints int    `json:"data_points"`
+	Ingested   int    `json:"ingested"`
+	Rejected   int    `json:"rejected"`
+	Message    string `json:"message,omitempty"`
+}
+
+// ProtoResponse ExportMetricsServiceResponse { ExportMetricsPartialSuccess partial_success = 1; }
+// ExportMetricsPartialSuccess { int64 rejected_data_points = 1; string error_message = 2; }. 모두 받았으면 빈 메시지
+func (r *OTLPResult) ProtoResponse() []byte {
+	if r.Rejected == 0 {
+		return []byte{}
+	}
+	var partial []byte
+	partial = protowire.AppendTag(partial, 1, protowire.VarintType)
+	partial = protowire.AppendVarint(partial, uint64(r.Rejected))
+	partial = protowire.AppendTag(partial, 2, protowire.BytesType)
+	partial = protowire.AppendString(partial, r.Message)
+	resp := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(resp, partial)
+}
+
+// JSONResponse ProtoResponse의 OTLP/JSON 형식
+func (r *OTLPResult) JSONResponse() map[string]any {
+	if r.Rejected == 0 {
+		return map[string]any{}
+	}
+	return map[string]any{"partialSuccess": map[string]any{
+		"rejectedDataPoints": strconv.Itoa(r.Rejected),
+		"errorMessage":       r.Message,
+	}}
+}
+
+// OTLPStatusProto 오류 응답 본문 google.rpc.Status { int32 code = 1; string message = 2; }
+func OTLPStatusProto(code int, message string) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(code))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendString(b, message)
+}
+
+func (r *OTLPResult) reject(n int, reason string) {
+	r.Rejected += n
+	if r.Message == "" {
+		r.Message = reason
+	}
+}
+
+// otlpDataPoints 지표 이름이 카테고리, 점 속성 값이 라벨(태그 이름 순으로 ','로 이음), 리소스 속성이 태그.
+// 게이지·합계는 점마다 값 하나, 히스토그램은 "<이름>.count" 등 타이머와 같은 통계. 시각이 없으면 now
+func otlpDataPoints(req *otlpRequest, now time.Time) ([]models.DataPointRequest, *OTLPResult) {
+	result := &OTLPResult{}
+	var points []models.DataPointRequest
+	for _, rm := range req.ResourceMetrics {
+		tags := limitDataTags(otlpAttributes(rm.Resource.Attributes))
+		add := func(category, label string, value float64, at time.Time) {
+			v := value
+			points = append(points, models.DataPointRequest{Category: category, Label: label, Value: &v, RecordedAt: &at, Tags: tags})
+		}
+		at := func(nanos otlpInt) time.Time {
+			if nanos <= 0 {
+				return now
+			}
+			return time.Unix(0, int64(nanos))
+		}
+
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				var numbers []otlpNumberPoint
+				switch {
+				case m.Gauge != nil:
+					numbers = m.Gauge.DataPoints
+				case m.Sum != nil:
+					numbers = m.Sum.DataPoints
+				case m.Histogram != nil:
+					for _, p := range m.Histogram.DataPoints {
+						result.DataPoints++
+						if p.Flags&otlpNoRecordedValue != 0 {
+							continue
+						}
+						key, err := lineSeriesKey(m.Name, otlpAttributes(p.Attributes))
+						if err != nil || utf8.RuneCountInString(key.category)+len(".count") > 50 {
+							result.reject(1, fmt.Sprintf("metric %q: name or attributes too long", m.Name))
+							continue
+						}
+						stats := otlpHistogramStats(p)
+						for _, stat := range timerStats {
+							if v, ok := stats[stat]; ok && storableValue(v) {
+								add(key.category+"."+stat, key.label, v, at(p.TimeUnixNano))
+							}
+						}
+					}
+					continue
+				case m.ExponentialHistogram != nil:
+					result.DataPoints += len(m.ExponentialHistogram.DataPoints)
+					result.reject(len(m.ExponentialHistogram.DataPoints), "exponential histograms are not supported")
+					continue
+				case m.Summary != nil:
+					result.DataPoints += len(m.Summary.DataPoints)
+					result.reject(len(m.Summary.DataPoints), "summaries are not supported")
+					continue
+				}
+
+				for _, p := range numbers {
+					result.DataPoints++
+					if p.Flags&otlpNoRecordedValue != 0 {
+						continue
+					}
+					var value float64
+					switch {
+					case p.AsDouble != nil:
+						value = float64(*p.AsDouble)
+					case p.AsInt != nil:
+						value = float64(*p.AsInt)
+					default:
+						result.reject(1, fmt.Sprintf("metric %q: data point without value", m.Name))
+						continue
+					}
+					key, err := lineSeriesKey(m.Name, otlpAttributes(p.Attributes))
+					if err != nil {
+						result.reject(1, fmt.Sprintf("metric %q: name or attributes too long", m.Name))
+						continue
+					}
+					if !storableValue(value) {
+						result.reject(1, fmt.Sprintf("metric %q: value out of range", m.Name))
+						continue
+					}
+					add(key.category, key.label, value, at(p.TimeUnixNano))
+				}
+			}
+		}
+	}
+	return points, result
+}
+
+// otlpHistogramStats 히스토그램 점의 건수·평균·최소·최대와 버킷으로 추정한 백분위 (없는 값은 뺌)
+func otlpHistogramStats(p otlpHistogramPoint) map[string]float64 {
+	count := float64(p.Count)
+	stats := map[string]float64{"count": count}
+	if count == 0 {
+		return stats
+	}
+	if p.Sum != nil {
+		stats["mean"] = float64(*p.Sum) / count
+	}
+	if p.Min != nil {
+		stats["min"] = float64(*p.Min)
+	}
+	if p.Max != nil {
+		stats["max"] = float64(*p.Max)
+	}
+	bounds := make([]float64, len(p.ExplicitBounds))
+	for i, b := range p.ExplicitBounds {
+		bounds[i] = float64(b)
+	}
+	counts := make([]float64, len(p.BucketCounts))
+	for i, c := range p.BucketCounts {
+		counts[i] = float64(c)
+	}
+	if len(bounds) == 0 || len(counts) != len(bounds)+1 {
+		return stats
+	}
+	for _, q := range []struct {
+		stat string
+		q    float64
+	}{{"p50", 0.5}, {"p95", 0.95}, {"p99", 0.99}} {
+		v := histogramQuantile(q.q, bounds, counts)
+		if min, ok := stats["min"]; ok {
+			v = math.Max(v, min)
+		}
+		if max, ok := stats["max"]; ok {
+			v = math.Min(v, max)
+		}
+		stats[q.stat] = v
+	}
+	return stats
+}
+
+// histogramQuantile 버킷 안에서 값이 고르게 퍼져 있다고 보고 선형 보간 (Prometheus histogram_quantile과 같음).
+// counts[i]는 (bounds[i-1], bounds[i]] 구간 수, 마지막은 bounds 마지막 값 초과
+func histogramQuantile(q float64, bounds, counts []float64) float64 {
+	if len(bounds) == 0 {
+		return 0
+	}
+	var total float64
+	for _, c := range counts {
+		total += c
+	}
+	rank := q * total
+	var cum float64
+	for i, c := range counts {
+		if c == 0 || cum+c < rank {
+			cum += c
+			continue
+		}
+		// 상한이 없는 마지막 버킷은 하한
+		if i == len(bounds) {
+			return bounds[i-1]
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = bounds[i-1]
+		} else if bounds[0] <= 0 {
+			return bounds[0]
+		}
+		return lower + (bounds[i]-lower)*(rank-cum)/c
+	}
+	return bounds[len(bounds)-1]
+}
+
+// limitDataTags 태그 수와 길이 제한. 긴 값은 버리고 이름 순으로 최대 수까지
+func limitDataTags(tags map[string]string) map[string]string {
+	keys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if k != "" && utf8.RuneCountInString(k) <= maxDataTagKey && utf8.RuneCountInString(v) <= maxDataTagValue {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	if len(keys) > maxDataTags {
+		keys = keys[:maxDataTags]
+	}
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		out[k] = tags[k]
+	}
+	return out
+}
+
+// OTLPService OpenTelemetry SDK가 OTLP/HTTP로 보낸 지표를 대시보드 데이터로 수집
+type OTLPService struct {
+	dashboardService *DashboardService
+}
+
+func NewOTLPService(dashboardService *DashboardService) *OTLPService {
+	return &OTLPService{dashboardService: dashboardService}
+}
+
+// Export 요청의 점을 한 번에 저장. 해석할 수 없는 본문은 ErrInvalidOTLP
+func (s *OTLPService) Export(body []byte, format OTLPFormat) (*OTLPResult, error) {
+	req, err := decodeOTLP(body, format)
+	if err != nil {
+		return nil, err
+	}
+	points, result := otlpDataPoints(req, time.Now())
+	if _, err := s.dashboardService.Ingest(points); err != nil {
+		return nil, err
+	}
+	result.Ingested = len(points)
+	return result, nil
+}
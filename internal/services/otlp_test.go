@@ -0,0 +1,188 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const testOTLPJSON = `{
+  "resourceMetrics": [{
+    "resource": {"attributes": [
+      {"key": "service.name", "value": {"stringValue": "checkout"}},
+      {"key": "process.pid", "value": {"intValue": "4242"}},
+      {"key": "process.command_args", "value": {"arrayValue": {"values": [{"stringValue": "--debug"}]}}}
+    ]},
+    "scopeMetrics": [{
+      "scope": {"name": "app"},
+      "metrics": [
+        {"name": "queue.size", "gauge": {"dataPoints": [
+          {"asInt": "12", "timeUnixNano": "1709294400000000000", "attributes": [{"key": "queue", "value": {"stringValue": "orders"}}]}
+        ]}},
+        {"name": "orders.placed", "unit": "1", "sum": {"aggregationTemporality": 1, "isMonotonic": true, "dataPoints": [
+          {"asDouble": 3, "timeUnixNano": "1709294400000000000"},
+          {"asDouble": "NaN", "timeUnixNano": "1709294400000000000", "flags": 1}
+        ]}},
+        {"name": "http.server.duration", "histogram": {"aggregationTemporality": 1, "dataPoints": [
+          {"timeUnixNano": "1709294400000000000", "count": "10", "sum": 1.5, "min": 0.01, "max": 0.9,
+           "bucketCounts": ["2", "6", "2"], "explicitBounds": [0.05, 0.2],
+           "attributes": [{"key": "http.route", "value": {"stringValue": "/cart"}}, {"key": "http.method", "value": {"stringValue": "GET"}}]}
+        ]}},
+        {"name": "rpc.latency", "exponentialHistogram": {"dataPoints": [{}, {}]}}
+      ]
+    }]
+  }]
+}`
+
+func otlpPointMap(points []models.DataPointRequest) map[string]float64 {
+	values := map[string]float64{}
+	for _, p := range points {
+		values[p.Category+"|"+p.Label] = *p.Value
+	}
+	return values
+}
+
+func TestOTLPDataPointsJSON(t *testing.T) {
+	req, err := decodeOTLP([]byte(testOTLPJSON), OTLPJSON)
+	require.NoError(t, err)
+	points, result := otlpDataPoints(req, time.Now())
+
+	assert.Equal(t, 6, result.DataPoints)
+	assert.Equal(t, 2, result.Rejected)
+	assert.Equal(t, "exponential histograms are not supported", result.Message)
+	assert.Equal(t, map[string]float64{
+		"queue.size|orders":                    12,
+		"orders.placed|":                       3,
+		"http.server.duration.count|GET,/cart": 10,
+		"http.server.duration.mean|GET,/cart":  0.15,
+		"http.server.duration.min|GET,/cart":   0.01,
+		"http.server.duration.max|GET,/cart":   0.9,
+		"http.server.duration.p50|GET,/cart":   0.125,
+		"http.server.duration.p95|GET,/cart":   0.2,
+		"http.server.duration.p99|GET,/cart":   0.2,
+	}, otlpPointMap(points))
+
+	// 리소스 속성이 태그 (배열 속성은 건너뜀), 점 시각은 그대로
+	for _, p := range points {
+		assert.Equal(t, map[string]string{"service.name": "checkout", "process.pid": "4242"}, p.Tags)
+		assert.True(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Equal(*p.RecordedAt))
+	}
+
+	_, err = decodeOTLP([]byte(`{"resourceMetrics": [{"scopeMetrics": 1}]}`), OTLPJSON)
+	assert.ErrorIs(t, err, ErrInvalidOTLP)
+}
+
+// pbMessage 필드 번호와 인코딩된 값으로 protobuf 메시지를 만드는 테스트 도우미
+type pbField struct {
+	num protowire.Number
+	val any // string, []byte(하위 메시지), float64(double), uint64(fixed64), int(varint)
+}
+
+func pbMessage(fields ...pbField) []byte {
+	var b []byte
+	for _, f := range fields {
+		switch v := f.val.(type) {
+		case string:
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendString(b, v)
+		case []byte:
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendBytes(b, v)
+		case float64:
+			b = protowire.AppendTag(b, f.num, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, math.Float64bits(v))
+		case uint64:
+			b = protowire.AppendTag(b, f.num, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, v)
+		case int:
+			b = protowire.AppendTag(b, f.num, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(v))
+		}
+	}
+	return b
+}
+
+func TestOTLPDataPointsProtobuf(t *testing.T) {
+	attr := func(key string, value []byte) pbField {
+		return pbField{1, pbMessage(pbField{1, key}, pbField{2, value})}
+	}
+	packed := func(values ...uint64) []byte {
+		var b []byte
+		for _, v := range values {
+			b = protowire.AppendFixed64(b, v)
+		}
+		return b
+	}
+	ts := uint64(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).UnixNano())
+
+	gauge := pbMessage(pbField{1, "cpu.usage"}, pbField{5, pbMessage(
+		pbField{1, pbMessage(pbField{3, ts}, pbField{4, 0.75}, pbField{7, pbMessage(pbField{1, "cpu"}, pbField{2, pbMessage(pbField{3, 2})})})},
+	)})
+	sum := pbMessage(pbField{1, "requests"}, pbField{7, pbMessage(
+		pbField{1, pbMessage(pbField{3, ts}, pbField{6, uint64(41)})},
+		pbField{2, 2}, pbField{3, 1},
+	)})
+	histogram := pbMessage(pbField{1, "latency"}, pbField{9, pbMessage(
+		pbField{1, pbMessage(pbField{3, ts}, pbField{4, uint64(4)}, pbField{5, 2.0},
+			pbField{6, packed(1, 3, 0)}, pbField{7, packed(math.Float64bits(0.5), math.Float64bits(1))})},
+	)})
+	body := pbMessage(pbField{1, pbMessage(
+		pbField{1, pbMessage(attr("service.name", pbMessage(pbField{1, "api"})), attr("debug", pbMessage(pbField{2, 1})))},
+		pbField{2, pbMessage(pbField{1, pbMessage(pbField{1, "scope"})}, pbField{2, gauge}, pbField{2, sum}, pbField{2, histogram})},
+	)})
+
+	req, err := decodeOTLP(body, OTLPProtobuf)
+	require.NoError(t, err)
+	points, result := otlpDataPoints(req, time.Now())
+	assert.Equal(t, OTLPResult{DataPoints: 3}, *result)
+	assert.Equal(t, map[string]float64{
+		"cpu.usage|2":    0.75,
+		"requests|":      41,
+		"latency.count|": 4,
+		"latency.mean|":  0.5,
+		"latency.p50|":   0.6666666666666666,
+		"latency.p95|":   0.9666666666666666,
+		"latency.p99|":   0.9933333333333334,
+	}, otlpPointMap(points))
+	assert.Equal(t, map[string]string{"service.name": "api", "debug": "true"}, points[0].Tags)
+
+	_, err = decodeOTLP([]byte{0x0a, 0x10, 0x01}, OTLPProtobuf)
+	assert.ErrorIs(t, err, ErrInvalidOTLP)
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	bounds := []float64{1, 2, 4}
+	counts := []float64{0, 10, 0, 5}
+	assert.InDelta(t, 1.375, histogramQuantile(0.25, bounds, counts), 1e-9)
+	assert.InDelta(t, 1.9, histogramQuantile(0.6, bounds, counts), 1e-9)
+	// 상한 없는 버킷은 마지막 경계
+	assert.Equal(t, 4.0, histogramQuantile(0.99, bounds, counts))
+	assert.Equal(t, 0.0, histogramQuantile(0.5, nil, []float64{3}))
+}
+
+func TestOTLPResponse(t *testing.T) {
+	ok := &OTLPResult{DataPoints: 3, Ingested: 3}
+	assert.Empty(t, ok.ProtoResponse())
+	assert.Empty(t, ok.JSONResponse())
+
+	partial := &OTLPResult{DataPoints: 3, Ingested: 1, Rejected: 2, Message: "summaries are not supported"}
+	assert.Equal(t, pbMessage(pbField{1, pbMessage(pbField{1, 2}, pbField{2, "summaries are not supported"})}), partial.ProtoResponse())
+	assert.Equal(t, map[string]any{"partialSuccess": map[string]any{"rejectedDataPoints": "2", "errorMessage": "summaries are not supported"}}, partial.JSONResponse())
+}
+
+func TestLimitDataTags(t *testing.T) {
+	tags := map[string]string{"": "x", "long": string(make([]byte, maxDataTagValue+1))}
+	for i := 0; i < maxDataTags+5; i++ {
+		tags[string(rune('a'+i))] = "v"
+	}
+	limited := limitDataTags(tags)
+	assert.Len(t, limited, maxDataTags)
+	assert.NotContains(t, limited, "long")
+	assert.Contains(t, limited, "a")
+	assert.Nil(t, limitDataTags(map[string]string{}))
+}
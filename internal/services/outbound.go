@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrUnsafeURL 내부망(루프백, 사설, 링크 로컬 등)을 가리키거나 http(s)가 아닌 외부 전송 URL
+var ErrUnsafeURL = errors.New("url must point to a public http(s) address")
+
+// 전역 유니캐스트지만 내부에서만 쓰는 대역 (CGNAT, 벤치마크)
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// publicAddr 사용자가 지정한 URL로 보내도 되는 주소인지 (서버 내부망 접근 방지)
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckOutboundURL 웹훅처럼 사용자가 등록한 URL의 호스트가 공인 주소로만 풀리는지 확인
+func CheckOutboundURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrUnsafeURL
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil {
+		if !publicAddr(ip) {
+			return ErrUnsafeURL
+		}
+		return nil
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil || len(ips) == 0 {
+		return ErrUnsafeURL
+	}
+	for _, ip := range ips {
+		if !publicAddr(ip) {
+			return ErrUnsafeURL
+		}
+	}
+	return nil
+}
+
+// newOutboundClient 접속 직전에 실제 IP를 다시 확인하는 HTTP 클라이언트.
+// 등록 뒤 DNS가 내부 주소로 바뀌어도(DNS rebinding) 보내지 않음. 프록시는 쓰지 않음
+func newOutboundClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddr(addr.Addr()) {
+				return ErrUnsafeURL
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
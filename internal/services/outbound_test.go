@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicAddr(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1"} {
+		assert.False(t, publicAddr(netip.MustParseAddr(ip)), ip)
+	}
+	for _, ip := range []string{"8.8.8.8", "203.0.113.10", "2001:4860:4860::8888"} {
+		assert.True(t, publicAddr(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func TestCheckOutboundURL(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, CheckOutboundURL(ctx, "https://8.8.8.8/hook"))
+	for _, raw := range []string{
+		"http://127.0.0.1:8080/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/",
+		"http://10.0.0.5/hook",
+		"http://localhost/hook",
+		"ftp://8.8.8.8/",
+		"not a url",
+	} {
+		assert.ErrorIs(t, CheckOutboundURL(ctx, raw), ErrUnsafeURL, raw)
+	}
+}
+
+func TestOutboundClient_RefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// 등록 뒤 주소가 바뀌어도 접속 시점에 막힘
+	_, err := newOutboundClient(time.Second).Get(srv.URL)
+	assert.ErrorIs(t, err, ErrUnsafeURL)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 (pt)
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+)
+
+// pdfColor 0~1 RGB
+type pdfColor struct{ R, G, B float64 }
+
+var (
+	pdfBlack  = pdfColor{0.07, 0.09, 0.15}
+	pdfGray   = pdfColor{0.42, 0.45, 0.5}
+	pdfLight  = pdfColor{0.95, 0.96, 0.97}
+	pdfGrid   = pdfColor{0.9, 0.91, 0.92}
+	pdfIndigo = pdfColor{0.31, 0.27, 0.9}
+	pdfGreen  = pdfColor{0.06, 0.6, 0.42}
+	pdfRed    = pdfColor{0.86, 0.15, 0.15}
+)
+
+// pdfDocument 보고서용 최소 PDF 작성기 (텍스트, 선, 사각형).
+// CHART_FONT가 TrueType이면 쓴 글자만 골라 PDF에 포함하고, 아니면 뷰어 내장 한국어 글꼴을 참조.
+// 좌표는 왼쪽 위가 (0, 0)
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	cur   *bytes.Buffer
+	font  pdfFont
+}
+
+func newPDFDocument() *pdfDocument {
+	var font pdfFont = pdfKoreaFont{}
+	if src := chartPDFFont(); src != nil {
+		font = newPDFTrueTypeFont(src)
+	}
+	return newPDFDocumentWithFont(font)
+}
+
+func newPDFDocumentWithFont(font pdfFont) *pdfDocument {
+	d := &pdfDocument{font: font}
+	d.AddPage()
+	return d
+}
+
+func (d *pdfDocument) AddPage() {
+	d.cur = &bytes.Buffer{}
+	d.pages = append(d.pages, d.cur)
+}
+
+// Text y는 글자 기준선 위치
+func (d *pdfDocument) Text(x, y, size float64, c pdfColor, s string) {
+	fmt.Fprintf(d.cur, "BT %s rg /F1 %s Tf %s %s Td <%s> Tj ET\n",
+		pdfRGB(c), pdfNum(size), pdfNum(x), pdfNum(pdfPageHeight-y), d.font.encode(s))
+}
+
+// TextRight x에서 끝나도록 오른쪽 정렬
+func (d *pdfDocument) TextRight(x, y, size float64, c pdfColor, s string) {
+	d.Text(x-d.font.width(s, size), y, size, c, s)
+}
+
+// FitText width 안에 들어가도록 뒤를 잘라 "…" 붙임
+func (d *pdfDocument) FitText(s string, size, width float64) string {
+	if d.font.width(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && d.font.width(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func (d *pdfDocument) Rect(x, y, w, h float64, fill pdfColor) {
+	fmt.Fprintf(d.cur, "%s rg %s %s %s %s re f\n",
+		pdfRGB(fill), pdfNum(x), pdfNum(pdfPageHeight-y-h), pdfNum(w), pdfNum(h))
+}
+
+func (d *pdfDocument) Line(x1, y1, x2, y2, width float64, c pdfColor) {
+	fmt.Fprintf(d.cur, "%s RG %s w %s %s m %s %s l S\n",
+		pdfRGB(c), pdfNum(width), pdfNum(x1), pdfNum(pdfPageHeight-y1), pdfNum(x2), pdfNum(pdfPageHeight-y2))
+}
+
+// Polyline xs, ys 길이가 같아야 함
+func (d *pdfDocument) Polyline(xs, ys []float64, width float64, c pdfColor) {
+	if len(xs) < 2 {
+		return
+	}
+	fmt.Fprintf(d.cur, "%s RG %s w 1 j %s %s m", pdfRGB(c), pdfNum(width), pdfNum(xs[0]), pdfNum(pdfPageHeight-ys[0]))
+	for i := 1; i < len(xs); i++ {
+		fmt.Fprintf(d.cur, " %s %s l", pdfNum(xs[i]), pdfNum(pdfPageHeight-ys[i]))
+	}
+	d.cur.WriteString(" S\n")
+}
+
+// Bytes PDF 1.4 파일
+func (d *pdfDocument) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: 카탈로그, 2: 페이지 트리, 3부터: 글꼴, 그 뒤로 페이지와 내용 스트림
+	fontObjects := d.font.objects(3)
+	firstPage := 3 + len(fontObjects)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, body := range fontObjects {
+		obj(body)
+	}
+
+	for _, content := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfNum(pdfPageWidth), pdfNum(pdfPageHeight), len(offsets)+2))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func pdfRGB(c pdfColor) string {
+	return pdfNum(c.R) + " " + pdfNum(c.G) + " " + pdfNum(c.B)
+}
+
+func pdfNum(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+var errNotTrueType = errors.New("font has no TrueType outlines")
+
+// pdfFont 보고서 PDF 글꼴. Bytes에서 objects(first)가 first번부터 객체를 만들고 첫 객체가 /F1
+type pdfFont interface {
+	// encode Tj에 넣을 hex 문자열
+	encode(s string) string
+	width(s string, size float64) float64
+	objects(first int) []string
+}
+
+// pdfKoreaFont 뷰어에 내장된 Adobe-Korea1 글꼴(HYGoThic-Medium) 참조. 포함할 글꼴이 없을 때만 사용
+// (Adobe 한국어 글꼴 팩이 없는 뷰어에서는 한글이 보이지 않음)
+type pdfKoreaFont struct{}
+
+func (pdfKoreaFont) encode(s string) string { return pdfUCS2(s) }
+
+// width 글꼴의 W 배열과 같은 규칙 (ASCII 반각, 나머지 전각)
+func (pdfKoreaFont) width(s string, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		if r < 0x80 {
+			w += 0.5
+		} else {
+			w++
+		}
+	}
+	return w * size
+}
+
+func (pdfKoreaFont) objects(first int) []string {
+	return []string{
+		fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /HYGoThic-Medium /Encoding /UniKS-UCS2-H /DescendantFonts [%d 0 R] >>", first+1),
+		// ASCII(CID 1~95)는 반각, 나머지는 전각
+		fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /HYGoThic-Medium "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Korea1) /Supplement 1 >> "+
+			"/FontDescriptor %d 0 R /DW 1000 /W [1 95 500] >>", first+2),
+		"<< /Type /FontDescriptor /FontName /HYGoThic-Medium /Flags 6 " +
+			"/FontBBox [-6 -145 1003 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>",
+	}
+}
+
+// pdfUCS2 UniKS-UCS2-H 인코딩용 hex 문자열. BMP 밖의 문자는 "?"로 대체
+func pdfUCS2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF || utf16.IsSurrogate(r) {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// trueTypeSource PDF에 부분 포함할 TrueType 글꼴 (CHART_FONT). 한 번 읽어 문서마다 공유
+type trueTypeSource struct {
+	font      *sfnt.Font
+	tables    map[string][]byte
+	numGlyphs int
+	upem      int
+	longLoca  bool
+	name      string
+	bbox      [4]int
+	ascent    int
+	descent   int
+}
+
+// parseTrueType data(.ttf 또는 .ttc의 첫 글꼴)의 테이블을 읽음. CFF 글꼴(.otf)은 errNotTrueType.
+// f는 같은 글꼴을 sfnt로 읽은 것 (문자→글리프, 폭, 이름)
+func parseTrueType(data []byte, f *sfnt.Font) (*trueTypeSource, error) {
+	dir := 0
+	if len(data) >= 16 && string(data[:4]) == "ttcf" {
+		dir = int(binary.BigEndian.Uint32(data[12:]))
+	}
+	if dir+12 > len(data) {
+		return nil, errNotTrueType
+	}
+	if v := binary.BigEndian.Uint32(data[dir:]); v != 0x00010000 && v != 0x74727565 {
+		return nil, errNotTrueType
+	}
+
+	tables := map[string][]byte{}
+	n := int(binary.BigEndian.Uint16(data[dir+4:]))
+	for i := 0; i < n; i++ {
+		rec := dir + 12 + i*16
+		if rec+16 > len(data) {
+			return nil, errNotTrueType
+		}
+		off := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if off+length > len(data) {
+			return nil, errNotTrueType
+		}
+		tables[string(data[rec:rec+4])] = data[off : off+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf"} {
+		if tables[tag] == nil {
+			return nil, errNotTrueType
+		}
+	}
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 8 || len(maxp) < 6 {
+		return nil, errNotTrueType
+	}
+
+	s := &trueTypeSource{
+		font:      f,
+		tables:    tables,
+		numGlyphs: int(binary.BigEndian.Uint16(maxp[4:])),
+		upem:      int(binary.BigEndian.Uint16(head[18:])),
+		longLoca:  binary.BigEndian.Uint16(head[50:]) == 1,
+		ascent:    int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent:   int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+		name:      "CommetFont",
+	}
+	for i := range s.bbox {
+		s.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+i*2:])))
+	}
+	if s.upem == 0 {
+		return nil, errNotTrueType
+	}
+	if f == nil {
+		return s, nil
+	}
+	if name, err := f.Name(nil, sfnt.NameIDPostScript); err == nil && name != "" {
+		s.name = strings.Map(func(r rune) rune {
+			if r > ' ' && r < 0x7F && !strings.ContainsRune("()<>[]{}/%#", r) {
+				return r
+			}
+			return -1
+		}, name)
+	}
+	return s, nil
+}
+
+// glyph gid의 glyf 데이터 (없으면 nil)
+func (s *trueTypeSource) glyph(gid uint16) []byte {
+	loca, glyf := s.tables["loca"], s.tables["glyf"]
+	var start, end int
+	if s.longLoca {
+		if int(gid)*4+8 > len(loca) {
+			return nil
+		}
+		start = int(binary.BigEndian.Uint32(loca[int(gid)*4:]))
+		end = int(binary.BigEndian.Uint32(loca[int(gid)*4+4:]))
+	} else {
+		if int(gid)*2+4 > len(loca) {
+			return nil
+		}
+		start = int(binary.BigEndian.Uint16(loca[int(gid)*2:])) * 2
+		end = int(binary.BigEndian.Uint16(loca[int(gid)*2+2:])) * 2
+	}
+	if start >= end || end > len(glyf) {
+		return nil
+	}
+	return glyf[start:end]
+}
+
+// compositeGlyphs 복합 글리프가 참조하는 글리프
+func compositeGlyphs(g []byte) []uint16 {
+	if len(g) < 10 || int16(binary.BigEndian.Uint16(g)) >= 0 {
+		return nil
+	}
+	var gids []uint16
+	for p := 10; p+4 <= len(g); {
+		flags := binary.BigEndian.Uint16(g[p:])
+		gids = append(gids, binary.BigEndian.Uint16(g[p+2:]))
+		p += 4
+		if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&0x0008 != 0: // WE_HAVE_A_SCALE
+			p += 2
+		case flags&0x0040 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+			p += 4
+		case flags&0x0080 != 0: // WE_HAVE_A_TWO_BY_TWO
+			p += 8
+		}
+		if flags&0x0020 == 0 { // MORE_COMPONENTS
+			break
+		}
+	}
+	return gids
+}
+
+// subset 쓴 글리프(와 복합 글리프의 구성 요소)만 윤곽을 남긴 글꼴. 글리프 번호는 그대로
+func (s *trueTypeSource) subset(used map[uint16]bool) []byte {
+	keep := map[uint16]bool{}
+	queue := []uint16{0}
+	for gid := range used {
+		queue = append(queue, gid)
+	}
+	for len(queue) > 0 {
+		gid := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if keep[gid] || int(gid) >= s.numGlyphs {
+			continue
+		}
+		keep[gid] = true
+		queue = append(queue, compositeGlyphs(s.glyph(gid))...)
+	}
+
+	var glyf bytes.Buffer
+	loca := make([]byte, (s.numGlyphs+1)*4)
+	for gid := 0; gid < s.numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(loca[gid*4:], uint32(glyf.Len()))
+		if keep[uint16(gid)] {
+			glyf.Write(s.glyph(uint16(gid)))
+			for glyf.Len()%4 != 0 {
+				glyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[s.numGlyphs*4:], uint32(glyf.Len()))
+
+	head := append([]byte(nil), s.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)  // checkSumAdjustment
+	binary.BigEndian.PutUint16(head[50:], 1) // 긴 loca
+
+	tables := map[string][]byte{
+		"head": head,
+		"hhea": s.tables["hhea"],
+		"maxp": s.tables["maxp"],
+		"hmtx": s.tables["hmtx"],
+		"loca": loca,
+		"glyf": glyf.Bytes(),
+	}
+	// 힌팅 테이블 (PDF가 요구하는 TrueType 테이블 목록에 포함, cmap은 Identity 매핑이라 불필요)
+	for _, tag := range []string{"cvt ", "fpgm", "prep"} {
+		if t := s.tables[tag]; t != nil {
+			tables[tag] = t
+		}
+	}
+	return writeSFNT(tables)
+}
+
+// writeSFNT 테이블로 TrueType 파일을 만듦
+func writeSFNT(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	selector := 0
+	for 1<<(selector+1) <= n {
+		selector++
+	}
+	var out bytes.Buffer
+	binary.Write(&out, binary.BigEndian, []uint16{1, 0, uint16(n), uint16(16 << selector), uint16(selector), uint16(n*16 - 16<<selector)})
+
+	offset := 12 + 16*n
+	for _, tag := range tags {
+		t := tables[tag]
+		out.WriteString(tag)
+		binary.Write(&out, binary.BigEndian, []uint32{sfntChecksum(t), uint32(offset), uint32(len(t))})
+		offset += (len(t) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		out.Write(tables[tag])
+		for out.Len()%4 != 0 {
+			out.WriteByte(0)
+		}
+	}
+	return out.Bytes()
+}
+
+func sfntChecksum(t []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(t); i += 4 {
+		var word [4]byte
+		copy(word[:], t[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// pdfTrueTypeFont 문서 하나에 부분 포함하는 TrueType 글꼴 (Identity-H, 글리프 번호가 CID)
+type pdfTrueTypeFont struct {
+	src      *trueTypeSource
+	buf      sfnt.Buffer
+	used     map[uint16]rune
+	advances map[uint16]float64
+}
+
+func newPDFTrueTypeFont(src *trueTypeSource) *pdfTrueTypeFont {
+	return &pdfTrueTypeFont{src: src, used: map[uint16]rune{}, advances: map[uint16]float64{}}
+}
+
+// glyphs 문자별 글리프 번호 (없는 문자는 .notdef)
+func (f *pdfTrueTypeFont) glyphs(s string) []uint16 {
+	gids := make([]uint16, 0, len(s))
+	for _, r := range s {
+		gid, err := f.src.font.GlyphIndex(&f.buf, r)
+		if err != nil {
+			gid = 0
+		}
+		gids = append(gids, uint16(gid))
+		if _, ok := f.used[uint16(gid)]; !ok && gid != 0 {
+			f.used[uint16(gid)] = r
+		}
+	}
+	return gids
+}
+
+// advance 글리프 폭 (1000 단위)
+func (f *pdfTrueTypeFont) advance(gid uint16) float64 {
+	if w, ok := f.advances[gid]; ok {
+		return w
+	}
+	ppem := fixed.Int26_6(f.src.upem << 6)
+	adv, err := f.src.font.GlyphAdvance(&f.buf, sfnt.GlyphIndex(gid), ppem, font.HintingNone)
+	w := 0.0
+	if err == nil {
+		w = float64(adv) / 64 * 1000 / float64(f.src.upem)
+	}
+	f.advances[gid] = w
+	return w
+}
+
+func (f *pdfTrueTypeFont) encode(s string) string {
+	var b strings.Builder
+	for _, gid := range f.glyphs(s) {
+		fmt.Fprintf(&b, "%04X", gid)
+	}
+	return b.String()
+}
+
+func (f *pdfTrueTypeFont) width(s string, size float64) float64 {
+	var w float64
+	for _, gid := range f.glyphs(s) {
+		w += f.advance(gid)
+	}
+	return w * size / 1000
+}
+
+func (f *pdfTrueTypeFont) objects(first int) []string {
+	gids := make([]int, 0, len(f.used))
+	used := make(map[uint16]bool, len(f.used))
+	for gid := range f.used {
+		gids = append(gids, int(gid))
+		used[gid] = true
+	}
+	sort.Ints(gids)
+
+	// 부분 포함 글꼴 이름 앞에 붙이는 6글자 태그 (같은 글리프 조합이면 같은 태그)
+	h := fnv.New32a()
+	var widths, cmap strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(h, "%d,", gid)
+		fmt.Fprintf(&widths, "%d [%s] ", gid, pdfNum(f.advance(uint16(gid))))
+	}
+	sum := h.Sum32()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(sum%26)
+		sum /= 26
+	}
+	name := string(tag) + "+" + f.src.name
+
+	for i := 0; i < len(gids); i += 100 {
+		block := gids[i:min(i+100, len(gids))]
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(block))
+		for _, gid := range block {
+			fmt.Fprintf(&cmap, "<%04X> <%s>\n", gid, pdfUTF16(f.used[uint16(gid)]))
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	toUnicode := "/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n" + cmap.String() +
+		"endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n"
+
+	raw := f.src.subset(used)
+	var packed bytes.Buffer
+	zw := zlib.NewWriter(&packed)
+	zw.Write(raw)
+	zw.Close()
+
+	scale := func(v int) string { return pdfNum(float64(v) * 1000 / float64(f.src.upem)) }
+	return []string{
+		fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			name, first+1, first+4),
+		fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW 1000 /W [%s] >>", name, first+2, widths.String()),
+		fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%s %s %s %s] /ItalicAngle 0 "+
+			"/Ascent %s /Descent %s /CapHeight %s /StemV 80 /FontFile2 %d 0 R >>",
+			name, scale(f.src.bbox[0]), scale(f.src.bbox[1]), scale(f.src.bbox[2]), scale(f.src.bbox[3]),
+			scale(f.src.ascent), scale(f.src.descent), scale(f.src.ascent), first+3),
+		fmt.Sprintf("<< /Length %d /Length1 %d /Filter /FlateDecode >>\nstream\n%s\nendstream", packed.Len(), len(raw), packed.String()),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(toUnicode), toUnicode),
+	}
+}
+
+// pdfUTF16 ToUnicode CMap용 UTF-16BE hex
+func pdfUTF16(r rune) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune{r}) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
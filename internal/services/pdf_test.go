@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+func testTrueTypeSource(t *testing.T) *trueTypeSource {
+	f, err := opentype.Parse(goregular.TTF)
+	require.NoError(t, err)
+	src, err := parseTrueType(goregular.TTF, f)
+	require.NoError(t, err)
+	return src
+}
+
+func TestTrueTypeSubset(t *testing.T) {
+	src := testTrueTypeSource(t)
+	var buf sfnt.Buffer
+	gid := func(r rune) uint16 {
+		g, err := src.font.GlyphIndex(&buf, r)
+		require.NoError(t, err)
+		return uint16(g)
+	}
+
+	sub, err := parseTrueType(src.subset(map[uint16]bool{gid('A'): true}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, src.numGlyphs, sub.numGlyphs)
+	assert.True(t, sub.longLoca)
+	assert.Equal(t, src.tables["hmtx"], sub.tables["hmtx"])
+
+	// 쓴 글리프와 .notdef만 윤곽이 남음
+	assert.Equal(t, src.glyph(gid('A')), sub.glyph(gid('A')))
+	assert.Nil(t, sub.glyph(gid('Z')))
+	assert.NotNil(t, sub.glyph(0))
+}
+
+func TestCompositeGlyphs(t *testing.T) {
+	// 구성 요소 2개: 7번(바이트 인자, MORE_COMPONENTS), 9번(워드 인자, 배율)
+	g := []byte{
+		0xFF, 0xFF, 0, 0, 0, 0, 0, 0, 0, 0,
+		0x00, 0x20, 0x00, 0x07, 1, 2,
+		0x00, 0x09, 0x00, 0x09, 0, 1, 0, 2, 0x40, 0x00,
+	}
+	assert.Equal(t, []uint16{7, 9}, compositeGlyphs(g))
+	assert.Nil(t, compositeGlyphs([]byte{0, 1, 0, 0, 0, 0, 0, 0, 0, 0}))
+}
+
+func TestPDFEmbeddedFont(t *testing.T) {
+	doc := newPDFDocumentWithFont(newPDFTrueTypeFont(testTrueTypeSource(t)))
+	doc.Text(10, 20, 12, pdfBlack, "Hi")
+	out := doc.Bytes()
+
+	assert.Contains(t, string(out), "/Encoding /Identity-H")
+	assert.Contains(t, string(out), "/CIDToGIDMap /Identity")
+	assert.Contains(t, string(out), "/ToUnicode 7 0 R")
+	assert.Contains(t, string(out), "/Resources << /Font << /F1 3 0 R >> >>")
+	assert.Regexp(t, `/BaseFont /[A-Z]{6}\+GoRegular`, string(out))
+	// ToUnicode로 글자를 다시 찾을 수 있음 (복사, 검색)
+	assert.Contains(t, string(out), "> <0048>\n")
+
+	// 포함한 글꼴 스트림은 압축을 풀면 TrueType
+	m := regexp.MustCompile(`/Length (\d+) /Length1 (\d+) /Filter /FlateDecode >>\nstream\n`).FindSubmatchIndex(out)
+	require.NotNil(t, m)
+	n, _ := strconv.Atoi(string(out[m[2]:m[3]]))
+	zr, err := zlib.NewReader(bytes.NewReader(out[m[1] : m[1]+n]))
+	require.NoError(t, err)
+	raw, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	length1, _ := strconv.Atoi(string(out[m[4]:m[5]]))
+	assert.Len(t, raw, length1)
+	_, err = parseTrueType(raw, nil)
+	assert.NoError(t, err)
+
+	// 글자 폭은 글꼴의 실제 advance
+	f := newPDFTrueTypeFont(testTrueTypeSource(t))
+	assert.Less(t, f.width("i", 10), f.width("W", 10))
+}
+
+func TestParseTrueTypeRejectsCFF(t *testing.T) {
+	_, err := parseTrueType(append([]byte("OTTO"), make([]byte, 12)...), nil)
+	assert.ErrorIs(t, err, errNotTrueType)
+}
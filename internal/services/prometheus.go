@@ -0,0 +1,329 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidRemoteWrite = errors.New("invalid remote write request")
+	ErrInvalidPromRules   = errors.New("invalid prometheus mapping rules")
+)
+
+// 해제한 remote-write 본문의 최대 크기
+const maxRemoteWriteSize = 32 << 20
+
+// PromSample 시계열 값 하나 (Timestamp: 유닉스 밀리초)
+type PromSample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// PromSeries remote-write 요청의 시계열 하나. Labels["__name__"]이 지표 이름
+type PromSeries struct {
+	Labels  map[string]string
+	Samples []PromSample
+}
+
+// DecodeRemoteWrite snappy로 압축된 remote-write 1.0 WriteRequest 해석.
+// 샘플만 읽고 메타데이터, 예시(exemplar), 네이티브 히스토그램은 건너뜀
+func DecodeRemoteWrite(body []byte) ([]PromSeries, error) {
+	// 해제 전에 머리의 길이로 크기를 제한
+	n, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRemoteWrite, err)
+	}
+	if n > maxRemoteWriteSize {
+		return nil, fmt.Errorf("%w: decoded body is %d bytes", ErrInvalidRemoteWrite, n)
+	}
+	raw, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRemoteWrite, err)
+	}
+	var series []PromSeries
+	err = consumeMessage(raw, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		s, err := decodePromSeries(v)
+		if err != nil {
+			return err
+		}
+		series = append(series, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRemoteWrite, err)
+	}
+	return series, nil
+}
+
+// consumeMessage protobuf 메시지의 필드를 차례로 fn에 넘김. v는 길이 구분 필드면 내용, 아니면 필드 값의 원본 바이트
+func consumeMessage(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var v []byte
+		if typ == protowire.BytesType {
+			v, n = protowire.ConsumeBytes(b)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n >= 0 {
+				v = b[:n]
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(num, typ, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodePromSeries TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+func decodePromSeries(b []byte) (PromSeries, error) {
+	s := PromSeries{Labels: map[string]string{}}
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			name, value, err := decodePromLabel(v)
+			if err != nil {
+				return err
+			}
+			s.Labels[name] = value
+		case 2:
+			sample, err := decodePromSample(v)
+			if err != nil {
+				return err
+			}
+			s.Samples = append(s.Samples, sample)
+		}
+		return nil
+	})
+	return s, err
+}
+
+// decodePromLabel Label { string name = 1; string value = 2; }
+func decodePromLabel(b []byte) (name, value string, err error) {
+	err = consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			name = string(v)
+		case 2:
+			value = string(v)
+		}
+		return nil
+	})
+	return name, value, err
+}
+
+// decodePromSample Sample { double value = 1; int64 timestamp = 2; }
+func decodePromSample(b []byte) (PromSample, error) {
+	var s PromSample
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			bits, _ := protowire.ConsumeFixed64(v)
+			s.Value = math.Float64frombits(bits)
+		case num == 2 && typ == protowire.VarintType:
+			ts, _ := protowire.ConsumeVarint(v)
+			s.Timestamp = int64(ts)
+		}
+		return nil
+	})
+	return s, err
+}
+
+// PrometheusRule 규칙 파일의 항목 하나. 지표 이름과 레이블 조건이 맞는 시계열을 카테고리·라벨로 옮김
+//
+//	metric: 지표 이름 정규식 (전체 일치)
+//	match: 레이블 이름 → 값 정규식 (전체 일치, 모두 맞아야 함)
+//	category, label: {레이블} 자리에 레이블 값을 넣는 틀 ({__name__}은 지표 이름)
+//	scale: 값에 곱할 수 (기본 1)
+type PrometheusRule struct {
+	Metric   string            `yaml:"metric"`
+	Match    map[string]string `yaml:"match"`
+	Category string            `yaml:"category"`
+	Label    string            `yaml:"label"`
+	Scale    float64           `yaml:"scale"`
+}
+
+type promRule struct {
+	metric   *regexp.Regexp
+	match    map[string]*regexp.Regexp
+	category string
+	label    string
+	scale    float64
+}
+
+// PrometheusMapper 시계열을 위에서부터 처음 맞는 규칙으로 옮김. 맞는 규칙이 없으면 버림
+type PrometheusMapper struct {
+	rules []promRule
+}
+
+var promTemplateVar = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// LoadPrometheusRules 규칙 파일(YAML) 읽기
+func LoadPrometheusRules(path string) (*PrometheusMapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrometheusRules(data)
+}
+
+// ParsePrometheusRules `rules:` 목록을 읽고 정규식을 검사
+func ParsePrometheusRules(data []byte) (*PrometheusMapper, error) {
+	var file struct {
+		Rules []PrometheusRule `yaml:"rules"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPromRules, err)
+	}
+
+	m := &PrometheusMapper{}
+	for i, r := range file.Rules {
+		if r.Metric == "" || r.Category == "" {
+			return nil, fmt.Errorf("%w: rules[%d]: metric and category are required", ErrInvalidPromRules, i)
+		}
+		metric, err := regexp.Compile("^(?:" + r.Metric + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w: rules[%d].metric: %v", ErrInvalidPromRules, i, err)
+		}
+		rule := promRule{metric: metric, match: map[string]*regexp.Regexp{}, category: r.Category, label: r.Label, scale: r.Scale}
+		for name, pattern := range r.Match {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("%w: rules[%d].match.%s: %v", ErrInvalidPromRules, i, name, err)
+			}
+			rule.match[name] = re
+		}
+		if rule.scale == 0 {
+			rule.scale = 1
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// Len 규칙 수
+func (m *PrometheusMapper) Len() int {
+	return len(m.rules)
+}
+
+// Map 레이블에 맞는 첫 규칙으로 카테고리·라벨과 배율을 구함
+func (m *PrometheusMapper) Map(labels map[string]string) (category, label string, scale float64, ok bool) {
+	for _, r := range m.rules {
+		if !r.matches(labels) {
+			continue
+		}
+		category = expandPromTemplate(r.category, labels)
+		label = expandPromTemplate(r.label, labels)
+		// DataPointRequest와 같은 길이 제한. 넘으면 자르지 않고 버림 (잘린 이름이 다른 시계열과 섞이지 않도록)
+		if category == "" || utf8.RuneCountInString(category) > 50 || utf8.RuneCountInString(label) > 100 {
+			return "", "", 0, false
+		}
+		return category, label, r.scale, true
+	}
+	return "", "", 0, false
+}
+
+func (r *promRule) matches(labels map[string]string) bool {
+	if !r.metric.MatchString(labels["__name__"]) {
+		return false
+	}
+	for name, re := range r.match {
+		// 없는 레이블은 빈 값으로 비교 (Prometheus 셀렉터와 같음)
+		if !re.MatchString(labels[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func expandPromTemplate(tmpl string, labels map[string]string) string {
+	return strings.TrimSpace(promTemplateVar.ReplaceAllStringFunc(tmpl, func(v string) string {
+		return labels[v[1:len(v)-1]]
+	}))
+}
+
+// PromWriteResult remote-write 요청 하나의 처리 결과
+type PromWriteResult struct {
+	Series   int `json:"series"`
+	Samples  int `json:"samples"`
+	Ingested int `json:"ingested"`
+	// Dropped 맞는 규칙이 없거나 값이 NaN·무한대(staleness 표시 포함)·저장 범위 밖인 샘플
+	Dropped int `json:"dropped"`
+}
+
+// PrometheusService remote-write로 받은 시계열을 규칙에 따라 대시보드 데이터로 수집
+type PrometheusService struct {
+	dashboardService *DashboardService
+	mapper           *PrometheusMapper
+}
+
+func NewPrometheusService(dashboardService *DashboardService, mapper *PrometheusMapper) *PrometheusService {
+	return &PrometheusService{dashboardService: dashboardService, mapper: mapper}
+}
+
+// Write 요청의 샘플을 한 번에 저장 (일부만 저장된 채 Prometheus가 다시 보내지 않도록)
+func (s *PrometheusService) Write(series []PromSeries) (*PromWriteResult, error) {
+	reqs, result := mapPromSeries(s.mapper, series)
+	if _, err := s.dashboardService.Ingest(reqs); err != nil {
+		return nil, err
+	}
+	result.Ingested = len(reqs)
+	return result, nil
+}
+
+// mapPromSeries 규칙에 맞는 시계열의 샘플을 데이터 포인트로 바꿈
+func mapPromSeries(mapper *PrometheusMapper, series []PromSeries) ([]models.DataPointRequest, *PromWriteResult) {
+	result := &PromWriteResult{Series: len(series)}
+	var reqs []models.DataPointRequest
+	for _, s := range series {
+		result.Samples += len(s.Samples)
+		category, label, scale, ok := mapper.Map(s.Labels)
+		if !ok {
+			result.Dropped += len(s.Samples)
+			continue
+		}
+		for _, sample := range s.Samples {
+			value := sample.Value * scale
+			if !storableValue(value) {
+				result.Dropped++
+				continue
+			}
+			recordedAt := time.UnixMilli(sample.Timestamp)
+			reqs = append(reqs, models.DataPointRequest{Category: category, Label: label, Value: &value, RecordedAt: &recordedAt})
+		}
+	}
+	return reqs, result
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeRequest 레이블과 (값, 시각) 샘플로 WriteRequest protobuf를 만듦
+func writeRequest(series ...PromSeries) []byte {
+	var req []byte
+	for _, s := range series {
+		var ts []byte
+		for name, value := range s.Labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+		for _, sample := range s.Samples {
+			var b []byte
+			b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, math.Float64bits(sample.Value))
+			b = protowire.AppendTag(b, 2, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(sample.Timestamp))
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, b)
+		}
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	// 모르는 필드(메타데이터)는 건너뜀
+	req = protowire.AppendTag(req, 3, protowire.BytesType)
+	req = protowire.AppendBytes(req, []byte{0x08, 0x01})
+	return req
+}
+
+func TestDecodeRemoteWrite(t *testing.T) {
+	body := snappy.Encode(nil, writeRequest(
+		PromSeries{
+			Labels:  map[string]string{"__name__": "up", "instance": "web-1:9100"},
+			Samples: []PromSample{{Value: 1, Timestamp: 1700000000000}, {Value: 0, Timestamp: 1700000015000}},
+		},
+		PromSeries{Labels: map[string]string{"__name__": "node_load1"}, Samples: []PromSample{{Value: 0.25, Timestamp: -1}}},
+	))
+
+	series, err := DecodeRemoteWrite(body)
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.Equal(t, map[string]string{"__name__": "up", "instance": "web-1:9100"}, series[0].Labels)
+	assert.Equal(t, []PromSample{{Value: 1, Timestamp: 1700000000000}, {Value: 0, Timestamp: 1700000015000}}, series[0].Samples)
+	assert.Equal(t, PromSample{Value: 0.25, Timestamp: -1}, series[1].Samples[0])
+
+	_, err = DecodeRemoteWrite([]byte("not snappy"))
+	assert.ErrorIs(t, err, ErrInvalidRemoteWrite)
+	_, err = DecodeRemoteWrite(snappy.Encode(nil, []byte{0x0a, 0x05, 0x01}))
+	assert.ErrorIs(t, err, ErrInvalidRemoteWrite)
+
+	// 해제 크기가 제한을 넘는다고 적힌 본문은 해제하지 않고 거절
+	_, err = DecodeRemoteWrite(append(binary.AppendUvarint(nil, maxRemoteWriteSize+1), 0))
+	assert.ErrorIs(t, err, ErrInvalidRemoteWrite)
+}
+
+const testPromRules = `
+rules:
+  - metric: node_cpu_seconds_total
+    match:
+      mode: idle
+    category: cpu_idle
+    label: "{instance}"
+  - metric: 'node_memory_(MemAvailable|MemTotal)_bytes'
+    category: memory_gb
+    label: "{instance} {__name__}"
+    scale: 1e-9
+  - metric: http_requests_total
+    match:
+      code: "5.."
+    category: "{job}"
+    label: "5xx"
+`
+
+func TestPrometheusMapper(t *testing.T) {
+	m, err := ParsePrometheusRules([]byte(testPromRules))
+	require.NoError(t, err)
+	assert.Equal(t, 3, m.Len())
+
+	category, label, scale, ok := m.Map(map[string]string{"__name__": "node_cpu_seconds_total", "mode": "idle", "instance": "web-1"})
+	require.True(t, ok)
+	assert.Equal(t, "cpu_idle", category)
+	assert.Equal(t, "web-1", label)
+	assert.Equal(t, 1.0, scale)
+
+	_, _, _, ok = m.Map(map[string]string{"__name__": "node_cpu_seconds_total", "mode": "user"})
+	assert.False(t, ok)
+	// 이름 정규식은 전체가 맞아야 함
+	_, _, _, ok = m.Map(map[string]string{"__name__": "node_cpu_seconds_total_extra", "mode": "idle"})
+	assert.False(t, ok)
+
+	category, label, scale, ok = m.Map(map[string]string{"__name__": "node_memory_MemTotal_bytes", "instance": "db"})
+	require.True(t, ok)
+	assert.Equal(t, "memory_gb", category)
+	assert.Equal(t, "db node_memory_MemTotal_bytes", label)
+	assert.Equal(t, 1e-9, scale)
+
+	// 카테고리 틀의 레이블이 없어 카테고리가 비면 버림
+	_, _, _, ok = m.Map(map[string]string{"__name__": "http_requests_total", "code": "503"})
+	assert.False(t, ok)
+	category, _, _, ok = m.Map(map[string]string{"__name__": "http_requests_total", "code": "503", "job": "api"})
+	require.True(t, ok)
+	assert.Equal(t, "api", category)
+
+	for _, bad := range []string{
+		"rules:\n  - metric: up\n",
+		"rules:\n  - metric: '('\n    category: up\n",
+		"rules:\n  - metric: up\n    category: up\n    labels: {a: b}\n",
+	} {
+		_, err := ParsePrometheusRules([]byte(bad))
+		assert.ErrorIs(t, err, ErrInvalidPromRules, bad)
+	}
+	empty, err := ParsePrometheusRules(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, empty.Len())
+}
+
+func TestMapPromSeries(t *testing.T) {
+	m, err := ParsePrometheusRules([]byte(testPromRules))
+	require.NoError(t, err)
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	reqs, result := mapPromSeries(m, []PromSeries{
+		{
+			Labels:  map[string]string{"__name__": "node_memory_MemAvailable_bytes", "instance": "db"},
+			Samples: []PromSample{{Value: 2e9, Timestamp: ts.UnixMilli()}, {Value: math.NaN(), Timestamp: ts.UnixMilli()}},
+		},
+		{Labels: map[string]string{"__name__": "go_goroutines"}, Samples: []PromSample{{Value: 12}, {Value: 13}}},
+	})
+	assert.Equal(t, PromWriteResult{Series: 2, Samples: 4, Dropped: 3}, *result)
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "memory_gb", reqs[0].Category)
+	assert.InDelta(t, 2, *reqs[0].Value, 1e-9)
+	assert.True(t, ts.Equal(*reqs[0].RecordedAt))
+}
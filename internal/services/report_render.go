@@ -0,0 +1,336 @@
+package services
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 라벨별 차트는 값이 큰 순서로 이만큼만 표시
+const reportTopRows = 12
+
+// ReportContent 메일 본문과 PDF가 함께 쓰는 보고서 데이터
+type ReportContent struct {
+	Name          string
+	DashboardName string
+	From, To      time.Time
+	Period        string
+	GeneratedAt   time.Time
+	KPIs          []KPIValue
+	Charts        []ReportChart
+}
+
+// KPIGrid 메일 본문용 3열 배치
+func (c *ReportContent) KPIGrid() [][]KPIValue {
+	var grid [][]KPIValue
+	for i := 0; i < len(c.KPIs); i += 3 {
+		grid = append(grid, c.KPIs[i:min(i+3, len(c.KPIs))])
+	}
+	return grid
+}
+
+// ReportChart 위젯 하나의 집계 결과
+type ReportChart struct {
+	Title      string
+	Subtitle   string
+	Type       string
+	TimeSeries bool
+	Labels     []string
+	Values     []float64
+	Unit       string
+	Suffix     string
+	// Annotations 차트 이미지에 함께 그릴 주석 (글꼴에 없는 글자의 주석 글은 생략)
+	Annotations []ChartAnnotation
+	// Anomalies 차트 이미지에 함께 표시할 이상치
+	Anomalies []ChartAnomaly
+	// Image 메일 본문에 넣을 차트 이미지 주소 (cid: 또는 data:). 비어 있으면 표로 표시
+	Image template.URL
+}
+
+// ReportRow 표/막대 한 줄. Percent는 최댓값 대비 0~100
+type ReportRow struct {
+	Label     string
+	Value     float64
+	Formatted string
+	Percent   float64
+}
+
+// Rows 시계열은 순서대로, 라벨별 차트는 값이 큰 순서로 상위 limit개 (0이면 전체)
+func (c ReportChart) Rows(limit int) []ReportRow {
+	rows := make([]ReportRow, 0, len(c.Values))
+	var peak float64
+	for i, v := range c.Values {
+		label := ""
+		if i < len(c.Labels) {
+			label = c.Labels[i]
+		}
+		rows = append(rows, ReportRow{Label: label, Value: v, Formatted: c.Format(v)})
+		peak = math.Max(peak, math.Abs(v))
+	}
+	if !c.TimeSeries {
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Value > rows[j].Value })
+	}
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	for i := range rows {
+		if peak > 0 {
+			rows[i].Percent = math.Abs(rows[i].Value) / peak * 100
+		}
+	}
+	return rows
+}
+
+// TopRows 메일 본문용 (라벨별 차트 상위 reportTopRows개)
+func (c ReportChart) TopRows() []ReportRow {
+	if c.TimeSeries {
+		return c.Rows(0)
+	}
+	return c.Rows(reportTopRows)
+}
+
+func (c ReportChart) FirstLabel() string {
+	if len(c.Labels) == 0 {
+		return ""
+	}
+	return c.Labels[0]
+}
+
+func (c ReportChart) LastLabel() string {
+	if len(c.Labels) < 2 {
+		return ""
+	}
+	return c.Labels[len(c.Labels)-1]
+}
+
+// Total 기간 합계
+func (c ReportChart) Total() string {
+	var sum float64
+	for _, v := range c.Values {
+		sum += v
+	}
+	return c.Format(sum)
+}
+
+func (c ReportChart) Format(v float64) string {
+	s := LookupLocale("ko-KR").FormatNumber(v)
+	if c.Unit != "" {
+		s = c.Unit + s
+	}
+	return s + c.Suffix
+}
+
+// 메일 본문 차트 이미지 크기 (본문 폭에 맞춘 px, 고해상도 화면용으로 2배로 그림)
+const (
+	reportChartWidth  = 592
+	reportChartHeight = 260
+)
+
+// renderReportChartPNG PNG 글꼴로 라벨을 그릴 수 없거나 이미지로 그릴 수 없는 차트 종류면 nil
+func renderReportChartPNG(c ReportChart) []byte {
+	if len(c.Values) == 0 || !ChartFontCovers(strings.Join(c.Labels, "")+c.Unit+c.Suffix) {
+		return nil
+	}
+	png, err := RenderChartPNG(&ChartData{Labels: c.Labels, Values: c.Values}, ChartImageOptions{
+		Type:        c.Type,
+		Width:       reportChartWidth,
+		Height:      reportChartHeight,
+		Scale:       2,
+		Unit:        c.Unit,
+		Suffix:      c.Suffix,
+		Annotations: c.Annotations,
+		Anomalies:   c.Anomalies,
+	})
+	if err != nil {
+		return nil
+	}
+	return png
+}
+
+// renderReportText HTML을 보지 못하는 메일 클라이언트용 본문
+func renderReportText(content *ReportContent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s · %s\n\n", content.Name, content.DashboardName, content.Period)
+	for _, k := range content.KPIs {
+		fmt.Fprintf(&b, "- %s: %s (%s)\n", k.Definition.Title, k.Formatted, kpiDeltaSign(k))
+	}
+	for _, c := range content.Charts {
+		fmt.Fprintf(&b, "\n[%s] 합계 %s\n", c.Title, c.Total())
+		for _, row := range c.TopRows() {
+			fmt.Fprintf(&b, "  %s: %s\n", row.Label, row.Formatted)
+		}
+	}
+	fmt.Fprintf(&b, "\n생성 시각: %s\n", content.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	return b.String()
+}
+
+func kpiDeltaSign(k KPIValue) string {
+	switch {
+	case k.Delta == nil:
+		return "-"
+	case k.DeltaUp():
+		return "▲ " + k.DeltaText()
+	default:
+		return "▼ " + k.DeltaText()
+	}
+}
+
+// PDF 레이아웃 (pt)
+const (
+	pdfMargin     = 40.0
+	pdfKPIColumns = 3
+	pdfKPIHeight  = 62.0
+	pdfChartPlotH = 150.0
+	pdfBarRowH    = 16.0
+)
+
+// reportPDF 세로로 내려가며 그리고 남은 공간이 부족하면 새 페이지
+type reportPDF struct {
+	doc *pdfDocument
+	y   float64
+}
+
+func (p *reportPDF) ensure(h float64) {
+	if p.y+h > pdfPageHeight-pdfMargin {
+		p.doc.AddPage()
+		p.y = pdfMargin
+	}
+}
+
+// renderReportPDF KPI 카드와 위젯 차트를 A4 세로 페이지에 그림
+func renderReportPDF(content *ReportContent) []byte {
+	p := &reportPDF{doc: newPDFDocument(), y: pdfMargin}
+	width := pdfPageWidth - 2*pdfMargin
+
+	p.doc.Text(pdfMargin, p.y+18, 18, pdfBlack, p.doc.FitText(content.Name, 18, width))
+	p.y += 34
+	p.doc.Text(pdfMargin, p.y, 10, pdfGray, fmt.Sprintf("%s · %s", content.DashboardName, content.Period))
+	p.y += 10
+	p.doc.Line(pdfMargin, p.y, pdfMargin+width, p.y, 0.5, pdfGrid)
+	p.y += 16
+
+	p.kpis(content.KPIs, width)
+	for _, c := range content.Charts {
+		p.chart(c, width)
+	}
+
+	p.ensure(20)
+	p.doc.Text(pdfMargin, p.y+10, 8, pdfGray, "생성 시각 "+content.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	return p.doc.Bytes()
+}
+
+func (p *reportPDF) kpis(kpis []KPIValue, width float64) {
+	const gap = 10.0
+	cardW := (width - gap*(pdfKPIColumns-1)) / pdfKPIColumns
+	for i, k := range kpis {
+		col := i % pdfKPIColumns
+		if col == 0 {
+			if i > 0 {
+				p.y += pdfKPIHeight + gap
+			}
+			p.ensure(pdfKPIHeight)
+		}
+		x := pdfMargin + float64(col)*(cardW+gap)
+		p.doc.Rect(x, p.y, cardW, pdfKPIHeight, pdfLight)
+		p.doc.Text(x+10, p.y+18, 9, pdfGray, p.doc.FitText(k.Definition.Title, 9, cardW-20))
+		p.doc.Text(x+10, p.y+40, 16, pdfBlack, p.doc.FitText(k.Formatted, 16, cardW-20))
+		delta := pdfGray
+		if k.Delta != nil {
+			delta = pdfRed
+			if k.DeltaUp() {
+				delta = pdfGreen
+			}
+		}
+		p.doc.Text(x+10, p.y+54, 8, delta, kpiDeltaSign(k))
+	}
+	if len(kpis) > 0 {
+		p.y += pdfKPIHeight + 20
+	}
+}
+
+func (p *reportPDF) chart(c ReportChart, width float64) {
+	rows := c.TopRows()
+	height := 36 + pdfChartPlotH
+	if !c.TimeSeries {
+		height = 36 + float64(len(rows))*pdfBarRowH
+	}
+	p.ensure(height + 20)
+
+	p.doc.Text(pdfMargin, p.y+12, 12, pdfBlack, p.doc.FitText(c.Title, 12, width-120))
+	p.doc.TextRight(pdfMargin+width, p.y+12, 9, pdfGray, "합계 "+c.Total())
+	if c.Subtitle != "" {
+		p.doc.Text(pdfMargin, p.y+26, 8, pdfGray, p.doc.FitText(c.Subtitle, 8, width))
+	}
+	p.y += 36
+
+	switch {
+	case len(rows) == 0:
+		p.doc.Text(pdfMargin, p.y+10, 9, pdfGray, "데이터 없음")
+		p.y += 20
+	case c.TimeSeries:
+		p.lineChart(c, rows, width)
+	default:
+		p.barList(rows, width)
+	}
+	p.y += 20
+}
+
+// lineChart 왼쪽에 최댓값/0 눈금, 아래에 처음/끝 라벨
+func (p *reportPDF) lineChart(c ReportChart, rows []ReportRow, width float64) {
+	const axisW = 60.0
+	left, top := pdfMargin+axisW, p.y
+	plotW, plotH := width-axisW, pdfChartPlotH-16
+
+	lo, hi := 0.0, 0.0
+	for _, r := range rows {
+		lo, hi = math.Min(lo, r.Value), math.Max(hi, r.Value)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+
+	for i := 0; i <= 4; i++ {
+		y := top + plotH*float64(i)/4
+		p.doc.Line(left, y, left+plotW, y, 0.5, pdfGrid)
+	}
+	p.doc.TextRight(left-6, top+4, 8, pdfGray, c.Format(hi))
+	p.doc.TextRight(left-6, top+plotH+3, 8, pdfGray, c.Format(lo))
+
+	xs := make([]float64, len(rows))
+	ys := make([]float64, len(rows))
+	for i, r := range rows {
+		xs[i] = left
+		if len(rows) > 1 {
+			xs[i] = left + plotW*float64(i)/float64(len(rows)-1)
+		}
+		ys[i] = top + plotH*(hi-r.Value)/(hi-lo)
+	}
+	if len(rows) == 1 {
+		p.doc.Rect(xs[0]-2, ys[0]-2, 4, 4, pdfIndigo)
+	}
+	p.doc.Polyline(xs, ys, 1.5, pdfIndigo)
+
+	p.doc.Text(left, top+plotH+14, 8, pdfGray, rows[0].Label)
+	if last := rows[len(rows)-1].Label; len(rows) > 1 {
+		p.doc.TextRight(left+plotW, top+plotH+14, 8, pdfGray, last)
+	}
+	p.y += pdfChartPlotH
+}
+
+// barList 라벨, 가로 막대, 값 세 칸
+func (p *reportPDF) barList(rows []ReportRow, width float64) {
+	const labelW, valueW = 120.0, 80.0
+	barW := width - labelW - valueW - 16
+	for _, r := range rows {
+		p.doc.Text(pdfMargin, p.y+11, 9, pdfBlack, p.doc.FitText(r.Label, 9, labelW-8))
+		p.doc.Rect(pdfMargin+labelW, p.y+3, barW, 10, pdfLight)
+		if w := barW * r.Percent / 100; w > 0 {
+			p.doc.Rect(pdfMargin+labelW, p.y+3, w, 10, pdfIndigo)
+		}
+		p.doc.TextRight(pdfMargin+width, p.y+11, 9, pdfBlack, r.Formatted)
+		p.y += pdfBarRowH
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportNotFound  = errors.New("report not found")
+	ErrInvalidTimezone = errors.New("invalid timezone")
+)
+
+// 보고서 작업 (reports 큐). dispatch가 매분 실행 시각이 된 보고서를 찾아 send 작업을 등록
+const (
+	JobDispatchReports = "reports.dispatch"
+	JobSendReport      = "report.send"
+)
+
+const (
+	reportDispatchBatch = 50
+	reportEmailTemplate = "reports/email.html"
+)
+
+type sendReportJob struct {
+	ReportID uint `json:"report_id"`
+}
+
+type ReportService struct {
+	reportRepo       *repository.ReportRepository
+	layoutService    *LayoutService
+	dashboardService *DashboardService
+	kpiService       *KPIService
+	mailer           Mailer
+	jobs             *JobQueue
+	tmpl             *template.Template
+}
+
+// NewReportService jobs의 reports 큐에 보고서 작업을 등록. mailer는 큐를 거치지 않는 발송기
+// (보고서 작업 자체가 재시도되므로 PDF를 작업 페이로드에 다시 넣지 않음)
+func NewReportService(reportRepo *repository.ReportRepository, layoutService *LayoutService, dashboardService *DashboardService,
+	kpiService *KPIService, mailer Mailer, jobs *JobQueue, tmpl *template.Template) *ReportService {
+	s := &ReportService{
+		reportRepo:       reportRepo,
+		layoutService:    layoutService,
+		dashboardService: dashboardService,
+		kpiService:       kpiService,
+		mailer:           mailer,
+		jobs:             jobs,
+		tmpl:             tmpl,
+	}
+	jobs.Register(JobDispatchReports, "reports", HandleJob(s.dispatchJob))
+	jobs.Register(JobSendReport, "reports", HandleJob(s.sendJob))
+	jobs.Every(JobDispatchReports, time.Minute, struct{}{})
+	return s
+}
+
+func (s *ReportService) List(ownerID uint) ([]models.Report, error) {
+	return s.reportRepo.List(ownerID)
+}
+
+func (s *ReportService) Create(ownerID uint, req *models.ReportRequest) (*models.Report, error) {
+	report := &models.Report{OwnerID: ownerID}
+	if err := s.apply(report, req, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.reportRepo.Create(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) Update(id, ownerID uint, req *models.ReportRequest) (*models.Report, error) {
+	report, err := s.owned(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(report, req, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.reportRepo.Update(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) Delete(id, ownerID uint) error {
+	if _, err := s.owned(id, ownerID); err != nil {
+		return err
+	}
+	return s.reportRepo.Delete(id)
+}
+
+// SendNow 일정과 관계없이 바로 한 번 발송
+func (s *ReportService) SendNow(id, ownerID uint) error {
+	if _, err := s.owned(id, ownerID); err != nil {
+		return err
+	}
+	return s.jobs.Enqueue(JobSendReport, sendReportJob{ReportID: id}, EnqueueOptions{})
+}
+
+// PreviewHTML 지금 발송하면 보낼 HTML 본문
+func (s *ReportService) PreviewHTML(id, ownerID uint) ([]byte, error) {
+	content, err := s.buildOwned(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	// 브라우저에서는 cid: 주소를 열 수 없으므로 data: URL로
+	for i, chart := range content.Charts {
+		if png := renderReportChartPNG(chart); png != nil {
+			content.Charts[i].Image = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+		}
+	}
+	return s.renderHTML(content)
+}
+
+// PreviewPDF 지금 발송하면 첨부할 PDF
+func (s *ReportService) PreviewPDF(id, ownerID uint) ([]byte, error) {
+	content, err := s.buildOwned(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return renderReportPDF(content), nil
+}
+
+func (s *ReportService) buildOwned(id, ownerID uint) (*ReportContent, error) {
+	report, err := s.owned(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Build(report, time.Now())
+}
+
+// apply 요청 검증 (cron 식, 시간대, 대시보드 접근 권한) 후 다음 실행 시각 계산
+func (s *ReportService) apply(report *models.Report, req *models.ReportRequest, now time.Time) error {
+	schedule, err := ParseCron(req.Cron)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(req.Timezone)
+	if err != nil {
+		return ErrInvalidTimezone
+	}
+	if _, err := s.layoutService.Get(req.DashboardID, report.OwnerID); err != nil {
+		return err
+	}
+
+	req.Apply(report)
+	report.NextRunAt = nextReportRun(schedule, now, loc)
+	return nil
+}
+
+func (s *ReportService) owned(id, ownerID uint) (*models.Report, error) {
+	report, err := s.reportRepo.Find(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	if report.OwnerID != ownerID {
+		return nil, ErrReportNotFound
+	}
+	return report, nil
+}
+
+func nextReportRun(schedule *CronSchedule, now time.Time, loc *time.Location) *time.Time {
+	next := schedule.Next(now.In(loc))
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// dispatchJob 실행 시각이 된 보고서마다 발송 작업을 등록하고 다음 실행 시각으로 넘김
+func (s *ReportService) dispatchJob(ctx context.Context, _ struct{}) error {
+	now := time.Now()
+	_, err := s.reportRepo.ClaimDue(now, reportDispatchBatch, func(report *models.Report) error {
+		due := *report.NextRunAt
+		err := s.jobs.Enqueue(JobSendReport, sendReportJob{ReportID: report.ID}, EnqueueOptions{
+			// 등록 후 트랜잭션이 실패해 다시 가져가도 같은 실행은 한 번만 보냄
+			UniqueKey: fmt.Sprintf("report:%d@%d", report.ID, due.Unix()),
+		})
+		if err != nil {
+			return err
+		}
+
+		report.NextRunAt = nil
+		if schedule, err := ParseCron(report.Cron); err == nil {
+			if loc, err := time.LoadLocation(report.Timezone); err == nil {
+				report.NextRunAt = nextReportRun(schedule, now, loc)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *ReportService) sendJob(ctx context.Context, job sendReportJob) error {
+	report, err := s.reportRepo.Find(job.ReportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sendErr := s.send(report, now)
+	status, message := "sent", ""
+	if sendErr != nil {
+		status, message = "failed", truncate(sendErr.Error(), 500)
+	}
+	if err := s.reportRepo.SaveResult(report.ID, now, status, message); err != nil {
+		log.Printf("Report %d: failed to save result: %v", report.ID, err)
+	}
+	return sendErr
+}
+
+func (s *ReportService) send(report *models.Report, now time.Time) error {
+	content, err := s.Build(report, now)
+	if err != nil {
+		return err
+	}
+
+	var attachments []MailAttachment
+	for i, chart := range content.Charts {
+		png := renderReportChartPNG(chart)
+		if png == nil {
+			continue
+		}
+		cid := fmt.Sprintf("chart-%d@commet", i+1)
+		content.Charts[i].Image = template.URL("cid:" + cid)
+		attachments = append(attachments, MailAttachment{
+			Filename:    fmt.Sprintf("chart-%d.png", i+1),
+			ContentType: "image/png",
+			ContentID:   cid,
+			Data:        png,
+		})
+	}
+	if report.AttachPDF {
+		attachments = append(attachments, MailAttachment{
+			Filename:    fmt.Sprintf("%s-%s.pdf", report.Name, content.From.Format("20060102")),
+			ContentType: "application/pdf",
+			Data:        renderReportPDF(content),
+		})
+	}
+
+	html, err := s.renderHTML(content)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(Mail{
+		To:          report.RecipientList(),
+		Subject:     fmt.Sprintf("[commet] %s (%s)", report.Name, content.Period),
+		Text:        renderReportText(content),
+		HTML:        string(html),
+		Attachments: attachments,
+	})
+}
+
+func (s *ReportService) renderHTML(content *ReportContent) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&buf, reportEmailTemplate, content); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Build 보고 기간의 KPI와 대시보드 위젯 차트 데이터를 모음
+func (s *ReportService) Build(report *models.Report, now time.Time) (*ReportContent, error) {
+	loc, err := time.LoadLocation(report.Timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	view, err := s.layoutService.Get(report.DashboardID, report.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	kpis, err := s.kpiService.Compute(now, loc, Comparison{})
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := reportPeriod(now, loc, report.RangeDays)
+	content := &ReportContent{
+		Name:          report.Name,
+		DashboardName: view.Dashboard.Name,
+		From:          from,
+		To:            to,
+		Period:        formatReportPeriod(from, to),
+		GeneratedAt:   now.In(loc),
+		KPIs:          kpis,
+	}
+	for _, w := range view.Widgets {
+		chart, err := s.widgetChart(w.Widget, from, to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("widget %q: %w", w.Title, err)
+		}
+		content.Charts = append(content.Charts, chart)
+	}
+	return content, nil
+}
+
+func (s *ReportService) widgetChart(w models.Widget, from, to time.Time, loc *time.Location) (ReportChart, error) {
+	var opts models.WidgetOptions
+	_ = w.Options.Decode(&opts)
+
+	bucket, err := ParseBucket(opts.Bucket)
+	if err != nil {
+		return ReportChart{}, err
+	}
+	if opts.Bucket == "" {
+		bucket = reportBucket(to.Sub(from))
+	}
+	agg, err := ParseAggregation(opts.Agg)
+	if err != nil {
+		return ReportChart{}, err
+	}
+	// 보고서는 한 계열로 그리므로 태그 필터와 빈 구간 채우기만 반영
+	tags, err := ParseTagFilter(opts.Tags)
+	if err != nil {
+		return ReportChart{}, err
+	}
+	fill, err := ParseFillMode(opts.Fill)
+	if err != nil {
+		return ReportChart{}, err
+	}
+
+	rendered, err := s.dashboardService.RenderChart(ChartRequest{
+		Category:  w.DataSource,
+		Type:      w.Type,
+		Series:    SeriesMode(opts.Series),
+		Options:   ChartOptions{Label: opts.Label, Unit: opts.Unit, Suffix: opts.Suffix, Max: opts.Max},
+		Query:     ChartQuery{From: &from, To: &to, Bucket: bucket, Aggregation: agg, Location: loc, Tags: tags, Fill: fill},
+		Dashboard: w.DashboardID,
+	})
+	if err != nil {
+		return ReportChart{}, err
+	}
+	return ReportChart{
+		Title:       w.Title,
+		Subtitle:    w.Subtitle,
+		Type:        w.Type,
+		TimeSeries:  rendered.Mode == SeriesTime,
+		Labels:      rendered.Data.Labels,
+		Values:      rendered.Data.Values,
+		Unit:        opts.Unit,
+		Suffix:      opts.Suffix,
+		Annotations: rendered.Meta.Annotations,
+		Anomalies:   rendered.Meta.Anomalies,
+	}, nil
+}
+
+// reportPeriod 실행일 0시(loc 기준)에서 끝나는 직전 days일
+func reportPeriod(now time.Time, loc *time.Location, days int) (from, to time.Time) {
+	local := now.In(loc)
+	to = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return to.AddDate(0, 0, -days), to
+}
+
+// formatReportPeriod 종료일은 포함되지 않으므로 전날까지로 표시
+func formatReportPeriod(from, to time.Time) string {
+	last := to.AddDate(0, 0, -1)
+	if !last.After(from) {
+		return from.Format("2006-01-02")
+	}
+	return from.Format("2006-01-02") + " ~ " + last.Format("2006-01-02")
+}
+
+// reportBucket 위젯에 집계 단위가 없으면 기간 길이로 결정
+func reportBucket(span time.Duration) repository.Bucket {
+	switch {
+	case span <= 2*24*time.Hour:
+		return repository.BucketHour
+	case span <= 92*24*time.Hour:
+		return repository.BucketDay
+	default:
+		return repository.BucketWeek
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReportContent() *ReportContent {
+	delta := 12.5
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	return &ReportContent{
+		Name:          "주간 매출 보고서",
+		DashboardName: "매출",
+		From:          from,
+		To:            from.AddDate(0, 0, 7),
+		Period:        formatReportPeriod(from, from.AddDate(0, 0, 7)),
+		GeneratedAt:   from.AddDate(0, 0, 7),
+		KPIs: []KPIValue{
+			{Definition: models.KPIDefinition{Title: "총 매출"}, Formatted: "₩1,200", Delta: &delta, DeltaUnit: "%"},
+			{Definition: models.KPIDefinition{Title: "방문자"}, Formatted: "340"},
+		},
+		Charts: []ReportChart{
+			{Title: "일별 매출", TimeSeries: true, Labels: []string{"03-01", "03-02", "03-03"}, Values: []float64{10, 30, 20}, Unit: "₩"},
+			{Title: "지역별", Labels: []string{"서울", "부산", "대구"}, Values: []float64{5, 20, 10}},
+			{Title: "빈 차트"},
+		},
+	}
+}
+
+func TestReportPeriod(t *testing.T) {
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	require.NoError(t, err)
+
+	// UTC로는 전날이어도 보고서 시간대의 실행일 0시 기준
+	from, to := reportPeriod(time.Date(2024, 3, 7, 23, 30, 0, 0, time.UTC), seoul, 7)
+	assert.Equal(t, time.Date(2024, 3, 8, 0, 0, 0, 0, seoul), to)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, seoul), from)
+	assert.Equal(t, "2024-03-01 ~ 2024-03-07", formatReportPeriod(from, to))
+
+	from, to = reportPeriod(time.Date(2024, 3, 8, 9, 0, 0, 0, seoul), seoul, 1)
+	assert.Equal(t, "2024-03-07", formatReportPeriod(from, to))
+}
+
+func TestReportBucket(t *testing.T) {
+	assert.Equal(t, repository.BucketHour, reportBucket(24*time.Hour))
+	assert.Equal(t, repository.BucketDay, reportBucket(30*24*time.Hour))
+	assert.Equal(t, repository.BucketWeek, reportBucket(180*24*time.Hour))
+}
+
+func TestReportChartRows(t *testing.T) {
+	labels := ReportChart{Labels: []string{"a", "b", "c"}, Values: []float64{5, 20, 10}, Suffix: "건"}
+	rows := labels.Rows(2)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "b", rows[0].Label)
+	assert.Equal(t, "20건", rows[0].Formatted)
+	assert.InDelta(t, 100.0, rows[0].Percent, 0.001)
+	assert.InDelta(t, 50.0, rows[1].Percent, 0.001)
+	assert.Equal(t, "35건", labels.Total())
+
+	// 시계열은 순서 유지
+	series := ReportChart{TimeSeries: true, Labels: []string{"1", "2", "3"}, Values: []float64{1, 3, 2}}
+	assert.Equal(t, []string{"1", "2", "3"}, []string{series.Rows(0)[0].Label, series.Rows(0)[1].Label, series.Rows(0)[2].Label})
+}
+
+func TestRenderReportPDF(t *testing.T) {
+	content := testReportContent()
+	pdf := renderReportPDF(content)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "/Count 1")
+	// 한글은 UCS-2 hex로 기록
+	assert.Contains(t, string(pdf), "<"+pdfUCS2("주간 매출 보고서")+">")
+
+	// 차트가 많으면 페이지를 나눔
+	for i := 0; i < 10; i++ {
+		content.Charts = append(content.Charts, content.Charts[0])
+	}
+	assert.Greater(t, strings.Count(string(renderReportPDF(content)), "/Type /Page "), 1)
+}
+
+func TestPDFDocumentXref(t *testing.T) {
+	s := string(newPDFDocument().Bytes())
+	start := strings.LastIndex(s, "startxref\n")
+	require.Greater(t, start, 0)
+	var offset int
+	_, err := fmt.Sscan(s[start+len("startxref\n"):], &offset)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(s[offset:], "xref\n0 8\n"))
+
+	// xref 항목마다 "n 0 obj" 위치를 가리킴
+	entries := strings.Split(s[offset:], "\n")[3:10]
+	for i, entry := range entries {
+		pos, err := strconv.Atoi(entry[:10])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(s[pos:], fmt.Sprintf("%d 0 obj\n", i+1)), entry)
+	}
+}
+
+func TestReportEmailTemplate(t *testing.T) {
+	raw, err := os.ReadFile("../../web/templates/reports/email.html")
+	require.NoError(t, err)
+	tmpl := template.Must(template.New(reportEmailTemplate).Parse(string(raw)))
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, testReportContent()))
+	html := buf.String()
+	assert.Contains(t, html, "주간 매출 보고서")
+	assert.Contains(t, html, "2024-03-01 ~ 2024-03-07")
+	assert.Contains(t, html, "▲ 12.5%")
+	assert.Contains(t, html, "합계 ₩60")
+	assert.Contains(t, html, "width:100%;height:10px")
+	assert.Contains(t, html, "데이터 없음")
+
+	text := renderReportText(testReportContent())
+	assert.Contains(t, text, "- 총 매출: ₩1,200 (▲ 12.5%)")
+	assert.Contains(t, text, "  부산: 20")
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRetentionNotFound = errors.New("retention policy not found")
+	ErrRetentionExists   = errors.New("retention policy already exists for category")
+	// ErrInvalidRetention 롤업이 원본보다 먼저 지워지는 정책 (조회 결과에 구멍이 생김)
+	ErrInvalidRetention = errors.New("rollups must be kept at least as long as finer data")
+)
+
+// JobEnforceRetention 보관 기간이 지난 데이터를 지우는 주기 작업 (default 큐)
+const JobEnforceRetention = "retention.enforce"
+
+const (
+	// 한 문장에서 지우는 최대 행 수
+	retentionBatch = 5000
+	// 작업 한 번에 실행하는 최대 삭제 문장 수 (남은 행은 다음 실행에서)
+	retentionMaxBatches = 200
+	// 보관 기간 비교에서 한 달을 30일로 계산
+	retentionMonthDays = 30
+)
+
+// RetentionItem 정책 하나가 데이터 종류 하나에서 지운(미리보기면 지울) 행 수
+type RetentionItem struct {
+	Category string                   `json:"category"`
+	Default  bool                     `json:"default,omitempty"`
+	Data     repository.RetentionData `json:"data"`
+	Before   time.Time                `json:"before"`
+	Rows     int64                    `json:"rows"`
+}
+
+// RetentionReport 보관 정책 실행 결과. Incomplete면 삭제 한도에 걸려 다음 실행에서 이어서 지움
+type RetentionReport struct {
+	DryRun     bool            `json:"dry_run"`
+	At         time.Time       `json:"at"`
+	Items      []RetentionItem `json:"items"`
+	Incomplete bool            `json:"incomplete,omitempty"`
+}
+
+// Total 모든 항목의 행 수
+func (r *RetentionReport) Total() int64 {
+	var n int64
+	for _, item := range r.Items {
+		n += item.Rows
+	}
+	return n
+}
+
+type RetentionService struct {
+	retentionRepo repository.RetentionRepositoryInterface
+	// rolledUpOnly 롤업을 쓰면 아직 롤업에 반영되지 않은 원본은 지우지 않음
+	rolledUpOnly bool
+}
+
+func NewRetentionService(retentionRepo repository.RetentionRepositoryInterface, rolledUpOnly bool) *RetentionService {
+	return &RetentionService{retentionRepo: retentionRepo, rolledUpOnly: rolledUpOnly}
+}
+
+// Schedule jobs의 default 큐에 interval마다 정리 작업을 등록
+func (s *RetentionService) Schedule(jobs *JobQueue, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	jobs.Register(JobEnforceRetention, "default", HandleJob(s.enforceJob))
+	jobs.Every(JobEnforceRetention, interval, struct{}{})
+}
+
+func (s *RetentionService) List() ([]models.RetentionPolicy, error) {
+	return s.retentionRepo.List()
+}
+
+func (s *RetentionService) Create(req *models.RetentionPolicyRequest) (*models.RetentionPolicy, error) {
+	if err := validateRetention(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(0, req.Category); err != nil {
+		return nil, err
+	}
+	p := &models.RetentionPolicy{}
+	req.Apply(p)
+	if err := s.retentionRepo.Create(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *RetentionService) Update(id uint, req *models.RetentionPolicyRequest) (*models.RetentionPolicy, error) {
+	p, err := s.retentionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRetentionNotFound
+		}
+		return nil, err
+	}
+	if err := validateRetention(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(id, req.Category); err != nil {
+		return nil, err
+	}
+	req.Apply(p)
+	if err := s.retentionRepo.Update(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *RetentionService) Delete(id uint) error {
+	return s.retentionRepo.Delete(id)
+}
+
+// checkCategory 카테고리마다 정책은 하나 (빈 카테고리 = 기본 정책도 하나)
+func (s *RetentionService) checkCategory(id uint, category string) error {
+	policies, err := s.retentionRepo.List()
+	if err != nil {
+		return err
+	}
+	for _, p := range policies {
+		if p.Category == category && p.ID != id {
+			return ErrRetentionExists
+		}
+	}
+	return nil
+}
+
+// validateRetention 원본 ≤ 시간 롤업 ≤ 일 롤업 순으로 오래 보관해야 함 (0은 계속 보관).
+// 시계열 조회가 롤업 구간을 롤업 테이블에서 읽으므로 롤업이 먼저 지워지면 남은 원본이 보이지 않음
+func validateRetention(req *models.RetentionPolicyRequest) error {
+	days := []int{req.RawDays, req.HourlyDays, req.DailyMonths * retentionMonthDays}
+	for i := 1; i < len(days); i++ {
+		prev, cur := days[i-1], days[i]
+		if cur != 0 && (prev == 0 || prev > cur) {
+			return ErrInvalidRetention
+		}
+	}
+	return nil
+}
+
+// retentionTargets 정책마다 지울 데이터 종류와 기준 시각. 롤업은 구간이 통째로 지난 것만 지우도록 구간 시작으로 내림
+func retentionTargets(policies []models.RetentionPolicy, now time.Time, rolledUpOnly bool) []repository.RetentionTarget {
+	var explicit []string
+	for _, p := range policies {
+		if p.Category != "" {
+			explicit = append(explicit, p.Category)
+		}
+	}
+
+	var targets []repository.RetentionTarget
+	for _, p := range policies {
+		base := repository.RetentionTarget{Category: p.Category}
+		if p.Category == "" {
+			base.Default = true
+			base.Exclude = explicit
+		}
+		add := func(data repository.RetentionData, before time.Time) {
+			t := base
+			t.Data = data
+			t.Before = before
+			t.RolledUpOnly = rolledUpOnly && data == repository.RetainRaw
+			targets = append(targets, t)
+		}
+		if p.RawDays > 0 {
+			before := now.Add(-time.Duration(p.RawDays) * 24 * time.Hour)
+			add(repository.RetainRaw, before)
+			add(repository.RetainAnomalies, before)
+		}
+		if p.HourlyDays > 0 {
+			add(repository.RetainHourly, now.Add(-time.Duration(p.HourlyDays)*24*time.Hour).Truncate(time.Hour))
+		}
+		if p.DailyMonths > 0 {
+			add(repository.RetainDaily, now.UTC().AddDate(0, -p.DailyMonths, 0).Truncate(24*time.Hour))
+		}
+	}
+	return targets
+}
+
+func (s *RetentionService) enforceJob(ctx context.Context, _ struct{}) error {
+	report, err := s.Enforce(ctx, time.Now(), false)
+	if err != nil {
+		return err
+	}
+	if n := report.Total(); n > 0 {
+		log.Printf("Retention: deleted %d rows (incomplete=%v)", n, report.Incomplete)
+	}
+	return nil
+}
+
+// Enforce 정책에 따라 오래된 데이터를 나눠 지움. dryRun이면 지우지 않고 지울 행 수만 셈
+func (s *RetentionService) Enforce(ctx context.Context, now time.Time, dryRun bool) (*RetentionReport, error) {
+	policies, err := s.retentionRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RetentionReport{DryRun: dryRun, At: now, Items: []RetentionItem{}}
+	budget := retentionMaxBatches
+	for _, t := range retentionTargets(policies, now, s.rolledUpOnly) {
+		item := RetentionItem{Category: t.Category, Default: t.Default, Data: t.Data, Before: t.Before}
+		if dryRun {
+			if item.Rows, err = s.retentionRepo.Count(t); err != nil {
+				return nil, err
+			}
+			report.Items = append(report.Items, item)
+			continue
+		}
+
+		for {
+			if budget == 0 || ctx.Err() != nil {
+				report.Incomplete = true
+				break
+			}
+			n, err := s.retentionRepo.DeleteBatch(t, retentionBatch)
+			if err != nil {
+				return nil, err
+			}
+			budget--
+			item.Rows += n
+			if n < retentionBatch {
+				break
+			}
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRetentionRepository is a mock implementation of RetentionRepositoryInterface
+type MockRetentionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRetentionRepository) List() ([]models.RetentionPolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]models.RetentionPolicy), args.Error(1)
+}
+
+func (m *MockRetentionRepository) FindByID(id uint) (*models.RetentionPolicy, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RetentionPolicy), args.Error(1)
+}
+
+func (m *MockRetentionRepository) Create(p *models.RetentionPolicy) error {
+	return m.Called(p).Error(0)
+}
+
+func (m *MockRetentionRepository) Update(p *models.RetentionPolicy) error {
+	return m.Called(p).Error(0)
+}
+
+func (m *MockRetentionRepository) Delete(id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockRetentionRepository) Count(t repository.RetentionTarget) (int64, error) {
+	args := m.Called(t)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRetentionRepository) DeleteBatch(t repository.RetentionTarget, limit int) (int64, error) {
+	args := m.Called(t, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// retentionData 대상의 데이터 종류가 data인지
+func retentionData(data repository.RetentionData) any {
+	return mock.MatchedBy(func(t repository.RetentionTarget) bool { return t.Data == data })
+}
+
+func TestValidateRetention(t *testing.T) {
+	valid := []models.RetentionPolicyRequest{
+		{},
+		{RawDays: 7},
+		{RawDays: 7, HourlyDays: 90, DailyMonths: 24},
+		{RawDays: 30, HourlyDays: 30, DailyMonths: 1},
+		{RawDays: 7, DailyMonths: 0},
+	}
+	for _, req := range valid {
+		assert.NoError(t, validateRetention(&req), "%+v", req)
+	}
+
+	invalid := []models.RetentionPolicyRequest{
+		// 시간 롤업이 원본보다 먼저 지워짐
+		{RawDays: 30, HourlyDays: 7},
+		// 원본은 계속 보관하는데 롤업은 지움
+		{HourlyDays: 7},
+		{RawDays: 7, HourlyDays: 90, DailyMonths: 1},
+	}
+	for _, req := range invalid {
+		assert.ErrorIs(t, validateRetention(&req), ErrInvalidRetention, "%+v", req)
+	}
+}
+
+func TestRetentionTargets(t *testing.T) {
+	now := time.Date(2024, 5, 31, 13, 45, 0, 0, time.UTC)
+	policies := []models.RetentionPolicy{
+		{Category: "", RawDays: 30, DailyMonths: 12},
+		{Category: "cpu", RawDays: 7, HourlyDays: 90, DailyMonths: 3},
+		{Category: "logs"},
+	}
+
+	targets := retentionTargets(policies, now, true)
+	require.Len(t, targets, 7)
+
+	// 기본 정책은 자기 정책이 있는 카테고리를 제외
+	raw := targets[0]
+	assert.True(t, raw.Default)
+	assert.Equal(t, repository.RetainRaw, raw.Data)
+	assert.Equal(t, []string{"cpu", "logs"}, raw.Exclude)
+	assert.Equal(t, now.AddDate(0, 0, -30), raw.Before)
+	assert.True(t, raw.RolledUpOnly)
+
+	// 이상치는 원본과 같은 기준이지만 롤업 여부와 무관
+	assert.Equal(t, repository.RetainAnomalies, targets[1].Data)
+	assert.Equal(t, raw.Before, targets[1].Before)
+	assert.False(t, targets[1].RolledUpOnly)
+
+	assert.Equal(t, repository.RetainDaily, targets[2].Data)
+	assert.Equal(t, time.Date(2023, 5, 31, 0, 0, 0, 0, time.UTC), targets[2].Before)
+
+	cpu := targets[3:]
+	for _, target := range cpu {
+		assert.Equal(t, "cpu", target.Category)
+		assert.False(t, target.Default)
+		assert.Empty(t, target.Exclude)
+	}
+	// 롤업 기준은 구간 시작으로 내림
+	assert.Equal(t, repository.RetainHourly, cpu[2].Data)
+	assert.Equal(t, time.Date(2024, 3, 2, 13, 0, 0, 0, time.UTC), cpu[2].Before)
+	assert.Equal(t, repository.RetainDaily, cpu[3].Data)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), cpu[3].Before)
+
+	// 롤업을 쓰지 않으면 원본을 바로 지움
+	for _, target := range retentionTargets(policies, now, false) {
+		assert.False(t, target.RolledUpOnly)
+	}
+}
+
+func TestRetentionEnforce_DeletesInBatches(t *testing.T) {
+	repo := new(MockRetentionRepository)
+	repo.On("List").Return([]models.RetentionPolicy{{Category: "cpu", RawDays: 7}}, nil)
+	// 한 번에 retentionBatch개를 지우면 더 남았을 수 있으니 모자랄 때까지 반복
+	repo.On("DeleteBatch", retentionData(repository.RetainRaw), retentionBatch).Return(int64(retentionBatch), nil).Twice()
+	repo.On("DeleteBatch", retentionData(repository.RetainRaw), retentionBatch).Return(int64(12), nil).Once()
+	repo.On("DeleteBatch", retentionData(repository.RetainAnomalies), retentionBatch).Return(int64(0), nil).Once()
+
+	now := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	report, err := NewRetentionService(repo, true).Enforce(context.Background(), now, false)
+	require.NoError(t, err)
+	assert.False(t, report.Incomplete)
+	require.Len(t, report.Items, 2)
+	assert.Equal(t, int64(2*retentionBatch+12), report.Items[0].Rows)
+	assert.Equal(t, int64(0), report.Items[1].Rows)
+	assert.Equal(t, int64(2*retentionBatch+12), report.Total())
+	repo.AssertExpectations(t)
+
+	// 롤업을 쓰면 원본은 롤업에 반영된 행만 지움
+	repo.AssertCalled(t, "DeleteBatch", mock.MatchedBy(func(t repository.RetentionTarget) bool {
+		return t.Data == repository.RetainRaw && t.RolledUpOnly && t.Category == "cpu" && t.Before.Equal(now.AddDate(0, 0, -7))
+	}), retentionBatch)
+}
+
+func TestRetentionEnforce_StopsAtBudget(t *testing.T) {
+	repo := new(MockRetentionRepository)
+	repo.On("List").Return([]models.RetentionPolicy{{RawDays: 1}}, nil)
+	repo.On("DeleteBatch", mock.Anything, retentionBatch).Return(int64(retentionBatch), nil)
+
+	report, err := NewRetentionService(repo, false).Enforce(context.Background(), time.Now(), false)
+	require.NoError(t, err)
+	assert.True(t, report.Incomplete)
+	repo.AssertNumberOfCalls(t, "DeleteBatch", retentionMaxBatches)
+
+	// 취소되면 지우지 않고 다음 실행으로 넘김
+	repo = new(MockRetentionRepository)
+	repo.On("List").Return([]models.RetentionPolicy{{RawDays: 1}}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = NewRetentionService(repo, false).Enforce(ctx, time.Now(), false)
+	require.NoError(t, err)
+	assert.True(t, report.Incomplete)
+	repo.AssertNotCalled(t, "DeleteBatch", mock.Anything, mock.Anything)
+}
+
+func TestRetentionEnforce_DryRunOnlyCounts(t *testing.T) {
+	repo := new(MockRetentionRepository)
+	repo.On("List").Return([]models.RetentionPolicy{{Category: "cpu", RawDays: 7, HourlyDays: 30}}, nil)
+	repo.On("Count", mock.Anything).Return(int64(42), nil)
+
+	report, err := NewRetentionService(repo, false).Enforce(context.Background(), time.Now(), true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Items, 3)
+	assert.Equal(t, int64(3*42), report.Total())
+	repo.AssertNotCalled(t, "DeleteBatch", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+)
+
+// JobRefreshRollups 새 원본 데이터를 시간·일 롤업 테이블에 반영하는 주기 작업 (default 큐)
+const JobRefreshRollups = "rollup.refresh"
+
+const (
+	// 한 번에 반영하는 원본 행 수
+	rollupBatch = 50000
+	// 작업 한 번에 반영하는 최대 횟수 (밀린 데이터는 다음 실행에서 이어서)
+	rollupMaxRounds = 20
+)
+
+// RollupService 롤업 테이블을 주기적으로 갱신. 조회는 DashboardRepository.UseRollups
+type RollupService struct {
+	rollupRepo repository.RollupRepositoryInterface
+}
+
+// NewRollupService jobs의 default 큐에 interval마다 갱신 작업을 등록
+func NewRollupService(rollupRepo repository.RollupRepositoryInterface, jobs *JobQueue, interval time.Duration) *RollupService {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	s := &RollupService{rollupRepo: rollupRepo}
+	jobs.Register(JobRefreshRollups, "default", HandleJob(s.refreshJob))
+	jobs.Every(JobRefreshRollups, interval, struct{}{})
+	return s
+}
+
+func (s *RollupService) refreshJob(ctx context.Context, _ struct{}) error {
+	return s.Refresh(ctx)
+}
+
+// Refresh 커밋이 확정된 데이터를 반영 (그 뒤의 데이터는 다음 실행에서)
+func (s *RollupService) Refresh(ctx context.Context) error {
+	for i := 0; i < rollupMaxRounds && ctx.Err() == nil; i++ {
+		done, err := s.rollupRepo.Advance(rollupBatch)
+		if err != nil || done {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRollupRepository is a mock implementation of RollupRepositoryInterface
+type MockRollupRepository struct {
+	mock.Mock
+}
+
+func (m *MockRollupRepository) Advance(batch uint) (bool, error) {
+	args := m.Called(batch)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestPlanRollup(t *testing.T) {
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	require.NoError(t, err)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	from := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 8, 15, 0, 0, time.UTC)
+	q := repository.TimeSeriesQuery{Category: "sales", From: from, To: to, Bucket: repository.BucketDay, Location: time.UTC}
+
+	// UTC 일 단위: 온전한 날만 일 롤업, 앞뒤 남는 시간은 원본
+	plan, ok := repository.PlanRollup(q)
+	require.True(t, ok)
+	assert.Equal(t, repository.RollupDaily, plan.Rollup)
+	assert.Equal(t, "dashboard_data_daily", plan.Table)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), plan.From)
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), plan.To)
+
+	// 시간 단위 버킷은 일 롤업을 쓰지 않음
+	q.Bucket = repository.BucketHour
+	plan, ok = repository.PlanRollup(q)
+	require.True(t, ok)
+	assert.Equal(t, repository.RollupHourly, plan.Rollup)
+	assert.Equal(t, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), plan.From)
+	assert.Equal(t, time.Date(2024, 3, 31, 8, 0, 0, 0, time.UTC), plan.To)
+
+	// 서울 자정은 UTC 15시라 일 롤업 대신 시간 롤업
+	q.Bucket = repository.BucketMonth
+	q.Location = seoul
+	plan, ok = repository.PlanRollup(q)
+	require.True(t, ok)
+	assert.Equal(t, repository.RollupHourly, plan.Rollup)
+
+	// 30분 오프셋 시간대는 롤업 경계와 맞지 않음
+	q.Location = kolkata
+	_, ok = repository.PlanRollup(q)
+	assert.False(t, ok)
+
+	// 온전한 구간이 없으면 원본
+	q.Location = time.UTC
+	q.Bucket = repository.BucketHour
+	q.From, q.To = from, from.Add(20*time.Minute)
+	_, ok = repository.PlanRollup(q)
+	assert.False(t, ok)
+}
+
+func TestPlanRollupDST(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	// 겨울(UTC+0)에는 일 롤업, 서머타임(UTC+1)이 끼면 시간 롤업
+	q := repository.TimeSeriesQuery{
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, london),
+		To:       time.Date(2024, 2, 1, 0, 0, 0, 0, london),
+		Bucket:   repository.BucketDay,
+		Location: london,
+	}
+	plan, ok := repository.PlanRollup(q)
+	require.True(t, ok)
+	assert.Equal(t, repository.RollupDaily, plan.Rollup)
+
+	q.To = time.Date(2024, 12, 1, 0, 0, 0, 0, london)
+	plan, ok = repository.PlanRollup(q)
+	require.True(t, ok)
+	assert.Equal(t, repository.RollupHourly, plan.Rollup)
+}
+
+func TestAdvanceHorizon(t *testing.T) {
+	state := &models.RollupState{}
+
+	// 처음 본 최대 ID는 보류
+	assert.False(t, repository.AdvanceHorizon(state, repository.RollupSnapshot{MaxID: 100, Xmin: 500, Xmax: 510}))
+	assert.Equal(t, uint(0), state.Horizon)
+	assert.Equal(t, uint(100), state.PendingID)
+
+	// 그때 진행 중이던 트랜잭션(505)이 아직 안 끝났으면 그 사이 더 큰 ID가 커밋돼도 기다림
+	assert.False(t, repository.AdvanceHorizon(state, repository.RollupSnapshot{MaxID: 150, Xmin: 505, Xmax: 520}))
+	assert.Equal(t, uint(0), state.Horizon)
+	assert.Equal(t, uint(100), state.PendingID)
+
+	// 모두 끝나면 보류한 ID까지 반영하고 새 최대 ID를 보류
+	assert.True(t, repository.AdvanceHorizon(state, repository.RollupSnapshot{MaxID: 150, Xmin: 515, Xmax: 520}))
+	assert.Equal(t, uint(100), state.Horizon)
+	assert.Equal(t, uint(150), state.PendingID)
+	assert.Equal(t, uint64(520), state.PendingXID)
+
+	// Horizon까지 반영한 뒤 다음 끝으로, 반영할 행이 없으면 false
+	state.LastID = 100
+	assert.True(t, repository.AdvanceHorizon(state, repository.RollupSnapshot{MaxID: 150, Xmin: 520, Xmax: 521}))
+	assert.Equal(t, uint(150), state.Horizon)
+	state.LastID = 150
+	assert.False(t, repository.AdvanceHorizon(state, repository.RollupSnapshot{MaxID: 150, Xmin: 521, Xmax: 522}))
+}
+
+func TestRollupRefresh(t *testing.T) {
+	newService := func(repo *MockRollupRepository) *RollupService {
+		jobs := NewJobQueue(nil, 0)
+		jobs.AddQueue("default", QueueOptions{})
+		return NewRollupService(repo, jobs, time.Minute)
+	}
+
+	// 다 반영할 때까지 batch씩 반복
+	repo := new(MockRollupRepository)
+	repo.On("Advance", uint(rollupBatch)).Return(false, nil).Twice()
+	repo.On("Advance", uint(rollupBatch)).Return(true, nil).Once()
+	require.NoError(t, newService(repo).Refresh(context.Background()))
+	repo.AssertNumberOfCalls(t, "Advance", 3)
+
+	// 밀린 데이터는 한 번에 rollupMaxRounds까지만
+	repo = new(MockRollupRepository)
+	repo.On("Advance", uint(rollupBatch)).Return(false, nil)
+	require.NoError(t, newService(repo).Refresh(context.Background()))
+	repo.AssertNumberOfCalls(t, "Advance", rollupMaxRounds)
+
+	repo = new(MockRollupRepository)
+	repo.On("Advance", uint(rollupBatch)).Return(false, errors.New("db down")).Once()
+	assert.EqualError(t, newService(repo).Refresh(context.Background()), "db down")
+	repo.AssertNumberOfCalls(t, "Advance", 1)
+}
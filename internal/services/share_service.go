@@ -0,0 +1,313 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShareLinkNotFound  = errors.New("share link not found")
+	ErrShareLinkExpired   = errors.New("share link expired")
+	ErrInvalidEmbedOrigin = errors.New("invalid embed origin")
+)
+
+// ShareUnlockTTL 비밀번호를 한 번 입력하면 다시 묻지 않는 시간
+const ShareUnlockTTL = 12 * time.Hour
+
+// ShareLinkView 관리 화면·API용 (공개 주소의 토큰 포함)
+type ShareLinkView struct {
+	models.ShareLink
+	Token       string `json:"token"`
+	Path        string `json:"path"`
+	EmbedPath   string `json:"embed_path"`
+	WidgetTitle string `json:"widget_title,omitempty"`
+	HasPassword bool   `json:"has_password"`
+}
+
+// ShareView 공개 링크로 보는 대시보드. 링크가 차트 하나면 그 위젯만 포함
+type ShareView struct {
+	Link      *models.ShareLink
+	Dashboard models.Dashboard
+	Widgets   []models.Widget
+}
+
+type ShareService struct {
+	shareRepo     *repository.ShareRepository
+	layoutService *LayoutService
+	secret        []byte
+}
+
+// NewShareService 서명 키가 비어 있으면 임의 키를 쓰므로 재시작하면 기존 링크가 무효가 됨
+func NewShareService(shareRepo *repository.ShareRepository, layoutService *LayoutService, secret string) *ShareService {
+	if secret == "" {
+		secret = randomHex(32)
+	}
+	return &ShareService{
+		shareRepo:     shareRepo,
+		layoutService: layoutService,
+		secret:        []byte(secret),
+	}
+}
+
+// List 대시보드 소유자만 공개 링크를 볼 수 있음
+func (s *ShareService) List(dashboardID, userID uint) ([]ShareLinkView, error) {
+	d, err := s.layoutService.owned(dashboardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.shareRepo.ListByDashboard(d.ID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]ShareLinkView, len(links))
+	for i, link := range links {
+		views[i] = s.view(link, d)
+	}
+	return views, nil
+}
+
+func (s *ShareService) Create(dashboardID, userID uint, req *models.ShareLinkRequest) (*ShareLinkView, error) {
+	d, err := s.layoutService.owned(dashboardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	origins, err := normalizeEmbedOrigins(req.EmbedOrigins)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.ShareLink{
+		OwnerID:      userID,
+		DashboardID:  d.ID,
+		EmbedOrigins: origins,
+		// 토큰에 초 단위로 들어가므로 DB 값과 맞춤
+		ExpiresAt: time.Now().AddDate(0, 0, req.ExpiresInDays).Truncate(time.Second),
+	}
+	if req.WidgetID != nil && *req.WidgetID != 0 {
+		if findWidget(d.Widgets, *req.WidgetID) == nil {
+			return nil, ErrWidgetNotFound
+		}
+		link.WidgetID = req.WidgetID
+	}
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), 12)
+		if err != nil {
+			return nil, err
+		}
+		link.PasswordHash = string(hash)
+	}
+
+	if err := s.shareRepo.Create(link); err != nil {
+		return nil, err
+	}
+	v := s.view(*link, d)
+	return &v, nil
+}
+
+// Revoke 폐기한 링크는 다시 살릴 수 없음 (새로 만들어야 함)
+func (s *ShareService) Revoke(dashboardID, id, userID uint) error {
+	if _, err := s.layoutService.owned(dashboardID, userID); err != nil {
+		return err
+	}
+	link, err := s.shareRepo.Find(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShareLinkNotFound
+		}
+		return err
+	}
+	if link.DashboardID != dashboardID {
+		return ErrShareLinkNotFound
+	}
+	if link.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	link.RevokedAt = &now
+	return s.shareRepo.Revoke(link)
+}
+
+// Resolve 공개 주소의 토큰으로 링크 조회. 서명이 틀리면 DB를 보지 않고 거절
+func (s *ShareService) Resolve(token string) (*models.ShareLink, error) {
+	id, expires, err := parseShareToken(s.secret, token)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(expires) {
+		return nil, ErrShareLinkExpired
+	}
+	link, err := s.shareRepo.Find(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, err
+	}
+	if link.ExpiresAt.Unix() != expires.Unix() || link.RevokedAt != nil {
+		return nil, ErrShareLinkNotFound
+	}
+	return link, nil
+}
+
+// CheckPassword 비밀번호가 없는 링크는 항상 통과
+func (s *ShareService) CheckPassword(link *models.ShareLink, password string) bool {
+	if !link.HasPassword() {
+		return true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) == nil
+}
+
+// UnlockToken 비밀번호 확인 후 쿠키에 넣는 값. 비밀번호를 바꾸거나 링크를 다시 만들면 무효
+func (s *ShareService) UnlockToken(link *models.ShareLink, now time.Time) string {
+	payload := strconv.FormatInt(now.Add(ShareUnlockTTL).Unix(), 36)
+	return payload + "." + s.unlockSignature(link, payload)
+}
+
+// Unlocked 비밀번호가 없거나 유효한 UnlockToken
+func (s *ShareService) Unlocked(link *models.ShareLink, value string, now time.Time) bool {
+	if !link.HasPassword() {
+		return true
+	}
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.unlockSignature(link, payload))) {
+		return false
+	}
+	expires, err := strconv.ParseInt(payload, 36, 64)
+	return err == nil && now.Unix() < expires
+}
+
+func (s *ShareService) unlockSignature(link *models.ShareLink, payload string) string {
+	return shareSignature(s.secret, "unlock:"+strconv.FormatUint(uint64(link.ID), 36)+":"+link.PasswordHash+":"+payload)
+}
+
+// View 링크 범위의 대시보드와 위젯
+func (s *ShareService) View(link *models.ShareLink) (*ShareView, error) {
+	d, err := s.layoutService.find(link.DashboardID)
+	if err != nil {
+		if errors.Is(err, ErrDashboardNotFound) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, err
+	}
+	v := &ShareView{Link: link, Dashboard: *d, Widgets: d.Widgets}
+	if link.WidgetID != nil {
+		w := findWidget(d.Widgets, *link.WidgetID)
+		if w == nil {
+			return nil, ErrShareLinkNotFound
+		}
+		v.Widgets = []models.Widget{*w}
+	}
+	return v, nil
+}
+
+// Widget 링크 범위 안의 위젯 하나 (다른 위젯 ID로 데이터를 보지 못하게)
+func (s *ShareService) Widget(link *models.ShareLink, widgetID uint) (*models.Widget, error) {
+	v, err := s.View(link)
+	if err != nil {
+		return nil, err
+	}
+	if w := findWidget(v.Widgets, widgetID); w != nil {
+		return w, nil
+	}
+	return nil, ErrWidgetNotFound
+}
+
+func (s *ShareService) view(link models.ShareLink, d *models.Dashboard) ShareLinkView {
+	token := signShareToken(s.secret, link.ID, link.ExpiresAt)
+	v := ShareLinkView{
+		ShareLink:   link,
+		Token:       token,
+		Path:        "/share/" + token,
+		EmbedPath:   "/embed/" + token,
+		HasPassword: link.HasPassword(),
+	}
+	if link.WidgetID != nil {
+		if w := findWidget(d.Widgets, *link.WidgetID); w != nil {
+			v.WidgetTitle = w.Title
+		}
+	}
+	return v
+}
+
+func findWidget(widgets []models.Widget, id uint) *models.Widget {
+	for i := range widgets {
+		if widgets[i].ID == id {
+			return &widgets[i]
+		}
+	}
+	return nil
+}
+
+// signShareToken "<링크 ID>.<만료 unix 초>.<서명>" (숫자는 36진수). 폐기 여부는 DB에서 다시 확인
+func signShareToken(secret []byte, id uint, expires time.Time) string {
+	payload := strconv.FormatUint(uint64(id), 36) + "." + strconv.FormatInt(expires.Unix(), 36)
+	return payload + "." + shareSignature(secret, "share:"+payload)
+}
+
+func parseShareToken(secret []byte, token string) (uint, time.Time, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return 0, time.Time{}, ErrShareLinkNotFound
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(shareSignature(secret, "share:"+payload))) {
+		return 0, time.Time{}, ErrShareLinkNotFound
+	}
+	idPart, expPart, _ := strings.Cut(payload, ".")
+	id, err := strconv.ParseUint(idPart, 36, 64)
+	if err != nil {
+		return 0, time.Time{}, ErrShareLinkNotFound
+	}
+	exp, err := strconv.ParseInt(expPart, 36, 64)
+	if err != nil {
+		return 0, time.Time{}, ErrShareLinkNotFound
+	}
+	return uint(id), time.Unix(exp, 0), nil
+}
+
+// shareSignature URL에 그대로 넣을 수 있는 HMAC-SHA256 (앞 144비트)
+func shareSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
+
+// 헤더 값에 다른 지시어를 끼워 넣지 못하도록 호스트 문자를 제한 (*.example.com 허용)
+var embedHostPattern = regexp.MustCompile(`^(\*\.)?[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*(:[0-9]{1,5})?$`)
+
+// normalizeEmbedOrigins 공백이나 쉼표로 구분한 출처를 frame-ancestors 값으로 정리.
+// "*" 또는 "https://intra.example.com", "https://*.example.com:8443" 형식만 허용
+func normalizeEmbedOrigins(s string) (string, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	seen := map[string]bool{}
+	var origins []string
+	for _, f := range fields {
+		origin := f
+		if f != "*" {
+			u, err := url.Parse(f)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil ||
+				(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || !embedHostPattern.MatchString(u.Host) {
+				return "", ErrInvalidEmbedOrigin
+			}
+			origin = u.Scheme + "://" + strings.ToLower(u.Host)
+		}
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	return strings.Join(origins, " "), nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestShareToken(t *testing.T) {
+	secret := []byte("secret")
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	token := signShareToken(secret, 42, expires)
+
+	id, exp, err := parseShareToken(secret, token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), id)
+	assert.True(t, exp.Equal(expires))
+
+	// 다른 키, ID나 만료 시각을 바꾼 토큰은 거절
+	_, _, err = parseShareToken([]byte("other"), token)
+	assert.ErrorIs(t, err, ErrShareLinkNotFound)
+	parts := strings.Split(token, ".")
+	parts[0] = strconv.FormatUint(43, 36)
+	_, _, err = parseShareToken(secret, strings.Join(parts, "."))
+	assert.ErrorIs(t, err, ErrShareLinkNotFound)
+	parts = strings.Split(token, ".")
+	parts[1] = strconv.FormatInt(expires.AddDate(1, 0, 0).Unix(), 36)
+	_, _, err = parseShareToken(secret, strings.Join(parts, "."))
+	assert.ErrorIs(t, err, ErrShareLinkNotFound)
+	for _, bad := range []string{"", "abc", token + "x", "x." + token} {
+		_, _, err = parseShareToken(secret, bad)
+		assert.ErrorIs(t, err, ErrShareLinkNotFound, bad)
+	}
+}
+
+func TestShareUnlock(t *testing.T) {
+	s := NewShareService(nil, nil, "secret")
+	hash, err := bcrypt.GenerateFromPassword([]byte("pass1234"), bcrypt.MinCost)
+	require.NoError(t, err)
+	link := &models.ShareLink{ID: 7, PasswordHash: string(hash)}
+	now := time.Now()
+
+	assert.True(t, s.CheckPassword(link, "pass1234"))
+	assert.False(t, s.CheckPassword(link, "wrong"))
+
+	cookie := s.UnlockToken(link, now)
+	assert.True(t, s.Unlocked(link, cookie, now))
+	assert.False(t, s.Unlocked(link, cookie, now.Add(ShareUnlockTTL+time.Second)))
+	assert.False(t, s.Unlocked(link, "", now))
+	assert.False(t, s.Unlocked(&models.ShareLink{ID: 8, PasswordHash: string(hash)}, cookie, now))
+
+	// 비밀번호가 바뀌면 이전 쿠키는 무효
+	other, _ := bcrypt.GenerateFromPassword([]byte("pass1234"), bcrypt.MinCost)
+	assert.False(t, s.Unlocked(&models.ShareLink{ID: 7, PasswordHash: string(other)}, cookie, now))
+
+	// 비밀번호 없는 링크는 항상 열림
+	assert.True(t, s.Unlocked(&models.ShareLink{ID: 9}, "", now))
+}
+
+func TestNormalizeEmbedOrigins(t *testing.T) {
+	got, err := normalizeEmbedOrigins(" https://Intra.Example.com/, https://*.example.com:8443\nhttp://localhost:3000 https://intra.example.com ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://intra.example.com https://*.example.com:8443 http://localhost:3000", got)
+
+	got, err = normalizeEmbedOrigins("*")
+	require.NoError(t, err)
+	assert.Equal(t, "*", got)
+
+	got, err = normalizeEmbedOrigins("")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	for _, bad := range []string{
+		"intra.example.com",
+		"javascript:alert(1)",
+		"https://a.com/path",
+		"https://a.com?x=1",
+		"https://user@a.com",
+		"https://a.com;script-src",
+		"https://'self'",
+		"ftp://a.com",
+	} {
+		_, err := normalizeEmbedOrigins(bad)
+		assert.ErrorIs(t, err, ErrInvalidEmbedOrigin, bad)
+	}
+}
+
+func TestShareLinkFrameAncestors(t *testing.T) {
+	assert.Equal(t, "'none'", (&models.ShareLink{}).FrameAncestors())
+	assert.Equal(t, "https://a.com", (&models.ShareLink{EmbedOrigins: "https://a.com"}).FrameAncestors())
+
+	now := time.Now()
+	assert.True(t, (&models.ShareLink{ExpiresAt: now.Add(time.Hour)}).Active(now))
+	assert.False(t, (&models.ShareLink{ExpiresAt: now}).Active(now))
+	assert.False(t, (&models.ShareLink{ExpiresAt: now.Add(time.Hour), RevokedAt: &now}).Active(now))
+}
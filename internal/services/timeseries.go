@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+)
+
+var (
+	ErrInvalidBucket      = errors.New("invalid bucket")
+	ErrInvalidAggregation = errors.New("invalid aggregation")
+	ErrInvalidRange       = errors.New("invalid time range")
+	ErrTooManyBuckets     = errors.New("too many buckets for time range")
+	ErrInvalidFill        = errors.New("invalid fill mode")
+)
+
+// FillMode 시계열에서 값이 없는 버킷을 채우는 방식
+type FillMode string
+
+const (
+	// FillAuto 합계/건수는 FillZero, 평균/최소/최대는 FillNull
+	FillAuto FillMode = ""
+	FillZero FillMode = "zero"
+	// FillNull 값 없음 (단일 계열은 항목을 생략, 여러 계열은 null)
+	FillNull FillMode = "null"
+	// FillPrevious 직전 버킷 값 (첫 값 이전은 FillNull과 같음)
+	FillPrevious FillMode = "previous"
+)
+
+func ParseFillMode(s string) (FillMode, error) {
+	switch f := FillMode(s); f {
+	case FillAuto, FillZero, FillNull, FillPrevious:
+		return f, nil
+	}
+	return "", ErrInvalidFill
+}
+
+// resolveFill FillAuto를 집계 함수에 맞는 방식으로
+func resolveFill(f FillMode, agg repository.Aggregation) FillMode {
+	if f != FillAuto {
+		return f
+	}
+	if agg == repository.AggSum || agg == repository.AggCount {
+		return FillZero
+	}
+	return FillNull
+}
+
+// fillGaps 버킷 순서의 값에서 nil을 f(FillAuto 제외) 방식으로 채움
+func fillGaps(values []*float64, f FillMode) {
+	var prev *float64
+	for i, v := range values {
+		switch {
+		case v != nil:
+			prev = v
+		case f == FillZero:
+			values[i] = new(float64)
+		case f == FillPrevious && prev != nil:
+			p := *prev
+			values[i] = &p
+		}
+	}
+}
+
+// 한 차트에 그릴 수 있는 최대 버킷 수 (예: 시간 단위로 약 3개월)
+const maxBuckets = 2500
+
+// ChartQuery 차트 조회 조건. From/To가 없으면 기간 필터 없이 전체 데이터 사용
+type ChartQuery struct {
+	From        *time.Time
+	To          *time.Time
+	Bucket      repository.Bucket
+	Aggregation repository.Aggregation
+	Location    *time.Location
+	// Compare 시계열 차트에 함께 그릴 비교 기간 (비어 있으면 없음)
+	Compare Comparison
+	// Forecast 시계열 차트 뒤에 이어 그릴 예측 (비어 있으면 없음)
+	Forecast ForecastOptions
+	// Tags 모든 태그가 일치하는 데이터만 집계
+	Tags map[string]string
+	// GroupBy 이 태그의 값별로 나눔 (시계열은 계열, 라벨별 차트는 항목). 비교·예측은 그리지 않음
+	GroupBy string
+	// SplitLabels 시계열을 라벨별 계열로 나눔 (GroupBy와 함께 쓸 수 없음). 비교·예측은 그리지 않음
+	SplitLabels bool
+	// Fill 시계열의 빈 버킷 채우기
+	Fill FillMode
+}
+
+// HasRange 기간 필터 사용 여부
+func (q ChartQuery) HasRange() bool {
+	return q.From != nil || q.To != nil
+}
+
+// Dimensional 태그 필터나 그룹 사용 여부. 행 단위로 그리지 않고 항상 기간 집계
+func (q ChartQuery) Dimensional() bool {
+	return len(q.Tags) > 0 || q.GroupBy != ""
+}
+
+// MultiSeries 시계열을 여러 계열로 나누는지 여부
+func (q ChartQuery) MultiSeries() bool {
+	return q.GroupBy != "" || q.SplitLabels
+}
+
+func ParseBucket(s string) (repository.Bucket, error) {
+	if s == "" {
+		return repository.BucketDay, nil
+	}
+	b := repository.Bucket(s)
+	if !b.Valid() {
+		return "", ErrInvalidBucket
+	}
+	return b, nil
+}
+
+func ParseAggregation(s string) (repository.Aggregation, error) {
+	if s == "" {
+		return repository.AggSum, nil
+	}
+	a := repository.Aggregation(s)
+	if !a.Valid() {
+		return "", ErrInvalidAggregation
+	}
+	return a, nil
+}
+
+// truncateTime PostgreSQL date_trunc와 같은 규칙으로 버킷 시작 시각 계산 (주는 월요일 시작)
+func truncateTime(t time.Time, b repository.Bucket, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch b {
+	case repository.BucketHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case repository.BucketWeek:
+		d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		offset := (int(d.Weekday()) + 6) % 7
+		return d.AddDate(0, 0, -offset)
+	case repository.BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+func nextBucket(t time.Time, b repository.Bucket) time.Time {
+	switch b {
+	case repository.BucketHour:
+		return t.Add(time.Hour)
+	case repository.BucketWeek:
+		return t.AddDate(0, 0, 7)
+	case repository.BucketMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// bucketStarts [from, to) 구간의 모든 버킷 시작 시각
+func bucketStarts(from, to time.Time, b repository.Bucket, loc *time.Location) ([]time.Time, error) {
+	var starts []time.Time
+	for t := truncateTime(from, b, loc); t.Before(to); t = nextBucket(t, b) {
+		if len(starts) == maxBuckets {
+			return nil, ErrTooManyBuckets
+		}
+		starts = append(starts, t)
+	}
+	return starts, nil
+}
+
+func bucketLabel(t time.Time, b repository.Bucket) string {
+	switch b {
+	case repository.BucketHour:
+		return t.Format("01-02 15:00")
+	case repository.BucketMonth:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// alignTimeSeries 빈 버킷을 fill(FillAuto 제외) 방식으로 채워 연속된 축으로 정렬. 채우지 못한 버킷은 생략
+func alignTimeSeries(points []repository.TimeSeriesPoint, starts []time.Time, b repository.Bucket, fill FillMode) *ChartData {
+	index := make(map[int64]int, len(starts))
+	for i, t := range starts {
+		index[t.Unix()] = i
+	}
+	values := make([]*float64, len(starts))
+	for _, p := range points {
+		if i, ok := index[p.Bucket.Unix()]; ok {
+			v := p.Value
+			values[i] = &v
+		}
+	}
+	fillGaps(values, fill)
+
+	chartData := &ChartData{Labels: []string{}, Values: []float64{}}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		chartData.Labels = append(chartData.Labels, bucketLabel(starts[i], b))
+		chartData.Values = append(chartData.Values, *v)
+	}
+	return chartData
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBucketAndAggregation(t *testing.T) {
+	b, err := ParseBucket("")
+	assert.NoError(t, err)
+	assert.Equal(t, repository.BucketDay, b)
+
+	_, err = ParseBucket("minute")
+	assert.Equal(t, ErrInvalidBucket, err)
+
+	a, err := ParseAggregation("avg")
+	assert.NoError(t, err)
+	assert.Equal(t, repository.AggAvg, a)
+
+	_, err = ParseAggregation("median")
+	assert.Equal(t, ErrInvalidAggregation, err)
+}
+
+func TestTruncateTime_WeekStartsMonday(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Seoul")
+	// 2024-03-17 is a Sunday
+	sunday := time.Date(2024, 3, 17, 13, 30, 0, 0, loc)
+
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, loc), truncateTime(sunday, repository.BucketWeek, loc))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, loc), truncateTime(sunday, repository.BucketMonth, loc))
+	assert.Equal(t, time.Date(2024, 3, 17, 13, 0, 0, 0, loc), truncateTime(sunday, repository.BucketHour, loc))
+}
+
+func TestBucketStarts_TooMany(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := bucketStarts(from, to, repository.BucketHour, time.UTC)
+	assert.Equal(t, ErrTooManyBuckets, err)
+
+	starts, err := bucketStarts(from, to, repository.BucketMonth, time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, starts, 48)
+}
+
+func TestAlignTimeSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	starts := []time.Time{day(1), day(2), day(3)}
+	points := []repository.TimeSeriesPoint{
+		{Bucket: day(1), Value: 10},
+		{Bucket: day(3), Value: 30},
+	}
+
+	// 합계는 빈 버킷을 0으로 채움
+	sum := alignTimeSeries(points, starts, repository.BucketDay, resolveFill(FillAuto, repository.AggSum))
+	assert.Equal(t, []string{"2024-01-01", "2024-01-02", "2024-01-03"}, sum.Labels)
+	assert.Equal(t, []float64{10, 0, 30}, sum.Values)
+
+	// 평균은 빈 버킷 생략
+	avg := alignTimeSeries(points, starts, repository.BucketDay, resolveFill(FillAuto, repository.AggAvg))
+	assert.Equal(t, []string{"2024-01-01", "2024-01-03"}, avg.Labels)
+	assert.Equal(t, []float64{10, 30}, avg.Values)
+
+	prev := alignTimeSeries(points, starts, repository.BucketDay, FillPrevious)
+	assert.Equal(t, []float64{10, 10, 30}, prev.Values)
+}
+
+func TestParseFillMode(t *testing.T) {
+	for _, s := range []string{"", "zero", "null", "previous"} {
+		f, err := ParseFillMode(s)
+		assert.NoError(t, err)
+		assert.Equal(t, FillMode(s), f)
+	}
+	_, err := ParseFillMode("linear")
+	assert.ErrorIs(t, err, ErrInvalidFill)
+	assert.Equal(t, FillNull, resolveFill(FillAuto, repository.AggMax))
+	assert.Equal(t, FillZero, resolveFill(FillZero, repository.AggAvg))
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/baltop/commet/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrWebhookEventForbidden 관리자만 구독할 수 있는 이벤트
+	ErrWebhookEventForbidden = errors.New("webhook event requires admin")
+	// ErrWebhookScope 관리자 전용 이벤트를 조직을 정하지 않고 보내려 함 (EmitToAdmins를 써야 함)
+	ErrWebhookScope = errors.New("admin-only webhook event needs explicit organizations")
+)
+
+const (
+	// 한 이벤트를 보내는 최대 시도 횟수 (30초부터 두 배씩, 약 1시간)
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second
+	// 연속으로 이만큼 실패한 엔드포인트는 자동 비활성화
+	webhookDisableAfter = 20
+	// 전송 중 서버가 죽어 delivering으로 남은 전송은 이 시간이 지나면 다시 보냄
+	webhookLease         = 2 * time.Minute
+	webhookTimeout       = 10 * time.Second
+	webhookResponseLimit = 1000
+	webhookDeliveryLog   = 50
+)
+
+// 수신 측에서 확인하는 헤더
+const (
+	WebhookSignatureHeader = "X-Commet-Signature"
+	WebhookEventHeader     = "X-Commet-Event"
+	WebhookDeliveryHeader  = "X-Commet-Delivery"
+)
+
+// WebhookEvent 웹훅 본문 (모든 이벤트 공통 형식)
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// JobDeliverWebhook 전송 한 건을 보내는 작업 (webhooks 큐)
+const JobDeliverWebhook = "webhook.deliver"
+
+type webhookDeliveryJob struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepositoryInterface
+	orgRepo     repository.OrganizationRepositoryInterface
+	jobs        *JobQueue
+	client      *http.Client
+	// adminEmails 관리자 전용 이벤트를 받는 조직의 소유자
+	adminEmails []string
+}
+
+// NewWebhookService jobs의 webhooks 큐에 전송 작업을 등록
+func NewWebhookService(webhookRepo repository.WebhookRepositoryInterface, orgRepo repository.OrganizationRepositoryInterface, jobs *JobQueue, client *http.Client) *WebhookService {
+	if client == nil {
+		client = newOutboundClient(webhookTimeout)
+	}
+	s := &WebhookService{webhookRepo: webhookRepo, orgRepo: orgRepo, jobs: jobs, client: client}
+	jobs.Register(JobDeliverWebhook, "webhooks", HandleJob(s.deliverJob))
+	return s
+}
+
+// UseAdmins 관리자 전용 이벤트(user.registered)를 받을 사용자 (ADMIN_EMAILS)
+func (s *WebhookService) UseAdmins(emails []string) {
+	s.adminEmails = emails
+}
+
+func (s *WebhookService) List(orgID uint) ([]models.WebhookEndpoint, error) {
+	return s.webhookRepo.ListEndpoints(orgID)
+}
+
+func (s *WebhookService) Get(orgID, id uint) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.webhookRepo.FindEndpoint(orgID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// Create 서명 키를 새로 만들어 엔드포인트 등록. URL은 공인 주소여야 하고,
+// 관리자 전용 이벤트는 admin일 때만 구독
+func (s *WebhookService) Create(orgID uint, admin bool, req *models.WebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	for _, event := range req.Events {
+		if models.AdminOnlyEvent(event) && !admin {
+			return nil, ErrWebhookEventForbidden
+		}
+	}
+	if err := CheckOutboundURL(context.Background(), req.URL); err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		OrganizationID: orgID,
+		URL:            req.URL,
+		Description:    req.Description,
+		Secret:         "whsec_" + randomHex(24),
+		Events:         strings.Join(req.Events, ","),
+		Enabled:        true,
+	}
+	if err := s.webhookRepo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// SetEnabled 다시 켜면 연속 실패 기록을 지우고 밀린 전송을 재개
+func (s *WebhookService) SetEnabled(orgID, id uint, enabled bool) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.Get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		err = s.webhookRepo.EnableEndpoint(endpoint)
+	} else {
+		endpoint.Enabled = false
+		err = s.webhookRepo.UpdateEndpoint(endpoint)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		s.resume(endpoint.ID)
+	}
+	return endpoint, nil
+}
+
+// resume 꺼져 있는 동안 쌓인 전송을 다시 대기열에 넣음
+func (s *WebhookService) resume(endpointID uint) {
+	deliveries, err := s.webhookRepo.ListUnfinished(endpointID)
+	if err != nil {
+		log.Printf("Webhook endpoint %d: failed to resume deliveries: %v", endpointID, err)
+		return
+	}
+	if err := s.enqueue(deliveries); err != nil {
+		log.Printf("Webhook endpoint %d: failed to resume deliveries: %v", endpointID, err)
+	}
+}
+
+func (s *WebhookService) Delete(orgID, id uint) error {
+	if _, err := s.Get(orgID, id); err != nil {
+		return err
+	}
+	return s.webhookRepo.DeleteEndpoint(id)
+}
+
+// Deliveries 최근 전송 기록 (최신순)
+func (s *WebhookService) Deliveries(orgID, id uint) ([]models.WebhookDelivery, error) {
+	if _, err := s.Get(orgID, id); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.ListDeliveries(id, webhookDeliveryLog)
+}
+
+// Redeliver 같은 이벤트를 새 전송으로 다시 보냄 (이벤트 ID 유지)
+func (s *WebhookService) Redeliver(orgID, id, deliveryID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.Get(orgID, id); err != nil {
+		return nil, err
+	}
+	original, err := s.webhookRepo.FindDelivery(id, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		EndpointID:    id,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	deliveries := []models.WebhookDelivery{delivery}
+	if err := s.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		return nil, err
+	}
+	if err := s.enqueue(deliveries); err != nil {
+		return nil, err
+	}
+	return &deliveries[0], nil
+}
+
+// Emit 이벤트를 구독 중인 엔드포인트마다 전송 대기열에 넣음. orgIDs가 nil이면 모든 조직
+// (관리자 전용 이벤트는 EmitToAdmins)
+func (s *WebhookService) Emit(eventType string, orgIDs []uint, data any) error {
+	if orgIDs == nil && models.AdminOnlyEvent(eventType) {
+		return ErrWebhookScope
+	}
+	endpoints, err := s.webhookRepo.ListEnabled(orgIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	event := WebhookEvent{ID: "evt_" + randomHex(12), Type: eventType, CreatedAt: now, Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, e := range endpoints {
+		if !e.Subscribed(eventType) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    e.ID,
+			EventID:       event.ID,
+			EventType:     eventType,
+			Payload:       models.JSON(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if err := s.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+	return s.enqueue(deliveries)
+}
+
+func (s *WebhookService) enqueue(deliveries []models.WebhookDelivery) error {
+	for _, d := range deliveries {
+		err := s.jobs.Enqueue(JobDeliverWebhook, webhookDeliveryJob{DeliveryID: d.ID}, EnqueueOptions{
+			RunAt:       d.NextAttemptAt,
+			MaxAttempts: webhookMaxAttempts,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmitForUser 사용자가 속한 조직에만 이벤트 발행
+func (s *WebhookService) EmitForUser(eventType string, userID uint, data any) error {
+	orgIDs, err := s.orgRepo.MemberOrgIDs(userID)
+	if err != nil {
+		return err
+	}
+	if len(orgIDs) == 0 {
+		return nil
+	}
+	return s.Emit(eventType, orgIDs, data)
+}
+
+// EmitToAdmins 관리자가 소유한 조직에만 이벤트 발행 (관리자가 없으면 보내지 않음)
+func (s *WebhookService) EmitToAdmins(eventType string, data any) error {
+	orgIDs, err := s.orgRepo.OwnedOrgIDs(s.adminEmails)
+	if err != nil {
+		return err
+	}
+	if len(orgIDs) == 0 {
+		return nil
+	}
+	return s.Emit(eventType, orgIDs, data)
+}
+
+// deliverJob 전송 한 번 시도. 실패하면 전송 기록에 남기고 백오프 뒤 작업을 다시 실행하게 함
+func (s *WebhookService) deliverJob(ctx context.Context, job webhookDeliveryJob) error {
+	d, busy, err := s.webhookRepo.StartAttempt(job.DeliveryID, time.Now(), webhookLease)
+	if err != nil {
+		return err
+	}
+	if busy {
+		return RetryJobAfter(errors.New("delivery in progress"), webhookLease)
+	}
+	if d == nil {
+		// 이미 끝났거나 엔드포인트가 꺼져 있음 (다시 켜면 resume)
+		return nil
+	}
+
+	started := time.Now()
+	status, body, err := s.post(ctx, &d.Endpoint, d)
+	if ctx.Err() != nil {
+		// 종료 중에 끊긴 전송은 실패로 세지 않고 대기 상태로 되돌림
+		if err := s.webhookRepo.AbortAttempt(d); err != nil {
+			log.Printf("Webhook delivery %d: failed to release: %v", d.ID, err)
+		}
+		return ctx.Err()
+	}
+
+	d.Attempts++
+	d.LastAttemptAt = &started
+	d.DurationMillis = time.Since(started).Milliseconds()
+	d.ResponseStatus = status
+	d.ResponseBody = body
+	d.Error = ""
+	succeeded := err == nil
+	var result error
+	if succeeded {
+		d.Status = models.DeliverySucceeded
+	} else {
+		d.Error = truncate(err.Error(), 500)
+		if d.Attempts >= webhookMaxAttempts {
+			d.Status = models.DeliveryFailed
+			result = PermanentJobError(err)
+		} else {
+			delay := webhookBackoff(d.Attempts)
+			d.Status = models.DeliveryRetrying
+			d.NextAttemptAt = started.Add(delay)
+			result = RetryJobAfter(err, delay)
+		}
+	}
+
+	reason := fmt.Sprintf("연속 %d회 전송 실패", webhookDisableAfter)
+	disabled, saveErr := s.webhookRepo.SaveAttempt(d, succeeded, webhookDisableAfter, reason)
+	if saveErr != nil {
+		return fmt.Errorf("save delivery %d: %w", d.ID, saveErr)
+	}
+	if disabled {
+		log.Printf("Webhook endpoint %d disabled after repeated failures", d.EndpointID)
+	}
+	return result
+}
+
+// post 서명한 본문을 보내고 응답 상태와 (잘린) 본문 반환. 2xx가 아니면 에러
+func (s *WebhookService) post(ctx context.Context, endpoint *models.WebhookEndpoint, d *models.WebhookDelivery) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "commet-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, d.EventType)
+	req.Header.Set(WebhookDeliveryHeader, d.EventID)
+	req.Header.Set(WebhookSignatureHeader, WebhookSignatureValue(endpoint.Secret, time.Now(), d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	body := strings.ToValidUTF8(string(raw), "")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, body, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// SignWebhook hex(HMAC-SHA256(secret, "<unix 초>.<본문>")). 타임스탬프를 포함해 재전송 공격을 막음
+func SignWebhook(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", ts.Unix())
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSignatureValue 서명 헤더 값 (t=<unix 초>,v1=<서명>)
+func WebhookSignatureValue(secret string, ts time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", ts.Unix(), SignWebhook(secret, ts, body))
+}
+
+// webhookBackoff attempt번째 실패 후 대기 시간 (30초, 1분, 2분, ...)
+func webhookBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	return webhookBaseBackoff << (attempt - 1)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// truncate 바이트 기준으로 자르되 깨진 UTF-8 문자는 버림
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
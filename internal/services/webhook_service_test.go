@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baltop/commet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepositoryInterface
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) ListEndpoints(orgID uint) ([]models.WebhookEndpoint, error) {
+	args := m.Called(orgID)
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListEnabled(orgIDs []uint) ([]models.WebhookEndpoint, error) {
+	args := m.Called(orgIDs)
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FindEndpoint(orgID, id uint) (*models.WebhookEndpoint, error) {
+	args := m.Called(orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return m.Called(endpoint).Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return m.Called(endpoint).Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteEndpoint(id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockWebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	return m.Called(deliveries).Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(endpointID uint, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(endpointID, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FindDelivery(endpointID, id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(endpointID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListUnfinished(endpointID uint) ([]models.WebhookDelivery, error) {
+	args := m.Called(endpointID)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) StartAttempt(id uint, now time.Time, lease time.Duration) (*models.WebhookDelivery, bool, error) {
+	args := m.Called(id, now, lease)
+	d, _ := args.Get(0).(*models.WebhookDelivery)
+	return d, args.Bool(1), args.Error(2)
+}
+
+func (m *MockWebhookRepository) AbortAttempt(delivery *models.WebhookDelivery) error {
+	return m.Called(delivery).Error(0)
+}
+
+func (m *MockWebhookRepository) SaveAttempt(delivery *models.WebhookDelivery, succeeded bool, disableAfter int, reason string) (bool, error) {
+	args := m.Called(delivery, succeeded, disableAfter, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) EnableEndpoint(endpoint *models.WebhookEndpoint) error {
+	return m.Called(endpoint).Error(0)
+}
+
+// MockOrganizationRepository is a mock implementation of OrganizationRepositoryInterface
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) ListForUser(userID uint) ([]models.Organization, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) MemberOrgIDs(userID uint) ([]uint, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) OwnedOrgIDs(emails []string) ([]uint, error) {
+	args := m.Called(emails)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) CreateWithOwner(org *models.Organization, ownerID uint) error {
+	return m.Called(org, ownerID).Error(0)
+}
+
+func newTestWebhookService(client *http.Client) *WebhookService {
+	jobs := NewJobQueue(nil, 0)
+	jobs.AddQueue("webhooks", QueueOptions{})
+	return NewWebhookService(nil, nil, jobs, client)
+}
+
+func TestSignWebhook(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"evt_1"}`)
+
+	sig := SignWebhook("whsec_test", ts, body)
+	assert.Len(t, sig, 64)
+	assert.Equal(t, sig, SignWebhook("whsec_test", ts, body))
+	assert.NotEqual(t, sig, SignWebhook("whsec_other", ts, body))
+	// 타임스탬프가 다르면 서명도 다름 (재전송 방지)
+	assert.NotEqual(t, sig, SignWebhook("whsec_test", ts.Add(time.Second), body))
+
+	assert.Equal(t, "t=1700000000,v1="+sig, WebhookSignatureValue("whsec_test", ts, body))
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(0))
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, time.Minute, webhookBackoff(2))
+	assert.Equal(t, 32*time.Minute, webhookBackoff(7))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 10))
+	assert.Equal(t, "ab", truncate("abc", 2))
+	// "가"는 3바이트. 중간에서 자르면 깨진 문자를 버림
+	assert.Equal(t, "가", truncate("가나", 4))
+}
+
+func TestWebhookEndpoint_Subscribed(t *testing.T) {
+	e := models.WebhookEndpoint{Events: "alert.fired,alert.resolved"}
+	assert.True(t, e.Subscribed(models.EventAlertFired))
+	assert.False(t, e.Subscribed(models.EventMetricIngested))
+	assert.False(t, (&models.WebhookEndpoint{}).Subscribed(models.EventAlertFired))
+}
+
+func TestWebhookPost_SignsBody(t *testing.T) {
+	secret := "whsec_test"
+	var gotSig, gotEvent, gotDelivery string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get(WebhookSignatureHeader)
+		gotEvent = r.Header.Get(WebhookEventHeader)
+		gotDelivery = r.Header.Get(WebhookDeliveryHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	s := newTestWebhookService(srv.Client())
+	endpoint := &models.WebhookEndpoint{URL: srv.URL, Secret: secret}
+	d := &models.WebhookDelivery{EventID: "evt_1", EventType: models.EventAlertFired, Payload: models.JSON(`{"id":"evt_1"}`)}
+
+	status, body, err := s.post(context.Background(), endpoint, d)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body)
+	assert.Equal(t, models.EventAlertFired, gotEvent)
+	assert.Equal(t, "evt_1", gotDelivery)
+
+	// 수신 측 검증 절차: t와 본문으로 서명을 다시 계산해 비교
+	parts := strings.SplitN(gotSig, ",", 2)
+	require.Len(t, parts, 2)
+	unix, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+	require.NoError(t, err)
+	expected := SignWebhook(secret, time.Unix(unix, 0), gotBody)
+	assert.True(t, hmac.Equal([]byte(expected), []byte(strings.TrimPrefix(parts[1], "v1="))))
+}
+
+func TestWebhookPost_NonSuccessStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	s := newTestWebhookService(srv.Client())
+	d := &models.WebhookDelivery{Payload: models.JSON(`{}`)}
+
+	status, body, err := s.post(context.Background(), &models.WebhookEndpoint{URL: srv.URL}, d)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Equal(t, "boom\n", body)
+}
+
+func TestWebhookCreate_RejectsUnsafeRequests(t *testing.T) {
+	s := newTestWebhookService(nil)
+
+	// 가입자 정보가 담긴 이벤트는 관리자만 구독
+	_, err := s.Create(1, false, &models.WebhookEndpointRequest{URL: "https://8.8.8.8/hook", Events: []string{models.EventUserRegistered}})
+	assert.ErrorIs(t, err, ErrWebhookEventForbidden)
+
+	// 내부망 주소는 등록하지 않음 (저장소까지 가지 않음)
+	_, err = s.Create(1, true, &models.WebhookEndpointRequest{URL: "http://169.254.169.254/", Events: []string{models.EventAlertFired}})
+	assert.ErrorIs(t, err, ErrUnsafeURL)
+}
+
+// newMockedWebhookService 저장소를 mock으로 바꾼 서비스. 전송 기록에는 1부터 ID를 붙이고 작업 등록은 그냥 받음
+func newMockedWebhookService() (*WebhookService, *MockWebhookRepository, *MockOrganizationRepository, *[]models.WebhookDelivery) {
+	webhookRepo := new(MockWebhookRepository)
+	orgRepo := new(MockOrganizationRepository)
+	jobRepo := new(MockJobRepository)
+	jobRepo.On("Enqueue", mock.Anything).Return(nil)
+	jobs := NewJobQueue(jobRepo, 0)
+	jobs.AddQueue("webhooks", QueueOptions{})
+
+	var created []models.WebhookDelivery
+	webhookRepo.On("CreateDeliveries", mock.Anything).Run(func(args mock.Arguments) {
+		deliveries := args.Get(0).([]models.WebhookDelivery)
+		for i := range deliveries {
+			deliveries[i].ID = uint(len(created) + 1)
+			created = append(created, deliveries[i])
+		}
+	}).Return(nil)
+	return NewWebhookService(webhookRepo, orgRepo, jobs, nil), webhookRepo, orgRepo, &created
+}
+
+func TestWebhookEmitForUser_OnlyMemberOrgs(t *testing.T) {
+	s, webhookRepo, orgRepo, created := newMockedWebhookService()
+	orgRepo.On("MemberOrgIDs", uint(5)).Return([]uint{1, 2}, nil)
+	orgRepo.On("MemberOrgIDs", uint(6)).Return([]uint{}, nil)
+	webhookRepo.On("ListEnabled", []uint{1, 2}).Return([]models.WebhookEndpoint{
+		{ID: 10, OrganizationID: 1, Events: "alert.fired"},
+		{ID: 11, OrganizationID: 2, Events: "alert.resolved"},
+	}, nil)
+
+	require.NoError(t, s.EmitForUser(models.EventAlertFired, 5, map[string]any{"rule": 1}))
+	require.Len(t, *created, 1)
+	assert.Equal(t, uint(10), (*created)[0].EndpointID)
+	assert.Equal(t, models.EventAlertFired, (*created)[0].EventType)
+
+	// 조직이 없는 사용자의 이벤트는 어디에도 보내지 않음 (nil이면 전체 조직이 되므로)
+	require.NoError(t, s.EmitForUser(models.EventAlertFired, 6, nil))
+	webhookRepo.AssertNumberOfCalls(t, "ListEnabled", 1)
+}
+
+func TestWebhookEmitToAdmins_OnlyAdminOwnedOrgs(t *testing.T) {
+	s, webhookRepo, orgRepo, created := newMockedWebhookService()
+	orgRepo.On("OwnedOrgIDs", []string(nil)).Return([]uint(nil), nil)
+	orgRepo.On("OwnedOrgIDs", []string{"admin@example.com"}).Return([]uint{3}, nil)
+	webhookRepo.On("ListEnabled", []uint{3}).Return([]models.WebhookEndpoint{{ID: 30, OrganizationID: 3, Events: "user.registered"}}, nil)
+
+	// 관리자가 없으면 보내지 않음
+	require.NoError(t, s.EmitToAdmins(models.EventUserRegistered, nil))
+	webhookRepo.AssertNotCalled(t, "ListEnabled", mock.Anything)
+
+	s.UseAdmins([]string{"admin@example.com"})
+	require.NoError(t, s.EmitToAdmins(models.EventUserRegistered, map[string]any{"email": "new@example.com"}))
+	require.Len(t, *created, 1)
+	assert.Equal(t, uint(30), (*created)[0].EndpointID)
+
+	// 가입자 정보를 모든 조직에 보내는 호출은 거절
+	assert.ErrorIs(t, s.Emit(models.EventUserRegistered, nil, nil), ErrWebhookScope)
+	webhookRepo.AssertNotCalled(t, "ListEnabled", []uint(nil))
+}
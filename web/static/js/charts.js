@@ -0,0 +1,379 @@
+// 범용 차트 렌더링 - 서버(services.ChartRenderer)가 만든 Chart.js 설정에
+// 현재 테마(라이트/다크)의 색상과 툴팁/눈금 콜백을 적용
+(function () {
+    const palettes = {
+        light: ['#6366F1', '#8B5CF6', '#EC4899', '#F59E0B', '#10B981', '#0EA5E9', '#F43F5E', '#84CC16'],
+        dark: ['#818CF8', '#A78BFA', '#F472B6', '#FBBF24', '#34D399', '#38BDF8', '#FB7185', '#A3E635']
+    };
+
+    function isDark() {
+        return document.documentElement.classList.contains('dark');
+    }
+
+    function alpha(hex, a) {
+        const n = parseInt(hex.slice(1), 16);
+        return 'rgba(' + (n >> 16) + ', ' + ((n >> 8) & 255) + ', ' + (n & 255) + ', ' + a + ')';
+    }
+
+    function verticalGradient(ctx, color, from, to) {
+        const gradient = ctx.createLinearGradient(0, 0, 0, 280);
+        gradient.addColorStop(0, alpha(color, from));
+        gradient.addColorStop(1, alpha(color, to));
+        return gradient;
+    }
+
+    function formatValue(meta, value) {
+        return (meta.unit || '') + Number(value).toLocaleString() + (meta.suffix || '');
+    }
+
+    function applyColors(ctx, config, meta, colors, dark) {
+        const datasets = config.data.datasets;
+        if (meta.colorMode === 'perPoint') {
+            datasets.forEach(ds => {
+                ds.backgroundColor = ds.data.map((_, i) => {
+                    const color = colors[i % colors.length];
+                    return meta.gradient ? verticalGradient(ctx, color, 1, 0.6) : color;
+                });
+            });
+        } else if (meta.colorMode === 'gauge') {
+            datasets[0].backgroundColor = [colors[0], dark ? 'rgba(75, 85, 99, 0.5)' : 'rgba(243, 244, 246, 1)'];
+        } else {
+            datasets.forEach((ds, i) => {
+                // 비교·예측 계열은 현재 계열 색을 흐리게, 예측구간은 옅은 음영
+                if (ds.commetRole) {
+                    const color = alpha(colors[0], ds.commetRole === 'forecastBand' ? 0.12 : 0.55);
+                    ds.borderColor = ds.pointBackgroundColor = ds.pointHoverBackgroundColor = color;
+                    ds.backgroundColor = color;
+                    return;
+                }
+                const color = colors[i % colors.length];
+                ds.borderColor = color;
+                ds.pointBackgroundColor = color;
+                ds.pointBorderColor = dark ? '#1f2937' : '#fff';
+                ds.pointHoverBackgroundColor = color;
+                ds.pointHoverBorderColor = dark ? '#1f2937' : '#fff';
+                ds.backgroundColor = meta.gradient ? verticalGradient(ctx, color, 0.3, 0.01) : color;
+            });
+        }
+    }
+
+    // compareChange 비교 값 대비 증감률 (비교 값이 없거나 0이면 빈 문자열)
+    function compareChange(value, previous) {
+        if (previous === null || previous === undefined || previous === 0) return '';
+        const pct = (value - previous) / Math.abs(previous) * 100;
+        return ' (' + (pct >= 0 ? '▲' : '▼') + Math.abs(pct).toFixed(1) + '%)';
+    }
+
+    function applyTheme(config, meta, dark) {
+        const options = config.options = config.options || {};
+        options.responsive = true;
+        if (options.maintainAspectRatio === undefined) options.maintainAspectRatio = false;
+
+        const textColor = '#9CA3AF';
+        const gridColor = dark ? 'rgba(75, 85, 99, 0.5)' : 'rgba(243, 244, 246, 1)';
+        Object.values(options.scales || {}).forEach(scale => {
+            scale.ticks = Object.assign({ color: textColor, font: { size: 12 } }, scale.ticks);
+            scale.grid = Object.assign({ color: gridColor, drawBorder: false }, scale.grid);
+        });
+        if (options.scales && options.scales.y && meta.type !== 'scatter') {
+            options.scales.y.ticks.callback = value => formatValue(meta, value);
+        }
+        if (meta.xLabels) {
+            options.scales.x.ticks.callback = value => meta.xLabels[value] || '';
+        }
+
+        options.plugins = Object.assign({
+            legend: {
+                display: meta.legend === 'top',
+                labels: {
+                    color: textColor,
+                    usePointStyle: true,
+                    filter: (item, data) => data.datasets[item.datasetIndex].commetRole !== 'forecastBand'
+                }
+            },
+            tooltip: {
+                enabled: meta.colorMode !== 'gauge',
+                backgroundColor: dark ? 'rgba(31, 41, 55, 0.95)' : 'rgba(17, 24, 39, 0.9)',
+                titleColor: '#fff',
+                bodyColor: '#fff',
+                borderColor: dark ? 'rgba(129, 140, 248, 0.5)' : 'rgba(99, 102, 241, 0.5)',
+                borderWidth: 1,
+                cornerRadius: 8,
+                padding: 12,
+                // 예측구간 데이터셋은 예측 항목에 함께 표시
+                filter: item => item.dataset.commetRole !== 'forecastBand',
+                callbacks: {
+                    title: items => meta.xLabels ? items.map(item => meta.xLabels[item.raw.x]) : undefined,
+                    label: context => {
+                        const raw = meta.xLabels ? context.raw.y : context.raw;
+                        const ds = context.dataset, i = context.dataIndex;
+                        let prefix = meta.colorMode === 'perPoint' ? context.label : ds.label;
+                        if (ds.commetRole === 'compare') {
+                            prefix += ' (' + meta.compareLabels[i] + ')';
+                        }
+                        let text = ' ' + (prefix ? prefix + ': ' : '') + formatValue(meta, raw);
+                        if (ds.commetRole === 'forecast') {
+                            const band = context.chart.data.datasets.filter(d => d.commetRole === 'forecastBand');
+                            if (band.length === 2 && band[0].data[i] !== band[1].data[i]) {
+                                text += ' (' + Math.round(meta.confidence * 100) + '% ' +
+                                    formatValue(meta, band[0].data[i]) + ' ~ ' + formatValue(meta, band[1].data[i]) + ')';
+                            }
+                        }
+                        if (ds.commetRole || !meta.compare) return text;
+                        const compare = context.chart.data.datasets.find(d => d.commetRole === 'compare');
+                        return text + compareChange(raw, compare.data[i]);
+                    },
+                    // 이상치가 있는 항목은 예상값과 점수를 덧붙임
+                    afterLabel: context => {
+                        if (context.dataset.commetRole || context.datasetIndex !== 0) return '';
+                        const a = (meta.anomalies || []).find(a => a.x === context.dataIndex);
+                        if (!a) return '';
+                        return ' 이상치' + (a.count > 1 ? ' ' + a.count + '건' : '') + ': ' + formatValue(meta, a.value) +
+                            ' (예상 ' + formatValue(meta, a.expected) + ', 점수 ' + a.score + ', ' + a.time + ')';
+                    }
+                }
+            }
+        }, options.plugins);
+    }
+
+    // annotationSpan 주석의 x축 픽셀 구간 (그림 영역 밖은 가장자리로)
+    function annotationSpan(chart, a) {
+        const scale = chart.scales.x, area = chart.chartArea;
+        const clamp = x => Math.min(Math.max(x, area.left), area.right);
+        return [clamp(scale.getPixelForValue(a.x)), clamp(scale.getPixelForValue(a.x2 === undefined ? a.x : a.x2))];
+    }
+
+    // 주석 - 기간은 데이터 아래 음영, 시점은 데이터 위 점선. 마우스를 올리면 내용·시각·작성자 표시
+    const annotationPlugin = {
+        id: 'commetAnnotations',
+        beforeDatasetsDraw(chart, args, opts) {
+            const area = chart.chartArea, ctx = chart.ctx;
+            ctx.save();
+            (opts.items || []).forEach(a => {
+                if (a.x2 === undefined) return;
+                const [x1, x2] = annotationSpan(chart, a);
+                ctx.fillStyle = alpha(a.color, 0.12);
+                ctx.fillRect(x1, area.top, Math.max(x2 - x1, 1), area.bottom - area.top);
+            });
+            ctx.restore();
+        },
+        afterDatasetsDraw(chart, args, opts) {
+            const area = chart.chartArea, ctx = chart.ctx;
+            ctx.save();
+            ctx.font = '11px sans-serif';
+            ctx.textBaseline = 'top';
+            ctx.lineWidth = 1.5;
+            ctx.setLineDash([4, 3]);
+            (opts.items || []).forEach(a => {
+                const [x] = annotationSpan(chart, a);
+                ctx.strokeStyle = ctx.fillStyle = a.color;
+                if (a.x2 === undefined) {
+                    ctx.beginPath();
+                    ctx.moveTo(x, area.top);
+                    ctx.lineTo(x, area.bottom);
+                    ctx.stroke();
+                }
+                ctx.fillText(a.text.length > 24 ? a.text.slice(0, 23) + '…' : a.text, x + 4, area.top + 2);
+            });
+            ctx.restore();
+        },
+        afterEvent(chart, args, opts) {
+            const e = args.event;
+            if (e.type !== 'mousemove') return;
+            const hit = (opts.items || []).find(a => {
+                const [x1, x2] = annotationSpan(chart, a);
+                return a.x2 === undefined ? Math.abs(e.x - x1) <= 4 : e.x >= x1 && e.x <= x2;
+            });
+            chart.canvas.title = hit ? hit.text + '\n' + hit.time + (hit.author ? ' · ' + hit.author : '') : '';
+        }
+    };
+
+    // 이상치 - 첫 데이터셋의 해당 항목에 빨간 고리
+    const anomalyPlugin = {
+        id: 'commetAnomalies',
+        afterDatasetsDraw(chart, args, opts) {
+            const points = chart.getDatasetMeta(0).data, ctx = chart.ctx;
+            ctx.save();
+            ctx.strokeStyle = '#EF4444';
+            ctx.lineWidth = 2;
+            (opts.items || []).forEach(a => {
+                const el = points[a.x];
+                if (!el) return;
+                const { x, y } = el.tooltipPosition();
+                ctx.beginPath();
+                ctx.arc(x, y, 7, 0, 2 * Math.PI);
+                ctx.stroke();
+            });
+            ctx.restore();
+        }
+    };
+
+    // 실시간 스트림 - 화면에 있는 차트들의 카테고리를 하나의 EventSource로 구독
+    const live = {
+        source: null,
+        key: '',
+        lastEventId: '',
+        charts: new Set(),
+
+        register(chart) {
+            this.charts.add(chart);
+            this.schedule();
+        },
+        unregister(chart) {
+            this.charts.delete(chart);
+            this.schedule();
+        },
+        // 위젯이 한꺼번에 로드되므로 카테고리 목록이 정해진 뒤 한 번만 다시 연결
+        schedule() {
+            clearTimeout(this.timer);
+            this.timer = setTimeout(() => this.connect(), 200);
+        },
+        connect() {
+            const categories = [...new Set([...this.charts].map(c => c.live.category))].sort();
+            const key = categories.join('\n');
+            if (key === this.key && this.source) return;
+            this.key = key;
+            if (this.source) this.source.close();
+            this.source = null;
+            if (!categories.length) return;
+
+            const params = new URLSearchParams();
+            categories.forEach(c => params.append('category', c));
+            // 구독 카테고리가 바뀌어 새로 연결할 때도 놓친 이벤트부터 이어 받음
+            if (this.lastEventId) params.set('lastEventId', this.lastEventId);
+
+            const source = new EventSource('/dashboard/stream?' + params.toString());
+            source.addEventListener('data', e => {
+                this.lastEventId = e.lastEventId;
+                const event = JSON.parse(e.data);
+                this.charts.forEach(c => c.apply(event));
+            });
+            // 누락분이 너무 많으면 서버가 reset을 보냄: 차트 전체를 다시 조회
+            source.addEventListener('reset', e => {
+                this.lastEventId = e.lastEventId;
+                this.charts.forEach(c => c.refresh());
+            });
+            this.source = source;
+        }
+    };
+
+    // combine 집계 함수별로 기존 값에 새 값을 반영. 다시 계산해야 하면 undefined
+    function combine(agg, current, value) {
+        switch (agg) {
+            case 'sum': return current + value;
+            case 'count': return current + 1;
+            case 'min': return Math.min(current, value);
+            case 'max': return Math.max(current, value);
+        }
+    }
+
+    const liveTypes = ['line', 'area', 'bar', 'pie', 'doughnut'];
+
+    window.commetChart = function () {
+        // Chart 인스턴스는 Alpine 반응형 프록시 밖에 둠 (프록시를 거친 update 호출 방지)
+        let chart, ctx, meta, colors, dark, handle, refreshTimer;
+
+        return {
+            legendItems: [],
+            init() {
+                const config = JSON.parse(this.$refs.config.textContent);
+                meta = JSON.parse(this.$refs.meta.textContent);
+                dark = isDark();
+                colors = palettes[dark ? 'dark' : 'light'];
+                ctx = this.$refs.canvas.getContext('2d');
+
+                applyColors(ctx, config, meta, colors, dark);
+                applyTheme(config, meta, dark);
+                config.plugins = [];
+                if (meta.annotations) {
+                    config.plugins.push(annotationPlugin);
+                    config.options.plugins.commetAnnotations = { items: meta.annotations };
+                }
+                if (meta.anomalies) {
+                    config.plugins.push(anomalyPlugin);
+                    config.options.plugins.commetAnomalies = { items: meta.anomalies };
+                }
+                this.updateLegend(config.data);
+
+                chart = new Chart(ctx, config);
+                if (meta.live) {
+                    handle = { live: meta.live, apply: e => this.apply(e), refresh: () => this.refresh() };
+                    live.register(handle);
+                }
+            },
+            destroy() {
+                if (handle) live.unregister(handle);
+                if (chart) chart.destroy();
+                clearTimeout(refreshTimer);
+            },
+            updateLegend(data) {
+                if (meta.legend !== 'side') return;
+                // 여러 계열은 계열마다 마지막 값
+                if (meta.series) {
+                    this.legendItems = data.datasets.map((ds, i) => {
+                        const last = ds.data.filter(v => v !== null && v !== undefined).pop();
+                        const value = last === undefined ? '-' : formatValue(meta, typeof last === 'object' ? last.y : last);
+                        return { label: ds.label, value: value, color: colors[i % colors.length], hidden: chart ? !chart.isDatasetVisible(i) : false };
+                    });
+                    return;
+                }
+                const ds = data.datasets[0];
+                this.legendItems = data.labels.map((label, i) => ({
+                    label: label,
+                    value: formatValue(meta, ds.data[i]),
+                    color: colors[i % colors.length]
+                }));
+            },
+            // toggle 옆 범례에서 계열 숨기기/보이기
+            toggle(i) {
+                chart.setDatasetVisibility(i, !chart.isDatasetVisible(i));
+                chart.update();
+                this.updateLegend(chart.data);
+            },
+            // apply 새 데이터 포인트를 차트에 바로 반영. 직접 반영할 수 없으면 partial을 다시 조회
+            apply(event) {
+                const spec = meta.live;
+                if (event.category !== spec.category) return;
+                const t = Date.parse(event.recordedAt);
+                if ((spec.from && t < spec.from) || (spec.to && t >= spec.to)) return;
+                if (spec.mode === 'refresh' || !liveTypes.includes(meta.type)) return this.refresh();
+
+                const data = chart.data;
+                const values = data.datasets[0].data;
+                if (spec.mode === 'raw') {
+                    data.labels.push(event.label);
+                    values.push(event.value);
+                } else {
+                    let i;
+                    if (spec.mode === 'time') {
+                        if (!spec.buckets) return this.refresh();
+                        for (i = spec.buckets.length - 1; i >= 0 && spec.buckets[i] > t; i--);
+                        if (i < 0) return;
+                    } else {
+                        i = data.labels.indexOf(event.label);
+                    }
+                    if (i < 0) {
+                        data.labels.push(event.label);
+                        values.push(spec.agg === 'count' ? 1 : event.value);
+                    } else {
+                        const next = combine(spec.agg, values[i], event.value);
+                        if (next === undefined) return this.refresh();
+                        values[i] = next;
+                    }
+                }
+
+                applyColors(ctx, { data: data }, meta, colors, dark);
+                this.updateLegend(data);
+                chart.update();
+            },
+            // refresh 이벤트가 몰려도 한 번만 다시 조회 (위젯 컨테이너의 refresh 트리거)
+            refresh() {
+                clearTimeout(refreshTimer);
+                refreshTimer = setTimeout(() => {
+                    const container = this.$el.closest('[hx-get]');
+                    if (container) htmx.trigger(container, 'refresh');
+                }, 2000);
+            }
+        };
+    };
+})();